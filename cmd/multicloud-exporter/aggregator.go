@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"multicloud-exporter/internal/aggregator"
+	"multicloud-exporter/internal/logger"
+	"multicloud-exporter/internal/metrics"
+	"multicloud-exporter/internal/utils"
+)
+
+// runAggregator 实现 aggregator 子命令：按 utils.ClusterPeers 的方式（CLUSTER_DISCOVERY=headless/file）发现分片实例，
+// 每次被抓取时并发抓取各实例的 /metrics，合并指标族（检测 HELP/类型/标签集合冲突）后统一暴露，
+// Prometheus 只需抓取聚合器一个目标。不加载云账号配置，也不执行采集。
+// 返回进程退出码：0 正常退出，1 服务错误，2 参数错误。
+func runAggregator(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("aggregator", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	listen := fs.String("listen", ":9102", "聚合器监听地址")
+	peersFlag := fs.String("peers", "", "逗号分隔的分片实例地址 host:port，为空时按 CLUSTER_DISCOVERY 发现")
+	peerPort := fs.String("peer-port", getEnvOrDefault("EXPORTER_PORT", "9101"), "发现的实例地址未带端口时使用的端口")
+	path := fs.String("path", aggregator.DefaultPath, "分片实例的指标路径")
+	timeout := fs.Duration("timeout", aggregator.DefaultTimeout, "单个实例的抓取超时")
+	peerLabel := fs.String("peer-label", aggregator.DefaultPeerLabel, "为合并样本附加的实例标签名，为空时不附加（同名样本视为重复）")
+	maxStale := fs.Duration("max-stale", 0, "实例抓取失败时沿用其上次成功结果的最长时间，0 表示不沿用")
+	minRatio := fs.Float64("min-peer-ratio", 0, "成功实例占比低于该值时返回 503，0 表示总是返回部分结果")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *minRatio < 0 || *minRatio > 1 {
+		fmt.Fprintf(stderr, "min-peer-ratio 须在 0-1 之间: %v\n", *minRatio)
+		return 2
+	}
+
+	static := splitPeers(*peersFlag, *peerPort)
+	peers := func() []string {
+		if len(static) > 0 {
+			return static
+		}
+		return splitPeers(strings.Join(utils.ClusterPeers(), ","), *peerPort)
+	}
+	agg := aggregator.New(aggregator.Options{
+		Peers:        peers,
+		Path:         *path,
+		Timeout:      *timeout,
+		PeerLabel:    *peerLabel,
+		MaxStale:     *maxStale,
+		MinPeerRatio: *minRatio,
+	})
+
+	// 聚合器自身只暴露聚合指标，避免与各实例的同名运行时指标混在同一指标族
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		metrics.AggregatorPeerUp,
+		metrics.AggregatorPeerScrapeDuration,
+		metrics.AggregatorPeerSamples,
+		metrics.AggregatorConflicts,
+		metrics.AggregatorPartialResults,
+	)
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", agg.Handler(reg))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "healthy", "time": time.Now().Unix()})
+	})
+	mux.HandleFunc("/api/aggregator/peers", handleAggregatorPeers(agg))
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	srv := &http.Server{Addr: *listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	ctxLog := logger.NewContextLogger("Aggregator", "resource_type", "HTTPServer")
+	ctxLog.Infof("聚合器启动，监听 %s，实例=%v", *listen, peers())
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(stderr, "聚合器服务错误: %v\n", err)
+		return 1
+	}
+	fmt.Fprintln(stdout, "聚合器已停止")
+	return 0
+}
+
+// handleAggregatorPeers 返回最近一次聚合的各实例状态与冲突统计；尚未聚合时立即聚合一次
+func handleAggregatorPeers(agg *aggregator.Aggregator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res := agg.Last()
+		if res == nil {
+			res, _ = agg.Scrape(r.Context())
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(res)
+	}
+}
+
+// splitPeers 解析逗号分隔的实例列表，未带端口的地址补上 port
+func splitPeers(list, port string) []string {
+	var out []string
+	for _, p := range strings.Split(list, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if !strings.Contains(p, "://") {
+			if _, _, err := net.SplitHostPort(p); err != nil {
+				p = net.JoinHostPort(p, port)
+			}
+		}
+		out = append(out, p)
+	}
+	return out
+}
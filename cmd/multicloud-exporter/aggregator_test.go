@@ -0,0 +1,22 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestSplitPeers(t *testing.T) {
+	got := splitPeers(" 10.0.0.1, pod-1:9200,,fd00::1,http://agg:9101 ", "9101")
+	want := []string{"10.0.0.1:9101", "pod-1:9200", "[fd00::1]:9101", "http://agg:9101"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("splitPeers() = %v, want %v", got, want)
+	}
+}
+
+func TestRunAggregator_InvalidRatio(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := runAggregator([]string{"-min-peer-ratio", "2"}, &stdout, &stderr); code != 2 {
+		t.Fatalf("exit code = %d, want 2", code)
+	}
+}
@@ -63,7 +63,9 @@ func startCollectionLoop(ctx context.Context, cfg *config.Config, coll *collecto
 		// 执行首次采集
 		ctxLog := logger.NewContextLogger("Collection", "resource_type", "FirstRun")
 		ctxLog.Info("开始首次采集...")
-		coll.Collect()
+		if err := coll.Run(ctx, collector.RunRequest{Trigger: collector.TriggerSchedule}); err != nil {
+			ctxLog.Warnf("首次采集跳过: %v", err)
+		}
 		ctxLog.Info("首次采集完成，进入定时采集循环")
 		// ========== 智能首次采集结束 ==========
 
@@ -87,15 +89,29 @@ func startCollectionLoop(ctx context.Context, cfg *config.Config, coll *collecto
 					versionChanged = true
 				}
 
-				// 版本变化时重置指标
+				// 版本变化时重置指标，同时清空产品调度状态，确保被重置的指标在本轮重新采集
 				if versionChanged {
 					metrics.Reset()
+					coll.ResetSchedules()
 				}
 
-				// 执行采集
-				coll.Collect()
+				// 执行采集：手动触发的采集进行中时本轮跳过（计入 multicloud_collection_cycles_skipped_total）
+				if err := coll.Run(ctx, collector.RunRequest{Trigger: collector.TriggerSchedule}); err != nil {
+					collectionLog.Warnf("本轮采集跳过: %v", err)
+					continue
+				}
 				duration := time.Since(start)
-				metrics.CollectionDuration.Observe(duration.Seconds())
+				metrics.CollectionCycleDuration.Observe(duration.Seconds())
+
+				// 本轮耗时超过采集间隔时丢弃积压的触发，避免采集首尾相接
+				if duration >= interval {
+					select {
+					case <-ticker.C:
+						metrics.CollectionCyclesSkipped.WithLabelValues(collector.TriggerSchedule, "overrun").Inc()
+						collectionLog.Warnf("采集耗时 %v 超过采集间隔 %v，跳过积压的一轮", duration, interval)
+					default:
+					}
+				}
 
 				collectionLog.Infof("==========================================")
 				collectionLog.Infof("采集周期完成，总耗时: %v", duration)
@@ -106,7 +122,7 @@ func startCollectionLoop(ctx context.Context, cfg *config.Config, coll *collecto
 }
 
 // initializeDiscovery 初始化发现服务并返回管理器
-func initializeDiscovery(cfg *config.Config) (*discovery.Manager, error) {
+func initializeDiscovery(cfg *config.Config, opts discovery.StartOptions) (*discovery.Manager, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("config is nil")
 	}
@@ -115,7 +131,7 @@ func initializeDiscovery(cfg *config.Config) (*discovery.Manager, error) {
 	ctx := context.Background()
 	discoveryStart := time.Now()
 
-	mgr.Start(ctx)
+	mgr.StartWithOptions(ctx, opts)
 
 	discoveryDuration := time.Since(discoveryStart)
 
@@ -134,8 +150,8 @@ func initializeDiscovery(cfg *config.Config) (*discovery.Manager, error) {
 	productInfo := buildProductStats(productsByProvider)
 
 	ctxLog := logger.NewContextLogger("Discovery", "resource_type", "Manager")
-	ctxLog.Infof("发现服务启动完成，总耗时: %v，发现产品数量: %d%s，版本=%d",
-		discoveryDuration, discoveredTotalProducts, productInfo, mgr.Version())
+	ctxLog.Infof("发现服务启动完成，总耗时: %v，发现产品数量: %d%s，版本=%d，来源=%s",
+		discoveryDuration, discoveredTotalProducts, productInfo, mgr.Version(), mgr.Status().Source)
 
 	// 如果配置中没有产品，使用发现的产品
 	if len(cfg.ProductsByProvider) == 0 && len(prods) > 0 {
@@ -168,6 +184,18 @@ func buildProductStats(productsByProvider map[string]int) string {
 	return info.String()
 }
 
+// getCollectionTimeout 获取单轮采集截止时间：server.collection_timeout，未配置或无效时等于采集间隔
+func getCollectionTimeout(cfg *config.Config, interval time.Duration) time.Duration {
+	if server := cfg.GetServer(); server != nil && server.CollectionTimeout != "" {
+		if d, err := utils.ParseDuration(server.CollectionTimeout); err == nil && d > 0 {
+			return d
+		}
+		ctxLog := logger.NewContextLogger("Collection", "resource_type", "Config")
+		ctxLog.Warnf("警告: 配置中的 collection_timeout 无效: %q，使用采集间隔 %v", server.CollectionTimeout, interval)
+	}
+	return interval
+}
+
 // getScrapeInterval 获取采集间隔（优先级：环境变量 > 配置文件 > 默认值）
 func getScrapeInterval(cfg *config.Config) time.Duration {
 	interval := 60 * time.Second
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"multicloud-exporter/internal/collector"
+	"multicloud-exporter/internal/logger"
+	providerscommon "multicloud-exporter/internal/providers/common"
+)
+
+const (
+	// 资源清单接口默认与最大分页大小
+	defaultInventoryLimit = 100
+	maxInventoryLimit     = 1000
+)
+
+// inventoryCSVHeader CSV 导出列
+var inventoryCSVHeader = []string{"provider", "account_id", "region", "namespace", "resource_type", "resource_id", "name", "code_name", "tags", "metadata", "updated_at"}
+
+// runInventory 实现 inventory 子命令：执行资源发现与一轮采集以枚举资源，按 CSV 或 JSON 导出资源清单。
+// 返回进程退出码：0 成功，1 存在失败账号或输出失败，2 参数或配置错误。
+func runInventory(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("inventory", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	provider := fs.String("provider", "", "仅枚举指定云平台（aliyun、tencent、aws、huawei）")
+	resource := fs.String("resource", "", "仅枚举指定资源类型（如 clb、s3）")
+	account := fs.String("account", "", "仅枚举指定账号 ID")
+	format := fs.String("format", "csv", "输出格式：csv | json")
+	output := fs.String("output", "-", "输出文件路径，- 表示标准输出")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *format != "csv" && *format != "json" {
+		fmt.Fprintf(stderr, "未知的输出格式 %q，可选: csv, json\n", *format)
+		return 2
+	}
+
+	coll, code := setupOneShot(*output, stderr)
+	if coll == nil {
+		return code
+	}
+	defer logger.Sync()
+	coll.CollectFilteredAccount(context.Background(), *provider, *resource, *account)
+
+	items, _ := providerscommon.QueryInventory(coll.Inventory(), providerscommon.InventoryQuery{Provider: *provider, AccountID: *account})
+	if err := writeOutput(*output, func(w io.Writer) error {
+		return writeInventory(w, items, *format)
+	}, stdout); err != nil {
+		fmt.Fprintf(stderr, "输出资源清单失败: %v\n", err)
+		return 1
+	}
+	return reportFailedAccounts(coll, stderr)
+}
+
+// writeInventory 按格式输出资源清单
+func writeInventory(w io.Writer, items []providerscommon.InventoryItem, format string) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(items)
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(inventoryCSVHeader); err != nil {
+		return err
+	}
+	for _, it := range items {
+		var meta string
+		if len(it.Metadata) > 0 {
+			bs, err := json.Marshal(it.Metadata)
+			if err != nil {
+				return err
+			}
+			meta = string(bs)
+		}
+		record := []string{it.Provider, it.AccountID, it.Region, it.Namespace, it.ResourceType, it.ResourceID,
+			it.Name, it.CodeName, formatTags(it.Tags), meta, it.UpdatedAt.Format(time.RFC3339)}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatTags 将标签格式化为按键排序的 k=v;k=v
+func formatTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+tags[k])
+	}
+	return strings.Join(parts, ";")
+}
+
+// parseInventoryQuery 解析资源清单查询参数：
+// provider、account_id、region、namespace、resource_type、q（模糊匹配 ID/名称/code_name）、
+// tag（可重复，key=value 或 key）、offset、limit（默认 100，最大 1000）
+func parseInventoryQuery(v url.Values) (providerscommon.InventoryQuery, error) {
+	q := providerscommon.InventoryQuery{
+		Provider:     v.Get("provider"),
+		AccountID:    v.Get("account_id"),
+		Region:       v.Get("region"),
+		Namespace:    v.Get("namespace"),
+		ResourceType: v.Get("resource_type"),
+		Search:       v.Get("q"),
+		Limit:        defaultInventoryLimit,
+	}
+	for _, t := range v["tag"] {
+		if q.Tags == nil {
+			q.Tags = make(map[string]string)
+		}
+		k, val, _ := strings.Cut(t, "=")
+		q.Tags[k] = val
+	}
+	if s := v.Get("offset"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return q, fmt.Errorf("invalid offset: %q", s)
+		}
+		q.Offset = n
+	}
+	if s := v.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			return q, fmt.Errorf("invalid limit: %q", s)
+		}
+		if n > maxInventoryLimit {
+			n = maxInventoryLimit
+		}
+		q.Limit = n
+	}
+	return q, nil
+}
+
+// handleDiscoveryResources 资源清单查询处理器，支持过滤与分页，format=csv 时导出当前页
+func handleDiscoveryResources(coll *collector.Collector) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q, err := parseInventoryQuery(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		items, total := providerscommon.QueryInventory(coll.Inventory(), q)
+
+		if r.URL.Query().Get("format") == "csv" {
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			w.Header().Set("X-Total-Count", strconv.Itoa(total))
+			_ = writeInventory(w, items, "csv")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(struct {
+			Total  int                             `json:"total"`
+			Offset int                             `json:"offset"`
+			Limit  int                             `json:"limit"`
+			Items  []providerscommon.InventoryItem `json:"items"`
+		}{Total: total, Offset: q.Offset, Limit: q.Limit, Items: items})
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"multicloud-exporter/internal/collector"
+	"multicloud-exporter/internal/config"
+	"multicloud-exporter/internal/discovery"
+	"multicloud-exporter/internal/providers"
+	providerscommon "multicloud-exporter/internal/providers/common"
+)
+
+type inventoryStub struct{}
+
+func (inventoryStub) Collect(config.CloudAccount)   {}
+func (inventoryStub) GetDefaultResources() []string { return nil }
+func (inventoryStub) Inventory() []providerscommon.InventoryItem {
+	ts := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []providerscommon.InventoryItem{
+		{Provider: "stub", AccountID: "a1", Region: "r1", Namespace: "ns", ResourceType: "clb", ResourceID: "lb-1", Name: "web", Tags: map[string]string{"env": "prod", "app": "x"}, Metadata: map[string]interface{}{"arn": "arn:1"}, UpdatedAt: ts},
+		{Provider: "stub", AccountID: "a1", Region: "r1", Namespace: "ns", ResourceType: "clb", ResourceID: "lb-2", UpdatedAt: ts},
+		{Provider: "stub", AccountID: "a1", Region: "r2", Namespace: "ns", ResourceType: "s3", ResourceID: "bucket", UpdatedAt: ts},
+	}
+}
+
+func init() {
+	providers.RegisterLegacy("inventory_stub", func(*config.Config, *discovery.Manager) providers.LegacyProvider { return inventoryStub{} })
+}
+
+func TestParseInventoryQuery(t *testing.T) {
+	v, _ := url.ParseQuery("provider=aws&tag=env=prod&tag=team&offset=5&limit=5000")
+	q, err := parseInventoryQuery(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Provider != "aws" || q.Offset != 5 || q.Limit != maxInventoryLimit {
+		t.Fatalf("unexpected query: %+v", q)
+	}
+	if q.Tags["env"] != "prod" || q.Tags["team"] != "" || len(q.Tags) != 2 {
+		t.Fatalf("unexpected tags: %v", q.Tags)
+	}
+	for _, bad := range []string{"offset=-1", "limit=0", "limit=abc"} {
+		v, _ := url.ParseQuery(bad)
+		if _, err := parseInventoryQuery(v); err == nil {
+			t.Fatalf("%s: expected error", bad)
+		}
+	}
+}
+
+func TestWriteInventoryCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeInventory(&buf, inventoryStub{}.Inventory()[:1], "csv"); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || len(rows[1]) != len(inventoryCSVHeader) {
+		t.Fatalf("unexpected rows: %v", rows)
+	}
+	row := rows[1]
+	if row[5] != "lb-1" || row[8] != "app=x;env=prod" || row[9] != `{"arn":"arn:1"}` || row[10] != "2026-01-01T00:00:00Z" {
+		t.Fatalf("unexpected row: %v", row)
+	}
+}
+
+func TestHandleDiscoveryResources(t *testing.T) {
+	coll := collector.NewCollector(&config.Config{}, nil)
+	h := handleDiscoveryResources(coll)
+
+	rec := httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodGet, "/api/discovery/resources?provider=stub&resource_type=clb&limit=1&offset=1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var resp struct {
+		Total  int                             `json:"total"`
+		Offset int                             `json:"offset"`
+		Limit  int                             `json:"limit"`
+		Items  []providerscommon.InventoryItem `json:"items"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Total != 2 || resp.Offset != 1 || resp.Limit != 1 || len(resp.Items) != 1 || resp.Items[0].ResourceID != "lb-2" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodGet, "/api/discovery/resources?provider=stub&format=csv", nil))
+	if rec.Header().Get("X-Total-Count") != "3" {
+		t.Fatalf("X-Total-Count = %q", rec.Header().Get("X-Total-Count"))
+	}
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil || len(rows) != 4 {
+		t.Fatalf("unexpected csv: %v %v", rows, err)
+	}
+
+	rec = httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodGet, "/api/discovery/resources?limit=x", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid limit should be 400, got %d", rec.Code)
+	}
+}
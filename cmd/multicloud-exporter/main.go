@@ -8,7 +8,9 @@ import (
 	"os/signal"
 	"syscall"
 
+	"multicloud-exporter/internal/cache"
 	"multicloud-exporter/internal/collector"
+	"multicloud-exporter/internal/discovery"
 	"multicloud-exporter/internal/logger"
 )
 
@@ -20,6 +22,23 @@ var (
 
 // main 启动 HTTP 服务并周期性采集各云资源指标
 func main() {
+	// 子命令：validate 校验配置文件后退出
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(runValidate(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	// 子命令：once 执行一轮采集并输出指标后退出
+	if len(os.Args) > 1 && os.Args[1] == "once" {
+		os.Exit(runOnce(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	// 子命令：inventory 枚举资源并导出资源清单后退出
+	if len(os.Args) > 1 && os.Args[1] == "inventory" {
+		os.Exit(runInventory(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	// 子命令：aggregator 抓取并合并各分片实例的指标后统一暴露
+	if len(os.Args) > 1 && os.Args[1] == "aggregator" {
+		os.Exit(runAggregator(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	// 设置信号处理，实现优雅关闭
 	setupSignalHandler()
 
@@ -43,15 +62,27 @@ func main() {
 	// 2. 记录账号统计
 	logAccountStats(cfg)
 
-	// 3. 加载指标映射
+	// 3. 加载指标映射与自定义标签
 	setupMetricMappings(cfg)
+	setupCustomLabels(cfg)
+	setupRateLimits(cfg)
+	setupAdaptiveConcurrency(cfg)
+	setupCircuitBreakers(cfg)
+	if err := setupSharding(cfg); err != nil {
+		ctxLog := logger.NewContextLogger("Main", "resource_type", "Sharding")
+		ctxLog.Errorf("Failed to setup sharding: %v", err)
+		os.Exit(1)
+	}
 
 	// 4. 获取服务端口和采集间隔
 	port := getServerPort(cfg)
 	interval := getScrapeInterval(cfg)
+	setupBudgets(cfg, interval)
+	setupCache(cfg)
+	setupMembership(shutdownCtx, cfg, port)
 
 	// 5. 初始化发现管理器（必须成功）
-	mgr, err := initializeDiscovery(cfg)
+	mgr, err := initializeDiscovery(cfg, discovery.StartOptions{})
 	if err != nil {
 		ctxLog := logger.NewContextLogger("Main", "resource_type", "Discovery")
 		ctxLog.Errorf("Failed to initialize discovery: %v", err)
@@ -60,15 +91,17 @@ func main() {
 
 	// 6. 创建采集器
 	coll := collector.NewCollector(cfg, mgr)
+	coll.SetCycleTimeout(getCollectionTimeout(cfg, interval))
 
 	// 7. 注册 Prometheus 指标
+	setupReplicaLabel()
 	registerPrometheusMetrics()
 
 	// 8. 启动周期性采集（支持优雅停止）
 	startCollectionLoop(shutdownCtx, cfg, coll, mgr, interval)
 
 	// 9. 设置 HTTP 路由
-	setupHTTPHandlers(cfg, coll, mgr)
+	setupHTTPHandlers(shutdownCtx, cfg, coll, mgr)
 
 	// 10. 启动 HTTP 服务器
 	ctxLog := logger.NewContextLogger("Main", "resource_type", "HTTPServer")
@@ -98,6 +131,11 @@ func main() {
 
 	// 给 HTTP 服务器一点时间处理最后的请求
 	shutdownCancel()
+
+	if err := cache.FlushAll(); err != nil {
+		ctxLog := logger.NewContextLogger("Main", "resource_type", "Cache")
+		ctxLog.Warnf("保存缓存失败: %v", err)
+	}
 }
 
 // setupSignalHandler 设置信号处理器
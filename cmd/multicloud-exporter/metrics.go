@@ -1,18 +1,41 @@
 package main
 
 import (
-	"github.com/prometheus/client_golang/prometheus"
 	"multicloud-exporter/internal/metrics"
 )
 
-// registerPrometheusMetrics 注册所有 Prometheus 指标
+// registerPrometheusMetrics 注册所有 Prometheus 指标（通过 metrics.Registerer，带上 replica 等外部标签）
 func registerPrometheusMetrics() {
-	prometheus.MustRegister(metrics.ResourceMetric)
-	prometheus.MustRegister(metrics.RequestTotal)
-	prometheus.MustRegister(metrics.RequestDuration)
-	prometheus.MustRegister(metrics.NamespaceMetric)
-	prometheus.MustRegister(metrics.RateLimitTotal)
-	prometheus.MustRegister(metrics.CollectionDuration)
-	prometheus.MustRegister(metrics.CacheSizeBytes)
-	prometheus.MustRegister(metrics.CacheEntriesTotal)
+	reg := metrics.Registerer()
+	reg.MustRegister(metrics.ResourceMetric)
+	reg.MustRegister(metrics.RequestTotal)
+	reg.MustRegister(metrics.RequestDuration)
+	reg.MustRegister(metrics.NamespaceMetric)
+	reg.MustRegister(metrics.RateLimitTotal)
+	reg.MustRegister(metrics.RateLimitWait)
+	reg.MustRegister(metrics.ConcurrencyLimit)
+	reg.MustRegister(metrics.BudgetUsage)
+	reg.MustRegister(metrics.BudgetExceeded)
+	reg.MustRegister(metrics.BudgetProjectedMonthlyCost)
+	reg.MustRegister(metrics.CircuitBreakerState)
+	reg.MustRegister(metrics.ClusterMembers)
+	reg.MustRegister(metrics.ClusterGeneration)
+	reg.MustRegister(metrics.ClusterMembershipChanges)
+	reg.MustRegister(metrics.ClusterPeerUp)
+	reg.MustRegister(metrics.CollectionCycleDuration)
+	reg.MustRegister(metrics.CollectionCyclesSkipped)
+	reg.MustRegister(metrics.CollectionCyclesOverrun)
+	reg.MustRegister(metrics.CollectionInProgress)
+	reg.MustRegister(metrics.CollectionUp)
+	reg.MustRegister(metrics.CollectionLastSuccess)
+	reg.MustRegister(metrics.CollectionDuration)
+	reg.MustRegister(metrics.CollectionErrorsTotal)
+	reg.MustRegister(metrics.CacheSizeBytes)
+	reg.MustRegister(metrics.CacheEntriesTotal)
+	reg.MustRegister(metrics.DiscoveryRefreshErrorsTotal)
+	reg.MustRegister(metrics.DiscoveryLastSuccess)
+	reg.MustRegister(metrics.ScheduleSkippedTotal)
+	reg.MustRegister(metrics.RegionDiscoveryStatus)
+	reg.MustRegister(metrics.RegionSkippedTotal)
+	reg.MustRegister(metrics.RegionInfo)
 }
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"multicloud-exporter/internal/collector"
+	"multicloud-exporter/internal/config"
+	"multicloud-exporter/internal/discovery"
+	"multicloud-exporter/internal/logger"
+)
+
+// runOnce 实现 once 子命令：加载配置、执行资源发现与一轮采集后输出指标并退出，
+// 不启动 HTTP 服务与定时采集。任一账号采集失败时返回非零退出码。
+// 返回进程退出码：0 成功，1 存在失败账号或输出失败，2 参数或配置错误。
+func runOnce(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("once", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	provider := fs.String("provider", "", "仅采集指定云平台（aliyun、tencent、aws、huawei）")
+	resource := fs.String("resource", "", "仅采集指定资源类型（如 clb、s3）")
+	account := fs.String("account", "", "仅采集指定账号 ID")
+	format := fs.String("format", "prometheus", "输出格式：prometheus | openmetrics | json")
+	output := fs.String("output", "-", "输出文件路径，- 表示标准输出")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	switch *format {
+	case "prometheus", "openmetrics", "json":
+	default:
+		fmt.Fprintf(stderr, "未知的输出格式 %q，可选: prometheus, openmetrics, json\n", *format)
+		return 2
+	}
+
+	coll, code := setupOneShot(*output, stderr)
+	if coll == nil {
+		return code
+	}
+	defer logger.Sync()
+	coll.CollectFilteredAccount(context.Background(), *provider, *resource, *account)
+
+	if err := writeOutput(*output, func(w io.Writer) error {
+		families, err := prometheus.DefaultGatherer.Gather()
+		if err != nil {
+			return fmt.Errorf("汇总指标失败: %v", err)
+		}
+		return writeFamilies(w, exporterFamilies(families), *format)
+	}, stdout); err != nil {
+		fmt.Fprintf(stderr, "输出指标失败: %v\n", err)
+		return 1
+	}
+
+	return reportFailedAccounts(coll, stderr)
+}
+
+// setupOneShot 为一次性子命令（once、inventory）加载配置、执行资源发现并创建采集器，不启动 HTTP 服务。
+// 输出写入标准输出时日志改写到标准错误，避免混入输出。失败时返回 nil 与退出码。
+func setupOneShot(output string, stderr io.Writer) (*collector.Collector, int) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "加载配置失败: %v\n", err)
+		return nil, 2
+	}
+	if server := cfg.GetServer(); server != nil && server.Log != nil {
+		logCfg := *server.Log
+		if output == "-" && logCfg.Output != "file" {
+			logCfg.Output = "stderr"
+		}
+		logger.Init(&logCfg)
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(stderr, "配置校验失败: %v\n", err)
+		return nil, 2
+	}
+
+	setupMetricMappings(cfg)
+	setupCustomLabels(cfg)
+	setupRateLimits(cfg)
+	setupAdaptiveConcurrency(cfg)
+	setupCircuitBreakers(cfg)
+	if err := setupSharding(cfg); err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return nil, 2
+	}
+	// 一次性运行同样受每日预算约束，调用计入持久化用量
+	setupBudgets(cfg, getScrapeInterval(cfg))
+	setupCache(cfg)
+	// 一次性运行不能依赖后台重新发现，发现目录存在时也同步刷新
+	mgr, err := initializeDiscovery(cfg, discovery.StartOptions{Revalidate: true})
+	if err != nil {
+		fmt.Fprintf(stderr, "初始化资源发现失败: %v\n", err)
+		return nil, 2
+	}
+	coll := collector.NewCollector(cfg, mgr)
+	setupReplicaLabel()
+	registerPrometheusMetrics()
+	return coll, 0
+}
+
+// writeOutput 将 write 的内容写入文件或标准输出（path 为 - 时）
+func writeOutput(path string, write func(io.Writer) error, stdout io.Writer) error {
+	if path == "-" {
+		return write(stdout)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// reportFailedAccounts 输出失败账号，存在失败账号时返回 1
+func reportFailedAccounts(coll *collector.Collector, stderr io.Writer) int {
+	if failed := coll.FailedAccounts(); len(failed) > 0 {
+		status := coll.GetStatus()
+		for _, k := range failed {
+			fmt.Fprintf(stderr, "账号采集失败: %s (%s)\n", k, status.LastResults[k].Error)
+		}
+		return 1
+	}
+	return 0
+}
+
+// exporterFamilies 过滤掉 Go 运行时与进程指标，仅保留导出器自身指标
+func exporterFamilies(families []*dto.MetricFamily) []*dto.MetricFamily {
+	out := families[:0]
+	for _, mf := range families {
+		name := mf.GetName()
+		if strings.HasPrefix(name, "go_") || strings.HasPrefix(name, "process_") || strings.HasPrefix(name, "promhttp_") {
+			continue
+		}
+		out = append(out, mf)
+	}
+	return out
+}
+
+// writeFamilies 按指定格式输出指标
+func writeFamilies(w io.Writer, families []*dto.MetricFamily, format string) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(familiesJSON(families))
+	}
+	fmtType := expfmt.FmtText
+	if format == "openmetrics" {
+		fmtType = expfmt.FmtOpenMetrics_1_0_0
+	}
+	enc := expfmt.NewEncoder(w, fmtType)
+	for _, mf := range families {
+		if err := enc.Encode(mf); err != nil {
+			return err
+		}
+	}
+	if closer, ok := enc.(expfmt.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// jsonFamily JSON 输出的指标族
+type jsonFamily struct {
+	Name    string       `json:"name"`
+	Help    string       `json:"help,omitempty"`
+	Type    string       `json:"type"`
+	Metrics []jsonSample `json:"metrics"`
+}
+
+// jsonSample JSON 输出的单个样本；直方图与摘要输出 count/sum
+type jsonSample struct {
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  *float64          `json:"value,omitempty"`
+	Count  *uint64           `json:"count,omitempty"`
+	Sum    *float64          `json:"sum,omitempty"`
+}
+
+func familiesJSON(families []*dto.MetricFamily) []jsonFamily {
+	out := make([]jsonFamily, 0, len(families))
+	for _, mf := range families {
+		jf := jsonFamily{
+			Name:    mf.GetName(),
+			Help:    mf.GetHelp(),
+			Type:    strings.ToLower(mf.GetType().String()),
+			Metrics: make([]jsonSample, 0, len(mf.GetMetric())),
+		}
+		for _, m := range mf.GetMetric() {
+			s := jsonSample{}
+			if len(m.GetLabel()) > 0 {
+				s.Labels = make(map[string]string, len(m.GetLabel()))
+				for _, lp := range m.GetLabel() {
+					s.Labels[lp.GetName()] = lp.GetValue()
+				}
+			}
+			switch {
+			case m.Gauge != nil:
+				s.Value = m.Gauge.Value
+			case m.Counter != nil:
+				s.Value = m.Counter.Value
+			case m.Untyped != nil:
+				s.Value = m.Untyped.Value
+			case m.Histogram != nil:
+				s.Count, s.Sum = m.Histogram.SampleCount, m.Histogram.SampleSum
+			case m.Summary != nil:
+				s.Count, s.Sum = m.Summary.SampleCount, m.Summary.SampleSum
+			}
+			jf.Metrics = append(jf.Metrics, s)
+		}
+		out = append(out, jf)
+	}
+	return out
+}
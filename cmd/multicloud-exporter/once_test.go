@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func testFamilies(t *testing.T) *prometheus.Registry {
+	t.Helper()
+	reg := prometheus.NewRegistry()
+	g := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "clb_active_connection", Help: "活跃连接数"}, []string{"resource_id"})
+	g.WithLabelValues("lb-1").Set(3)
+	reg.MustRegister(g, prometheus.NewGoCollector())
+	return reg
+}
+
+func TestWriteFamilies(t *testing.T) {
+	families, err := testFamilies(t).Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	families = exporterFamilies(families)
+	if len(families) != 1 {
+		t.Fatalf("go runtime metrics should be filtered, got %d families", len(families))
+	}
+
+	var buf bytes.Buffer
+	if err := writeFamilies(&buf, families, "prometheus"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `clb_active_connection{resource_id="lb-1"} 3`) {
+		t.Fatalf("unexpected text output:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := writeFamilies(&buf, families, "openmetrics"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(buf.String(), "# EOF\n") {
+		t.Fatalf("openmetrics output should end with # EOF:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := writeFamilies(&buf, families, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var out []jsonFamily
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 1 || out[0].Type != "gauge" || *out[0].Metrics[0].Value != 3 || out[0].Metrics[0].Labels["resource_id"] != "lb-1" {
+		t.Fatalf("unexpected json output: %+v", out)
+	}
+}
+
+func TestRunOnce_InvalidFormat(t *testing.T) {
+	var out, errOut bytes.Buffer
+	if code := runOnce([]string{"-format", "xml"}, &out, &errOut); code != 2 {
+		t.Fatalf("expected exit code 2, got %d", code)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"multicloud-exporter/internal/config"
+	providerscommon "multicloud-exporter/internal/providers/common"
+)
+
+// regionManagerFor 返回按 server.region_discovery 创建的共享区域管理器，未配置时返回 nil
+func regionManagerFor(cfg *config.Config) providerscommon.RegionManager {
+	if cfg == nil || cfg.GetServer() == nil {
+		return nil
+	}
+	return providerscommon.SharedRegionManager(cfg.GetServer().RegionDiscovery)
+}
+
+// handleRegions 查看区域管理器状态：统计与逐条 (provider, account, region, namespace) 记录，
+// 可按 provider、account 过滤；未配置 region_discovery 时 enabled=false
+func handleRegions(cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		rm := regionManagerFor(cfg)
+		if rm == nil {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": false})
+			return
+		}
+		q := r.URL.Query()
+		entries := rm.Entries(q.Get("provider"), q.Get("account"))
+		if entries == nil {
+			entries = []providerscommon.RegionEntry{}
+		}
+		_ = json.NewEncoder(w).Encode(struct {
+			Enabled bool                               `json:"enabled"`
+			Stats   providerscommon.RegionManagerStats `json:"stats"`
+			Entries []providerscommon.RegionEntry      `json:"entries"`
+		}{Enabled: true, Stats: rm.GetStats(), Entries: entries})
+	}
+}
+
+// handleRegionsRediscover 强制重新发现（POST）：指定 region 时重置该区域全部命名空间，否则重置账号全部区域，
+// 下一轮采集时重新探测；account 必填
+func handleRegionsRediscover(cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rm, ok := regionAdminPrecheck(w, r, cfg)
+		if !ok {
+			return
+		}
+		q := r.URL.Query()
+		provider, account, region := q.Get("provider"), q.Get("account"), q.Get("region")
+		if account == "" {
+			http.Error(w, "account is required", http.StatusBadRequest)
+			return
+		}
+		var marked int
+		if region != "" {
+			marked = rm.MarkRegionForRediscovery(provider, account, region)
+		} else {
+			marked = rm.MarkAccountForRediscovery(provider, account)
+		}
+		regionAdminCommit(w, map[string]interface{}{
+			"provider": provider, "account": account, "region": region, "marked": marked,
+		})
+	}
+}
+
+// handleRegionsReset 删除区域状态（POST），可按 provider、account 过滤，均为空时清空全部记录
+func handleRegionsReset(cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rm, ok := regionAdminPrecheck(w, r, cfg)
+		if !ok {
+			return
+		}
+		q := r.URL.Query()
+		provider, account := q.Get("provider"), q.Get("account")
+		removed := rm.Reset(provider, account)
+		regionAdminCommit(w, map[string]interface{}{
+			"provider": provider, "account": account, "removed": removed,
+		})
+	}
+}
+
+// regionAdminPrecheck 变更类端点仅接受 POST，要求已配置管理端点认证（未配置时 authWrapper 不做校验）
+// 且已配置 region_discovery
+func regionAdminPrecheck(w http.ResponseWriter, r *http.Request, cfg *config.Config) (providerscommon.RegionManager, bool) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil, false
+	}
+	if len(collectAuthPairs(cfg)) == 0 {
+		http.Error(w, "admin auth is not configured", http.StatusForbidden)
+		return nil, false
+	}
+	rm := regionManagerFor(cfg)
+	if rm == nil {
+		http.Error(w, "region_discovery is not configured", http.StatusNotFound)
+		return nil, false
+	}
+	return rm, true
+}
+
+// regionAdminCommit 变更后立即持久化并刷新区域指标，再返回结果
+func regionAdminCommit(w http.ResponseWriter, result map[string]interface{}) {
+	if err := providerscommon.SaveRegionStatus(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	providerscommon.PublishRegionMetrics()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"crypto/subtle"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,6 +17,7 @@ import (
 	"multicloud-exporter/internal/discovery"
 	"multicloud-exporter/internal/logger"
 	"multicloud-exporter/internal/metrics"
+	"multicloud-exporter/internal/utils"
 )
 
 const (
@@ -23,8 +27,8 @@ const (
 	maxConcurrentSubs = 100
 )
 
-// setupHTTPHandlers 设置所有 HTTP 处理器
-func setupHTTPHandlers(cfg *config.Config, coll *collector.Collector, mgr *discovery.Manager) {
+// setupHTTPHandlers 设置所有 HTTP 处理器，ctx 结束时取消手动触发的采集
+func setupHTTPHandlers(ctx context.Context, cfg *config.Config, coll *collector.Collector, mgr *discovery.Manager) {
 	// Prometheus 指标端点
 	http.Handle("/metrics", promhttp.Handler())
 
@@ -35,11 +39,22 @@ func setupHTTPHandlers(cfg *config.Config, coll *collector.Collector, mgr *disco
 	authWrapper := createAuthWrapper(cfg)
 
 	// 管理端点（需要认证）
-	http.HandleFunc("/collect", authWrapper(handleCollect(coll)))
+	http.HandleFunc("/collect", authWrapper(handleCollect(ctx, coll)))
 	http.HandleFunc("/status", authWrapper(handleStatus(coll)))
 	http.HandleFunc("/api/discovery/config", authWrapper(handleDiscoveryConfig(mgr)))
 	http.HandleFunc("/api/discovery/stream", authWrapper(handleDiscoveryStream(mgr)))
 	http.HandleFunc("/api/discovery/status", authWrapper(handleDiscoveryStatus(mgr)))
+	http.HandleFunc("/api/discovery/resources", authWrapper(handleDiscoveryResources(coll)))
+	http.HandleFunc("/api/shard", authWrapper(handleShard(coll)))
+	http.HandleFunc("/api/cluster", authWrapper(handleCluster()))
+	http.HandleFunc("/api/regions", authWrapper(handleRegions(cfg)))
+	http.HandleFunc("/api/regions/rediscover", authWrapper(handleRegionsRediscover(cfg)))
+	http.HandleFunc("/api/regions/reset", authWrapper(handleRegionsReset(cfg)))
+
+	// 成员心跳端点（由 server.cluster.token 校验，不使用管理端点认证）
+	if m := utils.ActiveMembership(); m != nil {
+		http.HandleFunc(utils.HeartbeatPath, m.Handler())
+	}
 }
 
 // handleHealthz 健康检查处理器（深度检查）
@@ -75,18 +90,41 @@ func handleHealthz(coll *collector.Collector, mgr *discovery.Manager) http.Handl
 	}
 }
 
-// handleCollect 手动触发采集处理器
-func handleCollect(coll *collector.Collector) http.HandlerFunc {
+// handleCollect 手动触发采集处理器；采集在后台执行，不随请求结束取消，随服务关闭（ctx）取消。
+// 其他采集进行中时返回 409，queue=true 时排队并返回 202；同范围采集进行中时合并到该采集。
+func handleCollect(ctx context.Context, coll *collector.Collector) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		provider := r.URL.Query().Get("provider")
-		resource := r.URL.Query().Get("resource")
-		go coll.CollectFiltered(provider, resource)
+		req := collector.RunRequest{
+			Provider: r.URL.Query().Get("provider"),
+			Resource: r.URL.Query().Get("resource"),
+			Account:  r.URL.Query().Get("account"),
+			Trigger:  collector.TriggerManual,
+		}
+		queue := r.URL.Query().Get("queue") == "true"
+		outcome, err := coll.Start(ctx, req, queue)
 
 		w.Header().Set("Content-Type", "application/json")
+		if errors.Is(err, collector.ErrCycleInProgress) {
+			w.WriteHeader(http.StatusConflict)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"status":  "in_progress",
+				"running": coll.Running(),
+			})
+			return
+		}
+		status := "triggered"
+		switch outcome {
+		case collector.RunCoalesced:
+			status = "coalesced"
+		case collector.RunQueued:
+			status = "queued"
+			w.WriteHeader(http.StatusAccepted)
+		}
 		_ = json.NewEncoder(w).Encode(map[string]string{
-			"status":   "triggered",
-			"provider": provider,
-			"resource": resource,
+			"status":   status,
+			"provider": req.Provider,
+			"resource": req.Resource,
+			"account":  req.Account,
 		})
 	}
 }
@@ -99,6 +137,51 @@ func handleStatus(coll *collector.Collector) http.HandlerFunc {
 	}
 }
 
+// shardKeyStatus 分片键归属及本实例资源清单中该键的资源数
+type shardKeyStatus struct {
+	utils.ShardKey
+	Resources int `json:"resources"`
+}
+
+// handleShard 查看本实例的分片归属：哈希环参数、本分片负责的 AccountID|Region|Namespace 键及资源数，
+// all=true 时返回本实例已知的全部键；last_rebalance 为最近一次分片数变化时迁移的键数
+func handleShard(coll *collector.Collector) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		total, index := utils.ClusterConfig()
+		st := utils.ShardSnapshot(total, index, r.URL.Query().Get("all") != "true")
+		resources := make(map[string]int)
+		for _, item := range coll.Inventory() {
+			resources[item.AccountID+"|"+item.Region+"|"+item.Namespace]++
+		}
+		keys := make([]shardKeyStatus, 0, len(st.Keys))
+		for _, k := range st.Keys {
+			keys = append(keys, shardKeyStatus{ShardKey: k, Resources: resources[k.Key]})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(struct {
+			utils.ShardStatus
+			Keys []shardKeyStatus `json:"keys"`
+		}{ShardStatus: st, Keys: keys})
+	}
+}
+
+// handleCluster 查看心跳成员管理状态：生效视图与代数、待生效的候选视图及各对端心跳；未启用时 enabled=false
+func handleCluster() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		m := utils.ActiveMembership()
+		if m == nil {
+			total, index := utils.ClusterConfig()
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"enabled": false, "total": total, "index": index})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(struct {
+			Enabled bool `json:"enabled"`
+			utils.MembershipStatus
+		}{Enabled: true, MembershipStatus: m.Status()})
+	}
+}
+
 // handleDiscoveryConfig 获取发现配置处理器
 func handleDiscoveryConfig(mgr *discovery.Manager) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -121,7 +204,10 @@ func handleDiscoveryConfig(mgr *discovery.Manager) http.HandlerFunc {
 	}
 }
 
-// handleDiscoveryStream 发现配置 SSE 流处理器（添加超时和并发控制）
+// handleDiscoveryStream 发现变更 SSE 流处理器（添加超时和并发控制）。
+// 连接建立时发送 init，随后推送 products/resources 事件（id 为事件 ID）；
+// 携带 Last-Event-ID（请求头或 last_event_id 参数）重连时先回放缓冲中的后续事件，
+// 所需事件已被淘汰时发送 reset，客户端应重新拉取 /api/discovery/config
 func handleDiscoveryStream(mgr *discovery.Manager) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// 检查当前订阅数是否超限
@@ -133,6 +219,8 @@ func handleDiscoveryStream(mgr *discovery.Manager) http.HandlerFunc {
 			return
 		}
 
+		lastID, resume := parseLastEventID(r)
+
 		w.Header().Set("Content-Type", "text/event-stream")
 		w.Header().Set("Cache-Control", "no-cache")
 		w.Header().Set("Connection", "keep-alive")
@@ -142,6 +230,7 @@ func handleDiscoveryStream(mgr *discovery.Manager) http.HandlerFunc {
 		ctx, cancel := context.WithTimeout(r.Context(), maxSSETimeout)
 		defer cancel()
 
+		// 先订阅再回放，避免两者之间产生的事件丢失
 		ch := mgr.Subscribe()
 		defer mgr.Unsubscribe(ch)
 
@@ -149,14 +238,12 @@ func handleDiscoveryStream(mgr *discovery.Manager) http.HandlerFunc {
 		ctxLog.Infof("SSE 连接建立，当前订阅数=%d", subsCount+1)
 
 		// 发送初始版本
-		initPayload := struct {
-			Version int64 `json:"version"`
-		}{Version: mgr.Version()}
-		bs, _ := json.Marshal(initPayload)
-		_, _ = w.Write([]byte("event: init\n"))
-		_, _ = w.Write([]byte("data: "))
-		_, _ = w.Write(bs)
-		_, _ = w.Write([]byte("\n\n"))
+		head := mgr.LastEventID()
+		writeSSE(w, 0, "init", streamCursor{Version: mgr.Version(), LastEventID: head})
+		if !resume {
+			lastID = head
+		}
+		lastID = sendChanges(w, mgr, lastID)
 		if fl != nil {
 			fl.Flush()
 		}
@@ -171,15 +258,7 @@ func handleDiscoveryStream(mgr *discovery.Manager) http.HandlerFunc {
 				return
 
 			case <-ch:
-				// 配置更新
-				payload := struct {
-					Version int64 `json:"version"`
-				}{Version: mgr.Version()}
-				bs, _ := json.Marshal(payload)
-				_, _ = w.Write([]byte("event: update\n"))
-				_, _ = w.Write([]byte("data: "))
-				_, _ = w.Write(bs)
-				_, _ = w.Write([]byte("\n\n"))
+				lastID = sendChanges(w, mgr, lastID)
 				if fl != nil {
 					fl.Flush()
 				}
@@ -188,6 +267,52 @@ func handleDiscoveryStream(mgr *discovery.Manager) http.HandlerFunc {
 	}
 }
 
+// streamCursor init/reset 事件内容：当前发现版本与最近的事件 ID
+type streamCursor struct {
+	Version     int64  `json:"version"`
+	LastEventID uint64 `json:"last_event_id"`
+}
+
+// parseLastEventID 读取 Last-Event-ID 请求头（EventSource 重连时自动携带）或 last_event_id 参数
+func parseLastEventID(r *http.Request) (uint64, bool) {
+	v := r.Header.Get("Last-Event-ID")
+	if v == "" {
+		v = r.URL.Query().Get("last_event_id")
+	}
+	if v == "" {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
+// sendChanges 推送 lastID 之后的事件并返回新的游标；事件已被环形缓冲淘汰时发送 reset 并跳到最新
+func sendChanges(w http.ResponseWriter, mgr *discovery.Manager, lastID uint64) uint64 {
+	events, complete := mgr.ChangesSince(lastID)
+	if !complete {
+		head := mgr.LastEventID()
+		writeSSE(w, 0, "reset", streamCursor{Version: mgr.Version(), LastEventID: head})
+		return head
+	}
+	for _, ev := range events {
+		writeSSE(w, ev.ID, ev.Type, ev)
+		lastID = ev.ID
+	}
+	return lastID
+}
+
+// writeSSE 写出一条 SSE 消息，id 为 0 时省略 id 行
+func writeSSE(w http.ResponseWriter, id uint64, event string, payload interface{}) {
+	bs, _ := json.Marshal(payload)
+	if id > 0 {
+		_, _ = fmt.Fprintf(w, "id: %d\n", id)
+	}
+	_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, bs)
+}
+
 // handleDiscoveryStatus 获取发现状态处理器
 func handleDiscoveryStatus(mgr *discovery.Manager) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
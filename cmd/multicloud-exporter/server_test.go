@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"multicloud-exporter/internal/collector"
+	"multicloud-exporter/internal/config"
+	"multicloud-exporter/internal/discovery"
+	"multicloud-exporter/internal/metrics"
+	"multicloud-exporter/internal/providers"
+	providerscommon "multicloud-exporter/internal/providers/common"
+	"multicloud-exporter/internal/utils"
+)
+
+// collectStub 采集时阻塞直到 release 关闭
+type collectStub struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *collectStub) Collect(ctx context.Context, account config.CloudAccount) providers.CollectResult {
+	s.started <- struct{}{}
+	select {
+	case <-s.release:
+	case <-ctx.Done():
+	}
+	return providers.CollectResult{Provider: account.Provider, AccountID: account.AccountID}
+}
+
+func (s *collectStub) GetDefaultResources() []string { return nil }
+
+var stubCollect = &collectStub{started: make(chan struct{}, 10), release: make(chan struct{})}
+
+func init() {
+	providers.Register("collect_stub", func(*config.Config, *discovery.Manager) providers.Provider { return stubCollect })
+}
+
+func TestHandleCollect_Overlap(t *testing.T) {
+	cfg := &config.Config{AccountsByProvider: map[string][]config.CloudAccount{"collect_stub": {{AccountID: "a1"}}}}
+	coll := collector.NewCollector(cfg, nil)
+	h := handleCollect(context.Background(), coll)
+
+	do := func(query string) (int, string) {
+		rec := httptest.NewRecorder()
+		h(rec, httptest.NewRequest(http.MethodPost, "/collect"+query, nil))
+		var body map[string]interface{}
+		_ = json.NewDecoder(rec.Body).Decode(&body)
+		status, _ := body["status"].(string)
+		return rec.Code, status
+	}
+
+	if code, status := do(""); code != http.StatusOK || status != "triggered" {
+		t.Fatalf("first trigger: %d %s", code, status)
+	}
+	<-stubCollect.started
+	if code, status := do(""); code != http.StatusOK || status != "coalesced" {
+		t.Fatalf("same scope should coalesce: %d %s", code, status)
+	}
+	if code, status := do("?account=a1"); code != http.StatusConflict || status != "in_progress" {
+		t.Fatalf("overlap should be 409: %d %s", code, status)
+	}
+	if code, status := do("?account=a1&queue=true"); code != http.StatusAccepted || status != "queued" {
+		t.Fatalf("queue should be 202: %d %s", code, status)
+	}
+	close(stubCollect.release)
+	select {
+	case <-stubCollect.started:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("queued collection did not run")
+	}
+}
+
+func TestHandleShard(t *testing.T) {
+	t.Setenv("CLUSTER_DISCOVERY", "")
+	t.Setenv("CLUSTER_WORKERS", "2")
+	t.Setenv("CLUSTER_INDEX", "0")
+	keys := []string{"acc|r1|ns-a", "acc|r1|ns-b", "acc|r2|ns-a", "acc|r2|ns-b", "acc|r3|ns-a"}
+	mine := 0
+	for _, k := range keys {
+		if utils.ShouldProcess(k, 2, 0) {
+			mine++
+		}
+	}
+	coll := collector.NewCollector(&config.Config{}, nil)
+	h := handleShard(coll)
+
+	get := func(query string) (st struct {
+		Total int `json:"total"`
+		Keys  []struct {
+			Key   string `json:"key"`
+			Owned bool   `json:"owned"`
+		} `json:"keys"`
+	}) {
+		rec := httptest.NewRecorder()
+		h(rec, httptest.NewRequest(http.MethodGet, "/api/shard"+query, nil))
+		if err := json.NewDecoder(rec.Body).Decode(&st); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return st
+	}
+	owned := get("")
+	if owned.Total != 2 || len(owned.Keys) != mine {
+		t.Fatalf("expected %d owned keys of 2 shards, got %+v", mine, owned)
+	}
+	for _, k := range owned.Keys {
+		if !k.Owned {
+			t.Fatalf("foreign key in owned view: %+v", k)
+		}
+	}
+	if all := get("?all=true"); len(all.Keys) < len(keys) {
+		t.Fatalf("all=true should list every known key, got %d", len(all.Keys))
+	}
+}
+
+func TestHandleCluster(t *testing.T) {
+	t.Setenv("CLUSTER_DISCOVERY", "")
+	t.Setenv("CLUSTER_WORKERS", "")
+	t.Setenv("EXPORT_SHARD_TOTAL", "")
+	var st struct {
+		Enabled bool     `json:"enabled"`
+		Total   int      `json:"total"`
+		Members []string `json:"members"`
+	}
+	get := func() {
+		rec := httptest.NewRecorder()
+		handleCluster()(rec, httptest.NewRequest(http.MethodGet, "/api/cluster", nil))
+		if err := json.NewDecoder(rec.Body).Decode(&st); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+	}
+	get()
+	if st.Enabled || st.Total != 1 {
+		t.Fatalf("expected disabled single-instance status, got %+v", st)
+	}
+
+	m := utils.NewMembership(utils.MembershipOptions{Self: "pod-a", Addr: "127.0.0.1:0"})
+	m.Tick(context.Background())
+	utils.UseMembership(m)
+	defer utils.UseMembership(nil)
+	get()
+	if !st.Enabled || len(st.Members) != 1 || st.Members[0] != "pod-a" {
+		t.Fatalf("expected membership view [pod-a], got %+v", st)
+	}
+}
+
+func TestHandleRegions(t *testing.T) {
+	rec := httptest.NewRecorder()
+	handleRegions(&config.Config{})(rec, httptest.NewRequest(http.MethodGet, "/api/regions", nil))
+	if rec.Body.String() != "{\"enabled\":false}\n" {
+		t.Fatalf("unconfigured body = %s", rec.Body.String())
+	}
+
+	cfg := &config.Config{Server: &config.ServerConf{RegionDiscovery: &config.RegionDiscoveryConf{
+		Enabled: true, EmptyThreshold: 1, DataDir: t.TempDir(), PersistFile: "region_status.json",
+	}}}
+	rm := regionManagerFor(cfg)
+	t.Cleanup(rm.Stop)
+	providerscommon.ReportRegionResources(rm, "aliyun", "acc", "cn-hangzhou", providerscommon.NamespaceAliyunSLBDashboard, 0)
+	providerscommon.ReportRegionResources(rm, "aliyun", "acc", "cn-hangzhou", providerscommon.NamespaceAliyunOSSDashboard, 2)
+	providerscommon.ReportRegionResources(rm, "tencent", "acc", "ap-guangzhou", providerscommon.NamespaceTencentLB, 0)
+
+	var list struct {
+		Enabled bool                          `json:"enabled"`
+		Entries []providerscommon.RegionEntry `json:"entries"`
+	}
+	rec = httptest.NewRecorder()
+	handleRegions(cfg)(rec, httptest.NewRequest(http.MethodGet, "/api/regions?provider=aliyun", nil))
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !list.Enabled || len(list.Entries) != 2 || list.Entries[1].Namespace != providerscommon.NamespaceAliyunSLBDashboard || !list.Entries[1].Skipped {
+		t.Fatalf("entries = %+v", list.Entries)
+	}
+
+	call := func(h http.HandlerFunc, method, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h(rec, httptest.NewRequest(method, target, nil))
+		return rec
+	}
+	post := func(h http.HandlerFunc, target string) *httptest.ResponseRecorder {
+		return call(h, http.MethodPost, target)
+	}
+	if rec := call(handleRegionsRediscover(cfg), http.MethodGet, "/api/regions/rediscover?account=acc"); rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("GET rediscover code = %d", rec.Code)
+	}
+	// 未配置管理端点认证时拒绝变更
+	for _, h := range []http.HandlerFunc{handleRegionsRediscover(cfg), handleRegionsReset(cfg)} {
+		if rec := post(h, "/api/regions/rediscover?account=acc"); rec.Code != http.StatusForbidden {
+			t.Fatalf("unauthenticated admin code = %d", rec.Code)
+		}
+	}
+	cfg.Server.AdminAuthEnabled = true
+	cfg.Server.AdminAuth = []config.BasicAuth{{Username: "admin", Password: "secret"}}
+	if rec := post(handleRegionsRediscover(cfg), "/api/regions/rediscover"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("missing account code = %d", rec.Code)
+	}
+	rec = post(handleRegionsRediscover(cfg), "/api/regions/rediscover?provider=aliyun&account=acc&region=cn-hangzhou")
+	var res map[string]interface{}
+	if err := json.NewDecoder(rec.Body).Decode(&res); err != nil || res["marked"] != float64(2) {
+		t.Fatalf("rediscover = %v %v", res, err)
+	}
+	if rm.ShouldSkipRegion("aliyun", "acc", "cn-hangzhou", providerscommon.NamespaceAliyunSLBDashboard) {
+		t.Fatal("rediscovered namespace should not be skipped")
+	}
+	if got := testutil.ToFloat64(metrics.RegionInfo.WithLabelValues("tencent", "acc", "ap-guangzhou", providerscommon.NamespaceTencentLB, "empty", "1")); got != 1 {
+		t.Fatalf("region info metric = %v", got)
+	}
+
+	rec = post(handleRegionsReset(cfg), "/api/regions/reset?provider=tencent")
+	if err := json.NewDecoder(rec.Body).Decode(&res); err != nil || res["removed"] != float64(1) {
+		t.Fatalf("reset = %v %v", res, err)
+	}
+	if n := testutil.CollectAndCount(metrics.RegionInfo); n != 2 {
+		t.Fatalf("region info series after reset = %d", n)
+	}
+}
+
+func TestHandleDiscoveryStream_Replay(t *testing.T) {
+	mgr := discovery.NewManager(&config.Config{Server: &config.ServerConf{DiscoveryFeed: &config.DiscoveryFeedConf{BufferSize: 2}}})
+	mgr.ObserveInventory(nil)
+	for _, id := range []string{"lb-1", "lb-2", "lb-3"} {
+		mgr.ObserveInventory([]discovery.ResourceRef{{Provider: "aliyun", AccountID: "acc", Region: "cn-hangzhou", Namespace: "acs_slb_dashboard", ResourceID: id}})
+	}
+	h := handleDiscoveryStream(mgr)
+	// 已取消的请求：处理器写完初始事件与回放后立即返回
+	stream := func(lastEventID string) string {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		req := httptest.NewRequest(http.MethodGet, "/api/discovery/stream", nil).WithContext(ctx)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		rec := httptest.NewRecorder()
+		h(rec, req)
+		return rec.Body.String()
+	}
+
+	if body := stream(""); body != "event: init\ndata: {\"version\":0,\"last_event_id\":3}\n\n" {
+		t.Fatalf("fresh stream = %q", body)
+	}
+	body := stream("2")
+	if !strings.Contains(body, "id: 3\nevent: resources\n") || strings.Contains(body, "id: 2\n") {
+		t.Fatalf("replay from 2 = %q", body)
+	}
+	// 事件 1 已被淘汰，无法从 0 之后完整回放
+	if body := stream("0"); !strings.Contains(body, "event: reset\ndata: {\"version\":0,\"last_event_id\":3}") || strings.Contains(body, "event: resources") {
+		t.Fatalf("replay from evicted cursor = %q", body)
+	}
+}
@@ -1,14 +1,21 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
+	"multicloud-exporter/internal/cache"
 	"multicloud-exporter/internal/config"
 	"multicloud-exporter/internal/logger"
+	"multicloud-exporter/internal/metrics"
+	providerscommon "multicloud-exporter/internal/providers/common"
+	"multicloud-exporter/internal/utils"
 )
 
 // setupConfig 加载并验证配置
@@ -58,6 +65,198 @@ func logAccountStats(cfg *config.Config) {
 	ctxLog.Infof("配置加载完成，账号配置集合 sizes: accounts=%d%s", totalAccounts, accountInfo.String())
 }
 
+// setupCustomLabels 根据 datatag、账号 labels 与 tag_labels 设置命名空间指标的自定义标签
+func setupCustomLabels(cfg *config.Config) {
+	names := metrics.SetCustomLabels(cfg.CustomLabelKeys())
+	if len(names) > 0 {
+		ctxLog := logger.NewContextLogger("Setup", "resource_type", "Config")
+		ctxLog.Infof("已启用自定义标签: %v", names)
+	}
+}
+
+// setupRateLimits 根据 server.rate_limits 配置云 API 限流预算（未配置时使用内置默认值）
+func setupRateLimits(cfg *config.Config) {
+	server := cfg.GetServer()
+	if server == nil {
+		providerscommon.ConfigureRateLimits(nil)
+		return
+	}
+	providerscommon.ConfigureRateLimits(server.RateLimits)
+	if len(server.RateLimits) > 0 {
+		ctxLog := logger.NewContextLogger("Setup", "resource_type", "Config")
+		ctxLog.Infof("已配置云 API 限流预算: %d 项", len(server.RateLimits))
+	}
+}
+
+// setupAdaptiveConcurrency 根据 server.adaptive_concurrency 启用基于限流反馈的自适应并发
+func setupAdaptiveConcurrency(cfg *config.Config) {
+	var conf *config.AdaptiveConcurrencyConf
+	if server := cfg.GetServer(); server != nil {
+		conf = server.AdaptiveConcurrency
+	}
+	providerscommon.ConfigureAdaptiveConcurrency(conf)
+	if conf != nil && conf.Enabled {
+		ctxLog := logger.NewContextLogger("Setup", "resource_type", "Config")
+		ctxLog.Infof("已启用自适应并发: min=%d decrease_factor=%v cooldown=%s", conf.Min, conf.DecreaseFactor, conf.Cooldown)
+	}
+}
+
+// setupCircuitBreakers 根据 server.circuit_breaker 启用账号/区域熔断
+func setupCircuitBreakers(cfg *config.Config) {
+	var conf *config.CircuitBreakerConf
+	if server := cfg.GetServer(); server != nil {
+		conf = server.CircuitBreaker
+	}
+	providerscommon.ConfigureCircuitBreakers(conf)
+	if conf != nil && conf.Enabled {
+		ctxLog := logger.NewContextLogger("Setup", "resource_type", "Config")
+		ctxLog.Infof("已启用熔断: failure_threshold=%d open_duration=%s max_open_duration=%s", conf.FailureThreshold, conf.OpenDuration, conf.MaxOpenDuration)
+	}
+}
+
+// setupSharding 根据 server.sharding 设置一致性哈希参数并加载键权重文件。
+// 权重文件加载失败时返回错误：各分片权重不一致会导致键被重复采集或遗漏，不能退回哈希环分配
+func setupSharding(cfg *config.Config) error {
+	var conf config.ShardingConf
+	if server := cfg.GetServer(); server != nil && server.Sharding != nil {
+		conf = *server.Sharding
+	}
+	weights, err := conf.LoadWeights()
+	if err != nil {
+		return fmt.Errorf("加载分片权重文件失败: %w", err)
+	}
+	if conf.WeightsFile != "" {
+		ctxLog := logger.NewContextLogger("Setup", "resource_type", "Sharding")
+		ctxLog.Infof("已加载分片权重: %d 个键", len(weights))
+	}
+	utils.ConfigureSharding(conf.VirtualNodes, conf.LoadFactor, weights)
+	utils.SetReplicationFactor(conf.ReplicationFactor)
+	return nil
+}
+
+// setupReplicaLabel 副本数大于 1 时为全部导出指标附加 replica 标签（本实例的成员 ID / Pod 名，缺省为分片序号），
+// 同一键的多个副本由 Prometheus/Thanos 按该标签去重；须在注册指标之前调用
+func setupReplicaLabel() {
+	replicas := utils.ReplicationFactor()
+	if replicas <= 1 {
+		metrics.SetExternalLabels(nil)
+		return
+	}
+	replica := getEnvOrDefault("POD_NAME", os.Getenv("HOSTNAME"))
+	if m := utils.ActiveMembership(); m != nil {
+		replica = m.Status().Self
+	}
+	if replica == "" {
+		_, index := utils.ClusterConfig()
+		replica = fmt.Sprintf("%d", index)
+	}
+	metrics.SetExternalLabels(map[string]string{"replica": replica})
+	ctxLog := logger.NewContextLogger("Setup", "resource_type", "Sharding")
+	ctxLog.Infof("副本采集已启用: replication_factor=%d replica=%s", replicas, replica)
+}
+
+// setupMembership 根据 server.cluster 启用心跳成员管理：完成首轮心跳后 ClusterConfig 改用其成员视图，
+// 心跳持续到 ctx 结束。未启用时返回 nil，沿用 CLUSTER_* 环境变量发现。
+func setupMembership(ctx context.Context, cfg *config.Config, port string) *utils.Membership {
+	server := cfg.GetServer()
+	if server == nil || server.Cluster == nil || !server.Cluster.Enabled {
+		return nil
+	}
+	conf := server.Cluster
+	ctxLog := logger.NewContextLogger("Setup", "resource_type", "Cluster")
+	opts := utils.MembershipOptions{
+		Self:              conf.MemberID,
+		Addr:              conf.AdvertiseAddr,
+		Token:             conf.Token,
+		HeartbeatInterval: parseClusterDuration(ctxLog, "heartbeat_interval", conf.HeartbeatInterval, utils.DefaultHeartbeatInterval),
+		GracePeriod:       parseClusterDuration(ctxLog, "grace_period", conf.GracePeriod, utils.DefaultGracePeriod),
+	}
+	opts.FailureTimeout = parseClusterDuration(ctxLog, "failure_timeout", conf.FailureTimeout, 0)
+	if opts.Self == "" {
+		opts.Self = getEnvOrDefault("POD_NAME", os.Getenv("HOSTNAME"))
+	}
+	if opts.Addr == "" && os.Getenv("POD_IP") != "" {
+		opts.Addr = net.JoinHostPort(os.Getenv("POD_IP"), port)
+	}
+	if opts.Self == "" || opts.Addr == "" {
+		ctxLog.Warnf("集群成员管理缺少 member_id 或 advertise_addr（POD_NAME/POD_IP），沿用 CLUSTER_* 环境变量发现")
+		return nil
+	}
+	svc := conf.PeerService
+	if svc == "" {
+		svc = os.Getenv("CLUSTER_SVC")
+	}
+	peers := append([]string(nil), conf.Peers...)
+	opts.Seeds = func() []string {
+		seeds := append([]string(nil), peers...)
+		if svc != "" {
+			if ips, err := net.LookupIP(svc); err == nil {
+				for _, ip := range ips {
+					seeds = append(seeds, net.JoinHostPort(ip.String(), port))
+				}
+			}
+		}
+		return seeds
+	}
+	m := utils.NewMembership(opts)
+	m.Start(ctx)
+	utils.UseMembership(m)
+	members, gen := m.Members()
+	ctxLog.Infof("集群成员管理已启用: member_id=%s generation=%d members=%v", opts.Self, gen, members)
+	return m
+}
+
+func parseClusterDuration(ctxLog *logger.ContextLogger, name, v string, def time.Duration) time.Duration {
+	if v == "" {
+		return def
+	}
+	d, err := utils.ParseDuration(v)
+	if err != nil || d < 0 {
+		ctxLog.Warnf("cluster.%s 解析失败，使用默认值 %v: %s", name, def, v)
+		return def
+	}
+	return d
+}
+
+// setupBudgets 根据 server.budgets 启用每日云 API 预算，用量持久化到 region_discovery.data_dir
+func setupBudgets(cfg *config.Config, interval time.Duration) {
+	server := cfg.GetServer()
+	if server == nil || server.Budgets == nil {
+		providerscommon.ConfigureBudgets(nil, "", interval)
+		return
+	}
+	dataDir := ""
+	if server.RegionDiscovery != nil {
+		dataDir = server.RegionDiscovery.DataDir
+	}
+	providerscommon.ConfigureBudgets(server.Budgets, dataDir, interval)
+	ctxLog := logger.NewContextLogger("Setup", "resource_type", "Config")
+	ctxLog.Infof("已启用每日云 API 预算: providers=%d accounts=%d degrade_factor=%d",
+		len(server.Budgets.Providers), len(server.Budgets.Accounts), server.Budgets.DegradeFactor)
+}
+
+// setupCache 根据 server.cache 选择采集器缓存后端，须在创建采集器之前调用；
+// disk 后端默认持久化到 region_discovery.data_dir/cache
+func setupCache(cfg *config.Config) {
+	server := cfg.GetServer()
+	if server == nil || server.Cache == nil {
+		cache.Configure(nil, "")
+		return
+	}
+	dataDir := ""
+	if server.RegionDiscovery != nil {
+		dataDir = server.RegionDiscovery.DataDir
+	}
+	cache.Configure(server.Cache, dataDir)
+	backend := server.Cache.Backend
+	if backend == "" {
+		backend = cache.BackendMemory
+	}
+	ctxLog := logger.NewContextLogger("Setup", "resource_type", "Config")
+	ctxLog.Infof("采集器缓存: backend=%s max_entries=%d max_bytes=%d",
+		backend, server.Cache.MaxEntries, server.Cache.MaxBytes)
+}
+
 // setupMetricMappings 加载指标映射配置
 func setupMetricMappings(cfg *config.Config) {
 	// 优先从环境变量 MAPPING_PATH 加载
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"multicloud-exporter/internal/config"
+	"multicloud-exporter/internal/providers"
+)
+
+// runValidate 实现 validate 子命令：严格解析 server.yaml、accounts.yaml 与指标映射文件，
+// 拒绝未知字段（带行号），校验资源类型与区域名称；-schema/-schema-out 输出编辑器使用的 JSON Schema。
+// 返回进程退出码：0 通过，1 发现问题，2 参数错误。
+func runValidate(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	serverPath := fs.String("server", os.Getenv("SERVER_PATH"), "server.yaml 路径（默认 SERVER_PATH 或 ./configs/server.yaml）")
+	accountsPath := fs.String("accounts", os.Getenv("ACCOUNTS_PATH"), "accounts.yaml 路径（默认 ACCOUNTS_PATH 或 ./configs/accounts.yaml）")
+	mappingPath := fs.String("mappings", os.Getenv("MAPPING_PATH"), "指标映射文件或目录，逗号分隔（默认 MAPPING_PATH 或 configs/mappings）")
+	schemaKind := fs.String("schema", "", "输出指定配置的 JSON Schema 到标准输出并退出："+strings.Join(config.SchemaKinds, "|"))
+	schemaOut := fs.String("schema-out", "", "将全部 JSON Schema 写入该目录（<kind>.schema.json）并退出")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if *schemaKind != "" {
+		data, ok := config.JSONSchema(*schemaKind)
+		if !ok {
+			fmt.Fprintf(stderr, "未知的 schema 类型 %q，可选: %s\n", *schemaKind, strings.Join(config.SchemaKinds, ", "))
+			return 2
+		}
+		_, _ = stdout.Write(data)
+		return 0
+	}
+	if *schemaOut != "" {
+		if err := writeSchemas(*schemaOut); err != nil {
+			fmt.Fprintf(stderr, "写入 JSON Schema 失败: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(stdout, "✓ JSON Schema 已写入 %s\n", *schemaOut)
+		return 0
+	}
+
+	var errs []string
+	cfg := &config.Config{}
+
+	// server.yaml
+	if data, path, err := config.LoadConfigFile(*serverPath, []string{"/app/configs/server.yaml", "./configs/server.yaml"}); err != nil {
+		errs = append(errs, err.Error())
+	} else if f, err := config.DecodeServerFile(data); err != nil {
+		errs = append(errs, fmt.Sprintf("%s: %v", path, err))
+	} else {
+		cfg.Server, cfg.ServerConf = f.Server, f.Server
+		cfg.Estimation, cfg.DataTag = f.Estimation, f.DataTag
+		fmt.Fprintf(stdout, "✓ %s\n", path)
+	}
+
+	// accounts.yaml
+	if data, path, err := config.LoadConfigFile(*accountsPath, []string{"/app/configs/accounts.yaml", "./configs/accounts.yaml"}); err != nil {
+		errs = append(errs, err.Error())
+	} else if f, err := config.DecodeAccountsFile(data); err != nil {
+		errs = append(errs, fmt.Sprintf("%s: %v", path, err))
+	} else {
+		cfg.AccountsByProvider = f.AccountsByProvider
+		errs = append(errs, validateAccounts(cfg.AccountsByProvider)...)
+		fmt.Fprintf(stdout, "✓ %s\n", path)
+	}
+
+	// 语义校验（端口、日志、并发、必填字段等）
+	if len(errs) == 0 {
+		if err := cfg.Validate(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	// 指标映射文件
+	files, err := mappingFiles(*mappingPath)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if _, err := config.DecodeMappingFile(f, data); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", f, err))
+			continue
+		}
+		fmt.Fprintf(stdout, "✓ %s\n", f)
+	}
+
+	if len(errs) > 0 {
+		fmt.Fprintf(stderr, "配置校验失败，共 %d 个问题:\n  - %s\n", len(errs), strings.Join(errs, "\n  - "))
+		return 1
+	}
+	fmt.Fprintf(stdout, "\n✓ 配置校验通过，映射文件 %d 个\n", len(files))
+	return 0
+}
+
+// validateAccounts 校验账号的云厂商、资源类型与区域名称
+// 资源类型优先使用 Provider 声明的 SupportedResources（含别名），否则使用 GetDefaultResources
+func validateAccounts(accounts map[string][]config.CloudAccount) []string {
+	var errs []string
+	names := make([]string, 0, len(accounts))
+	for name := range accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		factory, ok := providers.GetFactory(name)
+		if !ok {
+			known := providers.GetAllProviders()
+			sort.Strings(known)
+			errs = append(errs, fmt.Sprintf("accounts.%s: unknown provider (supported: %s)", name, strings.Join(known, ", ")))
+			continue
+		}
+		p := factory(&config.Config{}, nil)
+		supported := p.GetDefaultResources()
+		desc, hasDesc := p.(providers.Describer)
+		if hasDesc {
+			supported = desc.SupportedResources()
+		}
+		allowed := make(map[string]bool, len(supported))
+		for _, r := range supported {
+			allowed[r] = true
+		}
+
+		for i, acc := range accounts[name] {
+			for _, r := range acc.Resources {
+				rr := strings.ToLower(strings.TrimSpace(r))
+				if rr != "*" && !allowed[rr] {
+					errs = append(errs, fmt.Sprintf("accounts.%s[%d].resources: unknown resource %q (supported: %s)", name, i, r, strings.Join(supported, ", ")))
+				}
+			}
+			if acc.Filters != nil {
+				for r := range acc.Filters.Resources {
+					if !allowed[strings.ToLower(r)] {
+						errs = append(errs, fmt.Sprintf("accounts.%s[%d].filters.resources: unknown resource %q", name, i, r))
+					}
+				}
+			}
+			if !hasDesc {
+				continue
+			}
+			for _, region := range acc.Regions {
+				if region != "*" && !desc.ValidRegion(region) {
+					errs = append(errs, fmt.Sprintf("accounts.%s[%d].regions: invalid region %q", name, i, region))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// mappingFiles 解析映射路径（文件或目录，逗号分隔），为空时使用 configs/mappings
+func mappingFiles(mappingPath string) ([]string, error) {
+	if mappingPath == "" {
+		mappingPath = "configs/mappings"
+	}
+	var files []string
+	for _, p := range strings.Split(mappingPath, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		fi, err := os.Stat(p)
+		if err != nil {
+			return files, fmt.Errorf("映射路径未找到: %s (%v)", p, err)
+		}
+		if !fi.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		matched, err := filepath.Glob(filepath.Join(p, "*.yaml"))
+		if err != nil {
+			return files, fmt.Errorf("列出映射文件失败 %s: %v", p, err)
+		}
+		files = append(files, matched...)
+	}
+	return files, nil
+}
+
+// writeSchemas 将全部 JSON Schema 写入目录
+func writeSchemas(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, kind := range config.SchemaKinds {
+		data, _ := config.JSONSchema(kind)
+		if err := os.WriteFile(filepath.Join(dir, kind+".schema.json"), data, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
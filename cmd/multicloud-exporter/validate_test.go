@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestRunValidate(t *testing.T) {
+	dir := t.TempDir()
+	server := writeFile(t, dir, "server.yaml", "server:\n  port: 9101\n")
+	mapping := writeFile(t, dir, "clb.metrics.yaml", "prefix: clb\nnamespaces:\n  aliyun: acs_slb_dashboard\ncanonical:\n  a:\n    aliyun:\n      metric: A\n      unit: count\n")
+	good := writeFile(t, dir, "good.yaml", "accounts:\n  aliyun:\n    - account_id: a\n      access_key_id: k\n      access_key_secret: s\n      regions: [cn-hangzhou]\n      resources: [clb, s3]\n  aws:\n    - account_id: b\n      access_key_id: k\n      access_key_secret: s\n      regions: [us-east-1]\n      resources: [\"*\"]\n")
+	bad := writeFile(t, dir, "bad.yaml", "accounts:\n  aliyun:\n    - account_id: a\n      access_key_id: k\n      access_key_secret: s\n      regions: [Hangzhou]\n      resources: [clb, ecs]\n  gcp:\n    - account_id: c\n")
+
+	var out, errOut bytes.Buffer
+	if code := runValidate([]string{"-server", server, "-accounts", good, "-mappings", mapping}, &out, &errOut); code != 0 {
+		t.Fatalf("expected success, got %d: %s", code, errOut.String())
+	}
+
+	out.Reset()
+	errOut.Reset()
+	if code := runValidate([]string{"-server", server, "-accounts", bad, "-mappings", mapping}, &out, &errOut); code != 1 {
+		t.Fatalf("expected failure, got %d", code)
+	}
+	for _, want := range []string{`unknown resource "ecs"`, `invalid region "Hangzhou"`, "accounts.gcp: unknown provider"} {
+		if !strings.Contains(errOut.String(), want) {
+			t.Fatalf("missing %q in output:\n%s", want, errOut.String())
+		}
+	}
+}
+
+func TestRunValidate_Schema(t *testing.T) {
+	var out, errOut bytes.Buffer
+	if code := runValidate([]string{"-schema", "accounts"}, &out, &errOut); code != 0 || !strings.Contains(out.String(), `"accounts"`) {
+		t.Fatalf("unexpected schema output (%d): %s %s", code, out.String(), errOut.String())
+	}
+	dir := t.TempDir()
+	if code := runValidate([]string{"-schema-out", dir}, &out, &errOut); code != 0 {
+		t.Fatalf("schema-out failed: %s", errOut.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "mapping.schema.json")); err != nil {
+		t.Fatalf("mapping schema not written: %v", err)
+	}
+}
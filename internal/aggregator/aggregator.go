@@ -0,0 +1,370 @@
+// 聚合包：并发抓取各分片实例的 /metrics，合并指标族后统一对外暴露
+package aggregator
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"google.golang.org/protobuf/proto"
+
+	"multicloud-exporter/internal/metrics"
+)
+
+// 聚合默认参数
+const (
+	DefaultPath      = "/metrics"
+	DefaultTimeout   = 10 * time.Second
+	DefaultPeerLabel = "peer"
+)
+
+// 合并冲突类型（multicloud_aggregator_conflicts_total 的 kind 标签）
+const (
+	ConflictType      = "type"      // 同名指标族类型不一致，后到的指标族被丢弃
+	ConflictHelp      = "help"      // 同名指标族 HELP 不一致，保留先到的 HELP
+	ConflictLabels    = "labels"    // 同一指标族内样本的标签名集合不一致，样本保留
+	ConflictDuplicate = "duplicate" // 标签完全相同的重复样本，后到的样本被丢弃
+)
+
+// Options 聚合参数
+type Options struct {
+	// Peers 返回对端地址（host:port 或完整 URL），每次聚合调用
+	Peers func() []string
+	// Path 对端指标路径，默认 /metrics
+	Path string
+	// Timeout 单个对端的抓取超时，默认 10s
+	Timeout time.Duration
+	// PeerLabel 为每个合并样本附加的对端标签名（值为对端地址），区分各实例自身的同名指标；为空时不附加
+	PeerLabel string
+	// MaxStale 对端抓取失败时沿用其上次成功结果的最长时间，0 表示不沿用
+	MaxStale time.Duration
+	// MinPeerRatio 成功（含沿用）对端占比低于该值时聚合失败（HTTP 503），0 表示总是返回部分结果
+	MinPeerRatio float64
+	// Client 抓取对端的 HTTP 客户端，默认超时为 Timeout
+	Client *http.Client
+}
+
+// PeerResult 单个对端本次的抓取结果
+type PeerResult struct {
+	Peer     string  `json:"peer"`
+	Up       bool    `json:"up"`
+	Stale    bool    `json:"stale,omitempty"` // 抓取失败，沿用上次成功结果
+	Duration float64 `json:"duration_seconds"`
+	Samples  int     `json:"samples"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// Result 一次聚合的结果
+type Result struct {
+	Families  []*dto.MetricFamily `json:"-"`
+	Peers     []PeerResult        `json:"peers"`
+	Conflicts map[string]int      `json:"conflicts,omitempty"`
+	// Partial 存在抓取失败的对端（其数据缺失或来自上次成功结果）
+	Partial bool      `json:"partial"`
+	At      time.Time `json:"at"`
+}
+
+// Aggregator 分片指标聚合器
+type Aggregator struct {
+	opts Options
+
+	mu sync.Mutex
+	// good 对端上次成功抓取的指标族，供 MaxStale 内沿用
+	good map[string]peerFamilies
+	// peers 上次聚合的对端，用于清理已消失对端的指标
+	peers map[string]bool
+	last  *Result
+}
+
+type peerFamilies struct {
+	families map[string]*dto.MetricFamily
+	at       time.Time
+}
+
+// New 创建聚合器，未设置的参数使用默认值
+func New(opts Options) *Aggregator {
+	if opts.Path == "" {
+		opts.Path = DefaultPath
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: opts.Timeout}
+	}
+	return &Aggregator{opts: opts, good: make(map[string]peerFamilies), peers: make(map[string]bool)}
+}
+
+// Scrape 并发抓取全部对端并合并指标族；成功对端占比低于 MinPeerRatio 时返回错误（结果仍包含各对端状态）
+func (a *Aggregator) Scrape(ctx context.Context) (*Result, error) {
+	var peers []string
+	if a.opts.Peers != nil {
+		seen := make(map[string]bool)
+		for _, p := range a.opts.Peers() {
+			if p != "" && !seen[p] {
+				seen[p] = true
+				peers = append(peers, p)
+			}
+		}
+	}
+	sort.Strings(peers)
+
+	type scraped struct {
+		families map[string]*dto.MetricFamily
+		dur      time.Duration
+		err      error
+	}
+	out := make([]scraped, len(peers))
+	var wg sync.WaitGroup
+	for i, p := range peers {
+		wg.Add(1)
+		go func(i int, p string) {
+			defer wg.Done()
+			start := time.Now()
+			fams, err := a.fetch(ctx, p)
+			out[i] = scraped{families: fams, dur: time.Since(start), err: err}
+		}(i, p)
+	}
+	wg.Wait()
+
+	now := time.Now()
+	res := &Result{At: now}
+	m := newMerger(a.opts.PeerLabel)
+	ok := 0
+	a.mu.Lock()
+	for i, p := range peers {
+		r := PeerResult{Peer: p, Duration: out[i].dur.Seconds()}
+		fams := out[i].families
+		if out[i].err == nil {
+			r.Up = true
+			a.good[p] = peerFamilies{families: fams, at: now}
+		} else {
+			r.Error = out[i].err.Error()
+			res.Partial = true
+			fams = nil
+			if g, exists := a.good[p]; exists && a.opts.MaxStale > 0 && now.Sub(g.at) <= a.opts.MaxStale {
+				fams, r.Stale = g.families, true
+			}
+		}
+		if fams != nil {
+			ok++
+			r.Samples = m.add(p, fams)
+		}
+		res.Peers = append(res.Peers, r)
+	}
+	a.updateMetricsLocked(res.Peers)
+	a.mu.Unlock()
+
+	res.Families = m.families()
+	res.Conflicts = m.conflicts
+	for kind, n := range m.conflicts {
+		metrics.AggregatorConflicts.WithLabelValues(kind).Add(float64(n))
+	}
+	if res.Partial {
+		metrics.AggregatorPartialResults.Inc()
+	}
+	a.mu.Lock()
+	a.last = res
+	a.mu.Unlock()
+
+	if len(peers) > 0 && float64(ok)/float64(len(peers)) < a.opts.MinPeerRatio {
+		return res, fmt.Errorf("only %d/%d peers scraped successfully", ok, len(peers))
+	}
+	return res, nil
+}
+
+// Last 返回最近一次聚合的结果，尚未聚合时返回 nil
+func (a *Aggregator) Last() *Result {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.last
+}
+
+// updateMetricsLocked 更新对端指标并清理已消失的对端，调用方持有 a.mu
+func (a *Aggregator) updateMetricsLocked(results []PeerResult) {
+	current := make(map[string]bool, len(results))
+	for _, r := range results {
+		current[r.Peer] = true
+		up := 0.0
+		if r.Up {
+			up = 1
+		}
+		metrics.AggregatorPeerUp.WithLabelValues(r.Peer).Set(up)
+		metrics.AggregatorPeerScrapeDuration.WithLabelValues(r.Peer).Set(r.Duration)
+		metrics.AggregatorPeerSamples.WithLabelValues(r.Peer).Set(float64(r.Samples))
+	}
+	for p := range a.peers {
+		if !current[p] {
+			metrics.AggregatorPeerUp.DeleteLabelValues(p)
+			metrics.AggregatorPeerScrapeDuration.DeleteLabelValues(p)
+			metrics.AggregatorPeerSamples.DeleteLabelValues(p)
+			delete(a.good, p)
+		}
+	}
+	a.peers = current
+}
+
+// fetch 抓取单个对端的文本格式指标
+func (a *Aggregator) fetch(ctx context.Context, peer string) (map[string]*dto.MetricFamily, error) {
+	url := peer
+	if !strings.Contains(url, "://") {
+		url = "http://" + peer
+	}
+	ctx, cancel := context.WithTimeout(ctx, a.opts.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(url, "/")+a.opts.Path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", string(expfmt.FmtText))
+	resp, err := a.opts.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	var parser expfmt.TextParser
+	return parser.TextToMetricFamilies(resp.Body)
+}
+
+// Handler 聚合各对端指标后按请求协商的格式输出；own 为聚合器自身的指标（可为 nil），与对端指标一同输出
+func (a *Aggregator) Handler(own prometheus.Gatherer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := a.Scrape(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		families := res.Families
+		if own != nil {
+			if ownFamilies, err := own.Gather(); err == nil {
+				families = append(families, ownFamilies...)
+				sort.Slice(families, func(i, j int) bool { return families[i].GetName() < families[j].GetName() })
+			}
+		}
+		format := expfmt.Negotiate(r.Header)
+		w.Header().Set("Content-Type", string(format))
+		enc := expfmt.NewEncoder(w, format)
+		for _, mf := range families {
+			if err := enc.Encode(mf); err != nil {
+				return
+			}
+		}
+		if closer, ok := enc.(expfmt.Closer); ok {
+			_ = closer.Close()
+		}
+	}
+}
+
+// merger 按名称合并指标族并检测冲突
+type merger struct {
+	peerLabel string
+	byName    map[string]*dto.MetricFamily
+	// labelSets 指标族名 -> 首个样本的标签名集合
+	labelSets map[string]string
+	// series 已合并样本的指标族名与标签，用于检测重复样本
+	series    map[string]bool
+	conflicts map[string]int
+}
+
+func newMerger(peerLabel string) *merger {
+	return &merger{
+		peerLabel: peerLabel,
+		byName:    make(map[string]*dto.MetricFamily),
+		labelSets: make(map[string]string),
+		series:    make(map[string]bool),
+		conflicts: make(map[string]int),
+	}
+}
+
+// add 合并一个对端的指标族，返回合并的样本数
+func (m *merger) add(peer string, families map[string]*dto.MetricFamily) int {
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	added := 0
+	for _, name := range names {
+		src := families[name]
+		dst, exists := m.byName[name]
+		if !exists {
+			dst = &dto.MetricFamily{Name: src.Name, Help: src.Help, Type: src.Type}
+			m.byName[name] = dst
+		} else {
+			if dst.GetType() != src.GetType() {
+				m.conflicts[ConflictType]++
+				continue
+			}
+			if dst.GetHelp() != src.GetHelp() {
+				m.conflicts[ConflictHelp]++
+			}
+		}
+		for _, metric := range src.Metric {
+			metric = proto.Clone(metric).(*dto.Metric)
+			if m.peerLabel != "" && !hasLabel(metric, m.peerLabel) {
+				metric.Label = append(metric.Label, &dto.LabelPair{Name: proto.String(m.peerLabel), Value: proto.String(peer)})
+			}
+			sort.Slice(metric.Label, func(i, j int) bool { return metric.Label[i].GetName() < metric.Label[j].GetName() })
+			names, series := labelKeys(metric)
+			if set, ok := m.labelSets[name]; !ok {
+				m.labelSets[name] = names
+			} else if set != names {
+				m.conflicts[ConflictLabels]++
+			}
+			key := name + "{" + series + "}"
+			if m.series[key] {
+				m.conflicts[ConflictDuplicate]++
+				continue
+			}
+			m.series[key] = true
+			dst.Metric = append(dst.Metric, metric)
+			added++
+		}
+	}
+	return added
+}
+
+// families 返回按名称排序的合并结果，丢弃没有样本的指标族
+func (m *merger) families() []*dto.MetricFamily {
+	out := make([]*dto.MetricFamily, 0, len(m.byName))
+	for _, mf := range m.byName {
+		if len(mf.Metric) > 0 {
+			out = append(out, mf)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].GetName() < out[j].GetName() })
+	return out
+}
+
+func hasLabel(metric *dto.Metric, name string) bool {
+	for _, lp := range metric.Label {
+		if lp.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// labelKeys 返回已排序标签的名称集合与完整标签键
+func labelKeys(metric *dto.Metric) (string, string) {
+	var names, series strings.Builder
+	for i, lp := range metric.Label {
+		if i > 0 {
+			names.WriteByte(',')
+			series.WriteByte(',')
+		}
+		names.WriteString(lp.GetName())
+		fmt.Fprintf(&series, "%s=%q", lp.GetName(), lp.GetValue())
+	}
+	return names.String(), series.String()
+}
@@ -0,0 +1,160 @@
+package aggregator
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"multicloud-exporter/internal/metrics"
+)
+
+// peerServer 返回固定文本指标的分片实例
+func peerServer(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metrics" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func addr(srv *httptest.Server) string {
+	return strings.TrimPrefix(srv.URL, "http://")
+}
+
+const shardA = `# HELP clb_traffic_rx LB 入流量
+# TYPE clb_traffic_rx gauge
+clb_traffic_rx{resource_id="lb-1"} 10
+# HELP multicloud_collection_in_progress 采集进行中
+# TYPE multicloud_collection_in_progress gauge
+multicloud_collection_in_progress 0
+`
+
+const shardB = `# HELP clb_traffic_rx LB inbound traffic
+# TYPE clb_traffic_rx gauge
+clb_traffic_rx{resource_id="lb-2"} 20
+clb_traffic_rx{resource_id="lb-3",code_name="x"} 30
+# HELP multicloud_collection_in_progress 采集进行中
+# TYPE multicloud_collection_in_progress counter
+multicloud_collection_in_progress 1
+`
+
+func TestScrape_MergesAndDetectsConflicts(t *testing.T) {
+	a, b := peerServer(t, shardA), peerServer(t, shardB)
+	agg := New(Options{Peers: func() []string { return []string{addr(b), addr(a)} }, PeerLabel: DefaultPeerLabel})
+
+	res, err := agg.Scrape(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Partial || len(res.Peers) != 2 || !res.Peers[0].Up || !res.Peers[1].Up {
+		t.Fatalf("unexpected peer results: %+v", res.Peers)
+	}
+	byName := make(map[string]int)
+	for _, mf := range res.Families {
+		byName[mf.GetName()] = len(mf.GetMetric())
+		for _, m := range mf.GetMetric() {
+			found := false
+			for _, lp := range m.GetLabel() {
+				found = found || lp.GetName() == DefaultPeerLabel
+			}
+			if !found {
+				t.Fatalf("%s sample without peer label: %v", mf.GetName(), m.GetLabel())
+			}
+		}
+	}
+	if byName["clb_traffic_rx"] != 3 {
+		t.Fatalf("expected 3 merged clb_traffic_rx samples, got %v", byName)
+	}
+	// 类型冲突的指标族只保留先到实例的样本
+	if byName["multicloud_collection_in_progress"] != 1 {
+		t.Fatalf("conflicting family should keep a single peer's samples, got %v", byName)
+	}
+	for _, kind := range []string{ConflictType, ConflictHelp, ConflictLabels} {
+		if res.Conflicts[kind] == 0 {
+			t.Fatalf("expected %s conflict, got %v", kind, res.Conflicts)
+		}
+	}
+}
+
+func TestScrape_DuplicateWithoutPeerLabel(t *testing.T) {
+	a, b := peerServer(t, shardA), peerServer(t, shardA)
+	agg := New(Options{Peers: func() []string { return []string{addr(a), addr(b)} }})
+	res, err := agg.Scrape(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Conflicts[ConflictDuplicate] != 2 {
+		t.Fatalf("identical series from two peers should be reported as duplicates, got %v", res.Conflicts)
+	}
+}
+
+func TestScrape_PartialResults(t *testing.T) {
+	a := peerServer(t, shardA)
+	b := peerServer(t, shardB)
+	peers := []string{addr(a), addr(b)}
+	agg := New(Options{Peers: func() []string { return peers }, PeerLabel: DefaultPeerLabel, Timeout: time.Second, MaxStale: time.Minute, MinPeerRatio: 0.5})
+	if _, err := agg.Scrape(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 实例 b 下线：在 MaxStale 内沿用上次结果，结果标记为部分
+	b.Close()
+	partial := testutil.ToFloat64(metrics.AggregatorPartialResults)
+	res, err := agg.Scrape(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var down PeerResult
+	for _, r := range res.Peers {
+		if r.Peer == addr(b) {
+			down = r
+		}
+	}
+	if !res.Partial || down.Up || !down.Stale || down.Samples == 0 {
+		t.Fatalf("expected stale result for the down peer, got %+v", down)
+	}
+	if got := testutil.ToFloat64(metrics.AggregatorPeerUp.WithLabelValues(addr(b))); got != 0 {
+		t.Fatalf("peer_up for the down peer = %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.AggregatorPartialResults); got != partial+1 {
+		t.Fatalf("partial results = %v, want %v", got, partial+1)
+	}
+
+	// 不沿用上次结果时成功占比低于 MinPeerRatio，聚合失败
+	strict := New(Options{Peers: func() []string { return peers }, Timeout: time.Second, MinPeerRatio: 0.75})
+	rec := httptest.NewRecorder()
+	strict.Handler(nil)(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+}
+
+func TestHandler_ServesUnion(t *testing.T) {
+	a, b := peerServer(t, shardA), peerServer(t, shardA)
+	agg := New(Options{Peers: func() []string { return []string{addr(a), addr(b)} }, PeerLabel: DefaultPeerLabel})
+	rec := httptest.NewRecorder()
+	agg.Handler(nil)(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, p := range []string{addr(a), addr(b)} {
+		if !strings.Contains(body, `clb_traffic_rx{peer="`+p+`",resource_id="lb-1"} 10`) {
+			t.Fatalf("missing sample from %s:\n%s", p, body)
+		}
+	}
+	if strings.Count(body, "# TYPE clb_traffic_rx gauge") != 1 {
+		t.Fatalf("family should be written once:\n%s", body)
+	}
+}
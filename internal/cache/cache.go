@@ -0,0 +1,150 @@
+// Package cache 提供采集器共用的键值缓存：按 server.cache 选择内存或磁盘后端，
+// 支持 TTL、条目数与字节数上限（LRU 淘汰），磁盘后端重启后恢复已发现的资源，避免全量重新枚举。
+package cache
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"multicloud-exporter/internal/config"
+	"multicloud-exporter/internal/logger"
+)
+
+const (
+	// BackendMemory 进程内缓存，重启后丢失
+	BackendMemory = "memory"
+	// BackendDisk 内存索引 + JSON 分段文件持久化
+	BackendDisk = "disk"
+
+	defaultDataDir  = "/app/data"
+	defaultCacheDir = "cache"
+	defaultSegments = 16
+)
+
+// ErrTooLarge 单个条目超过缓存字节数上限
+var ErrTooLarge = errors.New("cache entry exceeds max_bytes")
+
+// Cache 键值缓存。值以 JSON 编码保存（磁盘后端据此持久化），Get 解码到 v；
+// ttl <= 0 表示条目不过期，只在超出容量时按 LRU 淘汰。实现需并发安全。
+type Cache interface {
+	// Get 读取未过期的条目并解码到 v，未命中或解码失败时返回 false
+	Get(key string, v interface{}) bool
+	// Set 写入条目，覆盖同名条目并重置 TTL
+	Set(key string, v interface{}, ttl time.Duration) error
+	// TTL 返回条目剩余有效期，不过期的条目返回 0
+	TTL(key string) (time.Duration, bool)
+	// Invalidate 删除条目
+	Invalidate(key string)
+	// InvalidatePrefix 删除键以 prefix 开头的全部条目，返回删除数量
+	InvalidatePrefix(prefix string) int
+	// Keys 返回键以 prefix 开头的未过期条目（已排序）
+	Keys(prefix string) []string
+	// Stats 返回缓存统计
+	Stats() Stats
+	// Flush 持久化未保存的变更，内存后端为空操作
+	Flush() error
+}
+
+// Stats 缓存统计
+type Stats struct {
+	Name        string `json:"name"`
+	Backend     string `json:"backend"`
+	Entries     int    `json:"entries"`
+	SizeBytes   int64  `json:"size_bytes"`
+	MaxEntries  int    `json:"max_entries,omitempty"`
+	MaxBytes    int64  `json:"max_bytes,omitempty"`
+	Hits        uint64 `json:"hits"`
+	Misses      uint64 `json:"misses"`
+	Evictions   uint64 `json:"evictions"`
+	Expirations uint64 `json:"expirations"`
+}
+
+// Options 缓存参数
+type Options struct {
+	// Name 缓存名，同时作为 multicloud_cache_* 指标的 cache_type 标签
+	Name string
+	// MaxEntries 条目数上限，0 表示不限制
+	MaxEntries int
+	// MaxBytes 键与编码后值的总字节数上限，0 表示不限制
+	MaxBytes int64
+}
+
+var (
+	mu       sync.Mutex
+	backend  = BackendMemory
+	baseOpts Options
+	diskDir  string
+	segments = defaultSegments
+	opened   = make(map[string]Cache)
+)
+
+// Configure 按 server.cache 设置 Open 使用的后端，conf 为 nil 时使用不限容量的内存后端。
+// 磁盘后端目录默认为 <region_discovery.data_dir>/cache。已打开的缓存不受影响。
+func Configure(conf *config.CacheConf, dataDir string) {
+	mu.Lock()
+	defer mu.Unlock()
+	backend = BackendMemory
+	baseOpts = Options{}
+	diskDir = ""
+	segments = defaultSegments
+	opened = make(map[string]Cache)
+	if conf == nil {
+		return
+	}
+	if conf.Backend != "" {
+		backend = conf.Backend
+	}
+	baseOpts = Options{MaxEntries: conf.MaxEntries, MaxBytes: conf.MaxBytes}
+	if conf.Segments > 0 {
+		segments = conf.Segments
+	}
+	diskDir = conf.Dir
+	if diskDir == "" {
+		if dataDir == "" {
+			dataDir = defaultDataDir
+		}
+		diskDir = filepath.Join(dataDir, defaultCacheDir)
+	}
+}
+
+// Open 按当前配置打开名为 name 的缓存。磁盘后端的同名缓存在进程内共享同一实例，
+// 首次打开时加载已持久化的条目；打开失败时退化为内存缓存。内存后端每次返回新实例。
+func Open(name string) Cache {
+	mu.Lock()
+	defer mu.Unlock()
+	opts := baseOpts
+	opts.Name = name
+	if backend != BackendDisk {
+		return NewMemory(opts)
+	}
+	if c, ok := opened[name]; ok {
+		return c
+	}
+	c, err := NewDisk(filepath.Join(diskDir, name), segments, opts)
+	if err != nil {
+		ctxLog := logger.NewContextLogger("Cache", "resource_type", "Persistence")
+		ctxLog.Warnf("打开磁盘缓存失败，使用内存缓存 name=%s: %v", name, err)
+		return NewMemory(opts)
+	}
+	opened[name] = c
+	return c
+}
+
+// FlushAll 持久化全部已打开的磁盘缓存，返回遇到的第一个错误
+func FlushAll() error {
+	mu.Lock()
+	caches := make([]Cache, 0, len(opened))
+	for _, c := range opened {
+		caches = append(caches, c)
+	}
+	mu.Unlock()
+	var first error
+	for _, c := range caches {
+		if err := c.Flush(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
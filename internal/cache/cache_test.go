@@ -0,0 +1,215 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"multicloud-exporter/internal/config"
+	"multicloud-exporter/internal/metrics"
+)
+
+type testEntry struct {
+	IDs       []string
+	UpdatedAt time.Time
+}
+
+func TestMemory_GetSetTTL(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	m := NewMemory(Options{Name: "test_memory"})
+	m.now = func() time.Time { return now }
+
+	if err := m.Set("a", testEntry{IDs: []string{"i-1"}}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Set("b", "forever", 0); err != nil {
+		t.Fatal(err)
+	}
+	var got testEntry
+	if !m.Get("a", &got) || len(got.IDs) != 1 || got.IDs[0] != "i-1" {
+		t.Fatalf("Get(a) = %+v", got)
+	}
+	if ttl, ok := m.TTL("a"); !ok || ttl != time.Minute {
+		t.Fatalf("TTL(a) = %v %v", ttl, ok)
+	}
+	if ttl, ok := m.TTL("b"); !ok || ttl != 0 {
+		t.Fatalf("TTL(b) = %v %v", ttl, ok)
+	}
+
+	now = now.Add(time.Minute)
+	if m.Get("a", &got) {
+		t.Fatal("expired entry should miss")
+	}
+	var s string
+	if !m.Get("b", &s) || s != "forever" {
+		t.Fatalf("Get(b) = %q", s)
+	}
+	st := m.Stats()
+	if st.Entries != 1 || st.Hits != 2 || st.Misses != 1 || st.Expirations != 1 {
+		t.Fatalf("stats = %+v", st)
+	}
+	if got := testutil.ToFloat64(metrics.CacheEntriesTotal.WithLabelValues("test_memory")); got != 1 {
+		t.Fatalf("entries metric = %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.CacheSizeBytes.WithLabelValues("test_memory")); got != float64(st.SizeBytes) {
+		t.Fatalf("size metric = %v, want %d", got, st.SizeBytes)
+	}
+}
+
+func TestMemory_EvictionAndInvalidate(t *testing.T) {
+	m := NewMemory(Options{MaxEntries: 2})
+	_ = m.Set("acc|r1|a", 1, 0)
+	_ = m.Set("acc|r1|b", 2, 0)
+	var v int
+	m.Get("acc|r1|a", &v) // a 成为最近访问
+	_ = m.Set("acc|r2|c", 3, 0)
+	if m.Get("acc|r1|b", &v) {
+		t.Fatal("least recently used entry should be evicted")
+	}
+	if keys := m.Keys("acc|"); len(keys) != 2 || keys[0] != "acc|r1|a" || keys[1] != "acc|r2|c" {
+		t.Fatalf("keys = %v", keys)
+	}
+	if n := m.InvalidatePrefix("acc|r1|"); n != 1 {
+		t.Fatalf("InvalidatePrefix = %d", n)
+	}
+	m.Invalidate("acc|r2|c")
+	if st := m.Stats(); st.Entries != 0 || st.SizeBytes != 0 || st.Evictions != 1 {
+		t.Fatalf("stats = %+v", st)
+	}
+
+	small := NewMemory(Options{MaxBytes: 16})
+	if err := small.Set("k", "a value that is too large", 0); err != ErrTooLarge {
+		t.Fatalf("Set oversized = %v", err)
+	}
+	_ = small.Set("k1", "12345", 0) // 2 + 7 字节
+	_ = small.Set("k2", "12345", 0)
+	if st := small.Stats(); st.Entries != 1 || st.SizeBytes > 16 {
+		t.Fatalf("byte limit not enforced: %+v", st)
+	}
+}
+
+func TestDisk_PersistsAcrossReopen(t *testing.T) {
+	dir := t.TempDir()
+	d, err := NewDisk(dir, 4, Options{Name: "test_disk"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated := time.Unix(1700000000, 0).UTC()
+	_ = d.Set("acc|cn-hangzhou|acs_slb|clb", testEntry{IDs: []string{"lb-1", "lb-2"}, UpdatedAt: updated}, 0)
+	_ = d.Set("acc|cn-beijing|acs_slb|clb", testEntry{IDs: []string{"lb-3"}, UpdatedAt: updated}, time.Hour)
+	_ = d.Set("short", "gone", time.Nanosecond)
+	if err := d.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewDisk(dir, 4, Options{Name: "test_disk"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got testEntry
+	if !reopened.Get("acc|cn-hangzhou|acs_slb|clb", &got) || len(got.IDs) != 2 || !got.UpdatedAt.Equal(updated) {
+		t.Fatalf("reloaded entry = %+v", got)
+	}
+	if ttl, ok := reopened.TTL("acc|cn-beijing|acs_slb|clb"); !ok || ttl <= 0 || ttl > time.Hour {
+		t.Fatalf("reloaded TTL = %v %v", ttl, ok)
+	}
+	if st := reopened.Stats(); st.Entries != 2 || st.Backend != BackendDisk {
+		t.Fatalf("stats = %+v", st)
+	}
+
+	// 删除后持久化，重新加载时不再出现；分段数变化时条目仍可加载
+	reopened.Invalidate("acc|cn-hangzhou|acs_slb|clb")
+	if err := reopened.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	resized, err := NewDisk(dir, 2, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := resized.Keys(""); len(keys) != 1 || keys[0] != "acc|cn-beijing|acs_slb|clb" {
+		t.Fatalf("keys after resize = %v", keys)
+	}
+	if err := resized.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "segment-*.json"))
+	for _, f := range files {
+		var idx int
+		if _, err := fmt.Sscanf(filepath.Base(f), "segment-%03d.json", &idx); err != nil || idx >= 2 {
+			t.Fatalf("stale segment file left after resize: %s", f)
+		}
+	}
+}
+
+func TestDisk_FlushRewritesOnlyDirtySegments(t *testing.T) {
+	dir := t.TempDir()
+	d, err := NewDisk(dir, 8, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{"a", "b", "c", "d", "e", "f"} {
+		_ = d.Set(k, k, 0)
+	}
+	if err := d.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Unix(1600000000, 0)
+	files, _ := filepath.Glob(filepath.Join(dir, "segment-*.json"))
+	for _, f := range files {
+		_ = os.Chtimes(f, old, old)
+	}
+
+	_ = d.Set("a", "changed", 0)
+	if err := d.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	changed := 0
+	for _, f := range files {
+		fi, err := os.Stat(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !fi.ModTime().Equal(old) {
+			changed++
+			if f != d.segmentPath(d.segment("a")) {
+				t.Fatalf("unexpected rewrite of %s", f)
+			}
+		}
+	}
+	if changed != 1 {
+		t.Fatalf("rewritten segments = %d, want 1", changed)
+	}
+}
+
+func TestOpen_Backends(t *testing.T) {
+	defer Configure(nil, "")
+
+	Configure(nil, "")
+	a, b := Open("x"), Open("x")
+	_ = a.Set("k", 1, 0)
+	var v int
+	if b.Get("k", &v) {
+		t.Fatal("memory backend should return independent caches")
+	}
+
+	dataDir := t.TempDir()
+	Configure(&config.CacheConf{Backend: BackendDisk, MaxEntries: 10}, dataDir)
+	c := Open("aliyun_resources")
+	if c != Open("aliyun_resources") {
+		t.Fatal("disk backend should share the cache per name")
+	}
+	if st := c.Stats(); st.Backend != BackendDisk || st.MaxEntries != 10 {
+		t.Fatalf("stats = %+v", st)
+	}
+	_ = c.Set("k", 1, 0)
+	if err := FlushAll(); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dataDir, "cache", "aliyun_resources", "segment-*.json")); len(files) != 1 {
+		t.Fatalf("segment files = %v", files)
+	}
+}
@@ -0,0 +1,185 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const segmentVersion = 1
+
+// segmentFile 分段文件格式
+type segmentFile struct {
+	Version int            `json:"version"`
+	Entries []segmentEntry `json:"entries"`
+}
+
+type segmentEntry struct {
+	Key       string          `json:"key"`
+	Value     json.RawMessage `json:"value"`
+	ExpiresAt *time.Time      `json:"expires_at,omitempty"`
+}
+
+// Disk 磁盘缓存：条目保存在内存（读写与 Memory 一致），按键哈希划分到 N 个 JSON 分段文件，
+// Flush 时只原子重写有变更的分段，单个大分段的写入不会阻塞其它分段。
+type Disk struct {
+	*Memory
+	dir   string
+	n     int
+	dirty map[int]bool // 由 Memory.mu 保护
+
+	flushMu sync.Mutex // 串行化 Flush，避免并发写同一分段的临时文件
+}
+
+// NewDisk 在 dir 下创建或加载磁盘缓存，segments 为分段数（<= 0 时使用默认值 16）。
+// 分段数变化后旧文件中的条目仍会加载，并在下次 Flush 时按新分段重写。
+func NewDisk(dir string, segments int, opts Options) (*Disk, error) {
+	if segments <= 0 {
+		segments = defaultSegments
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	d := &Disk{Memory: newMemory(opts, BackendDisk), dir: dir, n: segments, dirty: make(map[int]bool)}
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+	d.report()
+	return d, nil
+}
+
+func (d *Disk) segment(key string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(d.n))
+}
+
+func (d *Disk) segmentPath(i int) string {
+	return filepath.Join(d.dir, fmt.Sprintf("segment-%03d.json", i))
+}
+
+// load 读取全部分段文件，跳过已过期的条目；含过期、错位或损坏条目的分段以及超出当前分段数的文件
+// 标记为变更，下次 Flush 时重写或删除
+func (d *Disk) load() error {
+	files, err := filepath.Glob(filepath.Join(d.dir, "segment-*.json"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	now := d.now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, f := range files {
+		idx, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(filepath.Base(f), "segment-"), ".json"))
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		var seg segmentFile
+		if err := json.Unmarshal(data, &seg); err != nil || seg.Version != segmentVersion {
+			// 损坏或版本不兼容的分段直接丢弃
+			d.dirty[idx] = true
+			continue
+		}
+		if idx >= d.n {
+			d.dirty[idx] = true
+		}
+		for _, se := range seg.Entries {
+			e := &entry{key: se.Key, value: se.Value}
+			if se.ExpiresAt != nil {
+				e.expiresAt = *se.ExpiresAt
+			}
+			if e.expired(now) {
+				d.dirty[idx] = true
+				continue
+			}
+			d.putLocked(e)
+			if target := d.segment(e.key); target != idx {
+				d.dirty[idx] = true
+				d.dirty[target] = true
+			}
+		}
+	}
+	// 加载完成后再跟踪变更，超出容量的淘汰需要写回
+	d.onChange = func(key string) { d.dirty[d.segment(key)] = true }
+	d.evictLocked()
+	return nil
+}
+
+// Flush 原子重写有变更的分段（先写临时文件再重命名），空分段删除文件
+func (d *Disk) Flush() error {
+	d.flushMu.Lock()
+	defer d.flushMu.Unlock()
+	d.mu.Lock()
+	if len(d.dirty) == 0 {
+		d.mu.Unlock()
+		return nil
+	}
+	now := d.now()
+	segs := make(map[int]*segmentFile, len(d.dirty))
+	for i := range d.dirty {
+		segs[i] = &segmentFile{Version: segmentVersion}
+	}
+	for el := d.lru.Back(); el != nil; el = el.Prev() {
+		e := el.Value.(*entry)
+		seg, ok := segs[d.segment(e.key)]
+		if !ok || e.expired(now) {
+			continue
+		}
+		se := segmentEntry{Key: e.key, Value: e.value}
+		if !e.expiresAt.IsZero() {
+			t := e.expiresAt
+			se.ExpiresAt = &t
+		}
+		seg.Entries = append(seg.Entries, se)
+	}
+	d.dirty = make(map[int]bool)
+	d.mu.Unlock()
+
+	var first error
+	for i, seg := range segs {
+		if err := d.writeSegment(i, seg); err != nil {
+			if first == nil {
+				first = err
+			}
+			d.mu.Lock()
+			d.dirty[i] = true
+			d.mu.Unlock()
+		}
+	}
+	return first
+}
+
+func (d *Disk) writeSegment(i int, seg *segmentFile) error {
+	path := d.segmentPath(i)
+	if len(seg.Entries) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.Marshal(seg)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+package cache
+
+import (
+	"container/list"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"multicloud-exporter/internal/metrics"
+)
+
+// entry 缓存条目，value 为 JSON 编码后的值
+type entry struct {
+	key       string
+	value     json.RawMessage
+	expiresAt time.Time // 零值表示不过期
+}
+
+func (e *entry) size() int64 {
+	return int64(len(e.key) + len(e.value))
+}
+
+func (e *entry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// Memory 进程内 LRU 缓存，超出条目数或字节数上限时淘汰最久未访问的条目
+type Memory struct {
+	opts    Options
+	backend string
+
+	mu    sync.Mutex
+	items map[string]*list.Element
+	lru   *list.List // 头部为最近访问
+	size  int64
+	stats Stats
+
+	now      func() time.Time
+	onChange func(key string) // 条目写入或删除后回调（持有 mu），磁盘后端据此标记分段
+}
+
+// NewMemory 创建内存缓存
+func NewMemory(opts Options) *Memory {
+	return newMemory(opts, BackendMemory)
+}
+
+func newMemory(opts Options, backend string) *Memory {
+	m := &Memory{
+		opts:    opts,
+		backend: backend,
+		items:   make(map[string]*list.Element),
+		lru:     list.New(),
+		now:     time.Now,
+	}
+	m.report()
+	return m
+}
+
+// Get 读取未过期的条目并解码到 v
+func (m *Memory) Get(key string, v interface{}) bool {
+	m.mu.Lock()
+	e, expired := m.lookupLocked(key)
+	if e == nil {
+		m.stats.Misses++
+		m.mu.Unlock()
+		if expired {
+			m.report()
+		}
+		return false
+	}
+	m.stats.Hits++
+	raw := e.value
+	m.mu.Unlock()
+	return json.Unmarshal(raw, v) == nil
+}
+
+// Set 写入条目；单个条目超过 MaxBytes 时返回 ErrTooLarge 且不写入
+func (m *Memory) Set(key string, v interface{}, ttl time.Duration) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	e := &entry{key: key, value: raw}
+	if ttl > 0 {
+		e.expiresAt = m.now().Add(ttl)
+	}
+	if m.opts.MaxBytes > 0 && e.size() > m.opts.MaxBytes {
+		return ErrTooLarge
+	}
+	m.mu.Lock()
+	m.putLocked(e)
+	m.evictLocked()
+	m.mu.Unlock()
+	m.report()
+	return nil
+}
+
+// TTL 返回条目剩余有效期，不过期的条目返回 0
+func (m *Memory) TTL(key string) (time.Duration, bool) {
+	m.mu.Lock()
+	e, expired := m.lookupLocked(key)
+	m.mu.Unlock()
+	if expired {
+		m.report()
+	}
+	if e == nil {
+		return 0, false
+	}
+	if e.expiresAt.IsZero() {
+		return 0, true
+	}
+	return e.expiresAt.Sub(m.now()), true
+}
+
+// Invalidate 删除条目
+func (m *Memory) Invalidate(key string) {
+	m.mu.Lock()
+	if el, ok := m.items[key]; ok {
+		m.removeLocked(el)
+	}
+	m.mu.Unlock()
+	m.report()
+}
+
+// InvalidatePrefix 删除键以 prefix 开头的全部条目
+func (m *Memory) InvalidatePrefix(prefix string) int {
+	m.mu.Lock()
+	n := 0
+	for key, el := range m.items {
+		if strings.HasPrefix(key, prefix) {
+			m.removeLocked(el)
+			n++
+		}
+	}
+	m.mu.Unlock()
+	if n > 0 {
+		m.report()
+	}
+	return n
+}
+
+// Keys 返回键以 prefix 开头的未过期条目
+func (m *Memory) Keys(prefix string) []string {
+	m.mu.Lock()
+	now := m.now()
+	var out []string
+	for key, el := range m.items {
+		if strings.HasPrefix(key, prefix) && !el.Value.(*entry).expired(now) {
+			out = append(out, key)
+		}
+	}
+	m.mu.Unlock()
+	sort.Strings(out)
+	return out
+}
+
+// Stats 返回缓存统计
+func (m *Memory) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.statsLocked()
+}
+
+// Flush 内存缓存无需持久化
+func (m *Memory) Flush() error {
+	return nil
+}
+
+func (m *Memory) statsLocked() Stats {
+	s := m.stats
+	s.Name = m.opts.Name
+	s.Backend = m.backend
+	s.Entries = len(m.items)
+	s.SizeBytes = m.size
+	s.MaxEntries = m.opts.MaxEntries
+	s.MaxBytes = m.opts.MaxBytes
+	return s
+}
+
+// lookupLocked 返回未过期的条目并提升为最近访问；过期条目在此删除，expired 为 true
+func (m *Memory) lookupLocked(key string) (e *entry, expired bool) {
+	el, ok := m.items[key]
+	if !ok {
+		return nil, false
+	}
+	e = el.Value.(*entry)
+	if e.expired(m.now()) {
+		m.removeLocked(el)
+		m.stats.Expirations++
+		return nil, true
+	}
+	m.lru.MoveToFront(el)
+	return e, false
+}
+
+func (m *Memory) putLocked(e *entry) {
+	if el, ok := m.items[e.key]; ok {
+		m.size -= el.Value.(*entry).size()
+		el.Value = e
+		m.lru.MoveToFront(el)
+	} else {
+		m.items[e.key] = m.lru.PushFront(e)
+	}
+	m.size += e.size()
+	if m.onChange != nil {
+		m.onChange(e.key)
+	}
+}
+
+func (m *Memory) removeLocked(el *list.Element) {
+	e := el.Value.(*entry)
+	m.lru.Remove(el)
+	delete(m.items, e.key)
+	m.size -= e.size()
+	if m.onChange != nil {
+		m.onChange(e.key)
+	}
+}
+
+// evictLocked 超出上限时先清理过期条目，再从最久未访问的条目开始淘汰
+func (m *Memory) evictLocked() {
+	if !m.overLocked() {
+		return
+	}
+	now := m.now()
+	for el := m.lru.Back(); el != nil; {
+		prev := el.Prev()
+		if el.Value.(*entry).expired(now) {
+			m.removeLocked(el)
+			m.stats.Expirations++
+		}
+		el = prev
+	}
+	for m.overLocked() {
+		el := m.lru.Back()
+		if el == nil {
+			return
+		}
+		m.removeLocked(el)
+		m.stats.Evictions++
+	}
+}
+
+func (m *Memory) overLocked() bool {
+	return (m.opts.MaxEntries > 0 && len(m.items) > m.opts.MaxEntries) ||
+		(m.opts.MaxBytes > 0 && m.size > m.opts.MaxBytes)
+}
+
+// report 更新 multicloud_cache_size_bytes / multicloud_cache_entries_total
+func (m *Memory) report() {
+	if m.opts.Name == "" {
+		return
+	}
+	m.mu.Lock()
+	size, n := m.size, len(m.items)
+	m.mu.Unlock()
+	metrics.UpdateCacheMetrics(m.opts.Name, size, n)
+}
@@ -2,6 +2,7 @@
 package collector
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strings"
@@ -9,6 +10,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"multicloud-exporter/internal/cache"
 	"multicloud-exporter/internal/config"
 	"multicloud-exporter/internal/discovery"
 	"multicloud-exporter/internal/logger"
@@ -16,6 +18,7 @@ import (
 	"multicloud-exporter/internal/providers"
 	_ "multicloud-exporter/internal/providers/aliyun"
 	_ "multicloud-exporter/internal/providers/aws"
+	providerscommon "multicloud-exporter/internal/providers/common"
 	_ "multicloud-exporter/internal/providers/huawei"
 	_ "multicloud-exporter/internal/providers/tencent"
 )
@@ -27,11 +30,28 @@ type Status struct {
 	Duration     string                 `json:"duration"`
 	LastResults  map[string]AccountStat `json:"last_results"` // key: provider|account_id
 	SampleCounts map[string]int         `json:"sample_counts"`
+	// Schedules 产品级调度状态（上次运行/下次到期）
+	Schedules []providerscommon.ScheduleEntry `json:"schedules,omitempty"`
+	// Running 进行中的采集，空闲时为空
+	Running *RunInfo `json:"running,omitempty"`
+	// Budget 每日云 API 预算用量与月度成本估算，未配置预算时为空
+	Budget *providerscommon.BudgetStatus `json:"budget,omitempty"`
+	// CircuitBreakers 账号/区域熔断器状态，未启用熔断或尚无错误时为空
+	CircuitBreakers []providerscommon.BreakerEntry `json:"circuit_breakers,omitempty"`
 }
 
 type AccountStat struct {
 	Timestamp time.Time `json:"timestamp"`
-	Status    string    `json:"status"` // "running", "completed"
+	Status    string    `json:"status"` // "running", "completed", "failed", "skipped"（熔断中）
+	// Error 账号采集失败原因（未知云平台、panic、认证失败等）
+	Error string `json:"error,omitempty"`
+	// Errors 本轮采集中记录的错误状态计数，如 {"auth_error": 2}
+	Errors map[string]int `json:"errors,omitempty"`
+	// Samples 本轮导出的样本数
+	Samples int `json:"samples"`
+	// Targets/FailedTargets 本轮采集的目标（区域/命名空间）数与失败数
+	Targets       int `json:"targets"`
+	FailedTargets int `json:"failed_targets"`
 }
 
 // Collector 持有配置与各云采集器实例
@@ -41,6 +61,12 @@ type Collector struct {
 	providers  map[string]providers.Provider
 	status     Status
 	statusLock sync.RWMutex
+
+	// 采集运行协调（run.go）：进行中与排队的采集、单轮截止时间
+	runMu        sync.Mutex
+	current      *runState
+	pending      *pendingRun
+	cycleTimeout time.Duration
 }
 
 // NewCollector 创建调度器并初始化各云采集器
@@ -72,24 +98,99 @@ func (c *Collector) GetStatus() Status {
 		res[k] = v
 	}
 	return Status{
-		LastStart:   c.status.LastStart,
-		LastEnd:     c.status.LastEnd,
-		Duration:    c.status.Duration,
-		LastResults: res,
+		LastStart:       c.status.LastStart,
+		LastEnd:         c.status.LastEnd,
+		Duration:        c.status.Duration,
+		LastResults:     res,
+		Schedules:       c.scheduleEntries(),
+		Running:         c.Running(),
+		Budget:          providerscommon.BudgetSnapshot(),
+		CircuitBreakers: providerscommon.BreakerEntries(),
+	}
+}
+
+// scheduleEntries 汇总各云采集器的产品级调度状态
+func (c *Collector) scheduleEntries() []providerscommon.ScheduleEntry {
+	var out []providerscommon.ScheduleEntry
+	for _, p := range c.providers {
+		if sp, ok := providers.Underlying(p).(providerscommon.ScheduleProvider); ok {
+			out = append(out, sp.Scheduler().Entries()...)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// Inventory 汇总各云采集器上报的资源清单（实现 InventoryProvider 的采集器），已排序
+func (c *Collector) Inventory() []providerscommon.InventoryItem {
+	var out []providerscommon.InventoryItem
+	for _, p := range c.providers {
+		if ip, ok := providers.Underlying(p).(providerscommon.InventoryProvider); ok {
+			out = append(out, ip.Inventory()...)
+		}
+	}
+	providerscommon.SortInventory(out)
+	return out
+}
+
+// observeInventory 将本轮资源清单交给发现管理器，生成资源增减事件
+func (c *Collector) observeInventory() {
+	if c.disc == nil {
+		return
+	}
+	items := c.Inventory()
+	refs := make([]discovery.ResourceRef, 0, len(items))
+	for _, it := range items {
+		refs = append(refs, discovery.ResourceRef{
+			Provider:   it.Provider,
+			AccountID:  it.AccountID,
+			Region:     it.Region,
+			Namespace:  it.Namespace,
+			ResourceID: it.ResourceID,
+		})
 	}
+	c.disc.ObserveInventory(refs)
+}
+
+// ResetSchedules 清空各云采集器的产品级调度状态，下一轮全部产品立即采集
+func (c *Collector) ResetSchedules() {
+	for _, p := range c.providers {
+		if sp, ok := providers.Underlying(p).(providerscommon.ScheduleProvider); ok {
+			sp.Scheduler().Reset()
+		}
+	}
+}
+
+// CollectFiltered 执行带过滤条件的采集（经 Run 协调，其他采集进行中时跳过）
+func (c *Collector) CollectFiltered(ctx context.Context, filterProvider, filterResource string) {
+	_ = c.Run(ctx, RunRequest{Provider: filterProvider, Resource: filterResource})
+}
+
+// CollectFilteredAccount 执行带过滤条件的采集，filterAccount 非空时仅采集该账号
+func (c *Collector) CollectFilteredAccount(ctx context.Context, filterProvider, filterResource, filterAccount string) {
+	_ = c.Run(ctx, RunRequest{Provider: filterProvider, Resource: filterResource, Account: filterAccount})
 }
 
-// CollectFiltered 执行带过滤条件的采集
-func (c *Collector) CollectFiltered(filterProvider, filterResource string) {
-	c.collectInternal(filterProvider, filterResource)
+// Collect 为每个账号并发执行采集任务；ctx 取消或超时后各云采集器尽快中止进行中的调用
+func (c *Collector) Collect(ctx context.Context) {
+	_ = c.Run(ctx, RunRequest{})
 }
 
-// Collect 为每个账号并发执行采集任务
-func (c *Collector) Collect() {
-	c.collectInternal("", "")
+// FailedAccounts 返回最近一轮采集失败的账号（provider|account_id），按字典序排序
+func (c *Collector) FailedAccounts() []string {
+	c.statusLock.RLock()
+	defer c.statusLock.RUnlock()
+	var out []string
+	for k, st := range c.status.LastResults {
+		if st.Status == "failed" {
+			out = append(out, k)
+		}
+	}
+	sort.Strings(out)
+	return out
 }
 
-func (c *Collector) collectInternal(filterProvider, filterResource string) {
+func (c *Collector) collectInternal(ctx context.Context, filterProvider, filterResource, filterAccount string) {
 	c.cfg.Mu.RLock()
 	var accounts []config.CloudAccount
 	if c.cfg.AccountsByProvider != nil {
@@ -102,13 +203,17 @@ func (c *Collector) collectInternal(filterProvider, filterResource string) {
 	}
 	c.cfg.Mu.RUnlock()
 
-	// Filter accounts if provider is specified
-	if filterProvider != "" {
+	// Filter accounts if provider or account is specified
+	if filterProvider != "" || filterAccount != "" {
 		var filtered []config.CloudAccount
 		for _, acc := range accounts {
-			if acc.Provider == filterProvider {
-				filtered = append(filtered, acc)
+			if filterProvider != "" && acc.Provider != filterProvider {
+				continue
+			}
+			if filterAccount != "" && acc.AccountID != filterAccount {
+				continue
 			}
+			filtered = append(filtered, acc)
 		}
 		accounts = filtered
 	}
@@ -136,6 +241,20 @@ func (c *Collector) collectInternal(filterProvider, filterResource string) {
 		// Future optimization: If a provider does NOT support internal sharding,
 		// we should handle it here or enforce them to implement it.
 
+		// 账号熔断中（如 AccessKey 已失效）时跳过，熔断到期后放行一轮作为探测
+		if !providerscommon.AllowAccount(account.Provider, account.AccountID) {
+			ctxLog := logger.NewContextLogger("Collector", "provider", account.Provider, "account_id", account.AccountID)
+			ctxLog.Debugf("账号跳过（熔断中）")
+			c.statusLock.Lock()
+			c.status.LastResults[account.Provider+"|"+account.AccountID] = AccountStat{
+				Timestamp: time.Now(),
+				Status:    "skipped",
+				Error:     "circuit breaker open",
+			}
+			c.statusLock.Unlock()
+			continue
+		}
+
 		c.statusLock.Lock()
 		c.status.LastResults[account.Provider+"|"+account.AccountID] = AccountStat{
 			Timestamp: time.Now(),
@@ -148,15 +267,28 @@ func (c *Collector) collectInternal(filterProvider, filterResource string) {
 			defer wg.Done()
 			ctxLog := logger.NewContextLogger("Collector", "provider", acc.Provider, "account_id", acc.AccountID)
 			ctxLog.Debugf("开始账号采集")
-			c.collectAccount(acc, filterResource)
+			res, err := c.collectAccount(ctx, acc, filterResource)
 			atomic.AddInt32(&completedCount, 1)
-			ctxLog.Debugf("完成账号采集")
+			ctxLog.Debugf("完成账号采集 目标数=%d 失败目标数=%d 样本数=%d 耗时=%v", len(res.Targets), res.FailedTargets(), res.Samples, res.Duration)
 
-			c.statusLock.Lock()
-			c.status.LastResults[acc.Provider+"|"+acc.AccountID] = AccountStat{
-				Timestamp: time.Now(),
-				Status:    "completed",
+			stat := AccountStat{
+				Timestamp:     time.Now(),
+				Status:        "completed",
+				Errors:        res.Errors,
+				Samples:       res.Samples,
+				Targets:       len(res.Targets),
+				FailedTargets: res.FailedTargets(),
+			}
+			if err == nil {
+				err = res.Failure()
 			}
+			if err != nil {
+				stat.Status = "failed"
+				stat.Error = err.Error()
+				ctxLog.Errorf("账号采集失败: %v", err)
+			}
+			c.statusLock.Lock()
+			c.status.LastResults[acc.Provider+"|"+acc.AccountID] = stat
 			c.statusLock.Unlock()
 		}(account)
 	}
@@ -169,6 +301,21 @@ func (c *Collector) collectInternal(filterProvider, filterResource string) {
 	c.status.SampleCounts = metrics.GetSampleCounts()
 	c.statusLock.Unlock()
 
+	if err := providerscommon.SaveBudgetUsage(); err != nil {
+		ctxLog := logger.NewContextLogger("Collector", "resource_type", "Budget")
+		ctxLog.Warnf("保存预算用量失败: %v", err)
+	}
+	if err := providerscommon.SaveRegionStatus(); err != nil {
+		ctxLog := logger.NewContextLogger("Collector", "resource_type", "RegionManager")
+		ctxLog.Warnf("保存区域状态失败: %v", err)
+	}
+	providerscommon.PublishRegionMetrics()
+	c.observeInventory()
+	if err := cache.FlushAll(); err != nil {
+		ctxLog := logger.NewContextLogger("Collector", "resource_type", "Cache")
+		ctxLog.Warnf("保存缓存失败: %v", err)
+	}
+
 	// 输出采集完成日志，包含详细信息
 	collectionLog := logger.NewContextLogger("Collector", "resource_type", "Collection")
 	collectionLog.Infof("采集完成，账号数量=%d，已完成=%d，总耗时: %v", len(accounts), completedCount, duration)
@@ -202,14 +349,19 @@ func buildAccountInfo(accounts []config.CloudAccount) string {
 	return accountInfo.String()
 }
 
-// collectAccount 规范化资源类型并路由到对应云采集器
-func (c *Collector) collectAccount(account config.CloudAccount, filterResource string) {
+// collectAccount 规范化资源类型并路由到对应云采集器，返回采集结果；未知云平台或采集 panic 时返回错误
+func (c *Collector) collectAccount(ctx context.Context, account config.CloudAccount, filterResource string) (res providers.CollectResult, err error) {
 	p, ok := c.providers[account.Provider]
 	if !ok {
 		ctxLog := logger.NewContextLogger("Collector", "provider", account.Provider, "account_id", account.AccountID)
 		ctxLog.Warnf("未知的云平台")
-		return
+		return res, fmt.Errorf("unknown provider %q", account.Provider)
 	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
 
 	if filterResource != "" {
 		// Only collect specified resource
@@ -218,5 +370,5 @@ func (c *Collector) collectAccount(account config.CloudAccount, filterResource s
 		account.Resources = p.GetDefaultResources()
 	}
 
-	p.Collect(account)
+	return p.Collect(ctx, account), nil
 }
@@ -1,12 +1,15 @@
 package collector
 
 import (
+	"context"
 	"sync"
 	"testing"
+	"time"
 
 	"multicloud-exporter/internal/config"
 	"multicloud-exporter/internal/discovery"
 	"multicloud-exporter/internal/providers"
+	providerscommon "multicloud-exporter/internal/providers/common"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -31,7 +34,7 @@ func TestCollector_Collect(t *testing.T) {
 	// 1. Setup Mock Provider
 	mockP := &MockProvider{}
 	providers.Register("mock_cloud", func(cfg *config.Config, mgr *discovery.Manager) providers.Provider {
-		return mockP
+		return providers.Adapt(mockP)
 	})
 
 	// 2. Config
@@ -59,7 +62,7 @@ func TestCollector_Collect(t *testing.T) {
 	c := NewCollector(cfg, mgr)
 
 	// 4. Run Collect
-	c.Collect()
+	c.Collect(context.Background())
 
 	// 5. Verify
 	mockP.mu.Lock()
@@ -77,7 +80,7 @@ func TestCollector_CollectFiltered(t *testing.T) {
 	// 1. Setup Mock Provider
 	mockP := &MockProvider{}
 	providers.Register("mock_cloud_filter", func(cfg *config.Config, mgr *discovery.Manager) providers.Provider {
-		return mockP
+		return providers.Adapt(mockP)
 	})
 
 	// 2. Config
@@ -102,7 +105,7 @@ func TestCollector_CollectFiltered(t *testing.T) {
 	c := NewCollector(cfg, mgr)
 
 	// 3. Collect Filtered (should match)
-	c.CollectFiltered("mock_cloud_filter", "")
+	c.CollectFiltered(context.Background(), "mock_cloud_filter", "")
 	mockP.mu.Lock()
 	called := mockP.CollectCalled
 	mockP.mu.Unlock()
@@ -114,7 +117,7 @@ func TestCollector_CollectFiltered(t *testing.T) {
 	mockP.mu.Unlock()
 
 	// 4. Collect Filtered (should not match)
-	c.CollectFiltered("other_cloud", "")
+	c.CollectFiltered(context.Background(), "other_cloud", "")
 	mockP.mu.Lock()
 	called = mockP.CollectCalled
 	mockP.mu.Unlock()
@@ -128,7 +131,7 @@ func BenchmarkCollector_Collect(b *testing.B) {
 	// Setup Mock Provider
 	mockP := &MockProvider{}
 	providers.Register("mock_cloud_bench", func(cfg *config.Config, mgr *discovery.Manager) providers.Provider {
-		return mockP
+		return providers.Adapt(mockP)
 	})
 
 	// Create a realistic configuration with multiple accounts and regions
@@ -161,7 +164,7 @@ func BenchmarkCollector_Collect(b *testing.B) {
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		c.Collect()
+		c.Collect(context.Background())
 	}
 }
 
@@ -169,7 +172,7 @@ func BenchmarkCollector_Collect(b *testing.B) {
 func BenchmarkCollector_CollectFiltered(b *testing.B) {
 	mockP := &MockProvider{}
 	providers.Register("mock_cloud_filtered_bench", func(cfg *config.Config, mgr *discovery.Manager) providers.Provider {
-		return mockP
+		return providers.Adapt(mockP)
 	})
 
 	cfg := &config.Config{
@@ -189,7 +192,7 @@ func BenchmarkCollector_CollectFiltered(b *testing.B) {
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		c.CollectFiltered("mock_cloud_filtered_bench", "")
+		c.CollectFiltered(context.Background(), "mock_cloud_filtered_bench", "")
 	}
 }
 
@@ -197,7 +200,7 @@ func BenchmarkCollector_CollectFiltered(b *testing.B) {
 func BenchmarkCollector_ConcurrentCollect(b *testing.B) {
 	mockP := &MockProvider{}
 	providers.Register("mock_cloud_concurrent", func(cfg *config.Config, mgr *discovery.Manager) providers.Provider {
-		return mockP
+		return providers.Adapt(mockP)
 	})
 
 	accounts := make([]config.CloudAccount, 5)
@@ -229,7 +232,150 @@ func BenchmarkCollector_ConcurrentCollect(b *testing.B) {
 
 	b.RunParallel(func(pb *testing.PB) {
 		for pb.Next() {
-			c.Collect()
+			c.Collect(context.Background())
 		}
 	})
 }
+
+// scheduledProvider 支持产品级调度的 Mock Provider
+type scheduledProvider struct {
+	MockProvider
+	sched *providerscommon.ProductScheduler
+}
+
+func (s *scheduledProvider) Scheduler() *providerscommon.ProductScheduler {
+	return s.sched
+}
+
+func TestCollector_ScheduleEntriesAndReset(t *testing.T) {
+	sp := &scheduledProvider{sched: providerscommon.NewProductScheduler()}
+	c := &Collector{
+		providers: map[string]providers.Provider{"mock_sched": providers.Adapt(sp), "mock_plain": providers.Adapt(&MockProvider{})},
+		status:    Status{LastResults: make(map[string]AccountStat)},
+	}
+	sp.sched.Due(providerscommon.ScheduleKey("mock_sched", "acc", "r1", "ns"), time.Hour)
+
+	st := c.GetStatus()
+	assert.Len(t, st.Schedules, 1)
+	assert.Equal(t, "mock_sched|acc|r1|ns", st.Schedules[0].Key)
+
+	c.ResetSchedules()
+	assert.Empty(t, c.GetStatus().Schedules)
+}
+
+// failingProvider 记录认证失败或直接 panic 的 Mock Provider
+type failingProvider struct {
+	MockProvider
+	panics bool
+}
+
+func (f *failingProvider) Collect(account config.CloudAccount) {
+	if f.panics {
+		panic("boom")
+	}
+	providerscommon.RecordAccountError(account.Provider, account.AccountID, providerscommon.ErrorStatusAuth)
+}
+
+func TestCollector_FailedAccounts(t *testing.T) {
+	cfg := &config.Config{
+		AccountsByProvider: map[string][]config.CloudAccount{
+			"mock_ok":      {{AccountID: "a1"}},
+			"mock_auth":    {{AccountID: "a2"}},
+			"mock_panic":   {{AccountID: "a3"}},
+			"mock_unknown": {{AccountID: "a4"}},
+		},
+	}
+	c := &Collector{
+		cfg: cfg,
+		providers: map[string]providers.Provider{
+			"mock_ok":    providers.Adapt(&MockProvider{}),
+			"mock_auth":  providers.Adapt(&failingProvider{}),
+			"mock_panic": providers.Adapt(&failingProvider{panics: true}),
+		},
+		status: Status{LastResults: make(map[string]AccountStat)},
+	}
+
+	c.Collect(context.Background())
+	assert.Equal(t, []string{"mock_auth|a2", "mock_panic|a3", "mock_unknown|a4"}, c.FailedAccounts())
+	st := c.GetStatus()
+	assert.Equal(t, "completed", st.LastResults["mock_ok|a1"].Status)
+	assert.Equal(t, 1, st.LastResults["mock_auth|a2"].Errors[providerscommon.ErrorStatusAuth])
+	assert.Contains(t, st.LastResults["mock_panic|a3"].Error, "panic")
+
+	// 按账号过滤
+	c.status.LastResults = make(map[string]AccountStat)
+	c.CollectFilteredAccount(context.Background(), "", "", "a1")
+	assert.Empty(t, c.FailedAccounts())
+	assert.Len(t, c.GetStatus().LastResults, 1)
+}
+
+// resultProvider 直接实现 Provider，按目标上报样本与错误
+type resultProvider struct{}
+
+func (resultProvider) Collect(ctx context.Context, account config.CloudAccount) providers.CollectResult {
+	ctx, rec := providerscommon.BeginCollect(ctx, account.Provider, account.AccountID)
+	ok := providerscommon.StartTarget(ctx, account.Provider, account.AccountID, "r1", "ns_ok")
+	providerscommon.RecordTargetSamples(account.Provider, account.AccountID, "r1", "ns_ok", 3)
+	ok.Finish()
+	bad := providerscommon.StartTarget(ctx, account.Provider, account.AccountID, "r2", "ns_bad")
+	providerscommon.RecordTargetError(account.Provider, account.AccountID, "r2", "ns_bad", providerscommon.ErrorStatusNetwork)
+	bad.Finish()
+	return rec.Finish(ctx)
+}
+
+func (resultProvider) GetDefaultResources() []string { return nil }
+
+func TestCollector_CollectResults(t *testing.T) {
+	c := &Collector{
+		cfg:       &config.Config{AccountsByProvider: map[string][]config.CloudAccount{"mock_result": {{AccountID: "r1"}}}},
+		providers: map[string]providers.Provider{"mock_result": resultProvider{}},
+		status:    Status{LastResults: make(map[string]AccountStat)},
+	}
+	c.Collect(context.Background())
+	st := c.GetStatus().LastResults["mock_result|r1"]
+	// 目标失败不等于账号失败
+	assert.Equal(t, "completed", st.Status)
+	assert.Equal(t, 3, st.Samples)
+	assert.Equal(t, 2, st.Targets)
+	assert.Equal(t, 1, st.FailedTargets)
+	assert.Equal(t, 1, st.Errors[providerscommon.ErrorStatusNetwork])
+}
+
+func TestCollector_CollectCanceled(t *testing.T) {
+	mockP := &MockProvider{}
+	c := &Collector{
+		cfg:       &config.Config{AccountsByProvider: map[string][]config.CloudAccount{"mock_cancel": {{AccountID: "c1"}}}},
+		providers: map[string]providers.Provider{"mock_cancel": providers.Adapt(mockP)},
+		status:    Status{LastResults: make(map[string]AccountStat)},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c.Collect(ctx)
+	assert.False(t, mockP.CollectCalled, "legacy provider should not run after cancel")
+	st := c.GetStatus().LastResults["mock_cancel|c1"]
+	assert.Equal(t, "failed", st.Status)
+	assert.Equal(t, context.Canceled.Error(), st.Error)
+}
+
+func TestCollector_CircuitBreakerSkipsAccount(t *testing.T) {
+	providerscommon.ConfigureCircuitBreakers(&config.CircuitBreakerConf{Enabled: true, FailureThreshold: 1, OpenDuration: "1h"})
+	defer providerscommon.ConfigureCircuitBreakers(nil)
+
+	failing := &failingProvider{}
+	c := &Collector{
+		cfg:       &config.Config{AccountsByProvider: map[string][]config.CloudAccount{"mock_cb": {{AccountID: "cb1"}}}},
+		providers: map[string]providers.Provider{"mock_cb": providers.Adapt(failing)},
+		status:    Status{LastResults: make(map[string]AccountStat)},
+	}
+	c.Collect(context.Background())
+	assert.Equal(t, "failed", c.GetStatus().LastResults["mock_cb|cb1"].Status)
+
+	// 认证失败触发熔断，下一轮跳过账号
+	c.Collect(context.Background())
+	st := c.GetStatus()
+	assert.Equal(t, "skipped", st.LastResults["mock_cb|cb1"].Status)
+	if assert.Len(t, st.CircuitBreakers, 1) {
+		assert.Equal(t, providerscommon.BreakerOpen, st.CircuitBreakers[0].State)
+	}
+	assert.Empty(t, c.FailedAccounts())
+}
@@ -0,0 +1,182 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"multicloud-exporter/internal/logger"
+	"multicloud-exporter/internal/metrics"
+)
+
+// 采集触发来源
+const (
+	TriggerSchedule = "schedule" // 周期采集
+	TriggerManual   = "manual"   // /collect 手动触发、once/inventory 子命令
+)
+
+// ErrCycleInProgress 已有其他范围的采集进行中
+var ErrCycleInProgress = errors.New("collection cycle in progress")
+
+// RunRequest 一次采集请求：过滤条件（为空表示不过滤）与触发来源
+type RunRequest struct {
+	Provider string `json:"provider,omitempty"`
+	Resource string `json:"resource,omitempty"`
+	Account  string `json:"account,omitempty"`
+	Trigger  string `json:"trigger"`
+}
+
+// sameScope 判断两个请求的采集范围是否一致（忽略触发来源）
+func (r RunRequest) sameScope(o RunRequest) bool {
+	return r.Provider == o.Provider && r.Resource == o.Resource && r.Account == o.Account
+}
+
+// RunInfo 进行中的采集
+type RunInfo struct {
+	RunRequest
+	Started  time.Time `json:"started"`
+	Deadline time.Time `json:"deadline,omitempty"`
+}
+
+// RunOutcome 采集请求的处理结果
+type RunOutcome string
+
+const (
+	RunStarted   RunOutcome = "started"   // 已开始新一轮采集
+	RunCoalesced RunOutcome = "coalesced" // 合并到进行中的同范围采集
+	RunQueued    RunOutcome = "queued"    // 排队，当前采集结束后执行
+)
+
+type runState struct {
+	info RunInfo
+	done chan struct{}
+}
+
+type pendingRun struct {
+	ctx context.Context
+	req RunRequest
+}
+
+// SetCycleTimeout 设置单轮采集的截止时间，超时后取消进行中的云 API 调用；0 表示不限制
+func (c *Collector) SetCycleTimeout(d time.Duration) {
+	c.runMu.Lock()
+	c.cycleTimeout = d
+	c.runMu.Unlock()
+}
+
+// Running 返回进行中的采集，空闲时返回 nil
+func (c *Collector) Running() *RunInfo {
+	c.runMu.Lock()
+	defer c.runMu.Unlock()
+	if c.current == nil {
+		return nil
+	}
+	info := c.current.info
+	return &info
+}
+
+// Run 同步执行一轮采集，同一时刻只执行一轮：
+// 空闲时立即执行；同范围的采集进行中时合并并等待其结束；
+// 其他范围的采集进行中时返回 ErrCycleInProgress。
+func (c *Collector) Run(ctx context.Context, req RunRequest) error {
+	st, outcome, err := c.acquire(ctx, req, false)
+	if err != nil {
+		return err
+	}
+	if outcome == RunCoalesced {
+		select {
+		case <-st.done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	c.execute(ctx, st)
+	return nil
+}
+
+// Start 在后台执行一轮采集并立即返回。其他范围的采集进行中时：
+// queue 为 false 返回 ErrCycleInProgress；queue 为 true 则排队，当前采集结束后执行，
+// 多个排队请求合并为一轮（范围不同时合并为全量采集）。
+func (c *Collector) Start(ctx context.Context, req RunRequest, queue bool) (RunOutcome, error) {
+	st, outcome, err := c.acquire(ctx, req, queue)
+	if err != nil || outcome != RunStarted {
+		return outcome, err
+	}
+	go c.execute(ctx, st)
+	return RunStarted, nil
+}
+
+// acquire 登记采集请求，返回需要执行或等待的采集
+func (c *Collector) acquire(ctx context.Context, req RunRequest, queue bool) (*runState, RunOutcome, error) {
+	if req.Trigger == "" {
+		req.Trigger = TriggerManual
+	}
+	c.runMu.Lock()
+	defer c.runMu.Unlock()
+	if c.current == nil {
+		c.current = c.newRun(req)
+		metrics.CollectionInProgress.Set(1)
+		return c.current, RunStarted, nil
+	}
+	if c.current.info.sameScope(req) {
+		metrics.CollectionCyclesSkipped.WithLabelValues(req.Trigger, "coalesced").Inc()
+		return c.current, RunCoalesced, nil
+	}
+	if !queue {
+		metrics.CollectionCyclesSkipped.WithLabelValues(req.Trigger, "in_progress").Inc()
+		return nil, "", ErrCycleInProgress
+	}
+	if c.pending == nil {
+		c.pending = &pendingRun{ctx: ctx, req: req}
+		return nil, RunQueued, nil
+	}
+	metrics.CollectionCyclesSkipped.WithLabelValues(req.Trigger, "coalesced").Inc()
+	if !c.pending.req.sameScope(req) {
+		c.pending.req = RunRequest{Trigger: c.pending.req.Trigger}
+	}
+	return nil, RunQueued, nil
+}
+
+// newRun 创建采集记录，调用方持有 runMu
+func (c *Collector) newRun(req RunRequest) *runState {
+	now := time.Now()
+	info := RunInfo{RunRequest: req, Started: now}
+	if c.cycleTimeout > 0 {
+		info.Deadline = now.Add(c.cycleTimeout)
+	}
+	return &runState{info: info, done: make(chan struct{})}
+}
+
+// execute 在截止时间内执行采集，结束后启动排队的采集
+func (c *Collector) execute(ctx context.Context, st *runState) {
+	runCtx, cancel := ctx, context.CancelFunc(func() {})
+	if !st.info.Deadline.IsZero() {
+		runCtx, cancel = context.WithDeadline(ctx, st.info.Deadline)
+	}
+	c.collectInternal(runCtx, st.info.Provider, st.info.Resource, st.info.Account)
+	if errors.Is(runCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		metrics.CollectionCyclesOverrun.WithLabelValues(st.info.Trigger).Inc()
+		ctxLog := logger.NewContextLogger("Collector", "resource_type", "Collection")
+		ctxLog.Warnf("采集超过截止时间被取消，触发=%s 耗时=%v", st.info.Trigger, time.Since(st.info.Started))
+	}
+	cancel()
+
+	c.runMu.Lock()
+	c.current = nil
+	next := c.pending
+	c.pending = nil
+	var nst *runState
+	if next != nil && next.ctx.Err() == nil {
+		nst = c.newRun(next.req)
+		c.current = nst
+	}
+	if nst == nil {
+		metrics.CollectionInProgress.Set(0)
+	}
+	c.runMu.Unlock()
+	close(st.done)
+	if nst != nil {
+		go c.execute(next.ctx, nst)
+	}
+}
@@ -0,0 +1,128 @@
+package collector
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"multicloud-exporter/internal/config"
+	"multicloud-exporter/internal/metrics"
+	"multicloud-exporter/internal/providers"
+)
+
+// blockingProvider 采集时阻塞直到 release 关闭或 ctx 结束，记录调用次数与 ctx 错误
+type blockingProvider struct {
+	started chan string
+	release chan struct{}
+	calls   int32
+	mu      sync.Mutex
+	ctxErr  error
+}
+
+func (b *blockingProvider) Collect(ctx context.Context, account config.CloudAccount) providers.CollectResult {
+	atomic.AddInt32(&b.calls, 1)
+	b.started <- account.AccountID
+	select {
+	case <-b.release:
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.ctxErr = ctx.Err()
+		b.mu.Unlock()
+	}
+	return providers.CollectResult{Provider: account.Provider, AccountID: account.AccountID, Err: ctx.Err()}
+}
+
+func (b *blockingProvider) GetDefaultResources() []string { return nil }
+
+func newBlockingCollector() (*Collector, *blockingProvider) {
+	bp := &blockingProvider{started: make(chan string, 10), release: make(chan struct{})}
+	c := &Collector{
+		cfg: &config.Config{AccountsByProvider: map[string][]config.CloudAccount{
+			"mock_block": {{AccountID: "a1"}, {AccountID: "a2"}},
+		}},
+		providers: map[string]providers.Provider{"mock_block": bp},
+		status:    Status{LastResults: make(map[string]AccountStat)},
+	}
+	return c, bp
+}
+
+func waitStarted(t *testing.T, bp *blockingProvider, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-bp.started:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("provider not started")
+		}
+	}
+}
+
+func TestRun_RejectsOverlapAndCoalesces(t *testing.T) {
+	c, bp := newBlockingCollector()
+	ctx := context.Background()
+
+	outcome, err := c.Start(ctx, RunRequest{Trigger: TriggerSchedule}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, RunStarted, outcome)
+	waitStarted(t, bp, 2)
+	assert.NotNil(t, c.Running())
+	assert.NotNil(t, c.GetStatus().Running)
+
+	inProgress := testutil.ToFloat64(metrics.CollectionCyclesSkipped.WithLabelValues(TriggerManual, "in_progress"))
+	_, err = c.Start(ctx, RunRequest{Account: "a1"}, false)
+	assert.ErrorIs(t, err, ErrCycleInProgress)
+	assert.Equal(t, inProgress+1, testutil.ToFloat64(metrics.CollectionCyclesSkipped.WithLabelValues(TriggerManual, "in_progress")))
+
+	// 同范围请求合并，等待进行中的采集结束
+	done := make(chan error, 1)
+	go func() { done <- c.Run(ctx, RunRequest{Trigger: TriggerManual}) }()
+	select {
+	case <-done:
+		t.Fatalf("coalesced run should wait for the in-flight cycle")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(bp.release)
+	assert.NoError(t, <-done)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&bp.calls), "coalesced run must not collect again")
+	assert.Nil(t, c.Running())
+}
+
+func TestStart_QueueMergesPendingRuns(t *testing.T) {
+	c, bp := newBlockingCollector()
+	ctx := context.Background()
+
+	_, err := c.Start(ctx, RunRequest{Account: "a1"}, false)
+	assert.NoError(t, err)
+	waitStarted(t, bp, 1)
+
+	outcome, err := c.Start(ctx, RunRequest{Account: "a2"}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, RunQueued, outcome)
+	outcome, _ = c.Start(ctx, RunRequest{Provider: "mock_block"}, true)
+	assert.Equal(t, RunQueued, outcome)
+
+	close(bp.release)
+	// 不同范围的排队请求合并为一轮全量采集：a1、a2 各采集一次
+	waitStarted(t, bp, 2)
+	assert.Eventually(t, func() bool { return c.Running() == nil }, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&bp.calls))
+}
+
+func TestRun_DeadlineCancelsCycle(t *testing.T) {
+	c, bp := newBlockingCollector()
+	c.SetCycleTimeout(30 * time.Millisecond)
+	overrun := testutil.ToFloat64(metrics.CollectionCyclesOverrun.WithLabelValues(TriggerSchedule))
+
+	assert.NoError(t, c.Run(context.Background(), RunRequest{Trigger: TriggerSchedule}))
+
+	bp.mu.Lock()
+	assert.ErrorIs(t, bp.ctxErr, context.DeadlineExceeded)
+	bp.mu.Unlock()
+	assert.Equal(t, overrun+1, testutil.ToFloat64(metrics.CollectionCyclesOverrun.WithLabelValues(TriggerSchedule)))
+	assert.Equal(t, "failed", c.GetStatus().LastResults["mock_block|a1"].Status)
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CodeNameStep code_name 解析链中的一个步骤
+type CodeNameStep struct {
+	// Source 取值来源：tags、name、id
+	Source string
+	// Key 标签键（仅 Source 为 tags 时有效）
+	Key string
+}
+
+// ParseCodeNameChain 解析 code_name 解析链，格式如 "tags.CodeName -> tags.Name -> name -> id"。
+// name 也可写作 "resource name"/"resource_name"，id 也可写作 "resource id"/"resource_id"。
+// 空字符串返回 nil（表示使用默认链）。
+func ParseCodeNameChain(chain string) ([]CodeNameStep, error) {
+	if strings.TrimSpace(chain) == "" {
+		return nil, nil
+	}
+	var steps []CodeNameStep
+	for _, raw := range strings.Split(chain, "->") {
+		s := strings.TrimSpace(raw)
+		switch {
+		case s == "":
+			return nil, fmt.Errorf("empty step in %q", chain)
+		case strings.HasPrefix(s, "tags."):
+			key := strings.TrimSpace(strings.TrimPrefix(s, "tags."))
+			if key == "" {
+				return nil, fmt.Errorf("missing tag key in step %q", s)
+			}
+			steps = append(steps, CodeNameStep{Source: "tags", Key: key})
+		default:
+			switch strings.ToLower(strings.Join(strings.Fields(strings.ReplaceAll(s, "_", " ")), " ")) {
+			case "name", "resource name":
+				steps = append(steps, CodeNameStep{Source: "name"})
+			case "id", "resource id":
+				steps = append(steps, CodeNameStep{Source: "id"})
+			default:
+				return nil, fmt.Errorf("unknown step %q (expected tags.<Key>, name or id)", s)
+			}
+		}
+	}
+	return steps, nil
+}
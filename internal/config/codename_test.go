@@ -0,0 +1,33 @@
+package config
+
+import "testing"
+
+func TestParseCodeNameChain(t *testing.T) {
+	steps, err := ParseCodeNameChain("tags.CodeName -> tags.kubernetes.io/service-name -> resource name -> resource_id")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []CodeNameStep{
+		{Source: "tags", Key: "CodeName"},
+		{Source: "tags", Key: "kubernetes.io/service-name"},
+		{Source: "name"},
+		{Source: "id"},
+	}
+	if len(steps) != len(want) {
+		t.Fatalf("unexpected steps: %+v", steps)
+	}
+	for i := range want {
+		if steps[i] != want[i] {
+			t.Fatalf("step %d: got %+v want %+v", i, steps[i], want[i])
+		}
+	}
+
+	if steps, err := ParseCodeNameChain("  "); err != nil || steps != nil {
+		t.Fatalf("empty chain should return nil, got %+v %v", steps, err)
+	}
+	for _, bad := range []string{"tags.", "name -> -> id", "label.env"} {
+		if _, err := ParseCodeNameChain(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
@@ -4,6 +4,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"strings"
 	"sync"
 
@@ -18,6 +19,42 @@ type CloudAccount struct {
 	AccessKeySecret string   `yaml:"access_key_secret"`
 	Regions         []string `yaml:"regions"`
 	Resources       []string `yaml:"resources"`
+	// Filters 资源过滤规则（账号级 + 资源类型级），在资源枚举后、调用监控 API 前生效
+	Filters *AccountFilters `yaml:"filters,omitempty"`
+	// Labels 账号级静态标签，附加到该账号的所有命名空间指标，覆盖同名 datatag
+	Labels map[string]string `yaml:"labels,omitempty"`
+}
+
+// ResourceFilterRule 描述一条资源匹配规则，各类条件之间为“与”关系：
+//   - ids: 资源 ID 任一相等
+//   - name_regex: 资源名称或 code_name 任一正则匹配
+//   - tags: 所有标签键值均匹配，值为空或 "*" 表示仅要求标签键存在
+type ResourceFilterRule struct {
+	IDs       []string          `yaml:"ids,omitempty"`
+	NameRegex []string          `yaml:"name_regex,omitempty"`
+	Tags      map[string]string `yaml:"tags,omitempty"`
+}
+
+// IsEmpty 判断规则是否未配置任何条件
+func (r *ResourceFilterRule) IsEmpty() bool {
+	return r == nil || (len(r.IDs) == 0 && len(r.NameRegex) == 0 && len(r.Tags) == 0)
+}
+
+// ResourceFilter 资源过滤规则：配置 include 时仅保留命中的资源，命中 exclude 的资源被剔除
+type ResourceFilter struct {
+	Include *ResourceFilterRule `yaml:"include,omitempty"`
+	Exclude *ResourceFilterRule `yaml:"exclude,omitempty"`
+}
+
+// IsEmpty 判断过滤规则是否未配置
+func (f ResourceFilter) IsEmpty() bool {
+	return f.Include.IsEmpty() && f.Exclude.IsEmpty()
+}
+
+// AccountFilters 账号级过滤规则，Resources 按资源类型（如 clb、s3、bwp）追加规则
+type AccountFilters struct {
+	ResourceFilter `yaml:",inline"`
+	Resources      map[string]ResourceFilter `yaml:"resources,omitempty"`
 }
 
 // expandEnv 根据当前环境变量的值替换字符串中的 ${var} 或 $var
@@ -52,6 +89,25 @@ type Config struct {
 	ProductsByProvider map[string][]Product `yaml:"products"`
 }
 
+// CustomLabelKeys 返回所有自定义标签的原始键：datatag、各账号 labels 与 server.tag_labels
+func (c *Config) CustomLabelKeys() []string {
+	var keys []string
+	for _, dt := range c.DataTag {
+		keys = append(keys, dt.Key)
+	}
+	for _, accounts := range c.AccountsByProvider {
+		for _, acc := range accounts {
+			for k := range acc.Labels {
+				keys = append(keys, k)
+			}
+		}
+	}
+	if server := c.GetServer(); server != nil {
+		keys = append(keys, server.TagLabels...)
+	}
+	return keys
+}
+
 // GetServer 获取 Server 配置，优先返回 Server，如果为空则返回 ServerConf（向后兼容）
 func (c *Config) GetServer() *ServerConf {
 	if c.Server != nil {
@@ -95,6 +151,11 @@ func (c *Config) Validate() error {
 			errs = append(errs, fmt.Sprintf("invalid port: %d (must be 1-65535)", server.Port))
 		}
 
+		// 验证 code_name 解析链
+		if _, err := ParseCodeNameChain(server.CodeNameChain); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid code_name_chain: %v", err))
+		}
+
 		// 验证日志配置
 		if server.Log != nil {
 			level := strings.ToLower(server.Log.Level)
@@ -104,7 +165,7 @@ func (c *Config) Validate() error {
 			}
 
 			output := strings.ToLower(server.Log.Output)
-			validOutputs := map[string]bool{"stdout": true, "console": true, "file": true, "both": true}
+			validOutputs := map[string]bool{"stdout": true, "stderr": true, "console": true, "file": true, "both": true}
 			if output != "" && !validOutputs[output] {
 				errs = append(errs, fmt.Sprintf("invalid log output: %s", server.Log.Output))
 			}
@@ -120,6 +181,94 @@ func (c *Config) Validate() error {
 		if server.ProductConcurrency < 0 || server.ProductConcurrency > 10 {
 			errs = append(errs, fmt.Sprintf("invalid product_concurrency: %d (must be 0-10)", server.ProductConcurrency))
 		}
+
+		if ac := server.AdaptiveConcurrency; ac != nil {
+			if ac.Min < 0 {
+				errs = append(errs, fmt.Sprintf("invalid adaptive_concurrency.min: %d (must be >= 0)", ac.Min))
+			}
+			if ac.DecreaseFactor < 0 || ac.DecreaseFactor >= 1 {
+				errs = append(errs, fmt.Sprintf("invalid adaptive_concurrency.decrease_factor: %v (must be 0-1)", ac.DecreaseFactor))
+			}
+		}
+
+		if cb := server.CircuitBreaker; cb != nil {
+			if cb.FailureThreshold < 0 {
+				errs = append(errs, fmt.Sprintf("invalid circuit_breaker.failure_threshold: %d (must be >= 0)", cb.FailureThreshold))
+			}
+		}
+
+		// 心跳端点不经过管理认证，未配置令牌时任何人都可以伪造成员分走分片
+		if cl := server.Cluster; cl != nil && cl.Enabled && strings.TrimSpace(cl.Token) == "" {
+			errs = append(errs, "cluster.token is required when cluster.enabled is true")
+		}
+
+		if sh := server.Sharding; sh != nil {
+			if sh.VirtualNodes < 0 {
+				errs = append(errs, fmt.Sprintf("invalid sharding.virtual_nodes: %d (must be >= 0)", sh.VirtualNodes))
+			}
+			if sh.LoadFactor != 0 && sh.LoadFactor < 1 {
+				errs = append(errs, fmt.Sprintf("invalid sharding.load_factor: %v (must be >= 1)", sh.LoadFactor))
+			}
+			if sh.ReplicationFactor < 0 {
+				errs = append(errs, fmt.Sprintf("invalid sharding.replication_factor: %d (must be >= 0)", sh.ReplicationFactor))
+			}
+			// 权重文件缺失的实例会与其它分片得到不同的键归属，必须在启动时失败
+			if _, err := sh.LoadWeights(); err != nil {
+				errs = append(errs, fmt.Sprintf("invalid sharding.weights_file: %v", err))
+			}
+		}
+
+		if b := server.Budgets; b != nil {
+			if b.DegradeFactor < 0 {
+				errs = append(errs, fmt.Sprintf("invalid budgets.degrade_factor: %d (must be >= 0)", b.DegradeFactor))
+			}
+			for _, m := range []map[string]BudgetLimit{b.Providers, b.Accounts} {
+				for key, l := range m {
+					if l.DailyCalls < 0 || l.DailyMetrics < 0 {
+						errs = append(errs, fmt.Sprintf("invalid budget %q: daily_calls and daily_metrics must be >= 0", key))
+					}
+				}
+			}
+			for key, p := range b.Prices {
+				if p.PerThousandCalls < 0 || p.PerThousandMetrics < 0 {
+					errs = append(errs, fmt.Sprintf("invalid budgets.prices.%s: prices must be >= 0", key))
+				}
+			}
+		}
+
+		if c := server.Cache; c != nil {
+			if c.Backend != "" && c.Backend != "memory" && c.Backend != "disk" {
+				errs = append(errs, fmt.Sprintf("invalid cache.backend: %q (must be memory or disk)", c.Backend))
+			}
+			if c.MaxEntries < 0 || c.MaxBytes < 0 || c.Segments < 0 {
+				errs = append(errs, "invalid cache: max_entries, max_bytes and segments must be >= 0")
+			}
+		}
+
+		if server.DiscoveryRefreshJitter < 0 || server.DiscoveryRefreshJitter >= 1 {
+			errs = append(errs, fmt.Sprintf("invalid discovery_refresh_jitter: %v (must be in [0, 1))", server.DiscoveryRefreshJitter))
+		}
+
+		if f := server.DiscoveryFeed; f != nil {
+			if f.BufferSize < 0 {
+				errs = append(errs, fmt.Sprintf("invalid discovery_feed.buffer_size: %d (must be >= 0)", f.BufferSize))
+			}
+			for i, wh := range f.Webhooks {
+				if !strings.HasPrefix(wh.URL, "http://") && !strings.HasPrefix(wh.URL, "https://") {
+					errs = append(errs, fmt.Sprintf("invalid discovery_feed.webhooks[%d].url: %q (must be http(s) URL)", i, wh.URL))
+				}
+			}
+		}
+
+		// 验证限流预算
+		for key, rl := range server.RateLimits {
+			if key == "" || strings.HasPrefix(key, ".") || strings.HasSuffix(key, ".") {
+				errs = append(errs, fmt.Sprintf("invalid rate_limits key: %q (must be provider or provider.API)", key))
+			}
+			if rl.QPS < 0 || rl.Burst < 0 {
+				errs = append(errs, fmt.Sprintf("invalid rate_limits.%s: qps and burst must be >= 0", key))
+			}
+		}
 	}
 
 	// 验证账号配置
@@ -141,6 +290,7 @@ func (c *Config) Validate() error {
 			if len(acc.Regions) == 0 {
 				errs = append(errs, fmt.Sprintf("%s: account[%d].regions is empty", provider, i))
 			}
+			errs = append(errs, validateAccountFilters(provider, i, acc.Filters)...)
 		}
 	}
 
@@ -151,6 +301,49 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// tagFilterUnsupported 无法获取标签的资源类型，资源类型级规则不允许按标签过滤
+var tagFilterUnsupported = map[string][]string{
+	"aliyun": {"gwlb"},
+	"huawei": {"obs", "s3"},
+}
+
+// validateAccountFilters 校验资源过滤规则中的正则表达式，以及不支持标签的资源类型上的标签规则
+func validateAccountFilters(provider string, idx int, f *AccountFilters) []string {
+	if f == nil {
+		return nil
+	}
+	var errs []string
+	check := func(scope string, rf ResourceFilter) {
+		for _, rule := range []*ResourceFilterRule{rf.Include, rf.Exclude} {
+			if rule == nil {
+				continue
+			}
+			for _, p := range rule.NameRegex {
+				if _, err := regexp.Compile(p); err != nil {
+					errs = append(errs, fmt.Sprintf("%s: account[%d].filters%s invalid name_regex %q: %v", provider, idx, scope, p, err))
+				}
+			}
+		}
+	}
+	check("", f.ResourceFilter)
+	for name, rf := range f.Resources {
+		check(".resources."+name, rf)
+	}
+	for _, name := range tagFilterUnsupported[provider] {
+		rf, ok := f.Resources[name]
+		if !ok {
+			continue
+		}
+		for _, rule := range []*ResourceFilterRule{rf.Include, rf.Exclude} {
+			if rule != nil && len(rule.Tags) > 0 {
+				errs = append(errs, fmt.Sprintf("%s: account[%d].filters.resources.%s does not support tags (resource tags are not available)", provider, idx, name))
+				break
+			}
+		}
+	}
+	return errs
+}
+
 // LoadConfig 从环境变量加载拆分配置文件
 func LoadConfig() (*Config, error) {
 	var cfg Config
@@ -160,9 +353,7 @@ func LoadConfig() (*Config, error) {
 	data, actualPath, err := LoadConfigFile(serverPath, []string{"/app/configs/server.yaml", "./configs/server.yaml"})
 	if err == nil && data != nil {
 		expanded := expandEnv(string(data))
-		var s struct {
-			Server *ServerConf `yaml:"server"`
-		}
+		var s ServerFile
 		if err := yaml.Unmarshal([]byte(expanded), &s); err != nil {
 			return nil, fmt.Errorf("failed to parse server config from %s: %v", actualPath, err)
 		}
@@ -182,13 +373,9 @@ func LoadConfig() (*Config, error) {
 				}
 			}
 		}
-		// 解析估算配置
-		var est struct {
-			Estimation *EstimationConf `yaml:"estimation"`
-		}
-		if err := yaml.Unmarshal([]byte(expanded), &est); err == nil && est.Estimation != nil {
-			cfg.Estimation = est.Estimation
-		}
+		// 估算配置与全局静态标签
+		cfg.Estimation = s.Estimation
+		cfg.DataTag = s.DataTag
 	}
 
 	// 手工产品配置已废弃：Exporter 全面采用自动发现生成产品与指标配置
@@ -261,6 +448,37 @@ type ServerConf struct {
 	DiscoveryTTL     string `yaml:"discovery_ttl"`
 	DiscoveryRefresh string `yaml:"discovery_refresh"`
 	ScrapeInterval   string `yaml:"scrape_interval"`
+	// DiscoveryRefreshJitter 周期发现刷新的随机抖动比例（0~1），默认 0.1，即间隔在 ±10% 内随机
+	DiscoveryRefreshJitter float64 `yaml:"discovery_refresh_jitter"`
+	// DiscoveryTimeout 单个云平台产品发现的超时时间，默认 2m
+	DiscoveryTimeout string `yaml:"discovery_timeout"`
+	// DiscoveryFeed 发现变更流：/api/discovery/stream 的事件回放缓冲与变更 Webhook
+	DiscoveryFeed *DiscoveryFeedConf `yaml:"discovery_feed"`
+	// RateLimits 云 API 限流预算（令牌桶，按 provider/账号/API 独立计数）。
+	// Key 为 "provider.API"（如 "tencent.GetMonitorData"）或 "provider"（该云全部 API 的默认值），
+	// 未配置时使用内置的云厂商文档限额；qps 为 0 表示不限流。
+	RateLimits map[string]RateLimitConf `yaml:"rate_limits"`
+	// Budgets 每日云 API 调用预算与成本护栏，超出预算后降低采集频率
+	Budgets *BudgetConf `yaml:"budgets"`
+	// Cache 资源发现、标签与指标元数据缓存的后端与容量；未配置时使用不限容量的内存缓存
+	Cache *CacheConf `yaml:"cache"`
+	// CollectionTimeout 单轮采集的截止时间（支持 "d"），超时后取消进行中的云 API 调用；默认等于 scrape_interval
+	CollectionTimeout string `yaml:"collection_timeout"`
+	// ScrapeSchedules 按产品独立配置采集周期，覆盖由指标 Period 推导的周期。
+	// Key 为 "provider.namespace" 或 "namespace"，Value 为时间间隔（支持 "d"），例如：
+	//   "aliyun.acs_oss_dashboard": "1d"
+	//   "QCE/COS": "6h"
+	// 周期小于等于 scrape_interval 时每轮采集。
+	ScrapeSchedules map[string]string `yaml:"scrape_schedules"`
+	// TagLabels 需要提升为指标标签的云资源标签键，例如 ["env", "team", "cost_center"]。
+	// 标签名会被清洗为合法的 Prometheus 标签名；资源存在该标签时覆盖同名静态标签。
+	TagLabels []string `yaml:"tag_labels"`
+	// CodeNameChain code_name 解析链，步骤以 "->" 分隔，依次取第一个非空值：
+	//   tags.<Key>  资源标签（键优先精确匹配，其次忽略大小写）
+	//   name        资源名称
+	//   id          资源 ID
+	// 示例："tags.CodeName -> tags.Name -> name -> id"；未配置时各云使用内置默认链。
+	CodeNameChain string `yaml:"code_name_chain"`
 	// PeriodFallback 当无法从元数据获取 Period 时的默认值（秒），默认 60
 	PeriodFallback int `yaml:"period_fallback"`
 	// 区域级并发：同一账号下并行采集的地域数量，建议 1-8。
@@ -269,6 +487,14 @@ type ServerConf struct {
 	MetricConcurrency int `yaml:"metric_concurrency"`
 	// 产品级并发：同一地域下并行处理的命名空间（云产品）数量，建议 1-4。
 	ProductConcurrency int `yaml:"product_concurrency"`
+	// AdaptiveConcurrency 根据限流反馈自适应调整各级并发（AIMD），上限为上述并发配置
+	AdaptiveConcurrency *AdaptiveConcurrencyConf `yaml:"adaptive_concurrency"`
+	// CircuitBreaker 账号/区域熔断：连续认证失败或区域不可用时暂停采集，定期半开探测
+	CircuitBreaker *CircuitBreakerConf `yaml:"circuit_breaker"`
+	// Sharding 多实例分片的一致性哈希参数（分片数与序号由 cluster 成员视图或 CLUSTER_* 环境变量提供）
+	Sharding *ShardingConf `yaml:"sharding"`
+	// Cluster 多实例成员管理：实例间 HTTP 心跳维护稳定的成员视图，替代每次调用时的 DNS/文件快照
+	Cluster *ClusterConf `yaml:"cluster"`
 
 	// RegionDiscovery 定义智能区域发现配置
 	RegionDiscovery *RegionDiscoveryConf `yaml:"region_discovery"`
@@ -281,6 +507,133 @@ type ServerConf struct {
 	AdminAuth          []BasicAuth         `yaml:"admin_auth"`
 }
 
+// RateLimitConf 单个令牌桶的限流预算
+type RateLimitConf struct {
+	QPS   float64 `yaml:"qps"`   // 每秒请求数，0 表示不限流
+	Burst int     `yaml:"burst"` // 突发容量，默认 max(1, qps)
+}
+
+// BudgetConf 每日云 API 调用预算：按 provider（全部账号合计）与单账号限制调用次数及
+// CloudWatch 请求的指标数，任一预算用尽后该账号的产品采集周期放大 degrade_factor 倍，次日恢复。
+// 当日用量持久化到 region_discovery.data_dir，重启后继续累计。
+type BudgetConf struct {
+	// Providers 各云每日预算（全部账号合计），key 为 provider
+	Providers map[string]BudgetLimit `yaml:"providers"`
+	// Accounts 单账号每日预算，key 为 "provider.account_id"，或 provider（该云每个账号的默认值）
+	Accounts map[string]BudgetLimit `yaml:"accounts"`
+	// DegradeFactor 超出预算后采集周期的放大倍数，默认 4
+	DegradeFactor int `yaml:"degrade_factor"`
+	// Prices 单价，用于估算月度成本；key 为 "provider.API"，未配置时使用内置价格（AWS GetMetricData）
+	Prices map[string]BudgetPrice `yaml:"prices"`
+	// PersistFile 用量持久化文件名（相对于 region_discovery.data_dir），默认 budget_usage.json
+	PersistFile string `yaml:"persist_file"`
+}
+
+// CacheConf 采集器缓存配置（server.cache）。disk 后端把条目按键哈希写入 JSON 分段文件，
+// 每轮采集结束与退出时持久化，重启后直接复用未过期的资源发现结果，不必重新枚举全部区域。
+type CacheConf struct {
+	// Backend 缓存后端：memory（默认）或 disk
+	Backend string `yaml:"backend"`
+	// Dir disk 后端的目录，默认 <region_discovery.data_dir>/cache，每个缓存一个子目录
+	Dir string `yaml:"dir"`
+	// MaxEntries 单个缓存的条目数上限，超出后淘汰最久未访问的条目；0 表示不限制
+	MaxEntries int `yaml:"max_entries"`
+	// MaxBytes 单个缓存的字节数上限（键与 JSON 编码后的值），0 表示不限制
+	MaxBytes int64 `yaml:"max_bytes"`
+	// Segments disk 后端的分段文件数，默认 16
+	Segments int `yaml:"segments"`
+}
+
+// BudgetLimit 每日预算，0 表示不限制
+type BudgetLimit struct {
+	DailyCalls   int64 `yaml:"daily_calls"`   // 每日 API 调用次数
+	DailyMetrics int64 `yaml:"daily_metrics"` // 每日 CloudWatch GetMetricData 请求的指标数
+}
+
+// BudgetPrice 云 API 单价
+type BudgetPrice struct {
+	PerThousandCalls   float64 `yaml:"per_1k_calls"`   // 每千次调用
+	PerThousandMetrics float64 `yaml:"per_1k_metrics"` // 每千个请求的指标（CloudWatch GetMetricData 按指标数计费）
+}
+
+// AdaptiveConcurrencyConf 自适应并发配置：云 API 返回限流错误时按 provider/账号成倍降低有效并发，
+// 调用成功时逐步恢复，有效并发介于 min 与各级并发配置之间
+type AdaptiveConcurrencyConf struct {
+	Enabled        bool    `yaml:"enabled"`         // 是否启用，默认 false（使用静态并发配置）
+	Min            int     `yaml:"min"`             // 有效并发下限，默认 1
+	DecreaseFactor float64 `yaml:"decrease_factor"` // 限流时的降低系数（0-1），默认 0.5
+	Cooldown       string  `yaml:"cooldown"`        // 两次降低之间的最小间隔，默认 5s，避免同一波限流连续降低
+}
+
+// CircuitBreakerConf 熔断配置：账号连续 failure_threshold 次认证/权限错误（auth_error）后熔断整个账号，
+// 区域连续 region_skip 后熔断该区域；熔断期间跳过采集，open_duration 后半开探测一轮，
+// 探测失败时熔断时长翻倍（不超过 max_open_duration），成功后恢复
+type CircuitBreakerConf struct {
+	Enabled          bool   `yaml:"enabled"`           // 是否启用，默认 false
+	FailureThreshold int    `yaml:"failure_threshold"` // 触发熔断的连续错误数，默认 5
+	OpenDuration     string `yaml:"open_duration"`     // 熔断后到首次探测的时长，默认 5m
+	MaxOpenDuration  string `yaml:"max_open_duration"` // 探测失败后熔断时长的上限，默认 1h
+}
+
+// ShardingConf 分片配置：AccountID|Region|Namespace 键按一致性哈希环分配到各分片，
+// 分片数变化时只迁移少量键；weights_file 给出键权重（如资源数）时带权键按有界负载分配。
+// 所有分片必须使用相同的配置与权重文件。权重只来自静态文件：区域管理器的资源数只覆盖本分片的键，
+// 各实例不一致，不能用于分配。带权键在分片数变化时整体重新分配，不具备哈希环的最少迁移特性。
+type ShardingConf struct {
+	VirtualNodes int     `yaml:"virtual_nodes"` // 每个分片的虚拟节点数，默认 128
+	LoadFactor   float64 `yaml:"load_factor"`   // 带权分配时单个分片负载上限（相对平均负载），默认 1.25
+	WeightsFile  string  `yaml:"weights_file"`  // 键权重文件（YAML/JSON，键 -> 权重），可选
+	// ReplicationFactor 每个键由几个分片同时采集（默认 1），大于 1 时各实例指标带 replica 标签供去重；
+	// 环境变量 CLUSTER_REPLICATION_FACTOR 优先
+	ReplicationFactor int `yaml:"replication_factor"`
+}
+
+// LoadWeights 读取 weights_file（YAML/JSON，键 -> 权重）；未配置时返回 nil
+func (s *ShardingConf) LoadWeights() (map[string]float64, error) {
+	if s == nil || s.WeightsFile == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(s.WeightsFile)
+	if err != nil {
+		return nil, err
+	}
+	var weights map[string]float64
+	if err := yaml.Unmarshal(data, &weights); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.WeightsFile, err)
+	}
+	return weights, nil
+}
+
+// ClusterConf 心跳成员管理配置：实例间周期性互发心跳，存活实例按 member_id 排序构成成员视图，
+// 视图变化需保持 grace_period 后才生效（代数递增），滚动更新期间短暂重启的实例不会引起分片迁移
+type ClusterConf struct {
+	Enabled           bool     `yaml:"enabled"`
+	MemberID          string   `yaml:"member_id"`          // 成员 ID，默认 POD_NAME / HOSTNAME
+	AdvertiseAddr     string   `yaml:"advertise_addr"`     // 心跳地址 host:port，默认 POD_IP:服务端口
+	Peers             []string `yaml:"peers"`              // 种子地址 host:port
+	PeerService       string   `yaml:"peer_service"`       // 每轮解析为种子地址的 DNS 名称（如 Headless Service），默认 CLUSTER_SVC
+	Token             string   `yaml:"token"`              // 心跳共享令牌，启用时必填
+	HeartbeatInterval string   `yaml:"heartbeat_interval"` // 心跳间隔，默认 5s
+	FailureTimeout    string   `yaml:"failure_timeout"`    // 未收到心跳视为离开的时长，默认 3 倍心跳间隔
+	GracePeriod       string   `yaml:"grace_period"`       // 成员变化到分片归属迁移的宽限期，默认 1m
+}
+
+// DiscoveryFeedConf 发现变更流配置（server.discovery_feed）。每次产品/指标或资源清单变化生成一个事件，
+// 保存在有界环形缓冲中供 SSE 按 Last-Event-ID 回放，并可 POST 到 Webhook。
+type DiscoveryFeedConf struct {
+	// BufferSize 环形缓冲保留的事件数，默认 256
+	BufferSize int `yaml:"buffer_size"`
+	// Webhooks 变更事件推送地址，事件以 JSON POST，失败时重试
+	Webhooks []WebhookConf `yaml:"webhooks"`
+}
+
+// WebhookConf 单个 Webhook 地址
+type WebhookConf struct {
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"` // 附加请求头，如 Authorization
+	Timeout string            `yaml:"timeout"` // 单次请求超时，默认 5s
+}
+
 // RegionDiscoveryConf 定义智能区域发现配置
 type RegionDiscoveryConf struct {
 	Enabled           bool   `yaml:"enabled"`            // 是否启用智能区域发现，默认 true
@@ -301,7 +654,7 @@ type FileLogConfig struct {
 type LogConfig struct {
 	Level  string         `yaml:"level"`  // debug, info, warn, error
 	Format string         `yaml:"format"` // json, console
-	Output string         `yaml:"output"` // stdout, file, both
+	Output string         `yaml:"output"` // stdout, stderr, file, both
 	File   *FileLogConfig `yaml:"file"`
 }
 
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -146,3 +147,40 @@ func TestDefaultResourceDimMapping(t *testing.T) {
 		t.Error("DefaultResourceDimMapping() missing aliyun.acs_ecs_dashboard")
 	}
 }
+
+func TestShardingConf_LoadWeights(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "weights.yaml")
+	if err := os.WriteFile(path, []byte("acc|cn-hangzhou|acs_oss_dashboard: 120\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w, err := (&ShardingConf{WeightsFile: path}).LoadWeights()
+	if err != nil || w["acc|cn-hangzhou|acs_oss_dashboard"] != 120 {
+		t.Fatalf("weights = %v, err = %v", w, err)
+	}
+	if w, err := (&ShardingConf{}).LoadWeights(); err != nil || w != nil {
+		t.Fatalf("no weights file should return nil: %v %v", w, err)
+	}
+
+	// 权重文件缺失时配置校验失败，避免各分片归属不一致
+	cfg := &Config{Server: &ServerConf{Port: 9101, Sharding: &ShardingConf{WeightsFile: filepath.Join(dir, "missing.yaml")}}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "sharding.weights_file") {
+		t.Fatalf("missing weights file should fail validation: %v", err)
+	}
+}
+
+func TestValidateAccountFilters_TagsUnsupported(t *testing.T) {
+	tagRule := ResourceFilter{Include: &ResourceFilterRule{Tags: map[string]string{"env": "prod"}}}
+	f := &AccountFilters{Resources: map[string]ResourceFilter{"gwlb": tagRule, "clb": tagRule}}
+	errs := validateAccountFilters("aliyun", 0, f)
+	if len(errs) != 1 || !strings.Contains(errs[0], "filters.resources.gwlb does not support tags") {
+		t.Fatalf("errs = %v", errs)
+	}
+	if errs := validateAccountFilters("huawei", 0, &AccountFilters{Resources: map[string]ResourceFilter{"obs": tagRule}}); len(errs) != 1 {
+		t.Fatalf("huawei obs tag rule should be rejected: %v", errs)
+	}
+	// 账号级标签规则对其它资源类型仍然有效
+	if errs := validateAccountFilters("aliyun", 0, &AccountFilters{ResourceFilter: tagRule}); len(errs) != 0 {
+		t.Fatalf("account-level tag rule should be allowed: %v", errs)
+	}
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// jsonSchemaDraft 生成的 JSON Schema 版本
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// envPlaceholderPattern 环境变量占位符，非字符串字段允许写作 ${VAR:-default}
+const envPlaceholderPattern = `^\$\{?[A-Za-z_][A-Za-z0-9_]*`
+
+// SchemaKinds validate 子命令可生成 JSON Schema 的配置文件类型
+var SchemaKinds = []string{"server", "accounts", "mapping"}
+
+// JSONSchema 返回指定配置文件类型（server、accounts、mapping）的 JSON Schema，供编辑器补全与校验。
+// Schema 由配置结构体的 yaml 标签反射生成，与严格解码规则一致：对象不允许未知字段。
+func JSONSchema(kind string) ([]byte, bool) {
+	var (
+		v     interface{}
+		title string
+	)
+	switch kind {
+	case "server":
+		v, title = ServerFile{}, "multicloud-exporter server.yaml"
+	case "accounts":
+		v, title = AccountsFile{}, "multicloud-exporter accounts.yaml"
+	case "mapping":
+		v, title = MetricMapping{}, "multicloud-exporter metric mapping"
+	default:
+		return nil, false
+	}
+	schema := schemaFor(reflect.TypeOf(v))
+	schema["$schema"] = jsonSchemaDraft
+	schema["title"] = title
+	out, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, false
+	}
+	return append(out, '\n'), true
+}
+
+var mutexType = reflect.TypeOf(sync.RWMutex{})
+
+// schemaFor 按 yaml 解码语义生成类型的 Schema
+func schemaFor(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		props := make(map[string]interface{})
+		var additional interface{} = false
+		collectProperties(t, props, &additional)
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           props,
+			"additionalProperties": additional,
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": schemaFor(t.Elem()),
+		}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": schemaFor(t.Elem()),
+		}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return scalarOrEnv("boolean")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return scalarOrEnv("integer")
+	case reflect.Float32, reflect.Float64:
+		return scalarOrEnv("number")
+	}
+	return map[string]interface{}{}
+}
+
+// scalarOrEnv 非字符串标量同时允许环境变量占位符（加载时展开）
+func scalarOrEnv(typ string) map[string]interface{} {
+	return map[string]interface{}{
+		"anyOf": []interface{}{
+			map[string]interface{}{"type": typ},
+			map[string]interface{}{"type": "string", "pattern": envPlaceholderPattern},
+		},
+	}
+}
+
+// collectProperties 收集结构体字段，处理 yaml:",inline"（内嵌结构体合并字段，内联 map 作为 additionalProperties）
+func collectProperties(t reflect.Type, props map[string]interface{}, additional *interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || f.Type == mutexType {
+			continue
+		}
+		tag := f.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if strings.Contains(opts, "inline") {
+			ft := f.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Map {
+				*additional = schemaFor(ft.Elem())
+			} else {
+				collectProperties(ft, props, additional)
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		props[name] = schemaFor(f.Type)
+	}
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ServerFile server.yaml 的完整结构，LoadConfig 与 validate 子命令共用
+type ServerFile struct {
+	Server     *ServerConf     `yaml:"server"`
+	Estimation *EstimationConf `yaml:"estimation,omitempty"`
+	DataTag    []DataTag       `yaml:"datatag,omitempty"`
+	// RegionData 区域数据持久化配置，仅 Kubernetes 部署（Helm values）使用，导出器忽略
+	RegionData *RegionDataConf `yaml:"regionData,omitempty"`
+}
+
+// RegionDataConf Helm 区域数据持久化配置
+type RegionDataConf struct {
+	Persistence *RegionDataPersistence `yaml:"persistence,omitempty"`
+}
+
+// RegionDataPersistence Helm 区域数据 PVC 配置
+type RegionDataPersistence struct {
+	Enabled       bool   `yaml:"enabled"`
+	StorageClass  string `yaml:"storageClass"`
+	Size          string `yaml:"size"`
+	AccessMode    string `yaml:"accessMode"`
+	ExistingClaim string `yaml:"existingClaim"`
+}
+
+// AccountsFile accounts.yaml 的完整结构
+type AccountsFile struct {
+	AccountsByProvider map[string][]CloudAccount `yaml:"accounts"`
+}
+
+// DecodeStrict 严格解码 YAML：先展开环境变量占位符，未知字段与类型错误均返回带行号的错误
+func DecodeStrict(data []byte, out interface{}) error {
+	dec := yaml.NewDecoder(bytes.NewReader([]byte(expandEnv(string(data)))))
+	dec.KnownFields(true)
+	if err := dec.Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// DecodeServerFile 严格解析 server.yaml
+func DecodeServerFile(data []byte) (*ServerFile, error) {
+	var f ServerFile
+	if err := DecodeStrict(data, &f); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// DecodeAccountsFile 严格解析 accounts.yaml
+func DecodeAccountsFile(data []byte) (*AccountsFile, error) {
+	var f AccountsFile
+	if err := DecodeStrict(data, &f); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// DecodeMappingFile 严格解析指标映射文件，并复用 ValidateMappingStructure 校验云厂商键
+func DecodeMappingFile(path string, data []byte) (*MetricMapping, error) {
+	var m MetricMapping
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if err := ValidateMappingStructure(path); err != nil {
+		return nil, err
+	}
+	if len(m.Namespaces) == 0 {
+		return nil, fmt.Errorf("映射文件 %s 中未定义命名空间", path)
+	}
+	return &m, nil
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDecodeServerFile_UnknownField(t *testing.T) {
+	data := []byte("server:\n  port: 9101\n  scrape_intervl: 60s\n")
+	_, err := DecodeServerFile(data)
+	if err == nil || !strings.Contains(err.Error(), "line 3") || !strings.Contains(err.Error(), "scrape_intervl") {
+		t.Fatalf("expected unknown field error with line number, got %v", err)
+	}
+
+	t.Setenv("EXPORTER_PORT", "9200")
+	f, err := DecodeServerFile([]byte("server:\n  port: ${EXPORTER_PORT:-9101}\ndatatag:\n  - key: env\n    val: prod\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.Server.Port != 9200 || len(f.DataTag) != 1 {
+		t.Fatalf("unexpected decode result: %+v", f)
+	}
+}
+
+func TestDecodeAccountsFile_UnknownField(t *testing.T) {
+	data := []byte("accounts:\n  aliyun:\n    - account_id: a\n      region: [cn-hangzhou]\n")
+	if _, err := DecodeAccountsFile(data); err == nil || !strings.Contains(err.Error(), "line 4") {
+		t.Fatalf("expected unknown field error, got %v", err)
+	}
+}
+
+func TestDecodeMappingFile_UnknownVendorField(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "x.metrics.yaml")
+	content := "prefix: x\nnamespaces:\n  aliyun: acs_x\ncanonical:\n  a:\n    aliyun:\n      metric: A\n      unit: count\n      scal: 1\n"
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecodeMappingFile(p, []byte(content)); err == nil || !strings.Contains(err.Error(), "scal") {
+		t.Fatalf("expected unknown field error, got %v", err)
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	for _, kind := range SchemaKinds {
+		data, ok := JSONSchema(kind)
+		if !ok {
+			t.Fatalf("schema %s not generated", kind)
+		}
+		var s map[string]interface{}
+		if err := json.Unmarshal(data, &s); err != nil {
+			t.Fatalf("schema %s is not valid JSON: %v", kind, err)
+		}
+		if s["additionalProperties"] != false {
+			t.Fatalf("schema %s should reject unknown fields", kind)
+		}
+	}
+	data, _ := JSONSchema("server")
+	for _, want := range []string{`"code_name_chain"`, `"datatag"`, `"regionData"`} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("server schema missing %s", want)
+		}
+	}
+	if _, ok := JSONSchema("unknown"); ok {
+		t.Fatalf("unknown kind should not produce schema")
+	}
+}
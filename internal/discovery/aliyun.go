@@ -21,8 +21,14 @@ var newAliyunCMSClient = func(region, ak, sk string) (CMSClient, error) {
 type AliyunDiscoverer struct{}
 
 func (d *AliyunDiscoverer) Discover(ctx context.Context, cfg *config.Config) []config.Product {
+	prods, _ := d.DiscoverChecked(ctx, cfg)
+	return prods
+}
+
+// DiscoverChecked 同 Discover，全部指标元数据调用失败（结果仅含兜底指标）时返回错误
+func (d *AliyunDiscoverer) DiscoverChecked(ctx context.Context, cfg *config.Config) ([]config.Product, error) {
 	if cfg == nil {
-		return nil
+		return nil, nil
 	}
 	var accounts []config.CloudAccount
 	if cfg.AccountsByProvider != nil {
@@ -31,7 +37,7 @@ func (d *AliyunDiscoverer) Discover(ctx context.Context, cfg *config.Config) []c
 		}
 	}
 	if len(accounts) == 0 {
-		return nil
+		return nil, nil
 	}
 	nsSet := make(map[string]struct{})
 	for _, acc := range accounts {
@@ -67,6 +73,8 @@ func (d *AliyunDiscoverer) Discover(ctx context.Context, cfg *config.Config) []c
 		sk = cfg.Credential.AccessSecret
 	}
 	prods := make([]config.Product, 0)
+	var attempted int   // 指标元数据调用次数
+	var failed []string // 元数据调用失败的命名空间
 
 	// Fetch meta for each namespace
 	for ns := range nsSet {
@@ -213,8 +221,10 @@ func (d *AliyunDiscoverer) Discover(ctx context.Context, cfg *config.Config) []c
 			},
 		}
 
+		attempted++
 		client, err := newAliyunCMSClient(region, targetAK, targetSK)
 		if err != nil {
+			failed = append(failed, ns)
 			if list, ok := fallbackMap[ns]; ok {
 				ctxLog := logger.NewContextLogger("Aliyun", "resource_type", "Discovery", "namespace", ns)
 				ctxLog.Warnf("CMS 客户端创建失败，使用备用指标，错误=%v", err)
@@ -229,6 +239,7 @@ func (d *AliyunDiscoverer) Discover(ctx context.Context, cfg *config.Config) []c
 
 		// 1. Handle API Failure: Use fallback directly
 		if err != nil || resp == nil || resp.Resources.Resource == nil {
+			failed = append(failed, ns)
 			if list, ok := fallbackMap[ns]; ok {
 				ctxLog := logger.NewContextLogger("Aliyun", "resource_type", "Discovery", "namespace", ns)
 				ctxLog.Infof("发现服务启用备用指标，原因=元数据不可用")
@@ -350,7 +361,7 @@ func (d *AliyunDiscoverer) Discover(ctx context.Context, cfg *config.Config) []c
 		prods = append(prods, config.Product{Namespace: ns, AutoDiscover: true, MetricInfo: []config.MetricGroup{{MetricList: metrics}}})
 		ctxLogNs.Infof("发现服务完成，指标数量=%d", len(metrics))
 	}
-	return prods
+	return prods, metadataError(attempted, failed)
 }
 
 func init() {
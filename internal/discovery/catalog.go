@@ -0,0 +1,102 @@
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"multicloud-exporter/internal/config"
+)
+
+const catalogFileName = "discovery_catalog.json"
+
+// 产品列表来源，见 DiscoveryStatus.Source / ProviderStats.Source
+const (
+	SourceLive  = "live"  // 本进程内发现成功
+	SourceCache = "cache" // 启动时从持久化目录加载，尚未重新发现成功
+)
+
+// persistedCatalog 持久化的发现结果，仅包含发现成功（或沿用成功结果）的云平台
+type persistedCatalog struct {
+	Version   int64                       `json:"version"`
+	UpdatedAt time.Time                   `json:"updated_at"`
+	SavedAt   time.Time                   `json:"saved_at"`
+	Products  map[string][]config.Product `json:"products"`
+}
+
+// catalogPath 发现结果持久化到 region_discovery.data_dir/discovery_catalog.json，未配置 data_dir 时不持久化
+func catalogPath(server *config.ServerConf) string {
+	if server == nil || server.RegionDiscovery == nil || server.RegionDiscovery.DataDir == "" {
+		return ""
+	}
+	return filepath.Join(server.RegionDiscovery.DataDir, catalogFileName)
+}
+
+// loadCatalog 加载持久化的发现结果，标记为 cache 来源；文件不存在时返回 false
+func (m *Manager) loadCatalog() (bool, error) {
+	if m.catalogPath == "" {
+		return false, nil
+	}
+	data, err := os.ReadFile(m.catalogPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	var cat persistedCatalog
+	if err := json.Unmarshal(data, &cat); err != nil {
+		return false, fmt.Errorf("解析发现目录失败: %w", err)
+	}
+	if len(cat.Products) == 0 {
+		return false, nil
+	}
+	m.mu.Lock()
+	m.products = cat.Products
+	m.version = cat.Version
+	m.updatedAt = cat.UpdatedAt
+	for name := range cat.Products {
+		m.sources[name] = SourceCache
+	}
+	m.mu.Unlock()
+	return true, nil
+}
+
+// saveCatalog 原子写入当前发现结果；从未发现成功的云平台（仅有兜底结果）不写入
+func (m *Manager) saveCatalog() error {
+	if m.catalogPath == "" {
+		return nil
+	}
+	m.mu.RLock()
+	cat := persistedCatalog{
+		Version:   m.version,
+		UpdatedAt: m.updatedAt,
+		SavedAt:   time.Now(),
+		Products:  make(map[string][]config.Product, len(m.products)),
+	}
+	for name, prods := range m.products {
+		if m.sources[name] != "" {
+			cat.Products[name] = prods
+		}
+	}
+	m.mu.RUnlock()
+	data, err := json.MarshalIndent(cat, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(m.catalogPath), 0755); err != nil {
+		return err
+	}
+	tmp := m.catalogPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, m.catalogPath); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"multicloud-exporter/internal/config"
+)
+
+func TestCatalog_LoadedOnStartAndRevalidated(t *testing.T) {
+	dir := t.TempDir()
+	cfg := func() *config.Config {
+		return &config.Config{Server: &config.ServerConf{
+			DiscoveryRefresh: "0",
+			RegionDiscovery:  &config.RegionDiscoveryConf{DataDir: dir},
+		}}
+	}
+	good := []config.Product{product("catalog_ns", "m1", "m2")}
+	d := &checkedD{prods: good}
+	registerForTest(t, "catalog_test", d)
+
+	first := NewManager(cfg())
+	if err := first.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, catalogFileName)); err != nil {
+		t.Fatalf("catalog not saved: %v", err)
+	}
+	version := first.Version()
+
+	// 重启时云 API 不可用：直接使用发现目录，后台重新发现失败后仍沿用
+	d.err = errors.New("all 1 metric metadata calls failed")
+	d.prods = []config.Product{product("catalog_ns", "m1")}
+	m := NewManager(cfg())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m.Start(ctx)
+	if !reflect.DeepEqual(m.Get()["catalog_test"], good) || m.Version() != version {
+		t.Fatalf("catalog not loaded: %+v version=%d", m.Get()["catalog_test"], m.Version())
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for m.Status().RefreshCount == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("background revalidation did not run")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	st := m.Status()
+	if st.Source != SourceCache || st.ProviderStats["catalog_test"].Source != SourceCache || st.ProviderStats["catalog_test"].LastError == "" {
+		t.Fatalf("status = source %q, provider %+v", st.Source, st.ProviderStats["catalog_test"])
+	}
+	if !reflect.DeepEqual(m.Get()["catalog_test"], good) {
+		t.Fatalf("failed revalidation should keep cached products: %+v", m.Get()["catalog_test"])
+	}
+
+	d.err = nil
+	if err := m.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if st := m.Status(); st.Source != SourceLive || st.ProviderStats["catalog_test"].Source != SourceLive || m.Version() != version+1 {
+		t.Fatalf("status after revalidation = %q %+v version=%d", st.Source, st.ProviderStats["catalog_test"], m.Version())
+	}
+
+	// 一次性运行：加载发现目录后同步重新发现，返回时已是最新结果
+	d.prods = []config.Product{product("catalog_ns", "m1", "m2", "m3")}
+	once := NewManager(cfg())
+	once.StartWithOptions(ctx, StartOptions{Revalidate: true})
+	if st := once.Status(); st.RefreshCount != 1 || st.ProviderStats["catalog_test"].Source != SourceLive || len(once.Get()["catalog_test"][0].MetricInfo[0].MetricList) != 3 {
+		t.Fatalf("revalidate start = %+v products %+v", st.ProviderStats["catalog_test"], once.Get()["catalog_test"])
+	}
+
+	// 发现目录不存在或未配置 data_dir 时照常同步发现
+	if loaded, err := NewManager(&config.Config{Server: &config.ServerConf{}}).loadCatalog(); loaded || err != nil {
+		t.Fatalf("loadCatalog without data_dir = %v %v", loaded, err)
+	}
+}
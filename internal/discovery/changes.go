@@ -0,0 +1,287 @@
+package discovery
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"multicloud-exporter/internal/config"
+)
+
+// 变更事件类型，对应 SSE 的 event 字段
+const (
+	EventProducts  = "products"  // 产品或指标增减（Refresh）
+	EventResources = "resources" // 资源增减（采集轮次结束后的资源清单）
+)
+
+const defaultFeedBufferSize = 256
+
+// ProductDiff 单个云平台的产品与指标变化；指标变化只列出前后都存在的产品
+type ProductDiff struct {
+	Provider        string              `json:"provider"`
+	AddedProducts   []string            `json:"added_products,omitempty"`
+	RemovedProducts []string            `json:"removed_products,omitempty"`
+	AddedMetrics    map[string][]string `json:"added_metrics,omitempty"`   // namespace -> 指标
+	RemovedMetrics  map[string][]string `json:"removed_metrics,omitempty"` // namespace -> 指标
+}
+
+// ResourceRef 资源清单中的一个资源
+type ResourceRef struct {
+	Provider   string `json:"provider"`
+	AccountID  string `json:"account_id"`
+	Region     string `json:"region"`
+	Namespace  string `json:"namespace"`
+	ResourceID string `json:"resource_id"`
+}
+
+// ResourceDiff 单个 provider/account/region/namespace 下的资源变化
+type ResourceDiff struct {
+	Provider  string   `json:"provider"`
+	AccountID string   `json:"account_id"`
+	Region    string   `json:"region"`
+	Namespace string   `json:"namespace"`
+	Added     []string `json:"added,omitempty"`
+	Removed   []string `json:"removed,omitempty"`
+}
+
+// ChangeEvent 变更事件，ID 在进程内单调递增，用作 SSE 的 id 与 Last-Event-ID
+type ChangeEvent struct {
+	ID        uint64         `json:"id"`
+	Type      string         `json:"type"`
+	Version   int64          `json:"version"`
+	Time      time.Time      `json:"time"`
+	Products  []ProductDiff  `json:"products,omitempty"`
+	Resources []ResourceDiff `json:"resources,omitempty"`
+}
+
+// changeFeed 有界环形缓冲，保留最近的变更事件供断线重连回放
+type changeFeed struct {
+	mu     sync.Mutex
+	buf    []ChangeEvent
+	next   int // 下一个写入位置
+	full   bool
+	lastID uint64
+}
+
+func newChangeFeed(size int) *changeFeed {
+	if size <= 0 {
+		size = defaultFeedBufferSize
+	}
+	return &changeFeed{buf: make([]ChangeEvent, size)}
+}
+
+// append 分配事件 ID 并写入缓冲，最旧的事件被覆盖
+func (f *changeFeed) append(ev ChangeEvent) ChangeEvent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lastID++
+	ev.ID = f.lastID
+	f.buf[f.next] = ev
+	f.next = (f.next + 1) % len(f.buf)
+	if f.next == 0 {
+		f.full = true
+	}
+	return ev
+}
+
+// since 返回 ID 大于 after 的事件；after 早于缓冲中最旧的事件（已被覆盖）时 complete 为 false
+func (f *changeFeed) since(after uint64) (events []ChangeEvent, complete bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := f.next
+	start := 0
+	if f.full {
+		n = len(f.buf)
+		start = f.next
+	}
+	if n == 0 {
+		return nil, after <= f.lastID
+	}
+	oldest := f.buf[start].ID
+	complete = after+1 >= oldest && after <= f.lastID
+	for i := 0; i < n; i++ {
+		ev := f.buf[(start+i)%len(f.buf)]
+		if ev.ID > after {
+			events = append(events, ev)
+		}
+	}
+	return events, complete
+}
+
+func (f *changeFeed) last() uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lastID
+}
+
+// ChangesSince 返回 ID 大于 lastID 的变更事件；lastID 对应的事件已被环形缓冲淘汰时 complete 为 false，
+// 订阅方应重新拉取 /api/discovery/config
+func (m *Manager) ChangesSince(lastID uint64) (events []ChangeEvent, complete bool) {
+	return m.feed.since(lastID)
+}
+
+// LastEventID 返回最近一个变更事件的 ID，没有事件时为 0
+func (m *Manager) LastEventID() uint64 {
+	return m.feed.last()
+}
+
+// ObserveInventory 记录一轮采集后的资源清单并与上一轮比较，有增减时生成 resources 事件；
+// 首次调用只建立基线
+func (m *Manager) ObserveInventory(items []ResourceRef) {
+	cur := groupResources(items)
+	m.invMu.Lock()
+	prev := m.inventory
+	m.inventory = cur
+	m.invMu.Unlock()
+	if prev == nil {
+		return
+	}
+	diffs := diffResources(prev, cur)
+	if len(diffs) == 0 {
+		return
+	}
+	m.publish(ChangeEvent{Type: EventResources, Version: m.Version(), Resources: diffs})
+	m.broadcast()
+}
+
+// publish 写入环形缓冲并投递 Webhook
+func (m *Manager) publish(ev ChangeEvent) {
+	ev.Time = time.Now()
+	ev = m.feed.append(ev)
+	if m.webhooks != nil {
+		m.webhooks.enqueue(ev)
+	}
+}
+
+// diffProducts 比较两次发现结果，返回按云平台排序的产品与指标变化
+func diffProducts(old, cur map[string][]config.Product) []ProductDiff {
+	providers := make(map[string]struct{}, len(old)+len(cur))
+	for p := range old {
+		providers[p] = struct{}{}
+	}
+	for p := range cur {
+		providers[p] = struct{}{}
+	}
+	var out []ProductDiff
+	for p := range providers {
+		before, after := productMetrics(old[p]), productMetrics(cur[p])
+		d := ProductDiff{Provider: p}
+		for ns, metrics := range after {
+			prev, ok := before[ns]
+			if !ok {
+				d.AddedProducts = append(d.AddedProducts, ns)
+				continue
+			}
+			if added := setMinus(metrics, prev); len(added) > 0 {
+				if d.AddedMetrics == nil {
+					d.AddedMetrics = make(map[string][]string)
+				}
+				d.AddedMetrics[ns] = added
+			}
+			if removed := setMinus(prev, metrics); len(removed) > 0 {
+				if d.RemovedMetrics == nil {
+					d.RemovedMetrics = make(map[string][]string)
+				}
+				d.RemovedMetrics[ns] = removed
+			}
+		}
+		for ns := range before {
+			if _, ok := after[ns]; !ok {
+				d.RemovedProducts = append(d.RemovedProducts, ns)
+			}
+		}
+		if len(d.AddedProducts)+len(d.RemovedProducts)+len(d.AddedMetrics)+len(d.RemovedMetrics) == 0 {
+			continue
+		}
+		sort.Strings(d.AddedProducts)
+		sort.Strings(d.RemovedProducts)
+		out = append(out, d)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Provider < out[j].Provider })
+	return out
+}
+
+// productMetrics 产品命名空间 -> 指标集合
+func productMetrics(prods []config.Product) map[string]map[string]struct{} {
+	out := make(map[string]map[string]struct{}, len(prods))
+	for _, p := range prods {
+		set := out[p.Namespace]
+		if set == nil {
+			set = make(map[string]struct{})
+			out[p.Namespace] = set
+		}
+		for _, g := range p.MetricInfo {
+			for _, m := range g.MetricList {
+				set[m] = struct{}{}
+			}
+		}
+	}
+	return out
+}
+
+// setMinus 返回 a 中不在 b 中的元素（已排序）
+func setMinus(a, b map[string]struct{}) []string {
+	var out []string
+	for k := range a {
+		if _, ok := b[k]; !ok {
+			out = append(out, k)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+type resourceGroup struct {
+	provider, accountID, region, namespace string
+}
+
+// groupResources 按 provider/account/region/namespace 分组资源 ID
+func groupResources(items []ResourceRef) map[resourceGroup]map[string]struct{} {
+	out := make(map[resourceGroup]map[string]struct{})
+	for _, it := range items {
+		k := resourceGroup{it.Provider, it.AccountID, it.Region, it.Namespace}
+		set := out[k]
+		if set == nil {
+			set = make(map[string]struct{})
+			out[k] = set
+		}
+		set[it.ResourceID] = struct{}{}
+	}
+	return out
+}
+
+// diffResources 比较两次资源清单，返回排序后的分组变化
+func diffResources(old, cur map[resourceGroup]map[string]struct{}) []ResourceDiff {
+	keys := make(map[resourceGroup]struct{}, len(old)+len(cur))
+	for k := range old {
+		keys[k] = struct{}{}
+	}
+	for k := range cur {
+		keys[k] = struct{}{}
+	}
+	var out []ResourceDiff
+	for k := range keys {
+		added, removed := setMinus(cur[k], old[k]), setMinus(old[k], cur[k])
+		if len(added)+len(removed) == 0 {
+			continue
+		}
+		out = append(out, ResourceDiff{
+			Provider: k.provider, AccountID: k.accountID, Region: k.region, Namespace: k.namespace,
+			Added: added, Removed: removed,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		if a.AccountID != b.AccountID {
+			return a.AccountID < b.AccountID
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		return a.Namespace < b.Namespace
+	})
+	return out
+}
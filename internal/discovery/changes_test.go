@@ -0,0 +1,185 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"multicloud-exporter/internal/config"
+)
+
+func product(ns string, metrics ...string) config.Product {
+	return config.Product{Namespace: ns, MetricInfo: []config.MetricGroup{{MetricList: metrics}}}
+}
+
+func TestDiffProducts(t *testing.T) {
+	old := map[string][]config.Product{
+		"aliyun":  {product("acs_slb_dashboard", "TrafficRX", "TrafficTX"), product("acs_bwp", "In")},
+		"tencent": {product("QCE/LB", "ClientConnum")},
+	}
+	cur := map[string][]config.Product{
+		"aliyun": {product("acs_slb_dashboard", "TrafficRX", "QPS"), product("acs_alb", "LoadBalancerQPS")},
+		"aws":    {product("AWS/S3", "BucketSizeBytes")},
+	}
+	got := diffProducts(old, cur)
+	want := []ProductDiff{
+		{
+			Provider:        "aliyun",
+			AddedProducts:   []string{"acs_alb"},
+			RemovedProducts: []string{"acs_bwp"},
+			AddedMetrics:    map[string][]string{"acs_slb_dashboard": {"QPS"}},
+			RemovedMetrics:  map[string][]string{"acs_slb_dashboard": {"TrafficTX"}},
+		},
+		{Provider: "aws", AddedProducts: []string{"AWS/S3"}},
+		{Provider: "tencent", RemovedProducts: []string{"QCE/LB"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("diff = %+v\nwant %+v", got, want)
+	}
+	if d := diffProducts(cur, cur); len(d) != 0 {
+		t.Fatalf("identical products should not diff: %+v", d)
+	}
+}
+
+func TestChangeFeed_RingBufferReplay(t *testing.T) {
+	f := newChangeFeed(3)
+	if evs, ok := f.since(0); !ok || len(evs) != 0 {
+		t.Fatalf("empty feed = %v %v", evs, ok)
+	}
+	for i := 0; i < 5; i++ {
+		f.append(ChangeEvent{Type: EventProducts})
+	}
+	evs, ok := f.since(2)
+	if !ok || len(evs) != 3 || evs[0].ID != 3 || evs[2].ID != 5 {
+		t.Fatalf("since(2) = %+v %v", evs, ok)
+	}
+	if evs, ok := f.since(4); !ok || len(evs) != 1 || evs[0].ID != 5 {
+		t.Fatalf("since(4) = %+v %v", evs, ok)
+	}
+	// 事件 2 已被覆盖，无法从 1 之后完整回放
+	if _, ok := f.since(1); ok {
+		t.Fatal("evicted cursor should be incomplete")
+	}
+	// 进程重启后客户端携带的 ID 大于当前最新 ID
+	if _, ok := f.since(9); ok {
+		t.Fatal("unknown future cursor should be incomplete")
+	}
+}
+
+func TestManager_ChangeEvents(t *testing.T) {
+	d := &testD{prods: []config.Product{product("feed_ns", "m1")}}
+	Register("feed_test", d)
+	m := NewManager(&config.Config{Server: &config.ServerConf{DiscoveryFeed: &config.DiscoveryFeedConf{BufferSize: 8}}})
+	if err := m.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	head := m.LastEventID()
+
+	d.prods = []config.Product{product("feed_ns", "m1", "m2")}
+	ch := m.Subscribe()
+	defer m.Unsubscribe(ch)
+	if err := m.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	evs, ok := m.ChangesSince(head)
+	if !ok || len(evs) != 1 || evs[0].Type != EventProducts || evs[0].Version != m.Version() {
+		t.Fatalf("events = %+v %v", evs, ok)
+	}
+	if p := evs[0].Products; len(p) != 1 || p[0].Provider != "feed_test" || !reflect.DeepEqual(p[0].AddedMetrics["feed_ns"], []string{"m2"}) {
+		t.Fatalf("products diff = %+v", p)
+	}
+	select {
+	case <-ch:
+	default:
+		t.Fatal("subscribers should be notified")
+	}
+
+	// 仅 Period 变化：版本递增，事件不含产品差异
+	period := 300
+	changed := product("feed_ns", "m1", "m2")
+	changed.Period = &period
+	d.prods = []config.Product{changed}
+	if err := m.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	evs, _ = m.ChangesSince(evs[0].ID)
+	if len(evs) != 1 || evs[0].Type != EventProducts || evs[0].Version != m.Version() || len(evs[0].Products) != 0 {
+		t.Fatalf("period-only change events = %+v", evs)
+	}
+
+	// 资源清单：首次只建立基线
+	m.ObserveInventory([]ResourceRef{{Provider: "aliyun", AccountID: "acc", Region: "cn-hangzhou", Namespace: "acs_slb_dashboard", ResourceID: "lb-1"}})
+	if m.LastEventID() != evs[0].ID {
+		t.Fatal("first inventory observation should not emit an event")
+	}
+	m.ObserveInventory([]ResourceRef{
+		{Provider: "aliyun", AccountID: "acc", Region: "cn-hangzhou", Namespace: "acs_slb_dashboard", ResourceID: "lb-2"},
+		{Provider: "aliyun", AccountID: "acc", Region: "cn-beijing", Namespace: "acs_slb_dashboard", ResourceID: "lb-3"},
+	})
+	evs, _ = m.ChangesSince(evs[0].ID)
+	want := []ResourceDiff{
+		{Provider: "aliyun", AccountID: "acc", Region: "cn-beijing", Namespace: "acs_slb_dashboard", Added: []string{"lb-3"}},
+		{Provider: "aliyun", AccountID: "acc", Region: "cn-hangzhou", Namespace: "acs_slb_dashboard", Added: []string{"lb-2"}, Removed: []string{"lb-1"}},
+	}
+	if len(evs) != 1 || evs[0].Type != EventResources || !reflect.DeepEqual(evs[0].Resources, want) {
+		t.Fatalf("resource events = %+v", evs)
+	}
+}
+
+func TestWebhookNotifier_PostsEvents(t *testing.T) {
+	received := make(chan ChangeEvent, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var ev ChangeEvent
+		_ = json.Unmarshal(body, &ev)
+		received <- ev
+	}))
+	defer srv.Close()
+
+	n := newWebhookNotifier([]config.WebhookConf{{URL: srv.URL, Headers: map[string]string{"Authorization": "Bearer token"}, Timeout: "2s"}}, 4)
+	if n.targets[0].timeout != 2*time.Second {
+		t.Fatalf("timeout = %v", n.targets[0].timeout)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go n.run(ctx)
+	n.enqueue(ChangeEvent{ID: 7, Type: EventResources})
+
+	select {
+	case ev := <-received:
+		if ev.ID != 7 || ev.Type != EventResources {
+			t.Fatalf("received = %+v", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not called")
+	}
+	if newWebhookNotifier(nil, 4) != nil {
+		t.Fatal("no webhooks should disable the notifier")
+	}
+}
+
+func TestWebhookRetryable(t *testing.T) {
+	cases := map[error]bool{
+		&webhookStatusError{code: 500}:                        true,
+		&webhookStatusError{code: 503}:                        true,
+		&webhookStatusError{code: http.StatusTooManyRequests}: true,
+		&webhookStatusError{code: 400}:                        false,
+		&webhookStatusError{code: 401}:                        false,
+		fmt.Errorf("Post: %w", context.DeadlineExceeded):      true,
+	}
+	for err, want := range cases {
+		if got := webhookRetryable(err); got != want {
+			t.Errorf("webhookRetryable(%v) = %v, want %v", err, got, want)
+		}
+	}
+}
@@ -49,8 +49,14 @@ type HuaweiDiscoverer struct{}
 
 // Discover 发现华为云产品和指标
 func (d *HuaweiDiscoverer) Discover(ctx context.Context, cfg *config.Config) []config.Product {
+	prods, _ := d.DiscoverChecked(ctx, cfg)
+	return prods
+}
+
+// DiscoverChecked 同 Discover，全部指标元数据调用失败（结果仅含兜底指标）时返回错误
+func (d *HuaweiDiscoverer) DiscoverChecked(ctx context.Context, cfg *config.Config) ([]config.Product, error) {
 	if cfg == nil {
-		return nil
+		return nil, nil
 	}
 	var accounts []config.CloudAccount
 	if cfg.AccountsByProvider != nil {
@@ -61,7 +67,7 @@ func (d *HuaweiDiscoverer) Discover(ctx context.Context, cfg *config.Config) []c
 	ctxLog := logger.NewContextLogger("Huawei", "resource_type", "Discovery")
 	ctxLog.Debugf("发现服务开始，账号数量=%d", len(accounts))
 	if len(accounts) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	needELB := false
@@ -79,6 +85,8 @@ func (d *HuaweiDiscoverer) Discover(ctx context.Context, cfg *config.Config) []c
 	}
 
 	prods := make([]config.Product, 0)
+	var attempted int   // 指标元数据调用次数
+	var failed []string // 元数据调用失败的命名空间
 
 	if needELB {
 		region := "cn-north-4"
@@ -101,10 +109,12 @@ func (d *HuaweiDiscoverer) Discover(ctx context.Context, cfg *config.Config) []c
 		}
 
 		var metrics []string
+		attempted++
 		client, err := newHuaweiCESClient(region, ak, sk)
 		if err != nil {
 			ctxLog := logger.NewContextLogger("Huawei", "resource_type", "Discovery", "namespace", "SYS.ELB")
 			ctxLog.Warnf("CES 客户端创建失败，错误=%v", err)
+			failed = append(failed, "SYS.ELB")
 		} else {
 			ns := "SYS.ELB"
 			req := &cesmodel.ListMetricsRequest{
@@ -114,6 +124,7 @@ func (d *HuaweiDiscoverer) Discover(ctx context.Context, cfg *config.Config) []c
 			if err != nil {
 				ctxLog := logger.NewContextLogger("Huawei", "resource_type", "Discovery", "namespace", "SYS.ELB")
 				ctxLog.Warnf("ListMetrics API调用错误，错误=%v", err)
+				failed = append(failed, "SYS.ELB")
 			}
 			if resp != nil && resp.Metrics != nil {
 				for _, m := range *resp.Metrics {
@@ -172,10 +183,12 @@ func (d *HuaweiDiscoverer) Discover(ctx context.Context, cfg *config.Config) []c
 		}
 
 		var capacityMetrics, requestMetrics []string
+		attempted++
 		client, err := newHuaweiCESClient(region, ak, sk)
 		if err != nil {
 			ctxLog := logger.NewContextLogger("Huawei", "resource_type", "Discovery", "namespace", "SYS.OBS")
 			ctxLog.Warnf("CES 客户端创建失败，错误=%v", err)
+			failed = append(failed, "SYS.OBS")
 			// 使用兜底指标
 			capacityMetrics = capacityFallback
 			requestMetrics = requestFallback
@@ -188,6 +201,7 @@ func (d *HuaweiDiscoverer) Discover(ctx context.Context, cfg *config.Config) []c
 			if err != nil {
 				ctxLog := logger.NewContextLogger("Huawei", "resource_type", "Discovery", "namespace", "SYS.OBS")
 				ctxLog.Warnf("ListMetrics API调用错误，错误=%v", err)
+				failed = append(failed, "SYS.OBS")
 			}
 			if resp != nil && resp.Metrics != nil {
 				for _, m := range *resp.Metrics {
@@ -254,7 +268,7 @@ func (d *HuaweiDiscoverer) Discover(ctx context.Context, cfg *config.Config) []c
 		}
 	}
 
-	return prods
+	return prods, metadataError(attempted, failed)
 }
 
 func init() {
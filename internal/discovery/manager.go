@@ -2,12 +2,17 @@ package discovery
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"multicloud-exporter/internal/config"
 	"multicloud-exporter/internal/logger"
+	"multicloud-exporter/internal/metrics"
 
 	"gopkg.in/yaml.v3"
 )
@@ -27,15 +32,43 @@ type Manager struct {
 	lastRefreshDuration time.Duration
 	providerDurations   map[string]time.Duration
 	refreshCount        int64
+	// 变更流（changes.go）：事件环形缓冲、上一轮资源清单与 Webhook 推送
+	feed      *changeFeed
+	invMu     sync.Mutex
+	inventory map[resourceGroup]map[string]struct{} // nil 表示尚未观察到资源清单
+	webhooks  *webhookNotifier
+	// 周期刷新（refresh.go）：Refresh 串行执行，失败的云平台沿用上一次成功的产品列表
+	refresh     refreshSettings
+	refreshMu   sync.Mutex
+	lastSuccess map[string]time.Time
+	lastErrors  map[string]string
+	// 发现目录持久化（catalog.go）：各云平台产品列表的来源（live/cache），未记录表示仅有兜底结果
+	catalogPath string
+	sources     map[string]string
 }
 
 func NewManager(cfg *config.Config) *Manager {
+	var server *config.ServerConf
+	if cfg != nil {
+		server = cfg.GetServer()
+	}
+	var feedConf config.DiscoveryFeedConf
+	if server != nil && server.DiscoveryFeed != nil {
+		feedConf = *server.DiscoveryFeed
+	}
 	return &Manager{
 		cfg:               cfg,
 		products:          make(map[string][]config.Product),
 		subs:              make(map[chan struct{}]struct{}),
 		watchInterval:     3 * time.Second,
 		providerDurations: make(map[string]time.Duration),
+		feed:              newChangeFeed(feedConf.BufferSize),
+		webhooks:          newWebhookNotifier(feedConf.Webhooks, feedConf.BufferSize),
+		refresh:           newRefreshSettings(server),
+		lastSuccess:       make(map[string]time.Time),
+		lastErrors:        make(map[string]string),
+		catalogPath:       catalogPath(server),
+		sources:           make(map[string]string),
 	}
 }
 
@@ -75,6 +108,9 @@ type ProviderStats struct {
 	AccountsCount     int             `json:"accounts_count"`
 	DiscoveryDuration string          `json:"discovery_duration"`
 	Products          []ProductDetail `json:"products"`
+	LastSuccess       int64           `json:"last_success,omitempty"` // 最近一次成功发现的 Unix 时间戳
+	LastError         string          `json:"last_error,omitempty"`   // 最近一次发现失败的原因，成功后清空
+	Source            string          `json:"source,omitempty"`       // 产品列表来源：live 或 cache（启动时加载的发现目录）
 }
 
 type DiscoveryStatus struct {
@@ -87,6 +123,9 @@ type DiscoveryStatus struct {
 	ProductsCount       map[string]int           `json:"products_count"`
 	LastRefreshDuration string                   `json:"last_refresh_duration"`
 	RefreshCount        int64                    `json:"refresh_count"`
+	RefreshInterval     string                   `json:"refresh_interval"` // 周期刷新间隔，0s 表示关闭
+	Source              string                   `json:"source"`           // live：全部产品列表已在本进程发现；cache：仍有云平台使用启动时加载的发现目录
+	CatalogPath         string                   `json:"catalog_path,omitempty"`
 	ProviderStats       map[string]ProviderStats `json:"provider_stats"`
 }
 
@@ -133,15 +172,35 @@ func (m *Manager) Status() DiscoveryStatus {
 			discoveryDuration = dur.String()
 		}
 
+		var lastSuccess int64
+		if ts, ok := m.lastSuccess[provider]; ok {
+			lastSuccess = ts.Unix()
+		}
 		providerStats[provider] = ProviderStats{
 			ProductsCount:     len(products),
 			MetricsCount:      totalMetrics,
 			AccountsCount:     accountsByProvider[provider],
 			DiscoveryDuration: discoveryDuration,
 			Products:          productDetails,
+			LastSuccess:       lastSuccess,
+			LastError:         m.lastErrors[provider],
+			Source:            m.sources[provider],
+		}
+	}
+	// 从未成功发现过的云平台也给出失败原因
+	for provider, msg := range m.lastErrors {
+		if _, ok := providerStats[provider]; !ok {
+			providerStats[provider] = ProviderStats{AccountsCount: accountsByProvider[provider], LastError: msg}
 		}
 	}
 
+	source := SourceLive
+	for _, s := range m.sources {
+		if s == SourceCache {
+			source = SourceCache
+			break
+		}
+	}
 	ver := m.version
 	up := m.updatedAt.Unix()
 	accPath := m.lastAccPath
@@ -165,27 +224,86 @@ func (m *Manager) Status() DiscoveryStatus {
 		ProductsCount:       counts,
 		LastRefreshDuration: lastRefreshDuration,
 		RefreshCount:        refreshCount,
+		RefreshInterval:     m.refresh.interval.String(),
+		Source:              source,
+		CatalogPath:         m.catalogPath,
 		ProviderStats:       providerStats,
 	}
 }
 
+// Refresh 并行执行各云平台的产品发现。单个云平台超时、panic 或返回错误时记录
+// multicloud_discovery_refresh_errors_total 并沿用其上一次成功的产品列表，不影响其它云平台
 func (m *Manager) Refresh(ctx context.Context) error {
+	m.refreshMu.Lock()
+	defer m.refreshMu.Unlock()
 	start := time.Now()
-	prods := make(map[string][]config.Product)
-	providerDurations := make(map[string]time.Duration)
+	results := make(map[string]discoverResult)
+	var resMu sync.Mutex
+	var wg sync.WaitGroup
 	m.cfg.Mu.RLock()
 	for _, name := range GetAllDiscoverers() {
 		if d, ok := GetDiscoverer(name); ok {
-			providerStart := time.Now()
-			if ps := d.Discover(ctx, m.cfg); len(ps) > 0 {
-				prods[name] = ps
-			}
-			providerDurations[name] = time.Since(providerStart)
+			wg.Add(1)
+			go func(name string, d Discoverer) {
+				defer wg.Done()
+				res := m.discoverProvider(ctx, d)
+				resMu.Lock()
+				results[name] = res
+				resMu.Unlock()
+			}(name, d)
 		}
 	}
+	wg.Wait()
 	m.cfg.Mu.RUnlock()
+
+	now := time.Now()
+	prods := make(map[string][]config.Product)
+	providerDurations := make(map[string]time.Duration)
+	var failed []string
 	m.mu.Lock()
+	for name, res := range results {
+		providerDurations[name] = res.duration
+		var partial *PartialError
+		if errors.As(res.err, &partial) {
+			// 部分命名空间失败：这些命名空间沿用上一次成功的产品，其余命名空间按本次结果更新
+			m.lastErrors[name] = res.err.Error()
+			metrics.DiscoveryRefreshErrorsTotal.WithLabelValues(name).Inc()
+			ctxLog := logger.NewContextLogger("Discovery", "resource_type", "Manager", "provider", name)
+			ctxLog.Warnf("部分命名空间发现失败，沿用上一次成功的产品: %v", res.err)
+			res.products = mergeFailedNamespaces(res.products, m.products[name], partial.Namespaces)
+			m.lastSuccess[name] = now
+			metrics.DiscoveryLastSuccess.WithLabelValues(name).Set(float64(now.Unix()))
+			m.sources[name] = SourceLive
+		} else if res.err != nil {
+			failed = append(failed, name)
+			m.lastErrors[name] = res.err.Error()
+			metrics.DiscoveryRefreshErrorsTotal.WithLabelValues(name).Inc()
+			ctxLog := logger.NewContextLogger("Discovery", "resource_type", "Manager", "provider", name)
+			if prev, ok := m.products[name]; ok {
+				ctxLog.Warnf("发现失败，沿用上一次成功的产品列表（产品数=%d）: %v", len(prev), res.err)
+				prods[name] = prev
+				continue
+			}
+			// 没有可沿用的列表（如启动时首次发现失败）时使用本次返回的兜底结果，不写入发现目录
+			ctxLog.Warnf("发现失败: %v", res.err)
+			delete(m.sources, name)
+		} else {
+			delete(m.lastErrors, name)
+			m.lastSuccess[name] = now
+			metrics.DiscoveryLastSuccess.WithLabelValues(name).Set(float64(now.Unix()))
+			m.sources[name] = SourceLive
+		}
+		if len(res.products) > 0 {
+			prods[name] = res.products
+		} else {
+			delete(m.sources, name)
+		}
+	}
 	changed := !equalProducts(m.products, prods)
+	var diffs []ProductDiff
+	if changed {
+		diffs = diffProducts(m.products, prods)
+	}
 	m.products = prods
 	duration := time.Since(start)
 	m.lastRefreshDuration = duration
@@ -200,13 +318,57 @@ func (m *Manager) Refresh(ctx context.Context) error {
 		ctxLog := logger.NewContextLogger("Discovery", "resource_type", "Manager")
 		ctxLog.Infof("发现服务检查完成，无变化，总耗时: %v", duration)
 	}
+	version := m.version
 	m.mu.Unlock()
 	if changed {
+		// 仅 Period/Statistics 等变化时 products 为空，订阅方仍需根据 version 重新拉取配置
+		m.publish(ChangeEvent{Type: EventProducts, Version: version, Products: diffs})
 		m.broadcast()
 	}
+	// 至少一个云平台发现成功时持久化，供下次启动直接加载
+	if len(failed) < len(results) {
+		if err := m.saveCatalog(); err != nil {
+			ctxLog := logger.NewContextLogger("Discovery", "resource_type", "Manager")
+			ctxLog.Warnf("保存发现目录失败: %v", err)
+		}
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("discovery failed for %s", strings.Join(failed, ","))
+	}
 	return nil
 }
 
+// mergeFailedNamespaces 用上一次成功的产品替换元数据调用失败的命名空间；没有上一次结果的命名空间保留本次的兜底结果
+func mergeFailedNamespaces(cur, prev []config.Product, failed []string) []config.Product {
+	failedSet := make(map[string]struct{}, len(failed))
+	for _, ns := range failed {
+		failedSet[ns] = struct{}{}
+	}
+	prevByNS := make(map[string]config.Product, len(prev))
+	for _, p := range prev {
+		if _, ok := failedSet[p.Namespace]; ok {
+			prevByNS[p.Namespace] = p
+		}
+	}
+	out := make([]config.Product, 0, len(cur)+len(prevByNS))
+	for _, p := range cur {
+		if old, ok := prevByNS[p.Namespace]; ok {
+			out = append(out, old)
+			delete(prevByNS, p.Namespace)
+			continue
+		}
+		out = append(out, p)
+	}
+	// 本次失败且未返回兜底结果的命名空间
+	for _, p := range prev {
+		if _, ok := prevByNS[p.Namespace]; ok {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
 func equalProducts(a, b map[string][]config.Product) bool {
 	// 快速路径：长度不同
 	if len(a) != len(b) {
@@ -286,16 +448,44 @@ func equalProducts(a, b map[string][]config.Product) bool {
 	return true
 }
 
+// StartOptions 控制 Start 的启动行为
+type StartOptions struct {
+	// Revalidate 加载到发现目录后仍同步重新发现（once 等一次性运行不能使用过期目录）；
+	// 重新发现失败的云平台沿用发现目录中的结果
+	Revalidate bool
+}
+
 func (m *Manager) Start(ctx context.Context) {
+	m.StartWithOptions(ctx, StartOptions{})
+}
+
+// StartWithOptions 同 Start，按 opts 决定加载发现目录后是否同步重新发现
+func (m *Manager) StartWithOptions(ctx context.Context, opts StartOptions) {
 	p := os.Getenv("ACCOUNTS_PATH")
 	m.lastAccPath = p
 	m.lastAccSig = m.accountsSignature()
-	_ = m.Refresh(ctx)
+	if m.webhooks != nil {
+		go m.webhooks.run(ctx)
+	}
+	// 有持久化的发现目录时立即使用，并在后台重新发现，云 API 不可用时也能照常采集
+	ctxLog := logger.NewContextLogger("Discovery", "resource_type", "Manager")
+	loaded, err := m.loadCatalog()
+	if err != nil {
+		ctxLog.Warnf("加载发现目录失败，改为同步发现: %v", err)
+	}
+	if loaded && !opts.Revalidate {
+		ctxLog.Infof("已加载发现目录，版本=%d，后台重新发现: %s", m.Version(), m.catalogPath)
+		go func() { _ = m.Refresh(ctx) }()
+	} else {
+		if loaded {
+			ctxLog.Infof("已加载发现目录，版本=%d，同步重新发现: %s", m.Version(), m.catalogPath)
+		}
+		_ = m.Refresh(ctx)
+	}
 	go m.watchAccounts(ctx, p)
+	go m.refreshLoop(ctx)
 }
 
-// periodic refresh removed: discovery uses accounts resources changes as trigger
-
 func (m *Manager) watchAccounts(ctx context.Context, path string) {
 	if path == "" {
 		return
@@ -350,6 +540,8 @@ func (m *Manager) reloadAccounts(path string) string {
 		// 原子替换配置
 		m.cfg.Mu.Lock()
 		m.cfg.AccountsByProvider = newAccounts
+		// 账号 labels 可能增减，自定义标签集合随之更新（变化时重建命名空间指标）
+		metrics.SetCustomLabels(m.cfg.CustomLabelKeys())
 		sig := m.accountsSignatureLocked()
 		m.cfg.Mu.Unlock()
 		return sig
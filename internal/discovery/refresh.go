@@ -0,0 +1,118 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"multicloud-exporter/internal/config"
+	"multicloud-exporter/internal/logger"
+	"multicloud-exporter/internal/utils"
+)
+
+const (
+	defaultRefreshInterval = 6 * time.Hour
+	defaultRefreshJitter   = 0.1
+	defaultProviderTimeout = 2 * time.Minute
+)
+
+// refreshSettings 周期刷新参数，来自 server.discovery_refresh / discovery_refresh_jitter / discovery_timeout
+type refreshSettings struct {
+	interval time.Duration // 0 表示关闭周期刷新
+	jitter   float64
+	timeout  time.Duration
+}
+
+func newRefreshSettings(server *config.ServerConf) refreshSettings {
+	s := refreshSettings{interval: defaultRefreshInterval, jitter: defaultRefreshJitter, timeout: defaultProviderTimeout}
+	if server == nil {
+		return s
+	}
+	ctxLog := logger.NewContextLogger("Discovery", "resource_type", "Manager")
+	if server.DiscoveryRefresh != "" {
+		if d, err := utils.ParseDuration(server.DiscoveryRefresh); err == nil && d >= 0 {
+			s.interval = d
+		} else {
+			ctxLog.Warnf("discovery_refresh 无效，使用默认值 %v: %q", defaultRefreshInterval, server.DiscoveryRefresh)
+		}
+	}
+	if server.DiscoveryRefreshJitter > 0 && server.DiscoveryRefreshJitter < 1 {
+		s.jitter = server.DiscoveryRefreshJitter
+	}
+	if server.DiscoveryTimeout != "" {
+		if d, err := utils.ParseDuration(server.DiscoveryTimeout); err == nil && d > 0 {
+			s.timeout = d
+		} else {
+			ctxLog.Warnf("discovery_timeout 无效，使用默认值 %v: %q", defaultProviderTimeout, server.DiscoveryTimeout)
+		}
+	}
+	return s
+}
+
+// nextDelay 返回带随机抖动的下一次刷新间隔，避免多个实例同时调用云 API
+func (s refreshSettings) nextDelay() time.Duration {
+	if s.jitter <= 0 {
+		return s.interval
+	}
+	span := float64(s.interval) * s.jitter
+	return s.interval + time.Duration((rand.Float64()*2-1)*span)
+}
+
+// refreshLoop 按 discovery_refresh 周期刷新，让云端新增的指标无需修改 accounts.yaml 即可生效
+func (m *Manager) refreshLoop(ctx context.Context) {
+	if m.refresh.interval <= 0 {
+		return
+	}
+	for {
+		timer := time.NewTimer(m.refresh.nextDelay())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			_ = m.Refresh(ctx)
+		}
+	}
+}
+
+// discoverResult 单个云平台的发现结果
+type discoverResult struct {
+	products []config.Product
+	err      error
+	duration time.Duration
+}
+
+// discoverProvider 在独立 goroutine 中执行单个云平台的发现：超时、panic 与 CheckedDiscoverer 返回的错误
+// 都只影响该云平台
+func (m *Manager) discoverProvider(ctx context.Context, d Discoverer) discoverResult {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, m.refresh.timeout)
+	defer cancel()
+	done := make(chan discoverResult, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- discoverResult{err: fmt.Errorf("panic: %v", r)}
+			}
+		}()
+		if cd, ok := d.(CheckedDiscoverer); ok {
+			ps, err := cd.DiscoverChecked(ctx, m.cfg)
+			done <- discoverResult{products: ps, err: err}
+			return
+		}
+		done <- discoverResult{products: d.Discover(ctx, m.cfg)}
+	}()
+	var res discoverResult
+	select {
+	case res = <-done:
+		// 超时后才返回的结果可能不完整
+		if res.err == nil && ctx.Err() != nil {
+			res.err = ctx.Err()
+		}
+	case <-ctx.Done():
+		res.err = fmt.Errorf("timeout after %v: %w", m.refresh.timeout, ctx.Err())
+	}
+	res.duration = time.Since(start)
+	return res
+}
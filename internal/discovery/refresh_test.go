@@ -0,0 +1,160 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"multicloud-exporter/internal/config"
+	"multicloud-exporter/internal/metrics"
+)
+
+// checkedD 可切换为失败的 CheckedDiscoverer
+type checkedD struct {
+	prods []config.Product
+	err   error
+}
+
+func (d *checkedD) Discover(ctx context.Context, cfg *config.Config) []config.Product { return d.prods }
+
+func (d *checkedD) DiscoverChecked(ctx context.Context, cfg *config.Config) ([]config.Product, error) {
+	return d.prods, d.err
+}
+
+// blockingD 阻塞到超时
+type blockingD struct{}
+
+func (blockingD) Discover(ctx context.Context, cfg *config.Config) []config.Product {
+	<-ctx.Done()
+	return nil
+}
+
+type panicD struct{}
+
+func (panicD) Discover(ctx context.Context, cfg *config.Config) []config.Product { panic("boom") }
+
+// registerForTest 注册测试用发现器，测试结束后注销，避免影响其它测试的 Refresh
+func registerForTest(t *testing.T, name string, d Discoverer) {
+	Register(name, d)
+	t.Cleanup(func() {
+		mu.Lock()
+		delete(registry, name)
+		mu.Unlock()
+	})
+}
+
+func TestRefresh_FailedProviderKeepsLastGoodProducts(t *testing.T) {
+	good := []config.Product{product("ns", "m1", "m2")}
+	flaky := &checkedD{prods: good}
+	registerForTest(t, "refresh_flaky", flaky)
+	registerForTest(t, "refresh_panic", panicD{})
+	m := NewManager(&config.Config{Server: &config.ServerConf{}})
+
+	if err := m.Refresh(context.Background()); err == nil {
+		t.Fatal("panicking discoverer should be reported")
+	}
+	if !reflect.DeepEqual(m.Get()["refresh_flaky"], good) {
+		t.Fatalf("products = %+v", m.Get()["refresh_flaky"])
+	}
+	if ts := testutil.ToFloat64(metrics.DiscoveryLastSuccess.WithLabelValues("refresh_flaky")); ts == 0 {
+		t.Fatal("last success timestamp not exported")
+	}
+	version := m.Version()
+	errorsBefore := testutil.ToFloat64(metrics.DiscoveryRefreshErrorsTotal.WithLabelValues("refresh_flaky"))
+
+	// 元数据调用全部失败：仅返回兜底指标，应沿用上一次的完整列表
+	flaky.prods = []config.Product{product("ns", "m1")}
+	flaky.err = errors.New("all 1 metric metadata calls failed")
+	if err := m.Refresh(context.Background()); err == nil {
+		t.Fatal("expected refresh error")
+	}
+	if !reflect.DeepEqual(m.Get()["refresh_flaky"], good) || m.Version() != version {
+		t.Fatalf("failed provider should keep last good products, got %+v (version %d -> %d)", m.Get()["refresh_flaky"], version, m.Version())
+	}
+	if got := testutil.ToFloat64(metrics.DiscoveryRefreshErrorsTotal.WithLabelValues("refresh_flaky")); got != errorsBefore+1 {
+		t.Fatalf("refresh errors = %v, want %v", got, errorsBefore+1)
+	}
+	st := m.Status().ProviderStats
+	if st["refresh_flaky"].LastError == "" || st["refresh_flaky"].LastSuccess == 0 || st["refresh_panic"].LastError == "" {
+		t.Fatalf("provider stats = %+v", st)
+	}
+
+	flaky.err = nil
+	_ = m.Refresh(context.Background())
+	if m.Status().ProviderStats["refresh_flaky"].LastError != "" || len(m.Get()["refresh_flaky"][0].MetricInfo[0].MetricList) != 1 {
+		t.Fatal("successful refresh should replace the product list and clear the error")
+	}
+}
+
+func TestRefresh_PartialFailureKeepsFailedNamespaces(t *testing.T) {
+	d := &checkedD{prods: []config.Product{product("ns_a", "a1", "a2"), product("ns_b", "b1", "b2"), product("ns_c", "c1")}}
+	registerForTest(t, "refresh_partial", d)
+	m := NewManager(&config.Config{Server: &config.ServerConf{}})
+	if err := m.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// ns_a 退化为兜底指标，ns_b 未返回，ns_c 成功并新增指标
+	d.prods = []config.Product{product("ns_a", "a1"), product("ns_c", "c1", "c2")}
+	d.err = &PartialError{Namespaces: []string{"ns_a", "ns_b"}}
+	if err := m.Refresh(context.Background()); err != nil {
+		t.Fatalf("partial failure should not fail the provider: %v", err)
+	}
+	want := []config.Product{product("ns_a", "a1", "a2"), product("ns_c", "c1", "c2"), product("ns_b", "b1", "b2")}
+	if got := m.Get()["refresh_partial"]; !reflect.DeepEqual(got, want) {
+		t.Fatalf("products = %+v\nwant %+v", got, want)
+	}
+	if m.Status().ProviderStats["refresh_partial"].LastError == "" {
+		t.Fatal("partial failure should be reported in provider stats")
+	}
+}
+
+func TestRefresh_ProviderTimeout(t *testing.T) {
+	registerForTest(t, "refresh_slow", blockingD{})
+	registerForTest(t, "refresh_fast", &testD{prods: []config.Product{product("fast")}})
+	m := NewManager(&config.Config{Server: &config.ServerConf{DiscoveryTimeout: "50ms"}})
+
+	start := time.Now()
+	if err := m.Refresh(context.Background()); err == nil {
+		t.Fatal("timeout should be reported")
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Fatal("refresh should not wait past the provider timeout")
+	}
+	prods := m.Get()
+	if len(prods["refresh_fast"]) != 1 {
+		t.Fatalf("other providers should be unaffected: %+v", prods)
+	}
+	if m.Status().ProviderStats["refresh_slow"].LastError == "" {
+		t.Fatal("timed out provider should report its error")
+	}
+}
+
+func TestRefreshSettings(t *testing.T) {
+	s := newRefreshSettings(&config.ServerConf{DiscoveryRefresh: "1h", DiscoveryRefreshJitter: 0.2, DiscoveryTimeout: "30s"})
+	if s.interval != time.Hour || s.timeout != 30*time.Second {
+		t.Fatalf("settings = %+v", s)
+	}
+	for i := 0; i < 100; i++ {
+		if d := s.nextDelay(); d < 48*time.Minute || d > 72*time.Minute {
+			t.Fatalf("jittered delay out of range: %v", d)
+		}
+	}
+	if d := newRefreshSettings(nil); d.interval != defaultRefreshInterval || d.jitter != defaultRefreshJitter {
+		t.Fatalf("defaults = %+v", d)
+	}
+	if d := newRefreshSettings(&config.ServerConf{DiscoveryRefresh: "0"}); d.interval != 0 {
+		t.Fatalf("0 should disable periodic refresh: %+v", d)
+	}
+	if metadataError(0, nil) != nil || metadataError(2, []string{"a", "b"}) == nil {
+		t.Fatal("metadataError should fail when every call failed")
+	}
+	var partial *PartialError
+	if err := metadataError(3, []string{"b", "a"}); !errors.As(err, &partial) || !reflect.DeepEqual(partial.Namespaces, []string{"a", "b"}) {
+		t.Fatalf("partial failure = %v", err)
+	}
+}
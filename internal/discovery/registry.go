@@ -2,6 +2,9 @@ package discovery
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
 
 	"multicloud-exporter/internal/config"
@@ -17,6 +20,38 @@ var (
 	mu       sync.RWMutex
 )
 
+// CheckedDiscoverer is an optional interface for discoverers that can report
+// a failed discovery (e.g. all metadata API calls failed and only fallback
+// metrics were returned). Refresh keeps the last good product list on error,
+// or only the failed namespaces' products when the error is a *PartialError.
+type CheckedDiscoverer interface {
+	DiscoverChecked(ctx context.Context, cfg *config.Config) ([]config.Product, error)
+}
+
+// PartialError reports namespaces whose metadata call failed while others
+// succeeded. Products returned for these namespaces are fallbacks (or missing).
+type PartialError struct {
+	Namespaces []string
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("metric metadata calls failed for %s", strings.Join(e.Namespaces, ","))
+}
+
+// metadataError returns an error when every metadata call failed, or a
+// *PartialError naming the failed namespaces when only some failed
+func metadataError(attempted int, failed []string) error {
+	if len(failed) == 0 {
+		return nil
+	}
+	if len(failed) >= attempted {
+		return fmt.Errorf("all %d metric metadata calls failed", len(failed))
+	}
+	namespaces := append([]string(nil), failed...)
+	sort.Strings(namespaces)
+	return &PartialError{Namespaces: namespaces}
+}
+
 // Register registers a discoverer for a cloud provider
 func Register(provider string, d Discoverer) {
 	mu.Lock()
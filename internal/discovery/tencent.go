@@ -25,8 +25,14 @@ var newTencentMonitorClient = func(region, ak, sk string) (MonitorClient, error)
 type TencentDiscoverer struct{}
 
 func (d *TencentDiscoverer) Discover(ctx context.Context, cfg *config.Config) []config.Product {
+	prods, _ := d.DiscoverChecked(ctx, cfg)
+	return prods
+}
+
+// DiscoverChecked 同 Discover，全部指标元数据调用失败（结果仅含兜底指标）时返回错误
+func (d *TencentDiscoverer) DiscoverChecked(ctx context.Context, cfg *config.Config) ([]config.Product, error) {
 	if cfg == nil {
-		return nil
+		return nil, nil
 	}
 	var accounts []config.CloudAccount
 	if cfg.AccountsByProvider != nil {
@@ -37,7 +43,7 @@ func (d *TencentDiscoverer) Discover(ctx context.Context, cfg *config.Config) []
 	ctxLog := logger.NewContextLogger("Tencent", "resource_type", "Discovery")
 	ctxLog.Debugf("发现服务开始，账号数量=%d", len(accounts))
 	if len(accounts) == 0 {
-		return nil
+		return nil, nil
 	}
 	needBWP := false
 	needCLB := false
@@ -64,6 +70,8 @@ func (d *TencentDiscoverer) Discover(ctx context.Context, cfg *config.Config) []
 		}
 	}
 	prods := make([]config.Product, 0)
+	var attempted int   // 指标元数据调用次数
+	var failed []string // 元数据调用失败的命名空间
 	if needBWP {
 		region := "ap-guangzhou"
 		if len(accounts) > 0 && len(accounts[0].Regions) > 0 && accounts[0].Regions[0] != "*" {
@@ -80,10 +88,12 @@ func (d *TencentDiscoverer) Discover(ctx context.Context, cfg *config.Config) []
 		}
 
 		var metrics []string
+		attempted++
 		client, err := newTencentMonitorClient(region, ak, sk)
 		if err != nil {
 			ctxLog := logger.NewContextLogger("Tencent", "resource_type", "Discovery", "namespace", "QCE/BWP")
 			ctxLog.Warnf("客户端创建失败，错误=%v", err)
+			failed = append(failed, "QCE/BWP")
 		} else {
 			req := monitor.NewDescribeBaseMetricsRequest()
 			req.Namespace = common.StringPtr("QCE/BWP")
@@ -91,6 +101,7 @@ func (d *TencentDiscoverer) Discover(ctx context.Context, cfg *config.Config) []
 			if err != nil {
 				ctxLog := logger.NewContextLogger("Tencent", "resource_type", "Discovery", "namespace", "QCE/BWP")
 				ctxLog.Warnf("DescribeBaseMetrics API调用错误，错误=%v", err)
+				failed = append(failed, "QCE/BWP")
 			}
 			if err == nil && resp != nil && resp.Response != nil && resp.Response.MetricSet != nil {
 				for _, m := range resp.Response.MetricSet {
@@ -135,10 +146,12 @@ func (d *TencentDiscoverer) Discover(ctx context.Context, cfg *config.Config) []
 		}
 
 		var metrics []string
+		attempted++
 		client, err := newTencentMonitorClient(region, ak, sk)
 		if err != nil {
 			ctxLog := logger.NewContextLogger("Tencent", "resource_type", "Discovery", "namespace", "qce/gwlb")
 			ctxLog.Warnf("客户端创建失败，错误=%v", err)
+			failed = append(failed, "qce/gwlb")
 		} else {
 			ns := "qce/gwlb"
 			req := monitor.NewDescribeBaseMetricsRequest()
@@ -147,6 +160,7 @@ func (d *TencentDiscoverer) Discover(ctx context.Context, cfg *config.Config) []
 			if err != nil {
 				ctxLog := logger.NewContextLogger("Tencent", "resource_type", "Discovery", "namespace", ns)
 				ctxLog.Warnf("DescribeBaseMetrics API调用错误，错误=%v", err)
+				failed = append(failed, ns)
 			}
 			if resp != nil && resp.Response != nil && resp.Response.MetricSet != nil {
 				for _, m := range resp.Response.MetricSet {
@@ -204,10 +218,12 @@ func (d *TencentDiscoverer) Discover(ctx context.Context, cfg *config.Config) []
 		}
 
 		var metrics []string
+		attempted++
 		client, err := newTencentMonitorClient(region, ak, sk)
 		if err != nil {
 			ctxLog := logger.NewContextLogger("Tencent", "resource_type", "Discovery", "namespace", "QCE/LB")
 			ctxLog.Warnf("客户端创建失败，错误=%v", err)
+			failed = append(failed, "QCE/LB")
 		} else {
 			ns := "QCE/LB"
 			req := monitor.NewDescribeBaseMetricsRequest()
@@ -216,6 +232,7 @@ func (d *TencentDiscoverer) Discover(ctx context.Context, cfg *config.Config) []
 			if err != nil {
 				ctxLog := logger.NewContextLogger("Tencent", "resource_type", "Discovery", "namespace", ns)
 				ctxLog.Warnf("DescribeBaseMetrics API调用错误，错误=%v", err)
+				failed = append(failed, ns)
 			}
 			if resp != nil && resp.Response != nil && resp.Response.MetricSet != nil {
 				for _, m := range resp.Response.MetricSet {
@@ -286,10 +303,12 @@ func (d *TencentDiscoverer) Discover(ctx context.Context, cfg *config.Config) []
 		}
 
 		var capacityMetrics, requestMetrics []string
+		attempted++
 		client, err := newTencentMonitorClient(region, ak, sk)
 		if err != nil {
 			ctxLog := logger.NewContextLogger("Tencent", "resource_type", "Discovery", "namespace", "QCE/COS")
 			ctxLog.Warnf("客户端创建失败，错误=%v", err)
+			failed = append(failed, "QCE/COS")
 			// 使用兜底指标
 			capacityMetrics = capacityFallback
 			requestMetrics = requestFallback
@@ -300,6 +319,7 @@ func (d *TencentDiscoverer) Discover(ctx context.Context, cfg *config.Config) []
 			if err != nil {
 				ctxLog := logger.NewContextLogger("Tencent", "resource_type", "Discovery", "namespace", "QCE/COS")
 				ctxLog.Warnf("DescribeBaseMetrics API调用错误，错误=%v", err)
+				failed = append(failed, "QCE/COS")
 			}
 			if err == nil && resp != nil && resp.Response != nil && resp.Response.MetricSet != nil {
 				for _, m := range resp.Response.MetricSet {
@@ -366,7 +386,7 @@ func (d *TencentDiscoverer) Discover(ctx context.Context, cfg *config.Config) []
 			ctxLog.Warnf("发现服务未发现指标")
 		}
 	}
-	return prods
+	return prods, metadataError(attempted, failed)
 }
 
 func init() {
@@ -3,6 +3,7 @@ package discovery
 import (
 	"context"
 	"multicloud-exporter/internal/config"
+	"multicloud-exporter/internal/metrics"
 	"os"
 	"path/filepath"
 	"testing"
@@ -148,3 +149,17 @@ func TestManager_Watch(t *testing.T) {
 		assert.Equal(t, currentVersion, m.Version())
 	})
 }
+
+func TestReloadAccounts_UpdatesCustomLabels(t *testing.T) {
+	defer metrics.SetCustomLabels(nil)
+	path := filepath.Join(t.TempDir(), "accounts.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`accounts:
+  aliyun:
+    - account_id: acc
+      labels:
+        team: infra
+`), 0644))
+	m := NewManager(&config.Config{Server: &config.ServerConf{}})
+	m.reloadAccounts(path)
+	assert.Equal(t, []string{"team"}, metrics.CustomLabels())
+}
@@ -0,0 +1,137 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"multicloud-exporter/internal/config"
+	"multicloud-exporter/internal/logger"
+	"multicloud-exporter/internal/utils"
+)
+
+const defaultWebhookTimeout = 5 * time.Second
+
+// webhookTarget 解析后的 Webhook 地址
+type webhookTarget struct {
+	url     string
+	headers map[string]string
+	timeout time.Duration
+}
+
+// webhookNotifier 将变更事件异步 POST 到配置的 Webhook；队列满时丢弃事件，不阻塞 Refresh 与采集
+type webhookNotifier struct {
+	targets []webhookTarget
+	queue   chan ChangeEvent
+	client  *http.Client
+	retry   *utils.RetryConfig
+}
+
+func newWebhookNotifier(hooks []config.WebhookConf, queueSize int) *webhookNotifier {
+	if len(hooks) == 0 {
+		return nil
+	}
+	if queueSize <= 0 {
+		queueSize = defaultFeedBufferSize
+	}
+	retry := utils.DefaultRetryConfig()
+	retry.Retryable = webhookRetryable
+	n := &webhookNotifier{
+		queue:  make(chan ChangeEvent, queueSize),
+		client: utils.NewHTTPClient(),
+		retry:  retry,
+	}
+	for _, h := range hooks {
+		t := webhookTarget{url: h.URL, headers: h.Headers, timeout: defaultWebhookTimeout}
+		if h.Timeout != "" {
+			if d, err := utils.ParseDuration(h.Timeout); err == nil && d > 0 {
+				t.timeout = d
+			}
+		}
+		n.targets = append(n.targets, t)
+	}
+	return n
+}
+
+// enqueue 非阻塞入队
+func (n *webhookNotifier) enqueue(ev ChangeEvent) {
+	select {
+	case n.queue <- ev:
+	default:
+		ctxLog := logger.NewContextLogger("Discovery", "resource_type", "Webhook")
+		ctxLog.Warnf("Webhook 队列已满，丢弃变更事件 id=%d", ev.ID)
+	}
+}
+
+// run 依次投递队列中的事件，直到 ctx 结束
+func (n *webhookNotifier) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-n.queue:
+			body, err := json.Marshal(ev)
+			if err != nil {
+				continue
+			}
+			for _, t := range n.targets {
+				if err := utils.RetryWithContext(ctx, n.retry, func() error { return n.post(ctx, t, body) }); err != nil {
+					ctxLog := logger.NewContextLogger("Discovery", "resource_type", "Webhook", "url", t.url)
+					ctxLog.Warnf("Webhook 推送失败，事件 id=%d: %v", ev.ID, err)
+				}
+			}
+		}
+	}
+}
+
+func (n *webhookNotifier) post(ctx context.Context, t webhookTarget, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &webhookStatusError{code: resp.StatusCode}
+	}
+	return nil
+}
+
+// webhookStatusError Webhook 返回非 2xx 状态码
+type webhookStatusError struct {
+	code int
+}
+
+func (e *webhookStatusError) Error() string {
+	return fmt.Sprintf("webhook status %d", e.code)
+}
+
+// webhookRetryable 5xx、429 与超时（含单次请求超时 context deadline exceeded）重试，其它 4xx 不重试
+func webhookRetryable(err error) bool {
+	var se *webhookStatusError
+	if errors.As(err, &se) {
+		return se.code >= 500 || se.code == http.StatusTooManyRequests
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return true
+	}
+	return utils.IsTransientError(err)
+}
@@ -40,6 +40,8 @@ func Init(cfg *config.LogConfig) {
 	switch output {
 	case "", "stdout", "console":
 		outputs = append(outputs, zapcore.AddSync(os.Stdout))
+	case "stderr":
+		outputs = append(outputs, zapcore.AddSync(os.Stderr))
 	case "file":
 		if cfg.File != nil && cfg.File.Path != "" {
 			outputs = append(outputs, getFileWriteSyncer(cfg.File))
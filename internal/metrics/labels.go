@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// baseLabels 命名空间指标的固定标签，自定义标签与之冲突时加 label_ 前缀
+var baseLabels = []string{"cloud_provider", "account_id", "region", "resource_type", "resource_id", "namespace", "metric_name", "code_name"}
+
+// customLabelNames 全局自定义标签名（已清洗、去重、排序），追加在每个命名空间指标标签集合末尾。
+// 同一指标的标签集合必须固定，因此所有账号共享同一组标签名，未配置的账号取空值。
+var (
+	customLabelsMu   sync.RWMutex
+	customLabelNames []string
+)
+
+// SanitizeLabelName 将任意字符串转换为合法的 Prometheus 标签名：
+// 转小写，非 [a-z0-9_] 字符替换为下划线，去掉前导下划线（"__" 为保留前缀），
+// 以数字开头或清洗后为空时加 label_ 前缀。
+func SanitizeLabelName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	n := strings.TrimLeft(b.String(), "_")
+	if n == "" || (n[0] >= '0' && n[0] <= '9') {
+		n = "label_" + n
+	}
+	return n
+}
+
+// CustomLabelName 返回配置键对应的最终标签名，与固定标签冲突时加 label_ 前缀
+func CustomLabelName(key string) string {
+	n := SanitizeLabelName(key)
+	for _, l := range baseLabels {
+		if n == l {
+			return "label_" + n
+		}
+	}
+	return n
+}
+
+// SetCustomLabels 设置全局自定义标签（传入配置中的原始键），返回最终标签名。
+// 标签集合变化时注销已创建的命名空间指标，下次使用时按新标签集合重建。
+func SetCustomLabels(keys []string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, k := range keys {
+		if strings.TrimSpace(k) == "" {
+			continue
+		}
+		n := CustomLabelName(k)
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	customLabelsMu.Lock()
+	changed := strings.Join(names, ",") != strings.Join(customLabelNames, ",")
+	customLabelNames = names
+	customLabelsMu.Unlock()
+
+	if changed {
+		nsGaugesMu.Lock()
+		for key, info := range nsGauges {
+			Registerer().Unregister(info.vec)
+			delete(nsGauges, key)
+		}
+		nsGaugesMu.Unlock()
+	}
+	return CustomLabels()
+}
+
+// CustomLabels 返回当前全局自定义标签名
+func CustomLabels() []string {
+	customLabelsMu.RLock()
+	defer customLabelsMu.RUnlock()
+	return append([]string(nil), customLabelNames...)
+}
+
+// LabelValues 生成命名空间指标的标签值：values 按 count（NamespaceGauge 返回的标签数量）
+// 扣除自定义标签后截断或补空，再按自定义标签顺序追加 custom 中的值（以最终标签名为键，缺失为空）。
+func LabelValues(count int, custom map[string]string, values ...string) []string {
+	names := CustomLabels()
+	n := count - len(names)
+	if n < 0 {
+		n = 0
+	}
+	out := make([]string, 0, count)
+	if len(values) > n {
+		out = append(out, values[:n]...)
+	} else {
+		out = append(out, values...)
+		for len(out) < n {
+			out = append(out, "")
+		}
+	}
+	for _, l := range names {
+		out = append(out, custom[l])
+	}
+	return out
+}
@@ -9,6 +9,9 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+// targetLabels 采集目标健康指标的标签
+var targetLabels = []string{"cloud_provider", "account_id", "region", "namespace"}
+
 // ResourceMetric 统一的资源指标，标签包含云、账号、区域、资源、ID、指标名
 var (
 	ResourceMetric = prometheus.NewGaugeVec(
@@ -47,13 +50,185 @@ var (
 		},
 		[]string{"cloud_provider", "api"},
 	)
-	CollectionDuration = prometheus.NewHistogram(
+	// RateLimitWait 云 API 调用在客户端令牌桶上的等待时间
+	RateLimitWait = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "multicloud_rate_limit_wait_seconds",
+			Help:    " - 云 API 调用在客户端限流器上的等待时间（秒）",
+			Buckets: []float64{0.001, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		},
+		[]string{"cloud_provider", "api"},
+	)
+	// ConcurrencyLimit 自适应并发控制器当前的有效并发上限
+	ConcurrencyLimit = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "multicloud_concurrency_limit",
+			Help: " - 自适应并发控制器当前的有效并发上限（scope: region/product/metric）",
+		},
+		[]string{"cloud_provider", "account_id", "scope"},
+	)
+	// BudgetUsage 当日云 API 用量（kind: calls/metrics）
+	BudgetUsage = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "multicloud_budget_usage",
+			Help: " - 当日云 API 用量（kind: calls 调用次数 / metrics CloudWatch 请求的指标数）",
+		},
+		[]string{"cloud_provider", "account_id", "kind"},
+	)
+	// BudgetExceeded 账号当日预算是否用尽（1 表示已降级为低频采集）
+	BudgetExceeded = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "multicloud_budget_exceeded",
+			Help: " - 账号当日云 API 预算是否用尽，1 表示已降低采集频率",
+		},
+		[]string{"cloud_provider", "account_id"},
+	)
+	// BudgetProjectedMonthlyCost 按当日用量估算的月度云 API 成本
+	BudgetProjectedMonthlyCost = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "multicloud_budget_projected_monthly_cost",
+			Help: " - 按当日用量估算的月度云 API 成本（单位同 budgets.prices）",
+		},
+	)
+	// CircuitBreakerState 账号/区域熔断器状态（0 closed，1 open，2 half_open），账号级熔断器的 region 为空
+	CircuitBreakerState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "multicloud_circuit_breaker_state",
+			Help: " - 账号/区域熔断器状态：0 正常，1 熔断中，2 半开探测（账号级熔断 region 为空）",
+		},
+		[]string{"cloud_provider", "account_id", "region"},
+	)
+	// ClusterMembers 当前生效的成员视图中的实例数
+	ClusterMembers = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "multicloud_cluster_members",
+			Help: " - 当前生效的集群成员视图中的实例数",
+		},
+	)
+	// ClusterGeneration 当前生效的成员视图代数，每次视图变化递增
+	ClusterGeneration = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "multicloud_cluster_generation",
+			Help: " - 当前生效的集群成员视图代数",
+		},
+	)
+	// ClusterMembershipChanges 成员视图变化次数（change：join 成员加入，leave 成员离开）
+	ClusterMembershipChanges = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "multicloud_cluster_membership_changes_total",
+			Help: " - 集群成员视图中成员加入/离开的次数",
+		},
+		[]string{"change"},
+	)
+	// ClusterPeerUp 本实例观察到的对端心跳状态（1 存活，0 超时）
+	ClusterPeerUp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "multicloud_cluster_peer_up",
+			Help: " - 本实例观察到的集群对端心跳是否存活（1 存活，0 超时）",
+		},
+		[]string{"member"},
+	)
+	// AggregatorPeerUp aggregator 模式下最近一次抓取对端 /metrics 是否成功（1 成功，0 失败）
+	AggregatorPeerUp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "multicloud_aggregator_peer_up",
+			Help: " - 聚合模式下最近一次抓取分片实例 /metrics 是否成功（1 成功，0 失败）",
+		},
+		[]string{"peer"},
+	)
+	// AggregatorPeerScrapeDuration aggregator 模式下抓取对端 /metrics 的耗时
+	AggregatorPeerScrapeDuration = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "multicloud_aggregator_peer_scrape_duration_seconds",
+			Help: " - 聚合模式下最近一次抓取分片实例 /metrics 的耗时（秒）",
+		},
+		[]string{"peer"},
+	)
+	// AggregatorPeerSamples aggregator 模式下对端本次贡献的样本数
+	AggregatorPeerSamples = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "multicloud_aggregator_peer_samples",
+			Help: " - 聚合模式下分片实例最近一次贡献的样本数",
+		},
+		[]string{"peer"},
+	)
+	// AggregatorConflicts 合并指标族时的冲突（kind：type 类型不一致被丢弃，help 帮助文本不一致，labels 标签集合不一致，duplicate 重复样本被丢弃）
+	AggregatorConflicts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "multicloud_aggregator_conflicts_total",
+			Help: " - 聚合模式下合并指标族时检测到的冲突次数",
+		},
+		[]string{"kind"},
+	)
+	// AggregatorPartialResults 未能抓取全部对端、只返回部分结果的聚合次数
+	AggregatorPartialResults = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "multicloud_aggregator_partial_results_total",
+			Help: " - 聚合模式下只返回部分分片结果的次数",
+		},
+	)
+	CollectionCycleDuration = prometheus.NewHistogram(
 		prometheus.HistogramOpts{
-			Name:    "multicloud_collection_duration_seconds",
+			Name:    "multicloud_collection_cycle_duration_seconds",
 			Help:    " - 采集周期总耗时（秒）",
 			Buckets: prometheus.DefBuckets,
 		},
 	)
+	// CollectionCyclesSkipped 未执行的采集周期（trigger：schedule/manual；reason：in_progress 已有采集进行中被拒绝，coalesced 合并到进行中或排队的采集）
+	CollectionCyclesSkipped = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "multicloud_collection_cycles_skipped_total",
+			Help: " - 因已有采集进行中而跳过或合并的采集周期数",
+		},
+		[]string{"trigger", "reason"},
+	)
+	// CollectionCyclesOverrun 超过本轮截止时间被取消的采集周期
+	CollectionCyclesOverrun = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "multicloud_collection_cycles_overrun_total",
+			Help: " - 超过截止时间被取消的采集周期数",
+		},
+		[]string{"trigger"},
+	)
+	// CollectionInProgress 当前是否有采集周期进行中（1 是，0 否）
+	CollectionInProgress = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "multicloud_collection_in_progress",
+			Help: " - 当前是否有采集周期进行中",
+		},
+	)
+	// CollectionUp 采集目标（云/账号/区域/命名空间）最近一次采集是否成功（1 成功，0 失败）
+	CollectionUp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "multicloud_collection_up",
+			Help: " - 采集目标最近一次采集是否成功（1 成功，0 失败）",
+		},
+		targetLabels,
+	)
+	// CollectionLastSuccess 采集目标最近一次成功采集的时间戳
+	CollectionLastSuccess = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "multicloud_collection_last_success_timestamp_seconds",
+			Help: " - 采集目标最近一次成功采集的 Unix 时间戳（秒）",
+		},
+		targetLabels,
+	)
+	// CollectionDuration 采集目标最近一次采集耗时
+	CollectionDuration = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "multicloud_collection_duration_seconds",
+			Help: " - 采集目标最近一次采集耗时（秒）",
+		},
+		targetLabels,
+	)
+	// CollectionErrorsTotal 采集目标按错误类别统计的错误次数
+	CollectionErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "multicloud_collection_errors_total",
+			Help: " - 采集目标错误次数（按错误类别）",
+		},
+		append(append([]string{}, targetLabels...), "error_class"),
+	)
 	CacheSizeBytes = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "multicloud_cache_size_bytes",
@@ -68,6 +243,22 @@ var (
 		},
 		[]string{"cache_type"},
 	)
+	// DiscoveryRefreshErrorsTotal 产品发现刷新失败次数（超时、panic 或指标元数据调用全部失败）
+	DiscoveryRefreshErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "multicloud_discovery_refresh_errors_total",
+			Help: " - 产品发现刷新失败次数（失败时沿用上一次成功的产品列表）",
+		},
+		[]string{"cloud_provider"},
+	)
+	// DiscoveryLastSuccess 产品发现最近一次成功刷新的时间戳
+	DiscoveryLastSuccess = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "multicloud_discovery_last_success_timestamp_seconds",
+			Help: " - 产品发现最近一次成功刷新的 Unix 时间戳（秒）",
+		},
+		[]string{"cloud_provider"},
+	)
 	// RegionDiscovery 区域发现状态统计
 	RegionDiscoveryStatus = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -76,6 +267,14 @@ var (
 		},
 		[]string{"cloud_provider", "status"},
 	)
+	// RegionInfo 区域管理器的逐条状态，值恒为 1，status/empty_count 说明区域为何被跳过
+	RegionInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "multicloud_region_info",
+			Help: " - 区域状态（值恒为 1，status 为 active/empty/unknown，empty_count 为连续为空次数）",
+		},
+		[]string{"cloud_provider", "account_id", "region", "namespace", "status", "empty_count"},
+	)
 	// RegionDiscoveryDuration 区域发现耗时
 	RegionDiscoveryDuration = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
@@ -93,6 +292,14 @@ var (
 		},
 		[]string{"cloud_provider"},
 	)
+	// ScheduleSkippedTotal 因产品采集周期未到期而跳过的次数
+	ScheduleSkippedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "multicloud_schedule_skipped_total",
+			Help: " - 产品采集周期未到期而跳过的次数",
+		},
+		[]string{"cloud_provider", "namespace"},
+	)
 )
 
 var (
@@ -113,9 +320,18 @@ var (
 	scaleByNamespace  = make(map[string]map[string]float64)
 )
 
+// targetSamples 单个采集目标（provider|account|region|namespace）本轮导出的样本数
+type targetSamples struct {
+	namespace string
+	n         int
+}
+
+// sampleCounts 本轮各目标的样本数；lastSampleCounts 为上一轮的快照，
+// 本轮被产品级调度跳过的目标沿用上一轮的样本数（KeepSampleCount）
 var (
-	sampleCountsMu sync.Mutex
-	sampleCounts   = make(map[string]int)
+	sampleCountsMu   sync.Mutex
+	sampleCounts     = make(map[string]targetSamples)
+	lastSampleCounts = make(map[string]targetSamples)
 )
 
 func RegisterNamespacePrefix(namespace, prefix string) {
@@ -188,6 +404,31 @@ func sanitizeName(name string) string {
 	return n
 }
 
+// registerer 指标注册器：默认为 prometheus.DefaultRegisterer，设置外部标签后为附加这些标签的包装注册器
+var (
+	registererMu sync.RWMutex
+	registerer   prometheus.Registerer = prometheus.DefaultRegisterer
+)
+
+// SetExternalLabels 为之后注册的全部指标附加固定标签（如副本采集时的 replica），须在注册指标之前调用；
+// labels 为空时恢复默认注册器
+func SetExternalLabels(labels prometheus.Labels) {
+	registererMu.Lock()
+	defer registererMu.Unlock()
+	if len(labels) == 0 {
+		registerer = prometheus.DefaultRegisterer
+		return
+	}
+	registerer = prometheus.WrapRegistererWith(labels, prometheus.DefaultRegisterer)
+}
+
+// Registerer 返回指标注册器，导出的指标应通过它注册以带上外部标签
+func Registerer() prometheus.Registerer {
+	registererMu.RLock()
+	defer registererMu.RUnlock()
+	return registerer
+}
+
 func NamespaceGauge(namespace, metric string, extraLabels ...string) (*prometheus.GaugeVec, int) {
 	alias := aliasPrefixForNamespace(namespace)
 	metricAlias := aliasMetricForNamespace(namespace, metric)
@@ -216,12 +457,17 @@ func NamespaceGauge(namespace, metric string, extraLabels ...string) (*prometheu
 	help := metricHelpForNamespace(namespace, useMetric)
 	// 统一命名空间指标的标签集合：
 	// cloud_provider, account_id, region, resource_type, resource_id, namespace, metric_name, code_name
-	// 加上动态维度标签
-	labels := []string{"cloud_provider", "account_id", "region", "resource_type", "resource_id", "namespace", "metric_name", "code_name"}
+	// 加上动态维度标签，
+	// 最后追加全局自定义标签（datatag / 账号 labels / tag_labels），动态维度与之冲突时加数字后缀
+	labels := append([]string(nil), baseLabels...)
+	custom := CustomLabels()
 	seen := make(map[string]bool)
 	for _, l := range labels {
 		seen[l] = true
 	}
+	for _, l := range custom {
+		seen[l] = true
+	}
 	for _, l := range extraLabels {
 		sanitized := sanitizeName(l)
 		base := sanitized
@@ -233,6 +479,7 @@ func NamespaceGauge(namespace, metric string, extraLabels ...string) (*prometheu
 		seen[sanitized] = true
 		labels = append(labels, sanitized)
 	}
+	labels = append(labels, custom...)
 
 	g := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -243,7 +490,7 @@ func NamespaceGauge(namespace, metric string, extraLabels ...string) (*prometheu
 	)
 
 	// 注册到 Prometheus（不持锁，避免死锁）
-	registerErr := prometheus.Register(g)
+	registerErr := Registerer().Register(g)
 	if registerErr != nil {
 		if are, ok := registerErr.(prometheus.AlreadyRegisteredError); ok {
 			// 已注册，使用已存在的 collector
@@ -278,27 +525,43 @@ func NamespaceGauge(namespace, metric string, extraLabels ...string) (*prometheu
 	return g, len(labels)
 }
 
-func IncSampleCount(namespace string, n int) {
+// IncSampleCount 累加目标本轮导出的样本数，target 通常为 provider|account|region|namespace
+func IncSampleCount(target, namespace string, n int) {
 	if n <= 0 {
 		return
 	}
 	sampleCountsMu.Lock()
-	sampleCounts[namespace] += n
+	c := sampleCounts[target]
+	c.namespace = namespace
+	c.n += n
+	sampleCounts[target] = c
 	sampleCountsMu.Unlock()
 }
 
+// KeepSampleCount 本轮未采集（调度未到期）的目标沿用上一轮的样本数，避免在状态中显示为无样本
+func KeepSampleCount(target string) {
+	sampleCountsMu.Lock()
+	defer sampleCountsMu.Unlock()
+	if c, ok := lastSampleCounts[target]; ok {
+		sampleCounts[target] = c
+	}
+}
+
+// ResetSampleCounts 开始新一轮计数，上一轮计数保留供 KeepSampleCount 沿用
 func ResetSampleCounts() {
 	sampleCountsMu.Lock()
-	sampleCounts = make(map[string]int)
+	lastSampleCounts = sampleCounts
+	sampleCounts = make(map[string]targetSamples)
 	sampleCountsMu.Unlock()
 }
 
+// GetSampleCounts 返回按命名空间汇总的样本数
 func GetSampleCounts() map[string]int {
 	sampleCountsMu.Lock()
 	defer sampleCountsMu.Unlock()
-	out := make(map[string]int, len(sampleCounts))
-	for k, v := range sampleCounts {
-		out[k] = v
+	out := make(map[string]int)
+	for _, c := range sampleCounts {
+		out[c.namespace] += c.n
 	}
 	return out
 }
@@ -2,6 +2,8 @@ package metrics
 
 import (
 	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func TestSanitizeName(t *testing.T) {
@@ -96,3 +98,79 @@ func TestReset(t *testing.T) {
 	Reset()
 	// Just ensure no panic and coverage hit
 }
+
+func TestSanitizeLabelName(t *testing.T) {
+	cases := map[string]string{
+		"Cost-Center": "cost_center",
+		"team.name":   "team_name",
+		"__env":       "env",
+		"1st":         "label_1st",
+		"环境":          "label_",
+		"code_name":   "code_name",
+	}
+	for in, want := range cases {
+		if got := SanitizeLabelName(in); got != want {
+			t.Fatalf("SanitizeLabelName(%q)=%q want %q", in, got, want)
+		}
+	}
+	if got := CustomLabelName("Region"); got != "label_region" {
+		t.Fatalf("collision with base label should be prefixed, got %q", got)
+	}
+}
+
+func TestCustomLabels_NamespaceGauge(t *testing.T) {
+	defer SetCustomLabels(nil)
+	names := SetCustomLabels([]string{"team", "Cost-Center", "cost_center", "region", ""})
+	want := []string{"cost_center", "label_region", "team"}
+	if len(names) != len(want) {
+		t.Fatalf("unexpected names: %v", names)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("unexpected names: %v", names)
+		}
+	}
+
+	// 动态维度与自定义标签冲突时，动态维度加数字后缀，自定义标签名保持一致
+	g, c := NamespaceGauge("test_ns_custom", "met", "team")
+	if c != 8+1+3 {
+		t.Fatalf("expected 12 labels, got %d", c)
+	}
+	values := LabelValues(c, map[string]string{"team": "infra", "label_region": "east"}, "aws", "acc", "r", "t", "id", "ns", "met", "cn", "dim")
+	if len(values) != c {
+		t.Fatalf("expected %d values, got %d", c, len(values))
+	}
+	if values[8] != "dim" || values[9] != "" || values[10] != "east" || values[11] != "infra" {
+		t.Fatalf("unexpected label values: %v", values)
+	}
+	g.WithLabelValues(values...).Set(1)
+
+	// 截断多余的维度值，补齐缺失值
+	if v := LabelValues(c, nil, "a"); len(v) != c || v[0] != "a" || v[11] != "" {
+		t.Fatalf("unexpected padded values: %v", v)
+	}
+}
+
+func TestSetExternalLabels_NamespaceGauge(t *testing.T) {
+	SetExternalLabels(map[string]string{"replica": "pod-a"})
+	defer SetExternalLabels(nil)
+	g, count := NamespaceGauge("test_ns_replica", "value")
+	g.WithLabelValues(make([]string, count)...).Set(1)
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	for _, mf := range families {
+		if mf.GetName() != "test_ns_replica_value" {
+			continue
+		}
+		for _, lp := range mf.GetMetric()[0].GetLabel() {
+			if lp.GetName() == "replica" && lp.GetValue() == "pod-a" {
+				return
+			}
+		}
+		t.Fatalf("replica label missing: %v", mf.GetMetric()[0].GetLabel())
+	}
+	t.Fatal("namespace gauge not registered")
+}
@@ -2,6 +2,7 @@
 package aliyun
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"sort"
@@ -9,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"multicloud-exporter/internal/cache"
 	"multicloud-exporter/internal/config"
 	"multicloud-exporter/internal/discovery"
 	"multicloud-exporter/internal/logger"
@@ -32,31 +34,25 @@ import (
 type Collector struct {
 	cfg           *config.Config
 	disc          *discovery.Manager
-	metaCache     map[string]metricMeta
-	metaMu        sync.RWMutex
-	cacheMu       sync.RWMutex
-	resCache      map[string]resCacheEntry
-	uidCache      map[string]string
-	uidMu         sync.RWMutex
-	ossCache      map[string]ossCacheEntry
-	ossMu         sync.Mutex
-	tagCache      map[string]map[string]string // 标签缓存：key -> resourceID -> codeName
-	tagMu         sync.RWMutex                 // 标签缓存锁
+	metaCache     cache.Cache                             // 指标元数据：account|namespace|metric -> metricMeta
+	resCache      cache.Cache                             // 资源发现：account|region|namespace|rtype -> resCacheEntry
+	uidCache      cache.Cache                             // 账号 UID：AccessKeyID -> UID
+	ossCache      cache.Cache                             // 账号级 OSS Bucket 列表：AccountID -> []ossBucketInfo
+	tagCache      cache.Cache                             // 标签缓存：account:region:rtype -> resourceID -> codeName
+	tagMu         sync.RWMutex                            // resTags/resNames 锁
+	resTags       map[string]map[string]map[string]string // 完整标签缓存：key -> resourceID -> tagKey -> tagValue
+	resNames      map[string]map[string]string            // 资源名称缓存：key -> resourceID -> name（用于 code_name 解析链）
 	clientFactory ClientFactory
 	sf            singleflight.Group
 	regionManager common.RegionManager
+	scheduler     *common.ProductScheduler // 产品级采集调度
 }
 
-// resCacheEntry 缓存资源ID及元数据
+// resCacheEntry 缓存资源ID及子资源元数据（如 SLB 监听器、ALB/NLB 监听端口），
+// UpdatedAt 用于判断发现结果是否超过 discovery_ttl；过期条目仍保留供资源清单使用
 type resCacheEntry struct {
 	IDs       []string
-	Meta      map[string]interface{}
-	UpdatedAt time.Time
-}
-
-// ossCacheEntry 缓存账号级 OSS Bucket 列表
-type ossCacheEntry struct {
-	Buckets   []ossBucketInfo
+	Meta      map[string][]map[string]string
 	UpdatedAt time.Time
 }
 
@@ -70,57 +66,32 @@ func NewCollector(cfg *config.Config, mgr *discovery.Manager) *Collector {
 	c := &Collector{
 		cfg:           cfg,
 		disc:          mgr,
-		metaCache:     make(map[string]metricMeta),
-		resCache:      make(map[string]resCacheEntry),
-		uidCache:      make(map[string]string),
-		ossCache:      make(map[string]ossCacheEntry),
-		tagCache:      make(map[string]map[string]string), // 初始化标签缓存
+		metaCache:     cache.Open("aliyun_metric_meta"),
+		resCache:      cache.Open("aliyun_resources"),
+		uidCache:      cache.Open("aliyun_account_uid"),
+		ossCache:      cache.Open("aliyun_oss_buckets"),
+		tagCache:      cache.Open("aliyun_tags"),
+		resTags:       make(map[string]map[string]map[string]string),
+		resNames:      make(map[string]map[string]string),
 		clientFactory: &defaultClientFactory{},
+		scheduler:     common.NewProductScheduler(),
 	}
 
-	// 初始化区域管理器
-	if cfg != nil && cfg.GetServer() != nil && cfg.GetServer().RegionDiscovery != nil {
-		c.regionManager = common.NewRegionManager(common.RegionDiscoveryConfig{
-			Enabled:           cfg.GetServer().RegionDiscovery.Enabled,
-			DiscoveryInterval: parseDuration(cfg.GetServer().RegionDiscovery.DiscoveryInterval),
-			EmptyThreshold:    cfg.GetServer().RegionDiscovery.EmptyThreshold,
-			DataDir:           cfg.GetServer().RegionDiscovery.DataDir,
-			PersistFile:       cfg.GetServer().RegionDiscovery.PersistFile,
-		})
-
-		// 加载持久化的区域状态
-		if err := c.regionManager.Load(); err != nil {
-			ctxLog := logger.NewContextLogger("Aliyun", "resource_type", "RegionManager")
-			ctxLog.Warnf("加载区域状态失败: %v", err)
-		}
-
-		// 启动定期重新发现调度器
-		c.regionManager.StartRediscoveryScheduler()
+	// 区域管理器：各云共用，首次创建时加载持久化状态并启动重新发现调度器
+	if cfg != nil && cfg.GetServer() != nil {
+		c.regionManager = common.SharedRegionManager(cfg.GetServer().RegionDiscovery)
 	}
 
 	return c
 }
 
-// parseDuration 解析时长字符串为 time.Duration
-func parseDuration(s string) time.Duration {
-	if s == "" {
-		return 0
-	}
-	if d, err := time.ParseDuration(s); err == nil {
-		return d
-	}
-	return 0
-}
-
 // getAccountUID 获取阿里云账号的数字 ID (UID)
-func (a *Collector) getAccountUID(account config.CloudAccount, region string) string {
+func (a *Collector) getAccountUID(ctx context.Context, account config.CloudAccount, region string) string {
 	ctxLog := logger.NewContextLogger("Aliyun", "account_id", account.AccessKeyID)
 
 	// 1. 尝试从缓存获取
-	a.uidMu.RLock()
-	uid, ok := a.uidCache[account.AccessKeyID]
-	a.uidMu.RUnlock()
-	if ok {
+	var uid string
+	if a.uidCache.Get(account.AccessKeyID, &uid) {
 		return uid
 	}
 
@@ -137,22 +108,13 @@ func (a *Collector) getAccountUID(account config.CloudAccount, region string) st
 			return nil, err
 		}
 		req := sts.CreateGetCallerIdentityRequest()
-		// 增加重试逻辑 (3次)
-		var resp *sts.GetCallerIdentityResponse
-		var callErr error
-		for attempt := 0; attempt < 3; attempt++ {
-			resp, callErr = client.GetCallerIdentity(req)
-			if callErr == nil {
-				return resp, nil
-			}
-			// 指数退避重试
-			sleep := time.Duration(200*(1<<attempt)) * time.Millisecond
-			if sleep > 5*time.Second {
-				sleep = 5 * time.Second
-			}
-			time.Sleep(sleep)
-		}
-		return nil, callErr
+		// 重试 3 次（认证失败除外）
+		return common.Call(ctx, common.CallOptions{
+			Provider: "aliyun", AccountID: account.AccountID, Region: r, API: "GetCallerIdentity",
+			Attempts: 3, Retryable: common.RetryUnlessFatal,
+		}, func() (*sts.GetCallerIdentityResponse, error) {
+			return client.GetCallerIdentity(req)
+		})
 	}
 
 	// 首次尝试
@@ -172,9 +134,7 @@ func (a *Collector) getAccountUID(account config.CloudAccount, region string) st
 
 	uid = resp.AccountId
 	if uid != "" {
-		a.uidMu.Lock()
-		a.uidCache[account.AccessKeyID] = uid
-		a.uidMu.Unlock()
+		_ = a.uidCache.Set(account.AccessKeyID, uid, 0)
 		return uid
 	}
 
@@ -182,7 +142,7 @@ func (a *Collector) getAccountUID(account config.CloudAccount, region string) st
 }
 
 // getOrFetchTags 获取或缓存标签（资源枚举后调用一次，所有指标复用）
-func (a *Collector) getOrFetchTags(account config.CloudAccount, region string, rtype string, ids []string) map[string]string {
+func (a *Collector) getOrFetchTags(ctx context.Context, account config.CloudAccount, region string, rtype string, ids []string) map[string]string {
 	if len(ids) == 0 {
 		return map[string]string{}
 	}
@@ -191,13 +151,11 @@ func (a *Collector) getOrFetchTags(account config.CloudAccount, region string, r
 	cacheKey := account.AccountID + ":" + region + ":" + rtype
 
 	// 尝试从缓存读取
-	a.tagMu.RLock()
-	if tags, ok := a.tagCache[cacheKey]; ok {
-		a.tagMu.RUnlock()
-		logger.NewContextLogger("Aliyun", "account_id", account.AccountID, "region", region, "rtype", rtype).Debugf("标签缓存命中 数量=%d", len(tags))
-		return tags
+	var cached map[string]string
+	if a.tagCache.Get(cacheKey, &cached) {
+		logger.NewContextLogger("Aliyun", "account_id", account.AccountID, "region", region, "rtype", rtype).Debugf("标签缓存命中 数量=%d", len(cached))
+		return cached
 	}
-	a.tagMu.RUnlock()
 
 	// 缓存未命中，获取标签
 	logger.NewContextLogger("Aliyun", "account_id", account.AccountID, "region", region, "rtype", rtype).Debugf("标签缓存未命中，开始获取 数量=%d", len(ids))
@@ -205,35 +163,213 @@ func (a *Collector) getOrFetchTags(account config.CloudAccount, region string, r
 	var tags map[string]string
 	switch rtype {
 	case "cbwp", "bwp":
-		tags = a.fetchCBWPTags(account, region, ids)
+		tags = a.fetchCBWPTags(ctx, account, region, ids)
 	case "lb", "slb", "clb":
-		tags = a.fetchSLBTags(account, region, "", "", ids)
+		tags = a.fetchSLBTags(ctx, account, region, "", "", ids)
 	case "alb":
-		tags = a.fetchALBTags(account, region, ids)
+		tags = a.fetchALBTags(ctx, account, region, ids)
 	case "nlb":
-		tags = a.fetchNLBTags(account, region, ids)
+		tags = a.fetchNLBTags(ctx, account, region, ids)
 	case "oss":
-		tags = a.fetchOSSBucketTags(account, region, ids)
+		tags = a.fetchOSSBucketTags(ctx, account, region, ids)
 	default:
 		tags = map[string]string{}
 	}
 
 	// 存入缓存
 	if len(tags) > 0 {
-		a.tagMu.Lock()
-		a.tagCache[cacheKey] = tags
-		a.tagMu.Unlock()
+		_ = a.tagCache.Set(cacheKey, tags, a.discoveryTTL())
 		logger.NewContextLogger("Aliyun", "account_id", account.AccountID, "region", region, "rtype", rtype).Debugf("标签已缓存 数量=%d", len(tags))
 	}
 
 	return tags
 }
 
-// Collect 根据账号配置遍历区域与资源类型并采集
-func (a *Collector) Collect(account config.CloudAccount) {
+// tagResourceType 将标签相关的资源类型别名归一化
+func tagResourceType(rtype string) string {
+	switch rtype {
+	case "cbwp", "bwp":
+		return "cbwp"
+	case "lb", "slb", "clb":
+		return "clb"
+	default:
+		return rtype
+	}
+}
+
+// fetchedTags 单次标签拉取过程中收集的完整标签（并发安全）
+type fetchedTags struct {
+	mu   sync.Mutex
+	byID map[string]map[string]string
+}
+
+func newFetchedTags() *fetchedTags {
+	return &fetchedTags{byID: make(map[string]map[string]string)}
+}
+
+func (f *fetchedTags) add(id, key, value string) {
+	if id == "" || key == "" {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.byID[id] == nil {
+		f.byID[id] = make(map[string]string)
+	}
+	f.byID[id][key] = value
+}
+
+func (f *fetchedTags) addAll(tagsByID map[string]map[string]string) {
+	for id, tags := range tagsByID {
+		for k, v := range tags {
+			f.add(id, k, v)
+		}
+	}
+}
+
+// applyFetchedTags 以本次拉取结果替换 ids 的完整标签（未返回标签的资源视为无标签），
+// 并按 code_name 解析链将 code_name 写入 out
+func (a *Collector) applyFetchedTags(account config.CloudAccount, region, rtype string, ids []string, fetched *fetchedTags, out map[string]string) map[string]string {
+	cacheKey := account.AccountID + ":" + region + ":" + tagResourceType(rtype)
+	fetched.mu.Lock()
+	a.tagMu.Lock()
+	if a.resTags == nil {
+		a.resTags = make(map[string]map[string]map[string]string)
+	}
+	byID, ok := a.resTags[cacheKey]
+	if !ok {
+		byID = make(map[string]map[string]string, len(ids))
+		a.resTags[cacheKey] = byID
+	}
+	for _, id := range ids {
+		if tags, ok := fetched.byID[id]; ok {
+			byID[id] = tags
+		} else {
+			delete(byID, id)
+		}
+	}
+	a.tagMu.Unlock()
+	fetched.mu.Unlock()
+	return a.mergeCodeNames(account, region, rtype, ids, out)
+}
+
+// recordResourceNames 记录枚举时获得的资源名称，供 code_name 解析链的 name 步骤使用
+func (a *Collector) recordResourceNames(account config.CloudAccount, region, rtype string, names map[string]string) {
+	if len(names) == 0 {
+		return
+	}
+	cacheKey := account.AccountID + ":" + region + ":" + tagResourceType(rtype)
+	a.tagMu.Lock()
+	defer a.tagMu.Unlock()
+	if a.resNames == nil {
+		a.resNames = make(map[string]map[string]string)
+	}
+	byID, ok := a.resNames[cacheKey]
+	if !ok {
+		byID = make(map[string]string, len(names))
+		a.resNames[cacheKey] = byID
+	}
+	for id, name := range names {
+		if name != "" {
+			byID[id] = name
+		}
+	}
+}
+
+// mergeCodeNames 按 code_name 解析链为 ids 生成 code_name 并写入 out（仅写入非空值）
+func (a *Collector) mergeCodeNames(account config.CloudAccount, region, rtype string, ids []string, out map[string]string) map[string]string {
+	cacheKey := account.AccountID + ":" + region + ":" + tagResourceType(rtype)
+	a.tagMu.RLock()
+	defer a.tagMu.RUnlock()
+	tagsByID := a.resTags[cacheKey]
+	names := a.resNames[cacheKey]
+	for _, id := range ids {
+		if v := common.ResolveCodeName(a.cfg, common.DefaultCodeNameChain, tagsByID[id], names[id], id); v != "" {
+			out[id] = v
+		}
+	}
+	return out
+}
+
+// getResourceTags 获取资源完整标签（复用 getOrFetchTags 的拉取与缓存）
+func (a *Collector) getResourceTags(ctx context.Context, account config.CloudAccount, region, rtype string, ids []string) map[string]map[string]string {
+	a.getOrFetchTags(ctx, account, region, rtype, ids)
+	return a.recordedTags(account, region, rtype, ids)
+}
+
+// recordedTags 返回已记录的资源完整标签（不触发拉取）
+func (a *Collector) recordedTags(account config.CloudAccount, region, rtype string, ids []string) map[string]map[string]string {
+	cacheKey := account.AccountID + ":" + region + ":" + tagResourceType(rtype)
+	a.tagMu.RLock()
+	defer a.tagMu.RUnlock()
+	out := make(map[string]map[string]string, len(ids))
+	byID := a.resTags[cacheKey]
+	for _, id := range ids {
+		out[id] = map[string]string{}
+		for k, v := range byID[id] {
+			out[id][k] = v
+		}
+	}
+	return out
+}
+
+// customLabels 返回资源的自定义标签值（静态标签 + tag_labels 命中的资源标签）
+func (a *Collector) customLabels(account config.CloudAccount, region, rtype, rid string) map[string]string {
+	var tags map[string]string
+	if common.NeedsTagLabels(a.cfg) {
+		cacheKey := account.AccountID + ":" + region + ":" + tagResourceType(rtype)
+		a.tagMu.RLock()
+		if src := a.resTags[cacheKey][rid]; len(src) > 0 {
+			tags = make(map[string]string, len(src))
+			for k, v := range src {
+				tags[k] = v
+			}
+		}
+		a.tagMu.RUnlock()
+	}
+	return common.CustomLabels(a.cfg, account, tags)
+}
+
+// filterResourceIDs 按账号过滤规则过滤枚举结果，rtype 为内部资源类型（cbwp/clb/oss/alb/nlb/gwlb）
+func (a *Collector) filterResourceIDs(ctx context.Context, account config.CloudAccount, region, rtype string, ids []string, names map[string]string) []string {
+	var aliases []string
+	switch rtype {
+	case "cbwp":
+		aliases = []string{"bwp", "cbwp"}
+	case "oss":
+		aliases = []string{"s3", "oss"}
+	case "clb":
+		aliases = []string{"clb", "slb"}
+	default:
+		aliases = []string{rtype}
+	}
+	a.recordResourceNames(account, region, rtype, names)
+	f := common.NewResourceFilter(a.cfg, account, aliases...)
+	if f == nil {
+		return ids
+	}
+	var tagsOf func([]string) map[string]map[string]string
+	if rtype == "gwlb" {
+		common.WarnTagsUnsupported(f, "aliyun", account.AccountID, rtype)
+	} else {
+		tagsOf = func(missing []string) map[string]map[string]string {
+			return a.getResourceTags(ctx, account, region, rtype, missing)
+		}
+	}
+	out := common.FilterIDs(f, ids, names, tagsOf)
+	if len(out) != len(ids) {
+		logger.NewContextLogger("Aliyun", "account_id", account.AccountID, "region", region, "rtype", rtype).
+			Debugf("资源过滤完成 枚举=%d 保留=%d", len(ids), len(out))
+	}
+	return out
+}
+
+// Collect 根据账号配置遍历区域与资源类型并采集，ctx 取消后不再启动新的区域与请求
+func (a *Collector) Collect(ctx context.Context, account config.CloudAccount) common.CollectResult {
+	ctx, rec := common.BeginCollect(ctx, "aliyun", account.AccountID)
 	regions := account.Regions
 	if len(regions) == 0 || (len(regions) == 1 && regions[0] == "*") {
-		regions = a.getAllRegions(account)
+		regions = a.getAllRegions(ctx, account)
 	}
 
 	ctxLog := logger.NewContextLogger("Aliyun", "account_id", account.AccountID)
@@ -249,25 +385,34 @@ func (a *Collector) Collect(account config.CloudAccount) {
 	}
 	// 注意：分片逻辑已下沉到产品级（collectCMSMetrics 内部），此处不做区域级分片
 	// 这样可以避免双重分片导致的任务丢失问题
-	sem := make(chan struct{}, limit)
+	// 启用自适应并发时，有效并发随限流反馈在 [min, limit] 内调整
+	sem := common.ConcurrencyFor("aliyun", account.AccountID, common.ScopeRegion, limit).NewSemaphore()
 	var wg sync.WaitGroup
 	for _, region := range regions {
+		// 账号或区域熔断中时跳过（账号可能在本轮采集中途熔断）
+		if !common.AllowRegion("aliyun", account.AccountID, region) {
+			ctxLog.With("region", region).Debugf("区域跳过（熔断中）")
+			continue
+		}
+		if sem.Acquire(ctx) != nil {
+			break
+		}
 		wg.Add(1)
-		sem <- struct{}{}
 		go func(r string) {
 			defer wg.Done()
-			defer func() { <-sem }()
+			defer sem.Release()
 			regionLog := ctxLog.With("region", r)
 			regionLog.Debugf("开始区域采集")
-			a.collectCMSMetrics(account, r)
+			a.collectCMSMetrics(ctx, account, r)
 			regionLog.Debugf("完成区域采集")
 		}(region)
 	}
 	wg.Wait()
+	return rec.Finish(ctx)
 }
 
 // getAllRegions 通过 DescribeRegions 自动发现全部区域，并使用区域管理器进行智能过滤
-func (a *Collector) getAllRegions(account config.CloudAccount) []string {
+func (a *Collector) getAllRegions(ctx context.Context, account config.CloudAccount) []string {
 	ctxLog := logger.NewContextLogger("Aliyun", "account_id", account.AccountID)
 
 	client, err := a.clientFactory.NewECSClient("cn-hangzhou", account.AccessKeyID, account.AccessKeySecret)
@@ -277,18 +422,18 @@ func (a *Collector) getAllRegions(account config.CloudAccount) []string {
 	}
 
 	request := ecs.CreateDescribeRegionsRequest()
-	start := time.Now()
-	response, err := client.DescribeRegions(request)
+	response, err := common.Call(ctx, common.CallOptions{
+		Provider: "aliyun", AccountID: account.AccountID, Region: "cn-hangzhou", API: "DescribeRegions",
+	}, func() (*ecs.DescribeRegionsResponse, error) {
+		return client.DescribeRegions(request)
+	})
 	if err != nil {
-		msg := err.Error()
-		status := "error"
-		if strings.Contains(msg, "InvalidAccessKeyId") || strings.Contains(msg, "Forbidden") || strings.Contains(msg, "SignatureDoesNotMatch") {
-			status = "auth_error"
-		} else if strings.Contains(msg, "timeout") || strings.Contains(msg, "unreachable") || strings.Contains(msg, "Temporary network") {
-			status = "network_error"
+		if ctx.Err() != nil {
+			return []string{"cn-hangzhou"}
 		}
+		status := common.ClassifyAliyunError(err)
 		ctxLog.Errorf("描述区域错误，状态=%s 错误=%v", status, err)
-		metrics.RequestTotal.WithLabelValues("aliyun", "DescribeRegions", status).Inc()
+		common.RecordAccountError("aliyun", account.AccountID, status)
 		def := os.Getenv("DEFAULT_REGIONS")
 		if def != "" {
 			parts := strings.Split(def, ",")
@@ -305,8 +450,6 @@ func (a *Collector) getAllRegions(account config.CloudAccount) []string {
 		}
 		return []string{"cn-hangzhou"}
 	}
-	metrics.RequestTotal.WithLabelValues("aliyun", "DescribeRegions", "success").Inc()
-	metrics.RequestDuration.WithLabelValues("aliyun", "DescribeRegions").Observe(time.Since(start).Seconds())
 
 	var regions []string
 	for _, region := range response.Regions.Region {
@@ -314,9 +457,13 @@ func (a *Collector) getAllRegions(account config.CloudAccount) []string {
 	}
 	ctxLog.Debugf("DescribeRegions 成功，总区域数=%d", len(regions))
 
-	// 使用区域管理器进行智能过滤
+	// 使用区域管理器进行智能过滤：保留任一已配置命名空间可能有资源的区域
 	if a.regionManager != nil {
-		activeRegions := a.regionManager.GetActiveRegions(account.AccountID, regions)
+		var namespaces []string
+		if a.disc != nil {
+			namespaces = common.ProductNamespaces(a.disc.Get()["aliyun"])
+		}
+		activeRegions := a.regionManager.GetActiveRegions("aliyun", account.AccountID, regions, namespaces)
 		ctxLog.Infof("智能区域选择: 总=%d 活跃=%d",
 			len(regions), len(activeRegions))
 		return activeRegions
@@ -326,7 +473,7 @@ func (a *Collector) getAllRegions(account config.CloudAccount) []string {
 	return regions
 }
 
-func (a *Collector) collectCMSMetrics(account config.CloudAccount, region string) {
+func (a *Collector) collectCMSMetrics(ctx context.Context, account config.CloudAccount, region string) {
 	if a.cfg == nil {
 		return
 	}
@@ -364,22 +511,25 @@ func (a *Collector) collectCMSMetrics(account config.CloudAccount, region string
 	// 1) 区域级并发：在 Collect 中控制（同账号多 region 并行）
 	// 2) 产品级并发：在本函数内控制（同 region 下多个命名空间并行）
 	// 3) 指标级并发：在每个产品 goroutine 内控制（同命名空间下多个指标批次并行）
-	// 其中 mlimit 控制第 3 层并发，plimit 控制第 2 层并发。
+	// 其中 mlimit 控制第 3 层并发，plimit 控制第 2 层并发；启用自适应并发时二者为有效并发的上限。
 
 	// 指标并发控制（命名空间/指标级）
 	mlimit := getMetricConcurrency(a.cfg)
-	msem := make(chan struct{}, mlimit)
+	msem := common.ConcurrencyFor("aliyun", account.AccountID, common.ScopeMetric, mlimit).NewSemaphore()
 	var mwg sync.WaitGroup
 
 	// 产品并发控制（命名空间级）：控制同一地域内不同命名空间（如 ECS/BWP）并行度，避免串行导致总时长过长。
 	plimit := getProductConcurrency(a.cfg)
-	psem := make(chan struct{}, plimit)
+	psem := common.ConcurrencyFor("aliyun", account.AccountID, common.ScopeProduct, plimit).NewSemaphore()
 	var pwg sync.WaitGroup
 
 	// 产品级分片：获取集群配置用于产品级分片判断
 	wTotal, wIndex := utils.ClusterConfig()
 
 	for _, prod := range prods {
+		if ctx.Err() != nil {
+			break
+		}
 		if prod.Namespace == "" {
 			continue
 		}
@@ -393,11 +543,37 @@ func (a *Collector) collectCMSMetrics(account config.CloudAccount, region string
 			baseLog.With("namespace", prod.Namespace).Debugf("产品跳过（分片不匹配）")
 			continue
 		}
+		// 产品级调度：按指标 Period（或显式配置）判断本轮是否到期，未到期保留上次导出的值
+		interval := common.ResolveProductInterval(a.cfg, "aliyun", prod, func(metric string) int {
+			n, _ := strconv.Atoi(a.getMetricMeta(ctx, client, account.AccountID, prod.Namespace, metric).MinPeriod)
+			return n
+		})
+		if !a.scheduler.ShouldScrape("aliyun", account.AccountID, region, prod.Namespace, interval) {
+			baseLog.With("namespace", prod.Namespace).Debugf("产品跳过（采集周期未到期，周期=%v）", interval)
+			continue
+		}
+		// 区域级跳过：该命名空间在本区域连续多轮枚举为空，等待重新发现
+		if common.SkipEmptyRegion(a.regionManager, "aliyun", account.AccountID, region, prod.Namespace) {
+			continue
+		}
+		if psem.Acquire(ctx) != nil {
+			break
+		}
 		pwg.Add(1)
-		psem <- struct{}{}
 		go func(prod config.Product) {
 			defer pwg.Done()
-			defer func() { <-psem }()
+			defer psem.Release()
+			// 目标健康：产品的全部指标批次完成后结束本轮目标采集
+			target := common.StartTarget(ctx, "aliyun", account.AccountID, region, prod.Namespace)
+			var nwg sync.WaitGroup
+			defer func() {
+				mwg.Add(1)
+				go func() {
+					defer mwg.Done()
+					nwg.Wait()
+					a.scheduler.Finished("aliyun", account.AccountID, region, prod.Namespace, target.Finish())
+				}()
+			}()
 			for _, group := range prod.MetricInfo {
 				var period string
 				switch {
@@ -411,9 +587,12 @@ func (a *Collector) collectCMSMetrics(account config.CloudAccount, region string
 				// 对每个指标使用指标级并发进行批次拉取。每批最多 50 个维度（实例）。
 				idx := 0
 				for _, metricName := range group.MetricList {
+					if ctx.Err() != nil {
+						return
+					}
 					metricIdx := idx
 					idx++
-					meta := a.getMetricMeta(client, account.AccountID, prod.Namespace, metricName)
+					meta := a.getMetricMeta(ctx, client, account.AccountID, prod.Namespace, metricName)
 					localPeriod := period
 					if localPeriod == "" && meta.MinPeriod != "" {
 						localPeriod = meta.MinPeriod
@@ -469,7 +648,11 @@ func (a *Collector) collectCMSMetrics(account config.CloudAccount, region string
 						resIDs = cachedIDs
 						baseLog.With("namespace", prod.Namespace, "resource_type", rtype).Debugf("资源缓存命中 数量=%d", len(resIDs))
 					} else {
-						resIDs, rtype, metaInfo = a.resourceIDsForNamespace(account, region, prod.Namespace)
+						resIDs, rtype, metaInfo = a.resourceIDsForNamespace(ctx, account, region, prod.Namespace)
+						if ctx.Err() != nil {
+							// 采集取消时枚举结果可能不完整，不写入缓存
+							return
+						}
 						a.setCachedIDs(account, region, prod.Namespace, rtype, resIDs, metaInfo)
 						baseLog.With("namespace", prod.Namespace, "resource_type", rtype).Debugf("资源枚举完成 数量=%d", len(resIDs))
 						if len(resIDs) == 0 {
@@ -480,21 +663,25 @@ func (a *Collector) collectCMSMetrics(account config.CloudAccount, region string
 					// 修复：将标签获取移到 goroutine 内部，避免阻塞主循环
 					// 这样主循环不会被 getOrFetchTags 或 msem 阻塞，可以快速启动所有指标的 goroutine
 					mwg.Add(1)
+					nwg.Add(1)
 					go func(ns, m string, dkey string, rtype string, ids []string, p string, stats []string, meta map[string]interface{}, metricDims []string, accountID string, metricIdx int) {
 						defer mwg.Done()
+						defer nwg.Done()
 
 						ctxLog := logger.NewContextLogger("Aliyun", "account_id", accountID, "region", region, "namespace", ns, "metric", m)
 
-						msem <- struct{}{}
-						defer func() { <-msem }()
+						if msem.Acquire(ctx) != nil {
+							return
+						}
+						defer msem.Release()
 
 						// 在 goroutine 内部获取标签（第一次会调用API并缓存，后续使用缓存）
-						tagLabels := a.getOrFetchTags(account, region, rtype, ids)
+						tagLabels := a.getOrFetchTags(ctx, account, region, rtype, ids)
 
 						ctxLog.Debugf("开始构建维度 metric_idx=%d", metricIdx)
 						allDims, dynamicDims := a.buildMetricDimensions(accountID, ns, ids, dkey, metricDims, meta)
 
-						a.fetchAndRecordMetrics(client, account, region, ns, m, dkey, rtype, p, allDims, dynamicDims, tagLabels, stats, ctxLog)
+						a.fetchAndRecordMetrics(ctx, client, account, region, ns, m, dkey, rtype, p, allDims, dynamicDims, tagLabels, stats, ctxLog)
 					}(prod.Namespace, metricName, dimKey, rtype, resIDs, localPeriod, meta.Statistics, metaInfo, meta.Dimensions, account.AccountID, metricIdx)
 				}
 			}
@@ -531,16 +718,14 @@ type metricMeta struct {
 	MinPeriod  string
 }
 
-func (a *Collector) getMetricMeta(client CMSClient, accountID, namespace, metric string) metricMeta {
+func (a *Collector) getMetricMeta(ctx context.Context, client CMSClient, accountID, namespace, metric string) metricMeta {
 	ctxLog := logger.NewContextLogger("Aliyun", "account_id", accountID)
 
 	key := accountID + "|" + namespace + "|" + metric
 
-	// 首次检查缓存（读锁）
-	a.metaMu.RLock()
-	m, ok := a.metaCache[key]
-	a.metaMu.RUnlock()
-	if ok {
+	// 首次检查缓存
+	var m metricMeta
+	if a.metaCache.Get(key, &m) {
 		return m
 	}
 
@@ -549,57 +734,33 @@ func (a *Collector) getMetricMeta(client CMSClient, accountID, namespace, metric
 	// singleflight 确保只发出一个 API 请求，其他等待共享结果
 	val, err, _ := a.sf.Do(key, func() (interface{}, error) {
 		// Double-check cache inside singleflight (防止在等待期间其他 goroutine 已经更新了缓存)
-		a.metaMu.RLock()
-		if cached, ok := a.metaCache[key]; ok {
-			a.metaMu.RUnlock()
+		var cached metricMeta
+		if a.metaCache.Get(key, &cached) {
 			return cached, nil
 		}
-		a.metaMu.RUnlock()
 
 		// 执行 API 调用
 		var out metricMeta
 		req := cms.CreateDescribeMetricMetaListRequest()
 		req.Namespace = namespace
 		req.MetricName = metric
-		start := time.Now()
-		var resp *cms.DescribeMetricMetaListResponse
-		var apiErr error
-
-		// 重试机制：最多重试 5 次
-		for attempt := 0; attempt < 5; attempt++ {
-			resp, apiErr = client.DescribeMetricMetaList(req)
-			if apiErr == nil {
-				break
-			}
-			status := common.ClassifyAliyunError(apiErr)
-			if status == "limit_error" {
-				// 记录限流指标
-				metrics.RateLimitTotal.WithLabelValues("aliyun", "DescribeMetricMetaList").Inc()
-				sleep := time.Duration(200*(1<<attempt)) * time.Millisecond
-				if sleep > 5*time.Second {
-					sleep = 5 * time.Second
-				}
-				ctxLog.Debugf("getMetricMeta 限流重试，命名空间=%s 指标=%s 重试次数=%d/%d 延迟=%v", namespace, metric, attempt+1, 5, sleep)
-				time.Sleep(sleep)
-				continue
-			}
-			break
-		}
+
+		// 重试机制：限流时最多尝试 5 次
+		resp, apiErr := common.Call(ctx, common.CallOptions{
+			Provider: "aliyun", AccountID: accountID, API: "DescribeMetricMetaList",
+			Attempts: 5, Retryable: func(status string) bool { return status == common.ErrorStatusLimit },
+		}, func() (*cms.DescribeMetricMetaListResponse, error) {
+			return client.DescribeMetricMetaList(req)
+		})
 
 		if apiErr != nil {
-			st := common.ClassifyAliyunError(apiErr)
-			if st == "limit_error" {
+			if common.ClassifyAliyunError(apiErr) == common.ErrorStatusLimit {
 				ctxLog.Warnf("getMetricMeta 错误（重试5次后仍失败），命名空间=%s 指标=%s 错误=%v", namespace, metric, apiErr)
 			} else {
 				ctxLog.Warnf("getMetricMeta 错误，命名空间=%s 指标=%s 错误=%v", namespace, metric, apiErr)
 			}
-			metrics.RequestTotal.WithLabelValues("aliyun", "DescribeMetricMetaList", st).Inc()
-			metrics.RecordRequest("aliyun", "DescribeMetricMetaList", st)
 			// 错误时仍尝试使用默认维度，不返回空维度
 		} else {
-			metrics.RequestTotal.WithLabelValues("aliyun", "DescribeMetricMetaList", "success").Inc()
-			metrics.RequestDuration.WithLabelValues("aliyun", "DescribeMetricMetaList").Observe(time.Since(start).Seconds())
-			metrics.RecordRequest("aliyun", "DescribeMetricMetaList", "success")
 
 			// 【诊断日志】API 返回结果的详细信息
 			if len(resp.Resources.Resource) == 0 {
@@ -699,12 +860,10 @@ func (a *Collector) getMetricMeta(client CMSClient, accountID, namespace, metric
 		}
 
 		// 只缓存有维度的元数据，避免缓存空维度导致指标永久丢失
-		// 如果维度为空，下次采集会重新调用 API 获取
-		if len(out.Dimensions) > 0 {
-			a.metaMu.Lock()
-			a.metaCache[key] = out
-			a.metaMu.Unlock()
-		} else {
+		// 如果维度为空，下次采集会重新调用 API 获取；采集被取消时得到的兜底结果同样不缓存
+		if len(out.Dimensions) > 0 && ctx.Err() == nil {
+			_ = a.metaCache.Set(key, out, a.discoveryTTL())
+		} else if len(out.Dimensions) == 0 {
 			ctxLog.Warnf("getMetricMeta 跳过缓存（维度为空），命名空间=%s 指标=%s，将使用默认维度", namespace, metric)
 		}
 
@@ -912,21 +1071,21 @@ func resourceTypeForNamespace(namespace string) string {
 	}
 }
 
-func (a *Collector) resourceIDsForNamespace(account config.CloudAccount, region string, namespace string) ([]string, string, map[string]interface{}) {
+func (a *Collector) resourceIDsForNamespace(ctx context.Context, account config.CloudAccount, region string, namespace string) ([]string, string, map[string]interface{}) {
 	switch namespace {
 	case "acs_bandwidth_package":
-		return a.listCBWPIDs(account, region), "cbwp", nil
+		return a.listCBWPIDs(ctx, account, region), "cbwp", nil
 	case "acs_slb_dashboard":
-		ids, meta := a.listSLBIDs(account, region)
+		ids, meta := a.listSLBIDs(ctx, account, region)
 		return ids, "clb", meta
 	case "acs_oss_dashboard":
-		return a.listOSSIDs(account, region), "oss", nil
+		return a.listOSSIDs(ctx, account, region), "oss", nil
 	case "acs_alb":
-		return a.listALBIDs(account, region), "alb", nil
+		return a.listALBIDs(ctx, account, region), "alb", nil
 	case "acs_nlb":
-		return a.listNLBIDs(account, region), "nlb", nil
+		return a.listNLBIDs(ctx, account, region), "nlb", nil
 	case "acs_gwlb":
-		return a.listAliGWLBIDs(account, region), "gwlb", nil
+		return a.listAliGWLBIDs(ctx, account, region), "gwlb", nil
 	default:
 		return []string{}, "", nil
 	}
@@ -936,7 +1095,7 @@ func (a *Collector) cacheKey(account config.CloudAccount, region, namespace, rty
 	return account.AccountID + "|" + region + "|" + namespace + "|" + rtype
 }
 
-func (a *Collector) listALBIDs(account config.CloudAccount, region string) []string {
+func (a *Collector) listALBIDs(ctx context.Context, account config.CloudAccount, region string) []string {
 	ctxLog := logger.NewContextLogger("Aliyun", "account_id", account.AccessKeyID, "region", region, "rtype", "alb")
 
 	if ids, _, hit := a.getCachedIDs(account, region, "acs_alb", "alb"); hit {
@@ -944,6 +1103,8 @@ func (a *Collector) listALBIDs(account config.CloudAccount, region string) []str
 	}
 	var out []string
 	var meta map[string]interface{}
+	names := make(map[string]string)
+	var listErr error
 	albClient, err := a.clientFactory.NewALBClient(region, account.AccessKeyID, account.AccessKeySecret)
 	if err == nil && albClient != nil {
 		pageSize := 100
@@ -978,38 +1139,15 @@ func (a *Collector) listALBIDs(account config.CloudAccount, region string) []str
 				seenNextTokens[nextToken] = true
 				req.NextToken = tea.String(nextToken)
 			}
-			var resp *alb20200616.ListLoadBalancersResponse
-			var callErr error
-			for attempt := 0; attempt < 3; attempt++ {
-				startReq := time.Now()
-				resp, callErr = albClient.ListLoadBalancers(req)
-				if callErr == nil && resp != nil && resp.Body != nil {
-					metrics.RequestTotal.WithLabelValues("aliyun", "ListLoadBalancers", "success").Inc()
-					metrics.RequestDuration.WithLabelValues("aliyun", "ListLoadBalancers").Observe(time.Since(startReq).Seconds())
-					metrics.RecordRequest("aliyun", "ListLoadBalancers", "success")
-					break
-				}
-				if callErr != nil {
-					status := common.ClassifyAliyunError(callErr)
-					metrics.RequestTotal.WithLabelValues("aliyun", "ListLoadBalancers", status).Inc()
-					metrics.RecordRequest("aliyun", "ListLoadBalancers", status)
-					if status == "limit_error" {
-						// 记录限流指标
-						metrics.RateLimitTotal.WithLabelValues("aliyun", "ListLoadBalancers").Inc()
-					}
-					if status == "auth_error" || status == "region_skip" {
-						out = []string{}
-						break
-					}
-					// 指数退避重试
-					sleep := time.Duration(200*(1<<attempt)) * time.Millisecond
-					if sleep > 5*time.Second {
-						sleep = 5 * time.Second
-					}
-					time.Sleep(sleep)
-				} else {
-					break
-				}
+			resp, callErr := common.Call(ctx, common.CallOptions{
+				Provider: "aliyun", AccountID: account.AccountID, Region: region, API: "ListLoadBalancers",
+				Attempts: 3, Retryable: common.RetryUnlessFatal,
+			}, func() (*alb20200616.ListLoadBalancersResponse, error) {
+				return albClient.ListLoadBalancers(req)
+			})
+			if callErr != nil {
+				common.RecordTargetError("aliyun", account.AccountID, region, common.NamespaceAliyunALB, common.ClassifyAliyunError(callErr))
+				listErr = callErr
 			}
 			if callErr != nil || resp == nil || resp.Body == nil {
 				out = []string{}
@@ -1024,6 +1162,7 @@ func (a *Collector) listALBIDs(account config.CloudAccount, region string) []str
 						id := tea.StringValue(lb.LoadBalancerId)
 						if id != "" {
 							out = append(out, id)
+							names[id] = tea.StringValue(lb.LoadBalancerName)
 							pageDataCount++
 						}
 					}
@@ -1047,15 +1186,18 @@ func (a *Collector) listALBIDs(account config.CloudAccount, region string) []str
 				break
 			}
 			nextToken = tea.StringValue(resp.Body.NextToken)
-			time.Sleep(25 * time.Millisecond)
 		}
 	}
 
+	// 资源过滤：在补充 CMS 元数据之前执行；全部被过滤时不回退到 CMS 枚举
+	listed := len(out)
+	out = a.filterResourceIDs(ctx, account, region, "alb", out, names)
+
 	// 回退到 CMS 枚举的条件：
 	// 1. ALB API 客户端创建失败（err != nil 或 albClient == nil）
 	// 2. ALB API 调用成功但返回空列表（可能是该区域确实没有资源，或 API 权限问题）
 	// 注意：如果 ALB API 调用失败（callErr != nil），说明是认证或权限问题，不应该回退到 CMS
-	if len(out) == 0 {
+	if listed == 0 {
 		ctxLog.Debugf("ALB API 返回空列表，尝试回退到 CMS 枚举")
 		cmsClient, cmsErr := a.clientFactory.NewCMSClient(region, account.AccessKeyID, account.AccessKeySecret)
 		if cmsErr != nil {
@@ -1063,28 +1205,39 @@ func (a *Collector) listALBIDs(account config.CloudAccount, region string) []str
 			// 不缓存空结果，允许下次重新尝试
 			return []string{}
 		}
-		out = a.listIDsByCMS(cmsClient, region, "acs_alb", "LoadBalancerActiveConnection", "loadBalancerId")
+		var cmsListErr error
+		out, cmsListErr = a.listIDsByCMS(ctx, cmsClient, account.AccountID, region, "acs_alb", "LoadBalancerActiveConnection", "loadBalancerId")
+		if cmsListErr != nil && listErr != nil {
+			// API 与 CMS 枚举均失败：不缓存也不更新区域状态，避免把区域误标为无资源
+			return []string{}
+		}
+		listed = len(out)
+		out = a.filterResourceIDs(ctx, account, region, "alb", out, nil)
 		if len(out) > 0 {
 			ctxLog.Debugf("ALB CMS 枚举成功，数量=%d", len(out))
-			meta = a.buildALBMetaByCMS(cmsClient, region, out)
+			meta = a.buildALBMetaByCMS(ctx, cmsClient, account.AccountID, region, out)
 		} else {
 			ctxLog.Debugf("ALB CMS 枚举也返回空列表，该区域可能确实没有 ALB 资源")
 		}
-	} else {
+	} else if len(out) > 0 {
 		// ALB API 枚举成功，使用 CMS 补充元数据
 		cmsClient, cmsErr := a.clientFactory.NewCMSClient(region, account.AccessKeyID, account.AccessKeySecret)
 		if cmsErr == nil {
-			meta = a.buildALBMetaByCMS(cmsClient, region, out)
+			meta = a.buildALBMetaByCMS(ctx, cmsClient, account.AccountID, region, out)
 		}
 	}
 
 	// 只有在成功枚举到资源或确认该区域确实没有资源时才缓存
 	// 如果是因为 API 调用失败导致的空结果，不缓存，允许下次重新尝试
 	a.setCachedIDs(account, region, "acs_alb", "alb", out, meta)
+	// 区域状态按过滤前的枚举总数更新；API 失败且 CMS 也未枚举到资源时不更新
+	if listErr == nil || listed > 0 {
+		common.ReportRegionResources(a.regionManager, "aliyun", account.AccountID, region, common.NamespaceAliyunALB, listed)
+	}
 	return out
 }
 
-func (a *Collector) listNLBIDs(account config.CloudAccount, region string) []string {
+func (a *Collector) listNLBIDs(ctx context.Context, account config.CloudAccount, region string) []string {
 	ctxLog := logger.NewContextLogger("Aliyun", "account_id", account.AccessKeyID, "region", region, "rtype", "nlb")
 
 	if ids, _, hit := a.getCachedIDs(account, region, "acs_nlb", "nlb"); hit {
@@ -1092,6 +1245,8 @@ func (a *Collector) listNLBIDs(account config.CloudAccount, region string) []str
 	}
 	var out []string
 	var meta map[string]interface{}
+	names := make(map[string]string)
+	var listErr error
 	nlbClient, err := a.clientFactory.NewNLBClient(region, account.AccessKeyID, account.AccessKeySecret)
 	if err == nil && nlbClient != nil {
 		pageSize := 100
@@ -1126,38 +1281,15 @@ func (a *Collector) listNLBIDs(account config.CloudAccount, region string) []str
 				seenNextTokens[nextToken] = true
 				req.NextToken = tea.String(nextToken)
 			}
-			var resp *nlb20220430.ListLoadBalancersResponse
-			var callErr error
-			for attempt := 0; attempt < 3; attempt++ {
-				startReq := time.Now()
-				resp, callErr = nlbClient.ListLoadBalancers(req)
-				if callErr == nil && resp != nil && resp.Body != nil {
-					metrics.RequestTotal.WithLabelValues("aliyun", "ListLoadBalancers", "success").Inc()
-					metrics.RequestDuration.WithLabelValues("aliyun", "ListLoadBalancers").Observe(time.Since(startReq).Seconds())
-					metrics.RecordRequest("aliyun", "ListLoadBalancers", "success")
-					break
-				}
-				if callErr != nil {
-					status := common.ClassifyAliyunError(callErr)
-					metrics.RequestTotal.WithLabelValues("aliyun", "ListLoadBalancers", status).Inc()
-					metrics.RecordRequest("aliyun", "ListLoadBalancers", status)
-					if status == "limit_error" {
-						// 记录限流指标
-						metrics.RateLimitTotal.WithLabelValues("aliyun", "ListLoadBalancers").Inc()
-					}
-					if status == "auth_error" || status == "region_skip" {
-						out = []string{}
-						break
-					}
-					// 指数退避重试
-					sleep := time.Duration(200*(1<<attempt)) * time.Millisecond
-					if sleep > 5*time.Second {
-						sleep = 5 * time.Second
-					}
-					time.Sleep(sleep)
-				} else {
-					break
-				}
+			resp, callErr := common.Call(ctx, common.CallOptions{
+				Provider: "aliyun", AccountID: account.AccountID, Region: region, API: "ListLoadBalancers",
+				Attempts: 3, Retryable: common.RetryUnlessFatal,
+			}, func() (*nlb20220430.ListLoadBalancersResponse, error) {
+				return nlbClient.ListLoadBalancers(req)
+			})
+			if callErr != nil {
+				common.RecordTargetError("aliyun", account.AccountID, region, common.NamespaceAliyunNLB, common.ClassifyAliyunError(callErr))
+				listErr = callErr
 			}
 			if callErr != nil || resp == nil || resp.Body == nil {
 				out = []string{}
@@ -1172,6 +1304,7 @@ func (a *Collector) listNLBIDs(account config.CloudAccount, region string) []str
 						id := tea.StringValue(lb.LoadBalancerId)
 						if id != "" {
 							out = append(out, id)
+							names[id] = tea.StringValue(lb.LoadBalancerName)
 							pageDataCount++
 						}
 					}
@@ -1195,15 +1328,18 @@ func (a *Collector) listNLBIDs(account config.CloudAccount, region string) []str
 				break
 			}
 			nextToken = tea.StringValue(resp.Body.NextToken)
-			time.Sleep(25 * time.Millisecond)
 		}
 	}
 
+	// 资源过滤：在补充 CMS 元数据之前执行；全部被过滤时不回退到 CMS 枚举
+	listed := len(out)
+	out = a.filterResourceIDs(ctx, account, region, "nlb", out, names)
+
 	// 回退到 CMS 枚举的条件：
 	// 1. NLB API 客户端创建失败（err != nil 或 nlbClient == nil）
 	// 2. NLB API 调用成功但返回空列表（可能是该区域确实没有资源，或 API 权限问题）
 	// 注意：如果 NLB API 调用失败（callErr != nil），说明是认证或权限问题，不应该回退到 CMS
-	if len(out) == 0 {
+	if listed == 0 {
 		ctxLog.Debugf("NLB API 返回空列表，尝试回退到 CMS 枚举")
 		cmsClient, cmsErr := a.clientFactory.NewCMSClient(region, account.AccessKeyID, account.AccessKeySecret)
 		if cmsErr != nil {
@@ -1211,29 +1347,40 @@ func (a *Collector) listNLBIDs(account config.CloudAccount, region string) []str
 			// 不缓存空结果，允许下次重新尝试
 			return []string{}
 		}
-		out = a.listIDsByCMS(cmsClient, region, "acs_nlb", "InstanceActiveConnection", "instanceId")
+		var cmsListErr error
+		out, cmsListErr = a.listIDsByCMS(ctx, cmsClient, account.AccountID, region, "acs_nlb", "InstanceActiveConnection", "instanceId")
+		if cmsListErr != nil && listErr != nil {
+			// API 与 CMS 枚举均失败：不缓存也不更新区域状态，避免把区域误标为无资源
+			return []string{}
+		}
+		listed = len(out)
+		out = a.filterResourceIDs(ctx, account, region, "nlb", out, nil)
 		if len(out) > 0 {
 			ctxLog.Debugf("NLB CMS 枚举成功，数量=%d", len(out))
-			meta = a.buildNLBMetaByCMS(cmsClient, region, out)
+			meta = a.buildNLBMetaByCMS(ctx, cmsClient, account.AccountID, region, out)
 		} else {
 			ctxLog.Debugf("NLB CMS 枚举也返回空列表，该区域可能确实没有 NLB 资源")
 		}
-	} else {
+	} else if len(out) > 0 {
 		// NLB API 枚举成功，使用 CMS 补充元数据
 		cmsClient, cmsErr := a.clientFactory.NewCMSClient(region, account.AccessKeyID, account.AccessKeySecret)
 		if cmsErr == nil {
-			meta = a.buildNLBMetaByCMS(cmsClient, region, out)
+			meta = a.buildNLBMetaByCMS(ctx, cmsClient, account.AccountID, region, out)
 		}
 	}
 
 	// 只有在成功枚举到资源或确认该区域确实没有资源时才缓存
 	// 如果是因为 API 调用失败导致的空结果，不缓存，允许下次重新尝试
 	a.setCachedIDs(account, region, "acs_nlb", "nlb", out, meta)
+	// 区域状态按过滤前的枚举总数更新；API 失败且 CMS 也未枚举到资源时不更新
+	if listErr == nil || listed > 0 {
+		common.ReportRegionResources(a.regionManager, "aliyun", account.AccountID, region, common.NamespaceAliyunNLB, listed)
+	}
 	return out
 }
 
 // listAliGWLBIDs 通过 CMS 指标数据枚举 GWLB 资源 ID
-func (a *Collector) listAliGWLBIDs(account config.CloudAccount, region string) []string {
+func (a *Collector) listAliGWLBIDs(ctx context.Context, account config.CloudAccount, region string) []string {
 	if ids, _, hit := a.getCachedIDs(account, region, "acs_gwlb", "gwlb"); hit {
 		return ids
 	}
@@ -1242,13 +1389,20 @@ func (a *Collector) listAliGWLBIDs(account config.CloudAccount, region string) [
 		return []string{}
 	}
 	metric := "ActiveConnection"
-	out := a.listIDsByCMS(client, region, "acs_gwlb", metric, "instanceId")
+	out, err := a.listIDsByCMS(ctx, client, account.AccountID, region, "acs_gwlb", metric, "instanceId")
+	if err != nil {
+		return []string{}
+	}
+	listed := len(out)
+	out = a.filterResourceIDs(ctx, account, region, "gwlb", out, nil)
 	a.setCachedIDs(account, region, "acs_gwlb", "gwlb", out, nil)
+	// 区域状态按过滤前的枚举总数更新
+	common.ReportRegionResources(a.regionManager, "aliyun", account.AccountID, region, common.NamespaceAliyunGWLB, listed)
 	return out
 }
 
-// listIDsByCMS 使用 DescribeMetricList 拉取短时间窗口的数据，解析维度提取资源ID
-func (a *Collector) listIDsByCMS(client CMSClient, region, namespace, metric, idKey string) []string {
+// listIDsByCMS 使用 DescribeMetricList 拉取短时间窗口的数据，解析维度提取资源ID；调用失败时返回错误
+func (a *Collector) listIDsByCMS(ctx context.Context, client CMSClient, accountID, region, namespace, metric, idKey string) ([]string, error) {
 	req := cms.CreateDescribeMetricListRequest()
 	req.Namespace = namespace
 	req.MetricName = metric
@@ -1258,34 +1412,17 @@ func (a *Collector) listIDsByCMS(client CMSClient, region, namespace, metric, id
 	req.StartTime = start.Format("2006-01-02 15:04:05")
 	req.EndTime = end.Format("2006-01-02 15:04:05")
 	req.Period = "60"
-	var resp *cms.DescribeMetricListResponse
-	var callErr error
-	for attempt := 0; attempt < 3; attempt++ {
-		st := time.Now()
-		resp, callErr = client.DescribeMetricList(req)
-		if callErr == nil {
-			metrics.RequestTotal.WithLabelValues("aliyun", "DescribeMetricList", "success").Inc()
-			metrics.RequestDuration.WithLabelValues("aliyun", "DescribeMetricList").Observe(time.Since(st).Seconds())
-			metrics.RecordRequest("aliyun", "DescribeMetricList", "success")
-			break
-		}
-		status := common.ClassifyAliyunError(callErr)
-		metrics.RequestTotal.WithLabelValues("aliyun", "DescribeMetricList", status).Inc()
-		metrics.RecordRequest("aliyun", "DescribeMetricList", status)
-		if status == "limit_error" {
-			// 记录限流指标
-			metrics.RateLimitTotal.WithLabelValues("aliyun", "DescribeMetricList").Inc()
-		}
-		if status == "auth_error" || status == "region_skip" {
-			break
-		}
-		// 简单退避
-		time.Sleep(time.Duration(200*(1<<attempt)) * time.Millisecond)
+	resp, callErr := common.Call(ctx, common.CallOptions{
+		Provider: "aliyun", AccountID: accountID, Region: region, API: "DescribeMetricList",
+		Attempts: 3, Retryable: common.RetryUnlessFatal,
+	}, func() (*cms.DescribeMetricListResponse, error) {
+		return client.DescribeMetricList(req)
+	})
+	if callErr != nil {
+		return []string{}, callErr
 	}
-	if callErr != nil || resp == nil {
-		// region 参数保留用于未来可能的日志记录或错误处理
-		_ = region
-		return []string{}
+	if resp == nil {
+		return []string{}, nil
 	}
 	var out []string
 	seen := make(map[string]struct{})
@@ -1305,15 +1442,43 @@ func (a *Collector) listIDsByCMS(client CMSClient, region, namespace, metric, id
 			}
 		}
 	}
-	return out
+	return out, nil
 }
 func (a *Collector) getCachedIDs(account config.CloudAccount, region, namespace, rtype string) ([]string, map[string]interface{}, bool) {
-	a.cacheMu.RLock()
-	entry, ok := a.resCache[a.cacheKey(account, region, namespace, rtype)]
-	a.cacheMu.RUnlock()
-	if !ok {
+	var entry resCacheEntry
+	if !a.resCache.Get(a.cacheKey(account, region, namespace, rtype), &entry) {
 		return nil, nil, false
 	}
+	if time.Since(entry.UpdatedAt) > a.discoveryTTL() {
+		return nil, nil, false
+	}
+	var meta map[string]interface{}
+	if len(entry.Meta) > 0 {
+		meta = make(map[string]interface{}, len(entry.Meta))
+		for id, sub := range entry.Meta {
+			meta[id] = sub
+		}
+	}
+	return entry.IDs, meta, true
+}
+
+// setCachedIDs 缓存资源发现结果；meta 中的子资源列表（[]map[string]string）随资源 ID 一并缓存
+func (a *Collector) setCachedIDs(account config.CloudAccount, region, namespace, rtype string, ids []string, meta map[string]interface{}) {
+	entry := resCacheEntry{IDs: ids, UpdatedAt: time.Now()}
+	for id, v := range meta {
+		if sub, ok := v.([]map[string]string); ok {
+			if entry.Meta == nil {
+				entry.Meta = make(map[string][]map[string]string, len(meta))
+			}
+			entry.Meta[id] = sub
+		}
+	}
+	// 条目不设过期时间：超过 discovery_ttl 后不再用于采集，但仍作为资源清单的最近一次结果
+	_ = a.resCache.Set(a.cacheKey(account, region, namespace, rtype), entry, 0)
+}
+
+// discoveryTTL 返回 server.discovery_ttl，未配置或无法解析时为 1 小时
+func (a *Collector) discoveryTTL() time.Duration {
 	ttlDur := time.Hour
 	if server := a.cfg.GetServer(); server != nil {
 		if server.DiscoveryTTL != "" {
@@ -1328,18 +1493,10 @@ func (a *Collector) getCachedIDs(account config.CloudAccount, region, namespace,
 			}
 		}
 	}
-	if time.Since(entry.UpdatedAt) > ttlDur {
-		return nil, nil, false
-	}
-	return entry.IDs, entry.Meta, true
+	return ttlDur
 }
 
-func (a *Collector) setCachedIDs(account config.CloudAccount, region, namespace, rtype string, ids []string, meta map[string]interface{}) {
-	a.cacheMu.Lock()
-	a.resCache[a.cacheKey(account, region, namespace, rtype)] = resCacheEntry{IDs: ids, Meta: meta, UpdatedAt: time.Now()}
-	a.cacheMu.Unlock()
-}
-func (a *Collector) buildALBMetaByCMS(client CMSClient, region string, ids []string) map[string]interface{} {
+func (a *Collector) buildALBMetaByCMS(ctx context.Context, client CMSClient, accountID, region string, ids []string) map[string]interface{} {
 	// region 参数保留用于未来可能的日志记录或错误处理
 	_ = region
 	idSet := make(map[string]struct{}, len(ids))
@@ -1367,7 +1524,11 @@ func (a *Collector) buildALBMetaByCMS(client CMSClient, region string, ids []str
 		req.StartTime = start.Format("2006-01-02 15:04:05")
 		req.EndTime = end.Format("2006-01-02 15:04:05")
 		req.Period = "60"
-		resp, err := client.DescribeMetricList(req)
+		resp, err := common.Call(ctx, common.CallOptions{
+			Provider: "aliyun", AccountID: accountID, Region: region, API: "DescribeMetricList",
+		}, func() (*cms.DescribeMetricListResponse, error) {
+			return client.DescribeMetricList(req)
+		})
 		if err != nil || resp == nil {
 			continue
 		}
@@ -1433,7 +1594,7 @@ func (a *Collector) buildALBMetaByCMS(client CMSClient, region string, ids []str
 	}
 	return out
 }
-func (a *Collector) buildNLBMetaByCMS(client CMSClient, region string, ids []string) map[string]interface{} {
+func (a *Collector) buildNLBMetaByCMS(ctx context.Context, client CMSClient, accountID, region string, ids []string) map[string]interface{} {
 	// region 参数保留用于未来可能的日志记录或错误处理
 	_ = region
 	idSet := make(map[string]struct{}, len(ids))
@@ -1461,7 +1622,11 @@ func (a *Collector) buildNLBMetaByCMS(client CMSClient, region string, ids []str
 		req.StartTime = start.Format("2006-01-02 15:04:05")
 		req.EndTime = end.Format("2006-01-02 15:04:05")
 		req.Period = "60"
-		resp, err := client.DescribeMetricList(req)
+		resp, err := common.Call(ctx, common.CallOptions{
+			Provider: "aliyun", AccountID: accountID, Region: region, API: "DescribeMetricList",
+		}, func() (*cms.DescribeMetricListResponse, error) {
+			return client.DescribeMetricList(req)
+		})
 		if err != nil || resp == nil {
 			continue
 		}
@@ -1528,7 +1693,7 @@ func (a *Collector) buildNLBMetaByCMS(client CMSClient, region string, ids []str
 	return out
 }
 
-func (a *Collector) fetchALBTags(account config.CloudAccount, region string, ids []string) map[string]string {
+func (a *Collector) fetchALBTags(ctx context.Context, account config.CloudAccount, region string, ids []string) map[string]string {
 	if len(ids) == 0 {
 		return map[string]string{}
 	}
@@ -1538,9 +1703,10 @@ func (a *Collector) fetchALBTags(account config.CloudAccount, region string, ids
 		return map[string]string{}
 	}
 	out := make(map[string]string, len(ids))
+	fetched := newFetchedTags()
 	batchSize := 50
 	total := len(ids)
-	uid := a.getAccountUID(account, region)
+	uid := a.getAccountUID(ctx, account, region)
 	for start := 0; start < total; start += batchSize {
 		end := start + batchSize
 		if end > total {
@@ -1553,9 +1719,12 @@ func (a *Collector) fetchALBTags(account config.CloudAccount, region string, ids
 		req := tag.CreateListTagResourcesRequest()
 		req.RegionId = region
 		req.ResourceARN = &arns
-		resp, callErr := tagClient.ListTagResources(req)
+		resp, callErr := common.Call(ctx, common.CallOptions{
+			Provider: "aliyun", AccountID: account.AccountID, Region: region, API: "ListTagResources",
+		}, func() (*tag.ListTagResourcesResponse, error) {
+			return tagClient.ListTagResources(req)
+		})
 		if callErr != nil || resp == nil {
-			time.Sleep(50 * time.Millisecond)
 			continue
 		}
 		if len(resp.TagResources) > 0 {
@@ -1573,19 +1742,14 @@ func (a *Collector) fetchALBTags(account config.CloudAccount, region string, ids
 				for _, t := range tr.Tags {
 					k := t.Key
 					v := t.Value
-					if strings.EqualFold(k, "CodeName") || strings.EqualFold(k, "code_name") {
-						out[id] = v
-					}
+					fetched.add(id, k, v)
 				}
 			}
 		} else {
-			content := resp.GetHttpContentBytes()
-			parsed := parseContentCodeName(content)
-			for k, v := range parsed {
-				out[k] = v
-			}
+			fetched.addAll(parseTagResourcesContent(resp.GetHttpContentBytes()))
 		}
 	}
+	a.applyFetchedTags(account, region, "alb", ids, fetched, out)
 	assigned := 0
 	for _, v := range out {
 		if v != "" {
@@ -1596,7 +1760,7 @@ func (a *Collector) fetchALBTags(account config.CloudAccount, region string, ids
 	return out
 }
 
-func (a *Collector) fetchNLBTags(account config.CloudAccount, region string, ids []string) map[string]string {
+func (a *Collector) fetchNLBTags(ctx context.Context, account config.CloudAccount, region string, ids []string) map[string]string {
 	if len(ids) == 0 {
 		return map[string]string{}
 	}
@@ -1606,9 +1770,10 @@ func (a *Collector) fetchNLBTags(account config.CloudAccount, region string, ids
 		return map[string]string{}
 	}
 	out := make(map[string]string, len(ids))
+	fetched := newFetchedTags()
 	batchSize := 50
 	total := len(ids)
-	uid := a.getAccountUID(account, region)
+	uid := a.getAccountUID(ctx, account, region)
 	for start := 0; start < total; start += batchSize {
 		end := start + batchSize
 		if end > total {
@@ -1621,40 +1786,16 @@ func (a *Collector) fetchNLBTags(account config.CloudAccount, region string, ids
 		req := tag.CreateListTagResourcesRequest()
 		req.RegionId = region
 		req.ResourceARN = &arns
-		var resp *tag.ListTagResourcesResponse
-		var callErr error
-		for attempt := 0; attempt < 3; attempt++ {
-			startReq := time.Now()
-			resp, callErr = tagClient.ListTagResources(req)
-			if callErr == nil && resp != nil {
-				metrics.RequestTotal.WithLabelValues("aliyun", "ListTagResources", "success").Inc()
-				metrics.RequestDuration.WithLabelValues("aliyun", "ListTagResources").Observe(time.Since(startReq).Seconds())
-				metrics.RecordRequest("aliyun", "ListTagResources", "success")
-				break
-			}
-			if callErr != nil {
-				status := common.ClassifyAliyunError(callErr)
-				metrics.RequestTotal.WithLabelValues("aliyun", "ListTagResources", status).Inc()
-				metrics.RecordRequest("aliyun", "ListTagResources", status)
-				if status == "limit_error" {
-					// 记录限流指标
-					metrics.RateLimitTotal.WithLabelValues("aliyun", "ListTagResources").Inc()
-				}
-				if status == "auth_error" {
-					break
-				}
-				// 指数退避重试
-				sleep := time.Duration(200*(1<<attempt)) * time.Millisecond
-				if sleep > 5*time.Second {
-					sleep = 5 * time.Second
-				}
-				time.Sleep(sleep)
-			} else {
-				break
-			}
+		resp, callErr := common.Call(ctx, common.CallOptions{
+			Provider: "aliyun", AccountID: account.AccountID, Region: region, API: "ListTagResources",
+			Attempts: 3, Retryable: common.RetryUnlessFatal,
+		}, func() (*tag.ListTagResourcesResponse, error) {
+			return tagClient.ListTagResources(req)
+		})
+		if callErr != nil && common.ClassifyAliyunError(callErr) == common.ErrorStatusAuth {
+			common.RecordTargetError("aliyun", account.AccountID, region, common.NamespaceAliyunNLB, common.ErrorStatusAuth)
 		}
 		if callErr != nil || resp == nil {
-			time.Sleep(50 * time.Millisecond)
 			continue
 		}
 		if len(resp.TagResources) > 0 {
@@ -1672,19 +1813,14 @@ func (a *Collector) fetchNLBTags(account config.CloudAccount, region string, ids
 				for _, t := range tr.Tags {
 					k := t.Key
 					v := t.Value
-					if strings.EqualFold(k, "CodeName") || strings.EqualFold(k, "code_name") {
-						out[id] = v
-					}
+					fetched.add(id, k, v)
 				}
 			}
 		} else {
-			content := resp.GetHttpContentBytes()
-			parsed := parseContentCodeName(content)
-			for k, v := range parsed {
-				out[k] = v
-			}
+			fetched.addAll(parseTagResourcesContent(resp.GetHttpContentBytes()))
 		}
 	}
+	a.applyFetchedTags(account, region, "nlb", ids, fetched, out)
 	assigned := 0
 	for _, v := range out {
 		if v != "" {
@@ -1695,8 +1831,18 @@ func (a *Collector) fetchNLBTags(account config.CloudAccount, region string, ids
 	return out
 }
 
-func parseContentCodeName(content []byte) map[string]string {
-	out := make(map[string]string)
+// parseTagResourcesContent 解析 ListTagResources 原始响应中的完整标签（兼容两种响应结构）
+func parseTagResourcesContent(content []byte) map[string]map[string]string {
+	out := make(map[string]map[string]string)
+	add := func(id, k, v string) {
+		if id == "" || k == "" {
+			return
+		}
+		if out[id] == nil {
+			out[id] = make(map[string]string)
+		}
+		out[id][k] = v
+	}
 	var jrA struct {
 		TagResources []struct {
 			ResourceId  string `json:"ResourceId"`
@@ -1718,9 +1864,6 @@ func parseContentCodeName(content []byte) map[string]string {
 					id = parts[len(parts)-1]
 				}
 			}
-			if id == "" {
-				continue
-			}
 			for _, t := range tr.Tags {
 				k := t.Key
 				if k == "" {
@@ -1730,9 +1873,7 @@ func parseContentCodeName(content []byte) map[string]string {
 				if v == "" {
 					v = t.TagValue
 				}
-				if strings.EqualFold(k, "CodeName") || strings.EqualFold(k, "code_name") {
-					out[id] = v
-				}
+				add(id, k, v)
 			}
 		}
 	}
@@ -1749,9 +1890,6 @@ func parseContentCodeName(content []byte) map[string]string {
 	}
 	if err := json.Unmarshal(content, &jrB); err == nil && len(jrB.TagResources.TagResource) > 0 {
 		for _, tr := range jrB.TagResources.TagResource {
-			if tr.ResourceId == "" {
-				continue
-			}
 			k := tr.TagKey
 			if k == "" {
 				k = tr.Key
@@ -1760,13 +1898,12 @@ func parseContentCodeName(content []byte) map[string]string {
 			if v == "" {
 				v = tr.Value
 			}
-			if strings.EqualFold(k, "CodeName") || strings.EqualFold(k, "code_name") {
-				out[tr.ResourceId] = v
-			}
+			add(tr.ResourceId, k, v)
 		}
 	}
 	return out
 }
+
 func (a *Collector) buildMetricDimensions(accountID, namespace string, ids []string, dkey string, metricDims []string, meta map[string]interface{}) ([]map[string]string, []string) {
 	var dynamicDims []string
 	reserved := map[string]struct{}{
@@ -1857,6 +1994,7 @@ func (a *Collector) buildMetricDimensions(accountID, namespace string, ids []str
 }
 
 func (a *Collector) fetchAndRecordMetrics(
+	ctx context.Context,
 	client CMSClient,
 	account config.CloudAccount,
 	region, ns, m, dkey, rtype, period string,
@@ -1891,11 +2029,11 @@ func (a *Collector) fetchAndRecordMetrics(
 		}
 		req.Dimensions = string(dimsJSON)
 
-		a.processMetricBatch(client, req, dims, account, region, ns, m, dkey, rtype, dynamicDims, tags, stats, ctxLog)
+		a.processMetricBatch(ctx, client, req, dims, account, region, ns, m, dkey, rtype, dynamicDims, tags, stats, ctxLog)
 	}
 }
 
-func (a *Collector) processMetricBatch(client CMSClient, req *cms.DescribeMetricLastRequest, dims []map[string]string, account config.CloudAccount, region, ns, m, dkey, rtype string, dynamicDims []string, tags map[string]string, stats []string, ctxLog *logger.ContextLogger) {
+func (a *Collector) processMetricBatch(ctx context.Context, client CMSClient, req *cms.DescribeMetricLastRequest, dims []map[string]string, account config.CloudAccount, region, ns, m, dkey, rtype string, dynamicDims []string, tags map[string]string, stats []string, ctxLog *logger.ContextLogger) {
 	nextToken := ""
 	loopCount := 0
 	maxLoops := 100                         // 防止无限分页的安全上限
@@ -1916,37 +2054,14 @@ func (a *Collector) processMetricBatch(client CMSClient, req *cms.DescribeMetric
 			seenNextTokens[nextToken] = true
 			req.NextToken = nextToken
 		}
-		var resp *cms.DescribeMetricLastResponse
-		var callErr error
-		for attempt := 0; attempt < 5; attempt++ {
-			startReq := time.Now()
-			resp, callErr = client.DescribeMetricLast(req)
-			if callErr == nil {
-				metrics.RequestTotal.WithLabelValues("aliyun", "DescribeMetricLast", "success").Inc()
-				metrics.RequestDuration.WithLabelValues("aliyun", "DescribeMetricLast").Observe(time.Since(startReq).Seconds())
-				metrics.RecordRequest("aliyun", "DescribeMetricLast", "success")
-				break
-			}
-			status := common.ClassifyAliyunError(callErr)
-			metrics.RequestTotal.WithLabelValues("aliyun", "DescribeMetricLast", status).Inc()
-			metrics.RecordRequest("aliyun", "DescribeMetricLast", status)
-			if status == "auth_error" || status == "region_skip" {
-				ctxLog.Warnf("CMS DescribeMetricLast error status=%s: %v", status, callErr)
-				break
-			}
-			if status == "limit_error" {
-				// 记录限流指标
-				metrics.RateLimitTotal.WithLabelValues("aliyun", "DescribeMetricLast").Inc()
-			}
-
-			// 指数退避重试
-			sleep := time.Duration(200*(1<<attempt)) * time.Millisecond
-			if sleep > 5*time.Second {
-				sleep = 5 * time.Second
-			}
-			time.Sleep(sleep)
-		}
+		resp, callErr := common.Call(ctx, common.CallOptions{
+			Provider: "aliyun", AccountID: account.AccountID, Region: region, API: "DescribeMetricLast",
+			Attempts: 5, Retryable: common.RetryUnlessFatal,
+		}, func() (*cms.DescribeMetricLastResponse, error) {
+			return client.DescribeMetricLast(req)
+		})
 		if callErr != nil {
+			common.RecordTargetError("aliyun", account.AccountID, region, ns, common.ClassifyAliyunError(callErr))
 			ctxLog.Errorf("拉取指标失败 error=%v", callErr)
 			// API 调用失败时，不暴露指标（而不是设置 0 值）
 			// 根据 Prometheus 最佳实践：API 调用失败时，不应该暴露指标
@@ -1997,15 +2112,8 @@ func (a *Collector) processMetricBatch(client CMSClient, req *cms.DescribeMetric
 				labels := []string{"aliyun", account.AccountID, region, rtype, rid, ns, m, codeNameVal}
 				labels = append(labels, dynamicLabelValues...)
 				vec, count := metrics.NamespaceGauge(ns, m, dynamicDims...)
-				if len(labels) > count {
-					labels = labels[:count]
-				} else {
-					for len(labels) < count {
-						labels = append(labels, "")
-					}
-				}
-				vec.WithLabelValues(labels...).Set(0)
-				metrics.IncSampleCount(ns, 1)
+				vec.WithLabelValues(metrics.LabelValues(count, a.customLabels(account, region, rtype, rid), labels...)...).Set(0)
+				common.RecordTargetSamples("aliyun", account.AccountID, region, ns, 1)
 			}
 			continue
 		}
@@ -2051,18 +2159,12 @@ func (a *Collector) processMetricBatch(client CMSClient, req *cms.DescribeMetric
 				val *= scale
 			}
 
+			customLabels := a.customLabels(account, region, rtype, rid)
 			labels := []string{"aliyun", account.AccountID, region, rtype, rid, ns, m, codeNameVal}
 			labels = append(labels, dynamicLabelValues...)
 			vec, count := metrics.NamespaceGauge(ns, m, dynamicDims...)
-			if len(labels) > count {
-				labels = labels[:count]
-			} else {
-				for len(labels) < count {
-					labels = append(labels, "")
-				}
-			}
-			vec.WithLabelValues(labels...).Set(val)
-			metrics.IncSampleCount(ns, 1)
+			vec.WithLabelValues(metrics.LabelValues(count, customLabels, labels...)...).Set(val)
+			common.RecordTargetSamples("aliyun", account.AccountID, region, ns, 1)
 
 			// 估算阿里云 CLB 带宽利用率（基于配置的带宽上限）
 			if ns == "acs_slb_dashboard" {
@@ -2103,15 +2205,8 @@ func (a *Collector) processMetricBatch(client CMSClient, req *cms.DescribeMetric
 					uvec, ucount := metrics.NamespaceGauge(ns, metricName, dynamicDims...)
 					ulabels := []string{"aliyun", account.AccountID, region, rtype, rid, ns, metricName, codeNameVal}
 					ulabels = append(ulabels, dynamicLabelValues...)
-					if len(ulabels) > ucount {
-						ulabels = ulabels[:ucount]
-					} else {
-						for len(ulabels) < ucount {
-							ulabels = append(ulabels, "")
-						}
-					}
-					uvec.WithLabelValues(ulabels...).Set(util)
-					metrics.IncSampleCount(ns, 1)
+					uvec.WithLabelValues(metrics.LabelValues(ucount, customLabels, ulabels...)...).Set(util)
+					common.RecordTargetSamples("aliyun", account.AccountID, region, ns, 1)
 				}
 			}
 		nextPoint:
@@ -2123,7 +2218,6 @@ func (a *Collector) processMetricBatch(client CMSClient, req *cms.DescribeMetric
 		}
 		nextToken = resp.NextToken
 		ctxLog.Debugf("processMetricBatch 继续下一页 nextToken=%s loop=%d metric=%s", nextToken, loopCount, m)
-		time.Sleep(25 * time.Millisecond)
 	}
 	ctxLog.Debugf("processMetricBatch 完成 总循环次数=%d metric=%s", loopCount, m)
 }
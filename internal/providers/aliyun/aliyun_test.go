@@ -1,7 +1,10 @@
 package aliyun
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"multicloud-exporter/internal/config"
 	"multicloud-exporter/internal/providers/common"
 	"multicloud-exporter/internal/utils"
 	"testing"
@@ -90,7 +93,7 @@ func TestListIDsByCMS_ALB(t *testing.T) {
 		},
 	}
 	c := &Collector{}
-	ids := c.listIDsByCMS(mc, "cn-hangzhou", "acs_alb", "LoadBalancerActiveConnection", "loadBalancerId")
+	ids, _ := c.listIDsByCMS(context.Background(), mc, "acc", "cn-hangzhou", "acs_alb", "LoadBalancerActiveConnection", "loadBalancerId")
 	if len(ids) != 2 {
 		t.Fatalf("alb ids expected 2 got %d", len(ids))
 	}
@@ -103,7 +106,7 @@ func TestListIDsByCMS_NLB(t *testing.T) {
 		},
 	}
 	c := &Collector{}
-	ids := c.listIDsByCMS(mc, "cn-hangzhou", "acs_nlb", "InstanceActiveConnection", "instanceId")
+	ids, _ := c.listIDsByCMS(context.Background(), mc, "acc", "cn-hangzhou", "acs_nlb", "InstanceActiveConnection", "instanceId")
 	if len(ids) != 2 {
 		t.Fatalf("nlb ids expected 2 got %d", len(ids))
 	}
@@ -115,11 +118,18 @@ func TestListIDsByCMS_GWLB(t *testing.T) {
 			return &cms.DescribeMetricListResponse{Datapoints: `[{"instanceId":"gw-1"}]`}, nil
 		},
 	}
+	var traced []string
+	common.SetCallTracer(func(tr common.CallTrace) { traced = append(traced, tr.AccountID) })
+	defer common.SetCallTracer(nil)
 	c := &Collector{}
-	ids := c.listIDsByCMS(mc, "cn-hangzhou", "acs_gwlb", "ActiveConnection", "instanceId")
+	ids, _ := c.listIDsByCMS(context.Background(), mc, "acc", "cn-hangzhou", "acs_gwlb", "ActiveConnection", "instanceId")
 	if len(ids) != 1 || ids[0] != "gw-1" {
 		t.Fatalf("gwlb ids expected [gw-1] got %v", ids)
 	}
+	// 限流令牌按账号区分，CMS 枚举调用必须携带账号
+	if len(traced) != 1 || traced[0] != "acc" {
+		t.Fatalf("call account = %v", traced)
+	}
 }
 
 func TestClassifyAliyunError(t *testing.T) {
@@ -151,3 +161,35 @@ func TestAssignRegion(t *testing.T) {
 		t.Fatalf("assign single shard")
 	}
 }
+
+func TestListAliGWLBIDs_RegionStatusUsesListedCount(t *testing.T) {
+	mc := &mockCMSClient{
+		DescribeMetricListFunc: func(request *cms.DescribeMetricListRequest) (response *cms.DescribeMetricListResponse, err error) {
+			return &cms.DescribeMetricListResponse{Datapoints: `[{"instanceId":"gw-1"}]`}, nil
+		},
+	}
+	c := NewCollector(&config.Config{}, nil)
+	c.clientFactory = &mockClientFactory{cms: mc}
+	c.regionManager = common.NewRegionManager(common.RegionDiscoveryConfig{Enabled: true})
+	acc := config.CloudAccount{AccountID: "acc", Filters: &config.AccountFilters{
+		ResourceFilter: config.ResourceFilter{Exclude: &config.ResourceFilterRule{IDs: []string{"gw-1"}}},
+	}}
+
+	// 全部被过滤：区域仍按枚举总数标记为 active
+	if ids := c.listAliGWLBIDs(context.Background(), acc, "cn-hangzhou"); len(ids) != 0 {
+		t.Fatalf("filtered ids = %v", ids)
+	}
+	info, ok := c.regionManager.GetRegionInfo("aliyun", "acc", "cn-hangzhou", common.NamespaceAliyunGWLB)
+	if !ok || info.Status != common.RegionStatusActive || info.ResourceCount != 1 {
+		t.Fatalf("region info = %+v %v", info, ok)
+	}
+
+	// 枚举失败：不更新区域状态
+	mc.DescribeMetricListFunc = func(request *cms.DescribeMetricListRequest) (*cms.DescribeMetricListResponse, error) {
+		return nil, fmt.Errorf("Throttling")
+	}
+	c.listAliGWLBIDs(context.Background(), acc, "cn-beijing")
+	if _, ok := c.regionManager.GetRegionInfo("aliyun", "acc", "cn-beijing", common.NamespaceAliyunGWLB); ok {
+		t.Fatal("failed enumeration should not update region status")
+	}
+}
@@ -1,6 +1,7 @@
 package aliyun
 
 import (
+	"multicloud-exporter/internal/cache"
 	"multicloud-exporter/internal/config"
 	"testing"
 	"time"
@@ -21,3 +22,29 @@ func TestCacheTTL(t *testing.T) {
 		t.Fatalf("expired")
 	}
 }
+
+func TestCachedSubResourcesSurviveDiskCache(t *testing.T) {
+	dataDir := t.TempDir()
+	cache.Configure(&config.CacheConf{Backend: cache.BackendDisk}, dataDir)
+	defer cache.Configure(nil, "")
+
+	acc := config.CloudAccount{AccountID: "a"}
+	listeners := []map[string]string{{"port": "80", "protocol": "http"}}
+	a := NewCollector(&config.Config{}, nil)
+	a.setCachedIDs(acc, "cn", "acs_slb_dashboard", "clb", []string{"lb-1"}, map[string]interface{}{"lb-1": listeners})
+	if err := cache.FlushAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 模拟重启：重新配置后从磁盘加载
+	cache.Configure(&config.CacheConf{Backend: cache.BackendDisk}, dataDir)
+	b := NewCollector(&config.Config{}, nil)
+	ids, meta, ok := b.getCachedIDs(acc, "cn", "acs_slb_dashboard", "clb")
+	if !ok || len(ids) != 1 {
+		t.Fatalf("cached ids = %v %v", ids, ok)
+	}
+	sub, ok := meta["lb-1"].([]map[string]string)
+	if !ok || len(sub) != 1 || sub[0]["port"] != "80" {
+		t.Fatalf("sub resources = %#v", meta["lb-1"])
+	}
+}
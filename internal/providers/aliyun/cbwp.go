@@ -2,19 +2,17 @@ package aliyun
 
 import (
 	"context"
-	"strings"
 	"time"
 
 	"multicloud-exporter/internal/config"
 	"multicloud-exporter/internal/logger"
-	"multicloud-exporter/internal/metrics"
 	"multicloud-exporter/internal/providers/common"
 
 	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
 	"github.com/aliyun/alibaba-cloud-sdk-go/services/vpc"
 )
 
-func (a *Collector) listCBWPIDs(account config.CloudAccount, region string) []string {
+func (a *Collector) listCBWPIDs(ctx context.Context, account config.CloudAccount, region string) []string {
 	ctxLog := logger.NewContextLogger("Aliyun", "account_id", account.AccountID, "region", region)
 	ctxLog.Infof("枚举共享带宽包开始")
 	client, err := a.clientFactory.NewVPCClient(region, account.AccessKeyID, account.AccessKeySecret)
@@ -22,6 +20,7 @@ func (a *Collector) listCBWPIDs(account config.CloudAccount, region string) []st
 		return []string{}
 	}
 	var ids []string
+	names := make(map[string]string)
 	pageSize := 50
 	if a.cfg != nil {
 		if a.cfg.Server != nil && a.cfg.Server.PageSize > 0 {
@@ -40,48 +39,20 @@ func (a *Collector) listCBWPIDs(account config.CloudAccount, region string) []st
 		req.RegionId = region
 		req.PageSize = requests.NewInteger(pageSize)
 		req.PageNumber = requests.NewInteger(page)
-		var resp *vpc.DescribeCommonBandwidthPackagesResponse
-		var callErr error
-		for attempt := 0; attempt < 3; attempt++ {
-			start := time.Now()
-			resp, callErr = client.DescribeCommonBandwidthPackages(req)
-			if callErr == nil {
-				metrics.RequestTotal.WithLabelValues("aliyun", "DescribeCommonBandwidthPackages", "success").Inc()
-				metrics.RecordRequest("aliyun", "DescribeCommonBandwidthPackages", "success")
-				metrics.RequestDuration.WithLabelValues("aliyun", "DescribeCommonBandwidthPackages").Observe(time.Since(start).Seconds())
-				break
-			}
-			msg := callErr.Error()
-			if strings.Contains(msg, "InvalidParameter") || strings.Contains(msg, "InvalidPageSize") {
-				req.PageSize = requests.NewInteger(pageSize)
-				resp, callErr = client.DescribeCommonBandwidthPackages(req)
-				if callErr == nil {
-					metrics.RequestTotal.WithLabelValues("aliyun", "DescribeCommonBandwidthPackages", "success").Inc()
-					metrics.RecordRequest("aliyun", "DescribeCommonBandwidthPackages", "success")
-					metrics.RequestDuration.WithLabelValues("aliyun", "DescribeCommonBandwidthPackages").Observe(time.Since(start).Seconds())
-					break
-				}
-			}
+		resp, callErr := common.Call(ctx, common.CallOptions{
+			Provider: "aliyun", AccountID: account.AccountID, Region: region, API: "DescribeCommonBandwidthPackages",
+			Attempts: 3, Retryable: common.RetryUnlessFatal,
+		}, func() (*vpc.DescribeCommonBandwidthPackagesResponse, error) {
+			return client.DescribeCommonBandwidthPackages(req)
+		})
+		if callErr != nil {
 			status := common.ClassifyAliyunError(callErr)
-			metrics.RequestTotal.WithLabelValues("aliyun", "DescribeCommonBandwidthPackages", status).Inc()
-			metrics.RecordRequest("aliyun", "DescribeCommonBandwidthPackages", status)
-			if status == "limit_error" {
-				// 记录限流指标
-				metrics.RateLimitTotal.WithLabelValues("aliyun", "DescribeCommonBandwidthPackages").Inc()
-			}
-			if status == "region_skip" || status == "auth_error" {
+			if status == common.ErrorStatusRegion || status == common.ErrorStatusAuth {
 				ctxLog.Warnf("CBWP describe error page=%d status=%s: %v", page, status, callErr)
-				break
-			}
-			// 指数退避重试
-			sleep := time.Duration(200*(1<<attempt)) * time.Millisecond
-			if sleep > 5*time.Second {
-				sleep = 5 * time.Second
 			}
-			time.Sleep(sleep)
-		}
-		if callErr != nil {
-			break
+			common.RecordTargetError("aliyun", account.AccountID, region, common.NamespaceAliyunBandwidthPackage, status)
+			// 枚举失败：不更新区域状态，避免把区域误标为无资源
+			return []string{}
 		}
 		if resp == nil {
 			break
@@ -91,6 +62,7 @@ func (a *Collector) listCBWPIDs(account config.CloudAccount, region string) []st
 		}
 		for _, pkg := range resp.CommonBandwidthPackages.CommonBandwidthPackage {
 			ids = append(ids, pkg.BandwidthPackageId)
+			names[pkg.BandwidthPackageId] = pkg.Name
 		}
 
 		// 使用 TotalCount 和当前已获取的数量来判断是否还有更多数据
@@ -120,7 +92,6 @@ func (a *Collector) listCBWPIDs(account config.CloudAccount, region string) []st
 		// 继续下一页
 		page++
 		ctxLog.Debugf("CBWP 分页采集 page=%d current_count=%d total_collected=%d", page, currentCount, len(ids))
-		time.Sleep(50 * time.Millisecond)
 	}
 	// 打印缩略的 ID 列表，便于定位
 	if len(ids) > 0 {
@@ -135,29 +106,22 @@ func (a *Collector) listCBWPIDs(account config.CloudAccount, region string) []st
 	}
 
 	// 更新区域状态
-	if a.regionManager != nil {
-		status := common.RegionStatusEmpty
-		if len(ids) > 0 {
-			status = common.RegionStatusActive
-		}
-		a.regionManager.UpdateRegionStatus(account.AccountID, region, len(ids), status)
-		ctxLog.Debugf("更新区域状态 account=%s region=%s status=%s count=%d",
-			account.AccountID, region, status, len(ids))
-	}
+	common.ReportRegionResources(a.regionManager, "aliyun", account.AccountID, region, common.NamespaceAliyunBandwidthPackage, len(ids))
 
-	return ids
+	// 资源过滤：区域状态按枚举总数更新，过滤后的资源不再调用监控 API
+	return a.filterResourceIDs(ctx, account, region, "cbwp", ids, names)
 }
 
-func (a *Collector) fetchCBWPTags(account config.CloudAccount, region string, ids []string) map[string]string {
-	// 仅提取共享带宽包的 CodeName 标签：
-	// 返回值为带宽包ID到 CodeName 文本的映射，用于在指标的 code_name 标签中展示。
+func (a *Collector) fetchCBWPTags(ctx context.Context, account config.CloudAccount, region string, ids []string) map[string]string {
+	// 拉取共享带宽包的完整标签并按 code_name 解析链生成 code_name：
+	// 返回值为带宽包ID到 code_name 文本的映射，用于在指标的 code_name 标签中展示。
 	if len(ids) == 0 {
 		return map[string]string{}
 	}
 
 	// 添加超时保护：整个标签获取操作最多 30 秒
 	const timeout = 30 * time.Second
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	ctxLog := logger.NewContextLogger("Aliyun", "account_id", account.AccountID, "region", region, "rtype", "cbwp")
@@ -168,6 +132,7 @@ func (a *Collector) fetchCBWPTags(account config.CloudAccount, region string, id
 		return map[string]string{}
 	}
 	out := make(map[string]string, len(ids))
+	fetched := newFetchedTags()
 	// 预先初始化所有 ID 为空字符串，确保所有 ID 都有记录
 	for _, id := range ids {
 		out[id] = ""
@@ -179,7 +144,7 @@ func (a *Collector) fetchCBWPTags(account config.CloudAccount, region string, id
 		select {
 		case <-ctx.Done():
 			ctxLog.Warnf("获取 CodeName 标签超时，已处理 %d/%d 批次，返回部分结果", batchCount, (len(ids)+batchSize-1)/batchSize)
-			return out
+			return a.applyFetchedTags(account, region, "cbwp", ids, fetched, out)
 		default:
 			// 继续处理
 		}
@@ -203,65 +168,21 @@ func (a *Collector) fetchCBWPTags(account config.CloudAccount, region string, id
 			select {
 			case <-ctx.Done():
 				ctxLog.Warnf("批次 %d 获取标签时超时，跳过后续处理", batchCount)
-				return out
+				return a.applyFetchedTags(account, region, "cbwp", ids, fetched, out)
 			default:
 			}
 
 			if nextToken != "" {
 				req.NextToken = nextToken
 			}
-			var resp *vpc.ListTagResourcesResponse
-			var callErr error
-			for attempt := 0; attempt < 3; attempt++ {
-				// 每次重试前检查超时
-				select {
-				case <-ctx.Done():
-					ctxLog.Warnf("批次 %d 重试第 %d 次时超时，放弃", batchCount, attempt+1)
-					return out
-				default:
-				}
-
-				startReq := time.Now()
-				resp, callErr = client.ListTagResources(req)
-				if callErr == nil {
-					metrics.RequestTotal.WithLabelValues("aliyun", "ListTagResources", "success").Inc()
-					metrics.RequestDuration.WithLabelValues("aliyun", "ListTagResources").Observe(time.Since(startReq).Seconds())
-					break
-				}
-				msg := callErr.Error()
-				status := "error"
-				if strings.Contains(msg, "Throttling") || strings.Contains(msg, "flow control") {
-					status = "limit_error"
-					// 记录限流指标
-					metrics.RateLimitTotal.WithLabelValues("aliyun", "ListTagResources").Inc()
-				} else if strings.Contains(msg, "timeout") || strings.Contains(msg, "Temporary network") || strings.Contains(msg, "unreachable") {
-					status = "network_error"
-				} else if strings.Contains(msg, "InvalidAccessKeyId") || strings.Contains(msg, "Forbidden") || strings.Contains(msg, "SignatureDoesNotMatch") {
-					status = "auth_error"
-				}
-				metrics.RequestTotal.WithLabelValues("aliyun", "ListTagResources", status).Inc()
-				if status == "auth_error" {
-					break
-				}
-				// 指数退避重试，但考虑剩余时间
-				sleep := time.Duration(200*(1<<attempt)) * time.Millisecond
-				if sleep > 5*time.Second {
-					sleep = 5 * time.Second
-				}
-
-				// 使用 context 计算剩余时间，如果 sleep 会超时则缩短
-				// 注意：这里不使用 context.WithDeadline，因为需要等待 sleep
-				// 而是选择较短的 sleep 时间
-				deadline, ok := ctx.Deadline()
-				if ok && time.Now().Add(sleep).After(deadline) {
-					remaining := time.Until(deadline)
-					if remaining > 0 && remaining < sleep {
-						sleep = remaining
-					} else if remaining <= 0 {
-						break
-					}
-				}
-				time.Sleep(sleep)
+			resp, callErr := common.Call(ctx, common.CallOptions{
+				Provider: "aliyun", AccountID: account.AccountID, Region: region, API: "ListTagResources",
+				Attempts: 3, Retryable: common.RetryUnlessFatal,
+			}, func() (*vpc.ListTagResourcesResponse, error) {
+				return client.ListTagResources(req)
+			})
+			if callErr != nil && common.ClassifyAliyunError(callErr) == common.ErrorStatusAuth {
+				common.RecordTargetError("aliyun", account.AccountID, region, common.NamespaceAliyunBandwidthPackage, common.ErrorStatusAuth)
 			}
 			if callErr != nil {
 				ctxLog.Warnf("批次 %d 获取 CodeName 标签失败 error=%v batch=%v", batchCount, callErr, batch)
@@ -272,28 +193,24 @@ func (a *Collector) fetchCBWPTags(account config.CloudAccount, region string, id
 				break
 			}
 			batchSuccess = true
-			// 遍历标签资源，仅保留 TagKey=="CodeName" 的键值
+			// 记录完整标签，code_name 在全部批次完成后统一解析
 			for _, tr := range resp.TagResources.TagResource {
 				rid := tr.ResourceId
 				if rid == "" {
 					continue
 				}
-				if tr.TagKey == "CodeName" {
-					out[rid] = tr.TagValue
-					ctxLog.Debugf("找到 CodeName 标签 resource_id=%s code_name=%s", rid, tr.TagValue)
-				}
+				fetched.add(rid, tr.TagKey, tr.TagValue)
 			}
 			if resp.NextToken == "" {
 				break
 			}
 			nextToken = resp.NextToken
-			time.Sleep(25 * time.Millisecond)
 		}
 		if !batchSuccess {
 			ctxLog.Warnf("批次 %d 完全失败，这些 ID 的 code_name 将为空: %v", batchCount, batch)
 		}
-		time.Sleep(25 * time.Millisecond)
 	}
+	a.applyFetchedTags(account, region, "cbwp", ids, fetched, out)
 	withCodeName := 0
 	withoutCodeName := 0
 	emptyIDs := []string{}
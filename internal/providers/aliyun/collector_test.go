@@ -1,6 +1,7 @@
 package aliyun
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"path/filepath"
@@ -36,7 +37,7 @@ func TestCollector_getAccountUID(t *testing.T) {
 	c := NewCollector(&config.Config{}, nil)
 	c.clientFactory = factory
 
-	uid := c.getAccountUID(config.CloudAccount{
+	uid := c.getAccountUID(context.Background(), config.CloudAccount{
 		AccountID:       "test-account",
 		AccessKeyID:     "ak",
 		AccessKeySecret: "sk",
@@ -48,7 +49,7 @@ func TestCollector_getAccountUID(t *testing.T) {
 	mockSTS.GetCallerIdentityFunc = func(request *sts.GetCallerIdentityRequest) (*sts.GetCallerIdentityResponse, error) {
 		return nil, fmt.Errorf("should not be called")
 	}
-	uidCached := c.getAccountUID(config.CloudAccount{
+	uidCached := c.getAccountUID(context.Background(), config.CloudAccount{
 		AccountID:       "test-account",
 		AccessKeyID:     "ak",
 		AccessKeySecret: "sk",
@@ -66,7 +67,7 @@ func TestCollector_getAccountUID_Error(t *testing.T) {
 	c := NewCollector(&config.Config{}, nil)
 	c.clientFactory = factory
 
-	uid := c.getAccountUID(config.CloudAccount{
+	uid := c.getAccountUID(context.Background(), config.CloudAccount{
 		AccountID:       "test-account",
 		AccessKeyID:     "ak",
 		AccessKeySecret: "sk",
@@ -92,7 +93,7 @@ func TestCollector_getAllRegions(t *testing.T) {
 	c := NewCollector(&config.Config{}, nil)
 	c.clientFactory = factory
 
-	regions := c.getAllRegions(config.CloudAccount{
+	regions := c.getAllRegions(context.Background(), config.CloudAccount{
 		AccessKeyID: "ak",
 	})
 	assert.Len(t, regions, 2)
@@ -110,7 +111,7 @@ func TestCollector_getAllRegions_Error(t *testing.T) {
 	c := NewCollector(&config.Config{}, nil)
 	c.clientFactory = factory
 
-	regions := c.getAllRegions(config.CloudAccount{
+	regions := c.getAllRegions(context.Background(), config.CloudAccount{
 		AccessKeyID: "ak",
 		AccountID:   "acc",
 	})
@@ -290,7 +291,7 @@ func TestCollector_Collect(t *testing.T) {
 	c.clientFactory = factory
 
 	// Execute Collect
-	c.Collect(config.CloudAccount{
+	c.Collect(context.Background(), config.CloudAccount{
 		AccountID:       "test-acc",
 		AccessKeyID:     "ak",
 		AccessKeySecret: "sk",
@@ -397,7 +398,7 @@ func TestCollector_ALIYUN_CLB_Utilization_Estimate(t *testing.T) {
 	})
 	c := NewCollector(cfg, mgr)
 	c.clientFactory = factory
-	c.Collect(config.CloudAccount{
+	c.Collect(context.Background(), config.CloudAccount{
 		AccountID:       "test-acc",
 		AccessKeyID:     "ak",
 		AccessKeySecret: "sk",
@@ -1,10 +1,13 @@
 package aliyun
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"multicloud-exporter/internal/cache"
 	"multicloud-exporter/internal/config"
 	"multicloud-exporter/internal/logger"
+	"multicloud-exporter/internal/providers/common"
 	"os"
 	"testing"
 
@@ -20,7 +23,7 @@ import (
 
 func TestListOSSIDs_Pagination(t *testing.T) {
 	c := NewCollector(&config.Config{}, nil)
-	c.ossCache = make(map[string]ossCacheEntry)
+	c.ossCache = cache.NewMemory(cache.Options{})
 
 	mockOSS := &mockOSSClient{}
 	callCount := 0
@@ -40,7 +43,7 @@ func TestListOSSIDs_Pagination(t *testing.T) {
 	}
 
 	c.clientFactory = &mockClientFactory{oss: mockOSS}
-	ids := c.listOSSIDs(config.CloudAccount{AccountID: "acc2"}, "cn-hangzhou")
+	ids := c.listOSSIDs(context.Background(), config.CloudAccount{AccountID: "acc2"}, "cn-hangzhou")
 	assert.Len(t, ids, 2)
 	assert.Contains(t, ids, "b1")
 	assert.Contains(t, ids, "b2")
@@ -84,7 +87,7 @@ func TestListCBWPIDs(t *testing.T) {
 	}
 
 	c.clientFactory = &mockClientFactory{vpc: mockVPC}
-	ids := c.listCBWPIDs(config.CloudAccount{AccountID: "acc1"}, "cn-hangzhou")
+	ids := c.listCBWPIDs(context.Background(), config.CloudAccount{AccountID: "acc1"}, "cn-hangzhou")
 	assert.Len(t, ids, 2)
 	assert.Contains(t, ids, "cbwp-1")
 	assert.Contains(t, ids, "cbwp-2")
@@ -96,7 +99,7 @@ func TestFetchCBWPTags(t *testing.T) {
 	c.clientFactory = &mockClientFactory{vpc: mockVPC}
 
 	// Case 1: Empty IDs
-	tags := c.fetchCBWPTags(config.CloudAccount{}, "cn-hangzhou", nil)
+	tags := c.fetchCBWPTags(context.Background(), config.CloudAccount{}, "cn-hangzhou", nil)
 	assert.Empty(t, tags)
 
 	// Case 2: Success with CodeName
@@ -108,14 +111,14 @@ func TestFetchCBWPTags(t *testing.T) {
 		}
 		return resp, nil
 	}
-	tags = c.fetchCBWPTags(config.CloudAccount{}, "cn-hangzhou", []string{"cbwp-1"})
+	tags = c.fetchCBWPTags(context.Background(), config.CloudAccount{}, "cn-hangzhou", []string{"cbwp-1"})
 	assert.Equal(t, "test-code", tags["cbwp-1"])
 
 	// Case 3: Error
 	mockVPC.ListTagResourcesFunc = func(request *vpc.ListTagResourcesRequest) (*vpc.ListTagResourcesResponse, error) {
 		return nil, fmt.Errorf("error")
 	}
-	tags = c.fetchCBWPTags(config.CloudAccount{}, "cn-hangzhou", []string{"cbwp-1"})
+	tags = c.fetchCBWPTags(context.Background(), config.CloudAccount{}, "cn-hangzhou", []string{"cbwp-1"})
 	// 修复后行为：即使 API 失败，也会返回包含空字符串的 map，确保所有 ID 都有记录
 	assert.Equal(t, "", tags["cbwp-1"])
 }
@@ -141,7 +144,7 @@ func TestListSLBIDs(t *testing.T) {
 	}
 
 	c.clientFactory = &mockClientFactory{slb: mockSLB}
-	ids, meta := c.listSLBIDs(config.CloudAccount{AccountID: "acc1"}, "cn-hangzhou")
+	ids, meta := c.listSLBIDs(context.Background(), config.CloudAccount{AccountID: "acc1"}, "cn-hangzhou")
 	assert.Len(t, ids, 1)
 	assert.Equal(t, "lb-1", ids[0])
 
@@ -196,7 +199,7 @@ func TestListSLBIDs_Pagination(t *testing.T) {
 	}
 
 	c.clientFactory = &mockClientFactory{slb: mockSLB}
-	ids, _ := c.listSLBIDs(config.CloudAccount{AccountID: "acc1"}, "cn-hangzhou")
+	ids, _ := c.listSLBIDs(context.Background(), config.CloudAccount{AccountID: "acc1"}, "cn-hangzhou")
 	assert.Len(t, ids, 2, "Should collect all 2 LBs using TotalCount pagination")
 	assert.Contains(t, ids, "lb-1")
 	assert.Contains(t, ids, "lb-2")
@@ -235,14 +238,15 @@ func TestFetchSLBTags(t *testing.T) {
 		sts: mockSTS,
 	}
 
-	tags := c.fetchSLBTags(config.CloudAccount{AccountID: "acc1"}, "cn-hangzhou", "ns", "metric", []string{"lb-1"})
+	tags := c.fetchSLBTags(context.Background(), config.CloudAccount{AccountID: "acc1"}, "cn-hangzhou", "ns", "metric", []string{"lb-1"})
 	assert.Equal(t, "test-name", tags["lb-1"])
 }
 
-func TestParseSLBTagsContent(t *testing.T) {
+func TestParseTagResourcesContent(t *testing.T) {
 	content := `{"TagResources":{"TagResource":[{"ResourceId":"lb-1","TagKey":"code_name","TagValue":"v"}]}}`
-	tags := parseSLBTagsContent([]byte(content))
-	assert.Equal(t, "v", tags["lb-1"])
+	tags := parseTagResourcesContent([]byte(content))
+	assert.Equal(t, "v", tags["lb-1"]["code_name"])
+	assert.Equal(t, "v", common.ResolveCodeName(nil, common.DefaultCodeNameChain, tags["lb-1"], "", "lb-1"))
 }
 
 func TestBuildMetricDimensions(t *testing.T) {
@@ -292,7 +296,7 @@ func TestProcessMetricBatch(t *testing.T) {
 	mockCMS.DescribeMetricLastFunc = func(request *cms.DescribeMetricLastRequest) (*cms.DescribeMetricLastResponse, error) {
 		return nil, fmt.Errorf("cms error")
 	}
-	c.processMetricBatch(mockCMS, req, dims, config.CloudAccount{AccountID: "test-acc"}, "cn-hangzhou", "acs_ecs_dashboard", "CPU", "instanceId", "ecs", nil, nil, nil, ctxLog)
+	c.processMetricBatch(context.Background(), mockCMS, req, dims, config.CloudAccount{AccountID: "test-acc"}, "cn-hangzhou", "acs_ecs_dashboard", "CPU", "instanceId", "ecs", nil, nil, nil, ctxLog)
 
 	// Case 2: Success with data
 	mockCMS.DescribeMetricLastFunc = func(request *cms.DescribeMetricLastRequest) (*cms.DescribeMetricLastResponse, error) {
@@ -304,7 +308,7 @@ func TestProcessMetricBatch(t *testing.T) {
 		resp.Datapoints = string(data)
 		return resp, nil
 	}
-	c.processMetricBatch(mockCMS, req, dims, config.CloudAccount{AccountID: "test-acc"}, "cn-hangzhou", "acs_ecs_dashboard", "CPU", "instanceId", "ecs", nil, nil, []string{"Average"}, ctxLog)
+	c.processMetricBatch(context.Background(), mockCMS, req, dims, config.CloudAccount{AccountID: "test-acc"}, "cn-hangzhou", "acs_ecs_dashboard", "CPU", "instanceId", "ecs", nil, nil, []string{"Average"}, ctxLog)
 
 	// Case 3: JSON error
 	mockCMS.DescribeMetricLastFunc = func(request *cms.DescribeMetricLastRequest) (*cms.DescribeMetricLastResponse, error) {
@@ -312,7 +316,7 @@ func TestProcessMetricBatch(t *testing.T) {
 		resp.Datapoints = "invalid-json"
 		return resp, nil
 	}
-	c.processMetricBatch(mockCMS, req, dims, config.CloudAccount{AccountID: "test-acc"}, "cn-hangzhou", "acs_ecs_dashboard", "CPU", "instanceId", "ecs", nil, nil, []string{"Average"}, ctxLog)
+	c.processMetricBatch(context.Background(), mockCMS, req, dims, config.CloudAccount{AccountID: "test-acc"}, "cn-hangzhou", "acs_ecs_dashboard", "CPU", "instanceId", "ecs", nil, nil, []string{"Average"}, ctxLog)
 }
 
 func TestChooseStatistics(t *testing.T) {
@@ -343,7 +347,7 @@ func TestGetAccountUID(t *testing.T) {
 		resp.AccountId = "uid-123"
 		return resp, nil
 	}
-	uid := c.getAccountUID(acc, "cn-hangzhou")
+	uid := c.getAccountUID(context.Background(), acc, "cn-hangzhou")
 	assert.Equal(t, "uid-123", uid)
 
 	// Case 2: Cache Hit
@@ -351,12 +355,12 @@ func TestGetAccountUID(t *testing.T) {
 	mockSTS.GetCallerIdentityFunc = func(request *sts.GetCallerIdentityRequest) (*sts.GetCallerIdentityResponse, error) {
 		return nil, fmt.Errorf("error")
 	}
-	uid = c.getAccountUID(acc, "cn-hangzhou")
+	uid = c.getAccountUID(context.Background(), acc, "cn-hangzhou")
 	assert.Equal(t, "uid-123", uid)
 
 	// Case 3: STS Error (new account)
 	acc2 := config.CloudAccount{AccountID: "acc2", AccessKeyID: "ak2"}
-	uid = c.getAccountUID(acc2, "cn-hangzhou")
+	uid = c.getAccountUID(context.Background(), acc2, "cn-hangzhou")
 	assert.Equal(t, "acc2", uid) // Fallback to config AccountID
 }
 
@@ -375,7 +379,7 @@ func TestGetAllRegions(t *testing.T) {
 		}
 		return resp, nil
 	}
-	regions := c.getAllRegions(acc)
+	regions := c.getAllRegions(context.Background(), acc)
 	assert.Len(t, regions, 2)
 	assert.Contains(t, regions, "cn-hangzhou")
 
@@ -383,7 +387,7 @@ func TestGetAllRegions(t *testing.T) {
 	mockECS.DescribeRegionsFunc = func(request *ecs.DescribeRegionsRequest) (*ecs.DescribeRegionsResponse, error) {
 		return nil, fmt.Errorf("error")
 	}
-	regions = c.getAllRegions(acc)
+	regions = c.getAllRegions(context.Background(), acc)
 	assert.Len(t, regions, 1)
 	assert.Equal(t, "cn-hangzhou", regions[0])
 
@@ -392,7 +396,7 @@ func TestGetAllRegions(t *testing.T) {
 		t.Fatal(err)
 	}
 	defer func() { _ = os.Unsetenv("DEFAULT_REGIONS") }()
-	regions = c.getAllRegions(acc)
+	regions = c.getAllRegions(context.Background(), acc)
 	assert.Len(t, regions, 2)
 	assert.Contains(t, regions, "cn-shanghai")
 }
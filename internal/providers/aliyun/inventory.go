@@ -0,0 +1,47 @@
+package aliyun
+
+import (
+	"multicloud-exporter/internal/config"
+	"multicloud-exporter/internal/providers/common"
+)
+
+// Inventory 返回资源缓存中的资源清单，标签与名称取自最近一次拉取结果
+func (a *Collector) Inventory() []common.InventoryItem {
+	var out []common.InventoryItem
+	for _, key := range a.resCache.Keys("") {
+		var entry resCacheEntry
+		if !a.resCache.Get(key, &entry) {
+			continue
+		}
+		accountID, region, namespace, rtype, ok := common.SplitCacheKey(key)
+		if !ok {
+			continue
+		}
+		account := config.CloudAccount{AccountID: accountID}
+		tags := a.recordedTags(account, region, rtype, entry.IDs)
+		a.tagMu.RLock()
+		names := a.resNames[accountID+":"+region+":"+tagResourceType(rtype)]
+		a.tagMu.RUnlock()
+		for _, id := range entry.IDs {
+			it := common.InventoryItem{
+				Provider:     "aliyun",
+				AccountID:    accountID,
+				Region:       region,
+				Namespace:    namespace,
+				ResourceType: rtype,
+				ResourceID:   id,
+				Name:         names[id],
+				UpdatedAt:    entry.UpdatedAt,
+			}
+			if len(tags[id]) > 0 {
+				it.Tags = tags[id]
+			}
+			it.CodeName = common.ResolveCodeName(a.cfg, common.DefaultCodeNameChain, it.Tags, it.Name, id)
+			if sub, ok := entry.Meta[id]; ok {
+				it.Metadata = map[string]interface{}{"sub_resources": sub}
+			}
+			out = append(out, it)
+		}
+	}
+	return out
+}
@@ -1,20 +1,18 @@
 package aliyun
 
 import (
+	"context"
 	"strings"
 	"sync"
-	"time"
 
 	"multicloud-exporter/internal/config"
 	"multicloud-exporter/internal/logger"
-	"multicloud-exporter/internal/metrics"
 	"multicloud-exporter/internal/providers/common"
-	"multicloud-exporter/internal/utils"
 
 	"github.com/aliyun/aliyun-oss-go-sdk/oss"
 )
 
-func (a *Collector) listOSSIDs(account config.CloudAccount, region string) []string {
+func (a *Collector) listOSSIDs(ctx context.Context, account config.CloudAccount, region string) []string {
 	ctxLog := logger.NewContextLogger("Aliyun", "account_id", account.AccountID, "region", region)
 
 	// Check region-level cache first (consistent with other resources)
@@ -36,31 +34,13 @@ func (a *Collector) listOSSIDs(account config.CloudAccount, region string) []str
 
 	// Use account-level cache to avoid duplicate ListBuckets calls across regions
 	// OSS ListBuckets is a global operation, so we cache all buckets at account level
-	a.ossMu.Lock()
-	entry, ok := a.ossCache[account.AccountID]
-	a.ossMu.Unlock()
-
+	// (entries expire after discovery_ttl)
 	var allBuckets []ossBucketInfo
 	cachedFromAccountLevel := false
 
-	// TTL Logic
-	ttlDur := time.Hour
-	if a.cfg != nil {
-		if a.cfg.Server != nil && a.cfg.Server.DiscoveryTTL != "" {
-			if d, err := utils.ParseDuration(a.cfg.Server.DiscoveryTTL); err == nil {
-				ttlDur = d
-			}
-		} else if server := a.cfg.GetServer(); server != nil && server.DiscoveryTTL != "" {
-			if d, err := utils.ParseDuration(server.DiscoveryTTL); err == nil {
-				ttlDur = d
-			}
-		}
-	}
-
-	valid := ok && time.Since(entry.UpdatedAt) < ttlDur
+	valid := a.ossCache.Get(account.AccountID, &allBuckets)
 
 	if valid {
-		allBuckets = entry.Buckets
 		cachedFromAccountLevel = true
 		ctxLog.Debugf("OSS 账号级缓存命中 account=%s total_buckets=%d", account.AccountID, len(allBuckets))
 	} else {
@@ -69,12 +49,10 @@ func (a *Collector) listOSSIDs(account config.CloudAccount, region string) []str
 		key := "oss_list_buckets_" + account.AccountID
 		val, err, _ := a.sf.Do(key, func() (interface{}, error) {
 			// Double-check cache inside singleflight to ensure we don't fetch if just updated
-			a.ossMu.Lock()
-			if e, ok := a.ossCache[account.AccountID]; ok && time.Since(e.UpdatedAt) < ttlDur {
-				a.ossMu.Unlock()
-				return e.Buckets, nil
+			var cached []ossBucketInfo
+			if a.ossCache.Get(account.AccountID, &cached) {
+				return cached, nil
 			}
-			a.ossMu.Unlock()
 
 			// Fetch from API
 			// OSS ListBuckets is a global operation, but we need an endpoint.
@@ -88,41 +66,19 @@ func (a *Collector) listOSSIDs(account config.CloudAccount, region string) []str
 			var buckets []ossBucketInfo
 			marker := ""
 			for {
-				var lsRes oss.ListBucketsResult
-				var callErr error
-				start := time.Now()
-
-				// Retry logic with exponential backoff (consistent with AWS S3)
-				for attempt := 0; attempt < 5; attempt++ {
-					lsRes, callErr = client.ListBuckets(oss.Marker(marker), oss.MaxKeys(100))
-					if callErr == nil {
-						metrics.RequestTotal.WithLabelValues("aliyun", "ListBuckets", "success").Inc()
-						metrics.RecordRequest("aliyun", "ListBuckets", "success")
-						metrics.RequestDuration.WithLabelValues("aliyun", "ListBuckets").Observe(time.Since(start).Seconds())
-						break
-					}
-					status := common.ClassifyAliyunError(callErr)
-					metrics.RequestTotal.WithLabelValues("aliyun", "ListBuckets", status).Inc()
-					metrics.RecordRequest("aliyun", "ListBuckets", status)
-					if status == "limit_error" {
-						metrics.RateLimitTotal.WithLabelValues("aliyun", "ListBuckets").Inc()
-					}
-					if status == "auth_error" {
-						ctxLog.Errorf("OSS ListBuckets 认证失败 account=%s region=%s: %v", account.AccountID, region, callErr)
-						return nil, callErr
-					}
-					if attempt < 4 {
-						sleep := time.Duration(200*(1<<attempt)) * time.Millisecond
-						if sleep > 5*time.Second {
-							sleep = 5 * time.Second
-						}
-						ctxLog.Debugf("OSS ListBuckets 重试 account=%s region=%s attempt=%d/%d sleep=%v",
-							account.AccountID, region, attempt+1, 5, sleep)
-						time.Sleep(sleep)
-					}
+				lsRes, callErr := common.Call(ctx, common.CallOptions{
+					Provider: "aliyun", AccountID: account.AccountID, Region: region, API: "ListBuckets",
+					Attempts: 5, Retryable: common.RetryUnlessFatal,
+				}, func() (oss.ListBucketsResult, error) {
+					return client.ListBuckets(oss.Marker(marker), oss.MaxKeys(100), oss.WithContext(ctx))
+				})
+				if callErr != nil && common.ClassifyAliyunError(callErr) == common.ErrorStatusAuth {
+					common.RecordTargetError("aliyun", account.AccountID, region, common.NamespaceAliyunOSSDashboard, common.ErrorStatusAuth)
+					ctxLog.Errorf("OSS ListBuckets 认证失败 account=%s region=%s: %v", account.AccountID, region, callErr)
+					return nil, callErr
 				}
-
 				if callErr != nil {
+					common.RecordTargetError("aliyun", account.AccountID, region, common.NamespaceAliyunOSSDashboard, common.ClassifyAliyunError(callErr))
 					ctxLog.Errorf("OSS ListBuckets 失败 account=%s region=%s: %v", account.AccountID, region, callErr)
 					return nil, callErr
 				}
@@ -141,12 +97,7 @@ func (a *Collector) listOSSIDs(account config.CloudAccount, region string) []str
 			}
 
 			if len(buckets) > 0 {
-				a.ossMu.Lock()
-				a.ossCache[account.AccountID] = ossCacheEntry{
-					Buckets:   buckets,
-					UpdatedAt: time.Now(),
-				}
-				a.ossMu.Unlock()
+				_ = a.ossCache.Set(account.AccountID, buckets, a.discoveryTTL())
 				ctxLog.Debugf("OSS ListBuckets API 调用成功 account=%s total_buckets=%d", account.AccountID, len(buckets))
 			} else {
 				ctxLog.Debugf("OSS ListBuckets API 调用成功 account=%s total_buckets=0", account.AccountID)
@@ -174,6 +125,12 @@ func (a *Collector) listOSSIDs(account config.CloudAccount, region string) []str
 		}
 	}
 
+	// 区域状态按枚举总数更新
+	common.ReportRegionResources(a.regionManager, "aliyun", account.AccountID, region, common.NamespaceAliyunOSSDashboard, len(regionBuckets))
+
+	// 资源过滤：存储桶名称即资源 ID
+	regionBuckets = a.filterResourceIDs(ctx, account, region, "oss", regionBuckets, nil)
+
 	// Cache the filtered result at region level (consistent with other resources)
 	a.setCachedIDs(account, region, "acs_oss_dashboard", "oss", regionBuckets, nil)
 
@@ -193,9 +150,9 @@ func (a *Collector) listOSSIDs(account config.CloudAccount, region string) []str
 	return regionBuckets
 }
 
-func (a *Collector) fetchOSSBucketTags(account config.CloudAccount, region string, buckets []string) map[string]string {
+func (a *Collector) fetchOSSBucketTags(ctx context.Context, account config.CloudAccount, region string, buckets []string) map[string]string {
 	out := make(map[string]string, len(buckets))
-	var mu sync.Mutex
+	fetched := newFetchedTags()
 	client, err := a.clientFactory.NewOSSClient(region, account.AccessKeyID, account.AccessKeySecret)
 	if err != nil {
 		return out
@@ -209,20 +166,19 @@ func (a *Collector) fetchOSSBucketTags(account config.CloudAccount, region strin
 		go func(bucket string) {
 			defer wg.Done()
 			defer func() { <-sem }()
-			res, err := client.GetBucketTagging(bucket)
+			res, err := common.Call(ctx, common.CallOptions{
+				Provider: "aliyun", AccountID: account.AccountID, Region: region, API: "GetBucketTagging",
+			}, func() (oss.GetBucketTaggingResult, error) {
+				return client.GetBucketTagging(bucket)
+			})
 			if err != nil {
 				return
 			}
 			for _, t := range res.Tags {
-				if strings.EqualFold(t.Key, "CodeName") || strings.EqualFold(t.Key, "code_name") {
-					mu.Lock()
-					out[bucket] = t.Value
-					mu.Unlock()
-					break
-				}
+				fetched.add(bucket, t.Key, t.Value)
 			}
 		}(b)
 	}
 	wg.Wait()
-	return out
+	return a.applyFetchedTags(account, region, "oss", buckets, fetched, out)
 }
@@ -4,6 +4,7 @@ import (
 	"multicloud-exporter/internal/config"
 	"multicloud-exporter/internal/discovery"
 	"multicloud-exporter/internal/providers"
+	"multicloud-exporter/internal/providers/common"
 )
 
 // GetDefaultResources 返回阿里云默认采集的资源类型
@@ -11,6 +12,21 @@ func (a *Collector) GetDefaultResources() []string {
 	return []string{"bwp", "clb", "s3", "alb", "nlb", "gwlb"}
 }
 
+// SupportedResources 返回可在 resources 中配置的资源类型（含别名）
+func (a *Collector) SupportedResources() []string {
+	return []string{"bwp", "cbwp", "clb", "alb", "nlb", "gwlb", "s3", "oss"}
+}
+
+// ValidRegion 校验区域名称格式
+func (a *Collector) ValidRegion(region string) bool {
+	return providers.IsRegionName(region)
+}
+
+// Scheduler 返回产品级采集调度器
+func (a *Collector) Scheduler() *common.ProductScheduler {
+	return a.scheduler
+}
+
 func init() {
 	providers.Register("aliyun", func(cfg *config.Config, mgr *discovery.Manager) providers.Provider {
 		return NewCollector(cfg, mgr)
@@ -1,7 +1,7 @@
 package aliyun
 
 import (
-	"encoding/json"
+	"context"
 	"strconv"
 	"strings"
 	"sync"
@@ -9,7 +9,6 @@ import (
 
 	"multicloud-exporter/internal/config"
 	"multicloud-exporter/internal/logger"
-	"multicloud-exporter/internal/metrics"
 	"multicloud-exporter/internal/providers/common"
 
 	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
@@ -17,13 +16,14 @@ import (
 	"github.com/aliyun/alibaba-cloud-sdk-go/services/tag"
 )
 
-func (a *Collector) listSLBIDs(account config.CloudAccount, region string) ([]string, map[string]interface{}) {
+func (a *Collector) listSLBIDs(ctx context.Context, account config.CloudAccount, region string) ([]string, map[string]interface{}) {
 	ctxLog := logger.NewContextLogger("Aliyun", "account_id", account.AccountID, "region", region)
 	client, err := a.clientFactory.NewSLBClient(region, account.AccessKeyID, account.AccessKeySecret)
 	if err != nil {
 		return []string{}, nil
 	}
 	var ids []string
+	names := make(map[string]string)
 	meta := make(map[string]interface{})
 	pageSize := 50
 	if a.cfg != nil {
@@ -43,35 +43,20 @@ func (a *Collector) listSLBIDs(account config.CloudAccount, region string) ([]st
 		req.RegionId = region
 		req.PageSize = requests.NewInteger(pageSize)
 		req.PageNumber = requests.NewInteger(page)
-		var resp *slb.DescribeLoadBalancersResponse
-		var callErr error
-		for attempt := 0; attempt < 3; attempt++ {
-			start := time.Now()
-			resp, callErr = client.DescribeLoadBalancers(req)
-			if callErr == nil {
-				metrics.RequestTotal.WithLabelValues("aliyun", "DescribeLoadBalancers", "success").Inc()
-				metrics.RequestDuration.WithLabelValues("aliyun", "DescribeLoadBalancers").Observe(time.Since(start).Seconds())
-				break
-			}
+		resp, callErr := common.Call(ctx, common.CallOptions{
+			Provider: "aliyun", AccountID: account.AccountID, Region: region, API: "DescribeLoadBalancers",
+			Attempts: 3, Retryable: common.RetryUnlessFatal,
+		}, func() (*slb.DescribeLoadBalancersResponse, error) {
+			return client.DescribeLoadBalancers(req)
+		})
+		if callErr != nil {
 			status := common.ClassifyAliyunError(callErr)
-			metrics.RequestTotal.WithLabelValues("aliyun", "DescribeLoadBalancers", status).Inc()
-			if status == "limit_error" {
-				// 记录限流指标
-				metrics.RateLimitTotal.WithLabelValues("aliyun", "DescribeLoadBalancers").Inc()
-			}
-			if status == "region_skip" || status == "auth_error" {
+			if status == common.ErrorStatusRegion || status == common.ErrorStatusAuth {
 				ctxLog.Warnf("SLB describe error page=%d status=%s: %v", page, status, callErr)
-				break
-			}
-			// 指数退避重试
-			sleep := time.Duration(200*(1<<attempt)) * time.Millisecond
-			if sleep > 5*time.Second {
-				sleep = 5 * time.Second
 			}
-			time.Sleep(sleep)
-		}
-		if callErr != nil {
-			break
+			common.RecordTargetError("aliyun", account.AccountID, region, common.NamespaceAliyunSLBDashboard, status)
+			// 枚举失败：不更新区域状态，避免把区域误标为无资源
+			return []string{}, nil
 		}
 		if resp == nil {
 			break
@@ -82,6 +67,7 @@ func (a *Collector) listSLBIDs(account config.CloudAccount, region string) ([]st
 		for _, lb := range resp.LoadBalancers.LoadBalancer {
 			if lb.LoadBalancerId != "" {
 				ids = append(ids, lb.LoadBalancerId)
+				names[lb.LoadBalancerId] = lb.LoadBalancerName
 			}
 		}
 
@@ -112,9 +98,12 @@ func (a *Collector) listSLBIDs(account config.CloudAccount, region string) ([]st
 		// 继续下一页
 		page++
 		ctxLog.Debugf("SLB 分页采集 page=%d current_count=%d total_collected=%d", page, currentCount, len(ids))
-		time.Sleep(50 * time.Millisecond)
 	}
 
+	// 资源过滤：在获取监听器详情之前执行，被过滤的实例不产生任何后续调用
+	listed := len(ids)
+	ids = a.filterResourceIDs(ctx, account, region, "clb", ids, names)
+
 	// 并发获取每个实例的监听器详情（用于补充 port/protocol 维度）
 	if len(ids) > 0 {
 		ctxLog.Debugf("开始获取SLB监听器详情 count=%d", len(ids))
@@ -132,31 +121,17 @@ func (a *Collector) listSLBIDs(account config.CloudAccount, region string) ([]st
 				req := slb.CreateDescribeLoadBalancerAttributeRequest()
 				req.LoadBalancerId = lbId
 
-				var resp *slb.DescribeLoadBalancerAttributeResponse
-				var err error
-				for i := 0; i < 3; i++ {
-					startReq := time.Now()
-					resp, err = client.DescribeLoadBalancerAttribute(req)
-					if err == nil {
-						metrics.RequestTotal.WithLabelValues("aliyun", "DescribeLoadBalancerAttribute", "success").Inc()
-						metrics.RequestDuration.WithLabelValues("aliyun", "DescribeLoadBalancerAttribute").Observe(time.Since(startReq).Seconds())
-						metrics.RecordRequest("aliyun", "DescribeLoadBalancerAttribute", "success")
-						break
-					}
-					status := common.ClassifyAliyunError(err)
-					metrics.RequestTotal.WithLabelValues("aliyun", "DescribeLoadBalancerAttribute", status).Inc()
-					metrics.RecordRequest("aliyun", "DescribeLoadBalancerAttribute", status)
-					if status == "limit_error" {
-						// 记录限流指标
-						metrics.RateLimitTotal.WithLabelValues("aliyun", "DescribeLoadBalancerAttribute").Inc()
-					}
-					if status == "auth_error" || status == "region_skip" {
-						break
-					}
-					time.Sleep(time.Duration(100*(i+1)) * time.Millisecond)
-				}
-
+				resp, err := common.Call(ctx, common.CallOptions{
+					Provider: "aliyun", AccountID: account.AccountID, Region: region, API: "DescribeLoadBalancerAttribute",
+					Attempts: 3, Retryable: common.RetryUnlessFatal,
+					Backoff: common.RetryConfig{InitialDelay: 100 * time.Millisecond},
+				}, func() (*slb.DescribeLoadBalancerAttributeResponse, error) {
+					return client.DescribeLoadBalancerAttribute(req)
+				})
 				if err != nil {
+					if status := common.ClassifyAliyunError(err); status == common.ErrorStatusAuth || status == common.ErrorStatusRegion {
+						common.RecordTargetError("aliyun", account.AccountID, region, common.NamespaceAliyunSLBDashboard, status)
+					}
 					ctxLog.Warnf("fetch SLB attribute failed id=%s: %v", lbId, err)
 					return
 				}
@@ -187,21 +162,13 @@ func (a *Collector) listSLBIDs(account config.CloudAccount, region string) ([]st
 	ctxLog.Debugf("枚举SLB实例完成 实例数=%d 带监听器数=%d", len(ids), len(meta))
 
 	// 更新区域状态
-	if a.regionManager != nil {
-		status := common.RegionStatusEmpty
-		if len(ids) > 0 {
-			status = common.RegionStatusActive
-		}
-		a.regionManager.UpdateRegionStatus(account.AccountID, region, len(ids), status)
-		ctxLog.Debugf("更新区域状态 account=%s region=%s status=%s count=%d",
-			account.AccountID, region, status, len(ids))
-	}
+	common.ReportRegionResources(a.regionManager, "aliyun", account.AccountID, region, common.NamespaceAliyunSLBDashboard, listed)
 
 	return ids, meta
 }
 
 // fetchSLBTags 批量获取 SLB 标签（包含 code_name）
-func (a *Collector) fetchSLBTags(account config.CloudAccount, region, namespace, metric string, ids []string) map[string]string {
+func (a *Collector) fetchSLBTags(ctx context.Context, account config.CloudAccount, region, namespace, metric string, ids []string) map[string]string {
 	if len(ids) == 0 {
 		return map[string]string{}
 	}
@@ -214,12 +181,13 @@ func (a *Collector) fetchSLBTags(account config.CloudAccount, region, namespace,
 	}
 
 	out := make(map[string]string, len(ids))
+	fetched := newFetchedTags()
 	// 阿里云 ListTagResources 支持最多 50 个 ARN，这里使用 50
 	batchSize := 50
 	total := len(ids)
 
 	// 获取真实的 Account UID 用于构建 ARN
-	uid := a.getAccountUID(account, region)
+	uid := a.getAccountUID(ctx, account, region)
 
 	for start := 0; start < total; start += batchSize {
 		end := start + batchSize
@@ -239,33 +207,14 @@ func (a *Collector) fetchSLBTags(account config.CloudAccount, region, namespace,
 		// req.ResourceType = "loadbalancer" // SDK 中可能无此字段，依赖 ARN 推断
 		req.ResourceARN = &arns
 
-		var resp *tag.ListTagResourcesResponse
-		var callErr error
-		for attempt := 0; attempt < 3; attempt++ {
-			startReq := time.Now()
-			resp, callErr = tagClient.ListTagResources(req)
-			if callErr == nil {
-				metrics.RequestTotal.WithLabelValues("aliyun", "ListTagResources", "success").Inc()
-				metrics.RequestDuration.WithLabelValues("aliyun", "ListTagResources").Observe(time.Since(startReq).Seconds())
-				metrics.RecordRequest("aliyun", "ListTagResources", "success")
-				break
-			}
-			status := common.ClassifyAliyunError(callErr)
-			metrics.RequestTotal.WithLabelValues("aliyun", "ListTagResources", status).Inc()
-			metrics.RecordRequest("aliyun", "ListTagResources", status)
-			if status == "limit_error" {
-				// 记录限流指标
-				metrics.RateLimitTotal.WithLabelValues("aliyun", "ListTagResources").Inc()
-			}
-			if status == "auth_error" {
-				break
-			}
-			// 指数退避重试
-			sleep := time.Duration(200*(1<<attempt)) * time.Millisecond
-			if sleep > 5*time.Second {
-				sleep = 5 * time.Second
-			}
-			time.Sleep(sleep)
+		resp, callErr := common.Call(ctx, common.CallOptions{
+			Provider: "aliyun", AccountID: account.AccountID, Region: region, API: "ListTagResources",
+			Attempts: 3, Retryable: common.RetryUnlessFatal,
+		}, func() (*tag.ListTagResourcesResponse, error) {
+			return tagClient.ListTagResources(req)
+		})
+		if callErr != nil && common.ClassifyAliyunError(callErr) == common.ErrorStatusAuth {
+			common.RecordTargetError("aliyun", account.AccountID, region, namespace, common.ErrorStatusAuth)
 		}
 
 		if callErr == nil && resp != nil {
@@ -287,23 +236,12 @@ func (a *Collector) fetchSLBTags(account config.CloudAccount, region, namespace,
 						for _, t := range tr.Tags {
 							k := t.Key
 							v := t.Value
-							if strings.EqualFold(k, "CodeName") || strings.EqualFold(k, "code_name") {
-								out[id] = v
-							}
-							// 提取带宽上限 (BandwidthCapBps) 标签，格式为数字字符串
-							if strings.EqualFold(k, "BandwidthCapBps") || strings.EqualFold(k, "bandwidth_cap_bps") {
-								// 使用特殊前缀存储，以便后续解析
-								out["_cap_"+id] = v
-							}
+							fetched.add(id, k, v)
 						}
 					}
 				}
 			} else {
-				content := resp.GetHttpContentBytes()
-				parsed := parseSLBTagsContent(content)
-				for k, v := range parsed {
-					out[k] = v
-				}
+				fetched.addAll(parseTagResourcesContent(resp.GetHttpContentBytes()))
 			}
 		}
 
@@ -311,100 +249,23 @@ func (a *Collector) fetchSLBTags(account config.CloudAccount, region, namespace,
 		if (end)%100 == 0 || end == total {
 			ctxLog.Debugf("SLB 标签采集进度 progress=%d/%d (%.1f%%)", end, total, float64(end)/float64(total)*100)
 		}
-
-		// 批次间隔
-		time.Sleep(20 * time.Millisecond)
 	}
 
+	a.applyFetchedTags(account, region, "clb", ids, fetched, out)
 	assigned := 0
 	for _, v := range out {
 		if v != "" {
 			assigned++
 		}
 	}
-	ctxLog.Debugf("SLB 标签采集完成 资源数=%d 有code_name=%d", len(ids), assigned)
-	return out
-}
-
-func parseSLBTagsContent(content []byte) map[string]string {
-	out := make(map[string]string)
-	var jrA struct {
-		TagResources []struct {
-			ResourceId  string `json:"ResourceId"`
-			ResourceARN string `json:"ResourceARN"`
-			Tags        []struct {
-				Key      string `json:"Key"`
-				Value    string `json:"Value"`
-				TagKey   string `json:"TagKey"`
-				TagValue string `json:"TagValue"`
-			} `json:"Tags"`
-		} `json:"TagResources"`
-	}
-	if err := json.Unmarshal(content, &jrA); err == nil && len(jrA.TagResources) > 0 {
-		for _, tr := range jrA.TagResources {
-			id := tr.ResourceId
-			if id == "" && tr.ResourceARN != "" {
-				parts := strings.Split(tr.ResourceARN, "/")
-				if len(parts) > 0 {
-					id = parts[len(parts)-1]
-				}
-			}
-			if id == "" {
-				continue
-			}
-			for _, t := range tr.Tags {
-				k := t.Key
-				if k == "" {
-					k = t.TagKey
-				}
-				v := t.Value
-				if v == "" {
-					v = t.TagValue
-				}
-				if strings.EqualFold(k, "CodeName") || strings.EqualFold(k, "code_name") {
-					out[id] = v
-				}
-				// 提取带宽上限 (BandwidthCapBps) 标签，格式为数字字符串
-				if strings.EqualFold(k, "BandwidthCapBps") || strings.EqualFold(k, "bandwidth_cap_bps") {
-					// 使用特殊前缀存储，以便后续解析
-					out["_cap_"+id] = v
-				}
-			}
-		}
-	}
-	var jrB struct {
-		TagResources struct {
-			TagResource []struct {
-				ResourceId string `json:"ResourceId"`
-				TagKey     string `json:"TagKey"`
-				TagValue   string `json:"TagValue"`
-				Key        string `json:"Key"`
-				Value      string `json:"Value"`
-			} `json:"TagResource"`
-		} `json:"TagResources"`
-	}
-	if err := json.Unmarshal(content, &jrB); err == nil && len(jrB.TagResources.TagResource) > 0 {
-		for _, tr := range jrB.TagResources.TagResource {
-			if tr.ResourceId == "" {
-				continue
-			}
-			k := tr.TagKey
-			if k == "" {
-				k = tr.Key
-			}
-			v := tr.TagValue
-			if v == "" {
-				v = tr.Value
-			}
-			if strings.EqualFold(k, "CodeName") || strings.EqualFold(k, "code_name") {
-				out[tr.ResourceId] = v
-			}
-			// 提取带宽上限 (BandwidthCapBps) 标签，格式为数字字符串
+	// 提取带宽上限 (BandwidthCapBps) 标签，格式为数字字符串，使用特殊前缀存储以便后续解析
+	for id, tags := range a.recordedTags(account, region, "clb", ids) {
+		for k, v := range tags {
 			if strings.EqualFold(k, "BandwidthCapBps") || strings.EqualFold(k, "bandwidth_cap_bps") {
-				// 使用特殊前缀存储，以便后续解析
-				out["_cap_"+tr.ResourceId] = v
+				out["_cap_"+id] = v
 			}
 		}
 	}
+	ctxLog.Debugf("SLB 标签采集完成 资源数=%d 有code_name=%d", len(ids), assigned)
 	return out
 }
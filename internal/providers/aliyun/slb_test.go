@@ -2,22 +2,47 @@ package aliyun
 
 import (
 	"testing"
+
+	"multicloud-exporter/internal/config"
 )
 
-func TestParseSLBTagsContentFormats(t *testing.T) {
+func TestParseTagResourcesContentFormats(t *testing.T) {
+	c := NewCollector(&config.Config{}, nil)
+	acc := config.CloudAccount{AccountID: "acc"}
+	resolve := func(content []byte, id string) string {
+		fetched := newFetchedTags()
+		fetched.addAll(parseTagResourcesContent(content))
+		return c.applyFetchedTags(acc, "cn", "clb", []string{id}, fetched, map[string]string{})[id]
+	}
 	a := []byte(`{"TagResources":[{"ResourceId":"lb-1","Tags":[{"Key":"CodeName","Value":"api-gw"}]}]}`)
-	m := parseSLBTagsContent(a)
-	if m["lb-1"] != "api-gw" {
+	if resolve(a, "lb-1") != "api-gw" {
 		t.Fatalf("fmtA")
 	}
 	b := []byte(`{"TagResources":{"TagResource":[{"ResourceId":"lb-2","TagKey":"CodeName","TagValue":"web"}]}}`)
-	m2 := parseSLBTagsContent(b)
-	if m2["lb-2"] != "web" {
+	if resolve(b, "lb-2") != "web" {
 		t.Fatalf("fmtB")
 	}
-	c := []byte(`{"TagResources":[{"ResourceARN":"arn:acs:slb:cn:uid:loadbalancer/lb-3","Tags":[{"TagKey":"code_name","TagValue":"svc"}]}]}`)
-	m3 := parseSLBTagsContent(c)
-	if m3["lb-3"] != "svc" {
+	cc := []byte(`{"TagResources":[{"ResourceARN":"arn:acs:slb:cn:uid:loadbalancer/lb-3","Tags":[{"TagKey":"code_name","TagValue":"svc"}]}]}`)
+	if resolve(cc, "lb-3") != "svc" {
 		t.Fatalf("arn")
 	}
 }
+
+func TestMergeCodeNames_ConfiguredChain(t *testing.T) {
+	cfg := &config.Config{Server: &config.ServerConf{CodeNameChain: "tags.Name -> name -> id"}}
+	c := NewCollector(cfg, nil)
+	acc := config.CloudAccount{AccountID: "acc"}
+	fetched := newFetchedTags()
+	fetched.add("lb-1", "Name", "tagged")
+	c.recordResourceNames(acc, "cn", "clb", map[string]string{"lb-2": "named"})
+	ids := []string{"lb-1", "lb-2", "lb-3"}
+	out := c.applyFetchedTags(acc, "cn", "clb", ids, fetched, map[string]string{})
+	if out["lb-1"] != "tagged" || out["lb-2"] != "named" || out["lb-3"] != "lb-3" {
+		t.Fatalf("unexpected code names: %v", out)
+	}
+	// 再次拉取未返回标签时，旧标签被替换
+	out = c.applyFetchedTags(acc, "cn", "clb", ids, newFetchedTags(), map[string]string{})
+	if out["lb-1"] != "lb-1" {
+		t.Fatalf("stale tags should be replaced: %v", out)
+	}
+}
@@ -3,7 +3,7 @@ package aws
 import (
 	"context"
 	"strings"
-	"time"
+	"sync"
 
 	"multicloud-exporter/internal/config"
 	"multicloud-exporter/internal/discovery"
@@ -19,6 +19,9 @@ type Collector struct {
 	disc          *discovery.Manager
 	clientFactory ClientFactory
 	regionManager providerscommon.RegionManager
+	scheduler     *providerscommon.ProductScheduler          // 产品级采集调度
+	inventory     map[string][]providerscommon.InventoryItem // 最近一次枚举的资源清单：account|region|namespace|rtype -> items
+	invMu         sync.RWMutex
 }
 
 func NewCollector(cfg *config.Config, mgr *discovery.Manager) *Collector {
@@ -26,85 +29,70 @@ func NewCollector(cfg *config.Config, mgr *discovery.Manager) *Collector {
 		cfg:           cfg,
 		disc:          mgr,
 		clientFactory: &defaultClientFactory{},
+		scheduler:     providerscommon.NewProductScheduler(),
 	}
 
-	// 初始化区域管理器
-	if cfg != nil && cfg.GetServer() != nil && cfg.GetServer().RegionDiscovery != nil {
-		c.regionManager = providerscommon.NewRegionManager(providerscommon.RegionDiscoveryConfig{
-			Enabled:           cfg.GetServer().RegionDiscovery.Enabled,
-			DiscoveryInterval: parseDuration(cfg.GetServer().RegionDiscovery.DiscoveryInterval),
-			EmptyThreshold:    cfg.GetServer().RegionDiscovery.EmptyThreshold,
-			DataDir:           cfg.GetServer().RegionDiscovery.DataDir,
-			PersistFile:       cfg.GetServer().RegionDiscovery.PersistFile,
-		})
-
-		// 加载持久化的区域状态
-		if err := c.regionManager.Load(); err != nil {
-			ctxLog := logger.NewContextLogger("AWS", "resource_type", "RegionManager")
-			ctxLog.Warnf("加载区域状态失败: %v", err)
-		}
-
-		// 启动定期重新发现调度器
-		c.regionManager.StartRediscoveryScheduler()
+	// 区域管理器：各云共用，首次创建时加载持久化状态并启动重新发现调度器
+	if cfg != nil && cfg.GetServer() != nil {
+		c.regionManager = providerscommon.SharedRegionManager(cfg.GetServer().RegionDiscovery)
 	}
 
 	return c
 }
 
-// parseDuration 解析时长字符串为 time.Duration
-func parseDuration(s string) time.Duration {
-	if s == "" {
-		return 0
-	}
-	if d, err := time.ParseDuration(s); err == nil {
-		return d
-	}
-	return 0
-}
-
-func (c *Collector) Collect(account config.CloudAccount) {
+func (c *Collector) Collect(ctx context.Context, account config.CloudAccount) providerscommon.CollectResult {
+	ctx, rec := providerscommon.BeginCollect(ctx, "aws", account.AccountID)
 	// 注意：分片逻辑已下沉到产品级（collectS3/collectALB 等），此处不做账号级分片
 	// 这样可以避免双重分片导致的任务丢失问题
 	for _, resource := range account.Resources {
+		if ctx.Err() != nil {
+			break
+		}
 		r := strings.ToLower(strings.TrimSpace(resource))
 		switch r {
 		case "*":
-			c.collectS3(account)
-			c.collectALB(account)
-			c.collectCLB(account)
-			c.collectNLB(account)
-			c.collectGWLB(account)
+			c.collectS3(ctx, account)
+			c.collectALB(ctx, account)
+			c.collectCLB(ctx, account)
+			c.collectNLB(ctx, account)
+			c.collectGWLB(ctx, account)
 		case "s3":
-			c.collectS3(account)
+			c.collectS3(ctx, account)
 		case "alb":
-			c.collectALB(account)
+			c.collectALB(ctx, account)
 		case "clb":
-			c.collectCLB(account)
+			c.collectCLB(ctx, account)
 		case "nlb":
-			c.collectNLB(account)
+			c.collectNLB(ctx, account)
 		case "gwlb":
-			c.collectGWLB(account)
+			c.collectGWLB(ctx, account)
 		default:
 			ctxLog := logger.NewContextLogger("AWS", "account_id", account.AccountID, "resource_type", resource)
 			ctxLog.Warnf("资源类型尚未实现")
 		}
 	}
+	return rec.Finish(ctx)
 }
 
-// getAllRegions 通过 DescribeRegions 自动发现全部区域
-func (c *Collector) getAllRegions(account config.CloudAccount) []string {
+// getAllRegions 通过 DescribeRegions 自动发现全部区域；启用区域管理器时按 namespaces 过滤连续为空的区域
+func (c *Collector) getAllRegions(ctx context.Context, account config.CloudAccount, namespaces ...string) []string {
 	// 使用 us-east-1 作为默认接入点查询所有区域
-	client, err := c.clientFactory.NewEC2Client(context.Background(), "us-east-1", account.AccessKeyID, account.AccessKeySecret)
+	client, err := c.clientFactory.NewEC2Client(ctx, "us-east-1", account.AccessKeyID, account.AccessKeySecret)
 	if err != nil {
 		ctxLog := logger.NewContextLogger("AWS", "account_id", account.AccountID, "region", "us-east-1", "resource_type", "EC2")
 		ctxLog.Errorf("获取区域列表错误: %v", err)
 		return []string{"us-east-1"}
 	}
 
-	resp, err := client.DescribeRegions(context.Background(), &ec2.DescribeRegionsInput{})
+	resp, err := providerscommon.Call(ctx, providerscommon.CallOptions{
+		Provider: "aws", AccountID: account.AccountID, Region: "us-east-1", API: "DescribeRegions",
+	}, func() (*ec2.DescribeRegionsOutput, error) {
+		return client.DescribeRegions(ctx, &ec2.DescribeRegionsInput{})
+	})
 	if err != nil {
 		ctxLog := logger.NewContextLogger("AWS", "account_id", account.AccountID, "region", "us-east-1", "resource_type", "EC2")
 		ctxLog.Errorf("DescribeRegions API调用错误: %v", err)
+		providerscommon.RecordAccountError("aws", account.AccountID, providerscommon.ClassifyAWSError(err))
 		return []string{"us-east-1"}
 	}
 
@@ -119,7 +107,7 @@ func (c *Collector) getAllRegions(account config.CloudAccount) []string {
 
 	// 使用区域管理器进行智能过滤
 	if c.regionManager != nil {
-		activeRegions := c.regionManager.GetActiveRegions(account.AccountID, regions)
+		activeRegions := c.regionManager.GetActiveRegions("aws", account.AccountID, regions, namespaces)
 		ctxLog := logger.NewContextLogger("AWS", "account_id", account.AccountID, "resource_type", "RegionManager")
 		ctxLog.Infof("智能区域选择: 总=%d 活跃=%d",
 			len(regions), len(activeRegions))
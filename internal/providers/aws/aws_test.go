@@ -41,7 +41,7 @@ func TestGetAllRegions_FallbackOnError(t *testing.T) {
 		clientFactory: &mockFactory{newEC2Err: errors.New("boom")},
 	}
 	acc := config.CloudAccount{AccountID: "test"}
-	got := c.getAllRegions(acc)
+	got := c.getAllRegions(context.Background(), acc)
 	want := []string{"us-east-1"}
 	if !reflect.DeepEqual(got, want) {
 		t.Fatalf("fallback regions mismatch: got=%v want=%v", got, want)
@@ -52,13 +52,13 @@ func TestCollect_UnknownResource_NoPanic(t *testing.T) {
 	c := &Collector{clientFactory: &mockFactory{}}
 	acc := config.CloudAccount{AccountID: "test", Resources: []string{"unknown_service"}}
 	// Should not panic
-	c.Collect(acc)
+	c.Collect(context.Background(), acc)
 }
 
 func TestCollect_Wildcard_NoConfig_NoPanic(t *testing.T) {
 	c := &Collector{clientFactory: &mockFactory{}}
 	acc := config.CloudAccount{AccountID: "test", Resources: []string{"*"}}
-	c.Collect(acc)
+	c.Collect(context.Background(), acc)
 }
 
 func TestGetDefaultResources(t *testing.T) {
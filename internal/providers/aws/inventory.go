@@ -0,0 +1,38 @@
+package aws
+
+import (
+	"time"
+
+	"multicloud-exporter/internal/config"
+	providerscommon "multicloud-exporter/internal/providers/common"
+)
+
+// recordInventory 记录一次枚举（过滤后）的资源清单，整体替换 account/region/namespace 下的旧条目
+func (c *Collector) recordInventory(account config.CloudAccount, region, namespace, rtype string, items []providerscommon.InventoryItem) {
+	now := time.Now()
+	for i := range items {
+		items[i].Provider = "aws"
+		items[i].AccountID = account.AccountID
+		items[i].Region = region
+		items[i].Namespace = namespace
+		items[i].ResourceType = rtype
+		items[i].UpdatedAt = now
+	}
+	c.invMu.Lock()
+	defer c.invMu.Unlock()
+	if c.inventory == nil {
+		c.inventory = make(map[string][]providerscommon.InventoryItem)
+	}
+	c.inventory[account.AccountID+"|"+region+"|"+namespace+"|"+rtype] = items
+}
+
+// Inventory 返回最近一次枚举的资源清单（AWS 不缓存资源 ID，每轮采集时更新）
+func (c *Collector) Inventory() []providerscommon.InventoryItem {
+	c.invMu.RLock()
+	defer c.invMu.RUnlock()
+	var out []providerscommon.InventoryItem
+	for _, items := range c.inventory {
+		out = append(out, items...)
+	}
+	return out
+}
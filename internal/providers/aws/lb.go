@@ -29,8 +29,9 @@ type ResourceLister interface {
 // lbInfo 表示负载均衡器的通用信息
 type lbInfo struct {
 	Name     string
-	ARN      string // 用于 v2
-	CodeName string // 从标签解析
+	ARN      string            // 用于 v2
+	CodeName string            // 从标签解析
+	Tags     map[string]string // 资源标签（用于资源过滤）
 }
 
 // clbLister 实现 ResourceLister 接口，用于经典负载均衡器
@@ -51,25 +52,18 @@ func (l *clbLister) List(ctx context.Context, region string, account config.Clou
 	// - 多页结果：HasMorePages() 返回 true 直到所有页都被获取
 	paginator := elasticloadbalancing.NewDescribeLoadBalancersPaginator(client, &elasticloadbalancing.DescribeLoadBalancersInput{})
 	for paginator.HasMorePages() {
-		start := time.Now()
-		page, err := paginator.NextPage(ctx)
+		page, err := common.Call(ctx, common.CallOptions{
+			Provider: "aws", AccountID: account.AccountID, Region: region, API: "DescribeLoadBalancers",
+		}, func() (*elasticloadbalancing.DescribeLoadBalancersOutput, error) {
+			return paginator.NextPage(ctx)
+		})
 		if err != nil {
-			status := common.ClassifyAWSError(err)
-			metrics.RequestTotal.WithLabelValues("aws", "DescribeLoadBalancers", status).Inc()
-			metrics.RecordRequest("aws", "DescribeLoadBalancers", status)
-			metrics.RequestDuration.WithLabelValues("aws", "DescribeLoadBalancers").Observe(time.Since(start).Seconds())
-			if status == "limit_error" {
-				metrics.RateLimitTotal.WithLabelValues("aws", "DescribeLoadBalancers").Inc()
-			}
 			// API 调用失败时，返回已收集的数据和错误，允许上层决定如何处理
 			return lbs, err
 		}
-		metrics.RequestTotal.WithLabelValues("aws", "DescribeLoadBalancers", "success").Inc()
-		metrics.RecordRequest("aws", "DescribeLoadBalancers", "success")
-		metrics.RequestDuration.WithLabelValues("aws", "DescribeLoadBalancers").Observe(time.Since(start).Seconds())
 		for _, lb := range page.LoadBalancerDescriptions {
 			if lb.LoadBalancerName != nil {
-				lbs = append(lbs, lbInfo{Name: *lb.LoadBalancerName, CodeName: *lb.LoadBalancerName})
+				lbs = append(lbs, lbInfo{Name: *lb.LoadBalancerName})
 			}
 		}
 	}
@@ -90,25 +84,21 @@ func (l *clbLister) List(ctx context.Context, region string, account config.Clou
 				end = len(names)
 			}
 			batch := names[i:end]
-			start := time.Now()
-			out, err := client.DescribeTags(ctx, &elasticloadbalancing.DescribeTagsInput{
-				LoadBalancerNames: batch,
+			out, err := common.Call(ctx, common.CallOptions{
+				Provider: "aws", AccountID: account.AccountID, Region: region, API: "DescribeTags",
+			}, func() (*elasticloadbalancing.DescribeTagsOutput, error) {
+				return client.DescribeTags(ctx, &elasticloadbalancing.DescribeTagsInput{
+					LoadBalancerNames: batch,
+				})
 			})
 			if err != nil {
-				status := common.ClassifyAWSError(err)
-				metrics.RequestTotal.WithLabelValues("aws", "DescribeTags", status).Inc()
-				metrics.RecordRequest("aws", "DescribeTags", status)
-				metrics.RequestDuration.WithLabelValues("aws", "DescribeTags").Observe(time.Since(start).Seconds())
-				if status == "limit_error" {
-					metrics.RateLimitTotal.WithLabelValues("aws", "DescribeTags").Inc()
+				if ctx.Err() != nil {
+					break
 				}
 				ctxLog := logger.NewContextLogger("AWS", "account_id", account.AccountID, "region", region, "resource_type", "CLB")
 				ctxLog.Warnf("DescribeTags API调用失败: %v", err)
 				continue
 			}
-			metrics.RequestTotal.WithLabelValues("aws", "DescribeTags", "success").Inc()
-			metrics.RecordRequest("aws", "DescribeTags", "success")
-			metrics.RequestDuration.WithLabelValues("aws", "DescribeTags").Observe(time.Since(start).Seconds())
 			for _, desc := range out.TagDescriptions {
 				if desc.LoadBalancerName != nil {
 					if info, ok := lbMap[*desc.LoadBalancerName]; ok {
@@ -118,13 +108,14 @@ func (l *clbLister) List(ctx context.Context, region string, account config.Clou
 								tags[*t.Key] = *t.Value
 							}
 						}
-						info.CodeName = resolveCodeName(tags, info.Name)
+						info.Tags = tags
 					}
 				}
 			}
 		}
 	}
 
+	l.c.resolveLBCodeNames(lbs)
 	return lbs, nil
 }
 
@@ -147,25 +138,18 @@ func (l *elbv2Lister) List(ctx context.Context, region string, account config.Cl
 	// - 多页结果：HasMorePages() 返回 true 直到所有页都被获取
 	paginator := elasticloadbalancingv2.NewDescribeLoadBalancersPaginator(client, &elasticloadbalancingv2.DescribeLoadBalancersInput{})
 	for paginator.HasMorePages() {
-		start := time.Now()
-		page, err := paginator.NextPage(ctx)
+		page, err := common.Call(ctx, common.CallOptions{
+			Provider: "aws", AccountID: account.AccountID, Region: region, API: "DescribeLoadBalancers",
+		}, func() (*elasticloadbalancingv2.DescribeLoadBalancersOutput, error) {
+			return paginator.NextPage(ctx)
+		})
 		if err != nil {
-			status := common.ClassifyAWSError(err)
-			metrics.RequestTotal.WithLabelValues("aws", "DescribeLoadBalancers", status).Inc()
-			metrics.RecordRequest("aws", "DescribeLoadBalancers", status)
-			metrics.RequestDuration.WithLabelValues("aws", "DescribeLoadBalancers").Observe(time.Since(start).Seconds())
-			if status == "limit_error" {
-				metrics.RateLimitTotal.WithLabelValues("aws", "DescribeLoadBalancers").Inc()
-			}
 			// API 调用失败时，返回已收集的数据和错误，允许上层决定如何处理
 			return lbs, err
 		}
-		metrics.RequestTotal.WithLabelValues("aws", "DescribeLoadBalancers", "success").Inc()
-		metrics.RecordRequest("aws", "DescribeLoadBalancers", "success")
-		metrics.RequestDuration.WithLabelValues("aws", "DescribeLoadBalancers").Observe(time.Since(start).Seconds())
 		for _, lb := range page.LoadBalancers {
 			if lb.Type == l.lbType && lb.LoadBalancerName != nil && lb.LoadBalancerArn != nil {
-				lbs = append(lbs, lbInfo{Name: *lb.LoadBalancerName, ARN: *lb.LoadBalancerArn, CodeName: *lb.LoadBalancerName})
+				lbs = append(lbs, lbInfo{Name: *lb.LoadBalancerName, ARN: *lb.LoadBalancerArn})
 			}
 		}
 	}
@@ -188,25 +172,21 @@ func (l *elbv2Lister) List(ctx context.Context, region string, account config.Cl
 				end = len(arns)
 			}
 			batch := arns[i:end]
-			start := time.Now()
-			out, err := client.DescribeTags(ctx, &elasticloadbalancingv2.DescribeTagsInput{
-				ResourceArns: batch,
+			out, err := common.Call(ctx, common.CallOptions{
+				Provider: "aws", AccountID: account.AccountID, Region: region, API: "DescribeTags",
+			}, func() (*elasticloadbalancingv2.DescribeTagsOutput, error) {
+				return client.DescribeTags(ctx, &elasticloadbalancingv2.DescribeTagsInput{
+					ResourceArns: batch,
+				})
 			})
 			if err != nil {
-				status := common.ClassifyAWSError(err)
-				metrics.RequestTotal.WithLabelValues("aws", "DescribeTags", status).Inc()
-				metrics.RecordRequest("aws", "DescribeTags", status)
-				metrics.RequestDuration.WithLabelValues("aws", "DescribeTags").Observe(time.Since(start).Seconds())
-				if status == "limit_error" {
-					metrics.RateLimitTotal.WithLabelValues("aws", "DescribeTags").Inc()
+				if ctx.Err() != nil {
+					break
 				}
 				ctxLog := logger.NewContextLogger("AWS", "account_id", account.AccountID, "region", region, "resource_type", string(l.lbType))
 				ctxLog.Warnf("DescribeTags API调用失败: %v", err)
 				continue
 			}
-			metrics.RequestTotal.WithLabelValues("aws", "DescribeTags", "success").Inc()
-			metrics.RecordRequest("aws", "DescribeTags", "success")
-			metrics.RequestDuration.WithLabelValues("aws", "DescribeTags").Observe(time.Since(start).Seconds())
 			for _, desc := range out.TagDescriptions {
 				if desc.ResourceArn != nil {
 					if info, ok := lbMap[*desc.ResourceArn]; ok {
@@ -216,41 +196,69 @@ func (l *elbv2Lister) List(ctx context.Context, region string, account config.Cl
 								tags[*t.Key] = *t.Value
 							}
 						}
-						info.CodeName = resolveCodeName(tags, info.Name)
+						info.Tags = tags
 					}
 				}
 			}
 		}
 	}
 
+	l.c.resolveLBCodeNames(lbs)
 	return lbs, nil
 }
 
-func resolveCodeName(tags map[string]string, defaultName string) string {
-	// 优先使用 k8s service name，因为它通常包含业务信息 (namespace/service)
-	if v, ok := tags["kubernetes.io/service-name"]; ok && v != "" {
-		return v
+// lbCodeNameChain 负载均衡默认解析链：优先使用 k8s service name（通常包含 namespace/service），其次 Name 标签，最后为负载均衡名称
+const lbCodeNameChain = "tags.kubernetes.io/service-name -> tags.Name -> name"
+
+// resolveLBCodeNames 按解析链填充负载均衡的 code_name（标签拉取失败时 Tags 为空）
+func (c *Collector) resolveLBCodeNames(lbs []lbInfo) {
+	for i := range lbs {
+		lbs[i].CodeName = common.ResolveCodeName(c.cfg, lbCodeNameChain, lbs[i].Tags, lbs[i].Name, lbs[i].Name)
+	}
+}
+
+// filterLBs 按账号资源过滤规则筛选负载均衡器，标签已在枚举时获取
+func filterLBs(cfg *config.Config, account config.CloudAccount, namespace string, lbs []lbInfo) []lbInfo {
+	names := []string{"alb", "elb"}
+	switch namespace {
+	case "AWS/ELB":
+		names = []string{"clb", "elb"}
+	case "AWS/NetworkELB":
+		names = []string{"nlb", "elb"}
+	case "AWS/GatewayELB":
+		names = []string{"gwlb", "elb"}
+	}
+	f := common.NewResourceFilter(cfg, account, names...)
+	if f == nil {
+		return lbs
 	}
-	if v, ok := tags["Name"]; ok && v != "" {
-		return v
+	out := make([]lbInfo, 0, len(lbs))
+	for _, lb := range lbs {
+		tags := lb.Tags
+		if tags == nil {
+			tags = map[string]string{}
+		}
+		if f.Match(common.ResourceInfo{ID: lb.Name, Name: lb.Name, CodeName: lb.CodeName, Tags: tags}) {
+			out = append(out, lb)
+		}
 	}
-	return defaultName
+	return out
 }
 
-func (c *Collector) collectCLB(account config.CloudAccount) {
-	c.collectLBGeneric(account, "AWS/ELB", &clbLister{c: c})
+func (c *Collector) collectCLB(ctx context.Context, account config.CloudAccount) {
+	c.collectLBGeneric(ctx, account, "AWS/ELB", &clbLister{c: c})
 }
 
-func (c *Collector) collectALB(account config.CloudAccount) {
-	c.collectLBGeneric(account, "AWS/ApplicationELB", &elbv2Lister{c: c, lbType: elbv2types.LoadBalancerTypeEnumApplication})
+func (c *Collector) collectALB(ctx context.Context, account config.CloudAccount) {
+	c.collectLBGeneric(ctx, account, "AWS/ApplicationELB", &elbv2Lister{c: c, lbType: elbv2types.LoadBalancerTypeEnumApplication})
 }
 
-func (c *Collector) collectNLB(account config.CloudAccount) {
-	c.collectLBGeneric(account, "AWS/NetworkELB", &elbv2Lister{c: c, lbType: elbv2types.LoadBalancerTypeEnumNetwork})
+func (c *Collector) collectNLB(ctx context.Context, account config.CloudAccount) {
+	c.collectLBGeneric(ctx, account, "AWS/NetworkELB", &elbv2Lister{c: c, lbType: elbv2types.LoadBalancerTypeEnumNetwork})
 }
 
-func (c *Collector) collectGWLB(account config.CloudAccount) {
-	c.collectLBGeneric(account, "AWS/GatewayELB", &elbv2Lister{c: c, lbType: elbv2types.LoadBalancerTypeEnumGateway})
+func (c *Collector) collectGWLB(ctx context.Context, account config.CloudAccount) {
+	c.collectLBGeneric(ctx, account, "AWS/GatewayELB", &elbv2Lister{c: c, lbType: elbv2types.LoadBalancerTypeEnumGateway})
 }
 
 func (c *Collector) getProductConfig(namespace string) *config.Product {
@@ -267,7 +275,7 @@ func (c *Collector) getProductConfig(namespace string) *config.Product {
 	return nil
 }
 
-func (c *Collector) collectLBGeneric(account config.CloudAccount, namespace string, lister ResourceLister) {
+func (c *Collector) collectLBGeneric(ctx context.Context, account config.CloudAccount, namespace string, lister ResourceLister) {
 	prod := c.getProductConfig(namespace)
 	if prod == nil {
 		return
@@ -277,15 +285,18 @@ func (c *Collector) collectLBGeneric(account config.CloudAccount, namespace stri
 	wTotal, wIndex := utils.ClusterConfig()
 
 	var wg sync.WaitGroup
-	// Limit concurrency for regions
-	sem := make(chan struct{}, 5)
+	// Limit concurrency for regions（启用自适应并发时随限流反馈调整）
+	sem := common.ConcurrencyFor("aws", account.AccountID, common.ScopeRegion, 5).NewSemaphore()
 
 	regions := account.Regions
 	if len(regions) == 0 || (len(regions) == 1 && regions[0] == "*") {
-		regions = c.getAllRegions(account)
+		regions = c.getAllRegions(ctx, account, namespace)
 	}
 
 	for _, region := range regions {
+		if ctx.Err() != nil {
+			break
+		}
 		// 产品级分片判断：只有当前 Pod 应该处理的产品才进行采集
 		// 分片键格式：AccountID|Region|Namespace
 		productKey := account.AccountID + "|" + region + "|" + namespace
@@ -294,27 +305,58 @@ func (c *Collector) collectLBGeneric(account config.CloudAccount, namespace stri
 			ctxLog.Debugf("产品跳过（分片不匹配）")
 			continue
 		}
+		// 账号或区域熔断中时跳过（账号可能在本轮采集中途熔断）
+		if !common.AllowRegion("aws", account.AccountID, region) {
+			ctxLog := logger.NewContextLogger("AWS", "account_id", account.AccountID, "region", region, "namespace", namespace)
+			ctxLog.Debugf("产品跳过（熔断中）")
+			continue
+		}
+		// 产品级调度：未到期的地域保留上次导出的值
+		interval := common.ResolveProductInterval(c.cfg, "aws", *prod, nil)
+		if !c.scheduler.ShouldScrape("aws", account.AccountID, region, namespace, interval) {
+			ctxLog := logger.NewContextLogger("AWS", "account_id", account.AccountID, "region", region, "namespace", namespace)
+			ctxLog.Debugf("产品跳过（采集周期未到期，周期=%v）", interval)
+			continue
+		}
+		// 区域级跳过：该命名空间在本区域连续多轮枚举为空，等待重新发现
+		if common.SkipEmptyRegion(c.regionManager, "aws", account.AccountID, region, namespace) {
+			continue
+		}
+		if sem.Acquire(ctx) != nil {
+			break
+		}
 		wg.Add(1)
-		sem <- struct{}{}
 		go func(region string) {
 			defer wg.Done()
-			defer func() { <-sem }()
-			c.processRegionLB(account, region, prod, lister)
+			defer sem.Release()
+			target := common.StartTarget(ctx, "aws", account.AccountID, region, namespace)
+			c.processRegionLB(ctx, account, region, prod, lister)
+			c.scheduler.Finished("aws", account.AccountID, region, namespace, target.Finish())
 		}(region)
 	}
 	wg.Wait()
 }
 
-func (c *Collector) processRegionLB(account config.CloudAccount, region string, prod *config.Product, lister ResourceLister) {
-	// 创建上下文用于 LB 采集
-	ctx := context.Background()
-
+func (c *Collector) processRegionLB(ctx context.Context, account config.CloudAccount, region string, prod *config.Product, lister ResourceLister) {
 	lbs, err := lister.List(ctx, region, account)
 	if err != nil {
 		ctxLog := logger.NewContextLogger("AWS", "account_id", account.AccountID, "region", region, "namespace", prod.Namespace)
+		common.RecordTargetError("aws", account.AccountID, region, prod.Namespace, common.ClassifyAWSError(err))
 		ctxLog.Errorf("ListLB API调用失败: %v", err)
 		return
 	}
+	// 区域状态按枚举总数更新
+	common.ReportRegionResources(c.regionManager, "aws", account.AccountID, region, prod.Namespace, len(lbs))
+	lbs = filterLBs(c.cfg, account, prod.Namespace, lbs)
+	items := make([]common.InventoryItem, 0, len(lbs))
+	for _, lb := range lbs {
+		it := common.InventoryItem{ResourceID: lb.Name, Name: lb.Name, CodeName: lb.CodeName, Tags: lb.Tags}
+		if lb.ARN != "" {
+			it.Metadata = map[string]interface{}{"arn": lb.ARN}
+		}
+		items = append(items, it)
+	}
+	c.recordInventory(account, region, prod.Namespace, metrics.GetNamespacePrefix(prod.Namespace), items)
 	if len(lbs) == 0 {
 		return
 	}
@@ -322,6 +364,7 @@ func (c *Collector) processRegionLB(account config.CloudAccount, region string,
 	cwClient, err := c.clientFactory.NewCloudWatchClient(ctx, region, account.AccessKeyID, account.AccessKeySecret)
 	if err != nil {
 		ctxLog := logger.NewContextLogger("AWS", "account_id", account.AccountID, "region", region, "namespace", prod.Namespace)
+		common.RecordTargetError("aws", account.AccountID, region, prod.Namespace, common.ClassifyAWSError(err))
 		ctxLog.Errorf("CloudWatch客户端创建失败: %v", err)
 		return
 	}
@@ -337,6 +380,7 @@ func (c *Collector) processRegionLB(account config.CloudAccount, region string,
 		MetricName string
 		Stat       string
 		CodeName   string
+		Labels     map[string]string
 	})
 
 	period := int32(60) // Default 60s
@@ -350,6 +394,7 @@ func (c *Collector) processRegionLB(account config.CloudAccount, region string,
 
 	// Build queries
 	for _, lb := range lbs {
+		customLabels := common.CustomLabels(c.cfg, account, lb.Tags)
 		for _, mGroup := range prod.MetricInfo {
 			for _, metricName := range mGroup.MetricList {
 				// ID must start with a lowercase letter and contain only alphanumeric characters and underscores.
@@ -391,9 +436,6 @@ func (c *Collector) processRegionLB(account config.CloudAccount, region string,
 				// Initialize gauge to 0 to ensure metric is exposed even if CloudWatch returns no data
 				vec, labelCount := metrics.NamespaceGauge(prod.Namespace, metricName)
 				codeName := lb.CodeName
-				if codeName == "" {
-					codeName = lb.Name
-				}
 
 				// Build label values array matching the expected label count
 				labelValues := []string{
@@ -408,11 +450,7 @@ func (c *Collector) processRegionLB(account config.CloudAccount, region string,
 				}
 
 				// Pad with empty strings if more labels are expected (for extra dimensions)
-				for len(labelValues) < labelCount {
-					labelValues = append(labelValues, "")
-				}
-
-				vec.WithLabelValues(labelValues...).Set(0)
+				vec.WithLabelValues(metrics.LabelValues(labelCount, customLabels, labelValues...)...).Set(0)
 
 				queries = append(queries, cwtypes.MetricDataQuery{
 					Id: aws.String(id),
@@ -431,7 +469,8 @@ func (c *Collector) processRegionLB(account config.CloudAccount, region string,
 					MetricName string
 					Stat       string
 					CodeName   string
-				}{LBName: lb.Name, MetricName: metricName, Stat: stat, CodeName: lb.CodeName}
+					Labels     map[string]string
+				}{LBName: lb.Name, MetricName: metricName, Stat: stat, CodeName: lb.CodeName, Labels: customLabels}
 			}
 		}
 	}
@@ -451,23 +490,22 @@ func (c *Collector) processRegionLB(account config.CloudAccount, region string,
 			EndTime:           aws.Time(endTime),
 		}
 
-		start := time.Now()
-		out, err := cwClient.GetMetricData(ctx, input)
+		out, err := common.Call(ctx, common.CallOptions{
+			Provider: "aws", AccountID: account.AccountID, Region: region, API: "GetMetricData",
+		}, func() (*cloudwatch.GetMetricDataOutput, error) {
+			common.RecordMetricsRequested("aws", account.AccountID, "GetMetricData", len(batch))
+			return cwClient.GetMetricData(ctx, input)
+		})
 		if err != nil {
-			status := common.ClassifyAWSError(err)
-			metrics.RequestTotal.WithLabelValues("aws", "GetMetricData", status).Inc()
-			metrics.RecordRequest("aws", "GetMetricData", status)
-			metrics.RequestDuration.WithLabelValues("aws", "GetMetricData").Observe(time.Since(start).Seconds())
-			if status == "limit_error" {
-				metrics.RateLimitTotal.WithLabelValues("aws", "GetMetricData").Inc()
+			if ctx.Err() != nil {
+				break
 			}
+			status := common.ClassifyAWSError(err)
+			common.RecordTargetError("aws", account.AccountID, region, prod.Namespace, status)
 			ctxLog := logger.NewContextLogger("AWS", "account_id", account.AccountID, "region", region, "namespace", prod.Namespace)
 			ctxLog.Warnf("GetMetricData API调用失败: %v", err)
 			continue
 		}
-		metrics.RequestTotal.WithLabelValues("aws", "GetMetricData", "success").Inc()
-		metrics.RecordRequest("aws", "GetMetricData", "success")
-		metrics.RequestDuration.WithLabelValues("aws", "GetMetricData").Observe(time.Since(start).Seconds())
 
 		if len(out.MetricDataResults) == 0 {
 			ctxLog := logger.NewContextLogger("AWS", "account_id", account.AccountID, "region", region, "namespace", prod.Namespace)
@@ -497,9 +535,6 @@ func (c *Collector) processRegionLB(account config.CloudAccount, region string,
 
 					// Set labels: cloud_provider, account_id, region, resource_type, resource_id, namespace, metric_name, code_name
 					codeName := info.CodeName
-					if codeName == "" {
-						codeName = info.LBName
-					}
 
 					// Build label values array matching the expected label count
 					labelValues := []string{
@@ -514,11 +549,8 @@ func (c *Collector) processRegionLB(account config.CloudAccount, region string,
 					}
 
 					// Pad with empty strings if more labels are expected (for extra dimensions)
-					for len(labelValues) < labelCount {
-						labelValues = append(labelValues, "")
-					}
-
-					vec.WithLabelValues(labelValues...).Set(val)
+					vec.WithLabelValues(metrics.LabelValues(labelCount, info.Labels, labelValues...)...).Set(val)
+					common.RecordTargetSamples("aws", account.AccountID, region, prod.Namespace, 1)
 				}
 			}
 		}
@@ -90,7 +90,7 @@ func TestGetProductConfig_Found(t *testing.T) {
 func TestCollectLBGeneric_NoProduct(t *testing.T) {
 	c := &Collector{}
 	acc := config.CloudAccount{AccountID: "acc", Regions: []string{"us-east-1"}}
-	c.collectLBGeneric(acc, "AWS/ELB", &emptyLister{})
+	c.collectLBGeneric(context.Background(), acc, "AWS/ELB", &emptyLister{})
 }
 
 func TestProcessRegionLB_ErrorFromLister(t *testing.T) {
@@ -99,7 +99,7 @@ func TestProcessRegionLB_ErrorFromLister(t *testing.T) {
 		MetricInfo: []config.MetricGroup{{MetricList: []string{"qps"}}},
 	}
 	c := &Collector{}
-	c.processRegionLB(config.CloudAccount{AccountID: "acc"}, "us-east-1", prod, &errLister{})
+	c.processRegionLB(context.Background(), config.CloudAccount{AccountID: "acc"}, "us-east-1", prod, &errLister{})
 }
 
 type fixedLister struct {
@@ -144,7 +144,7 @@ func TestProcessRegionLB_BuildQueries_HandleCWError(t *testing.T) {
 		{Name: "alb-2", ARN: "arn:aws:elasticloadbalancing:us-east-1:123:loadbalancer/app/alb-2/bbbbbbbbbbbbbbbb", CodeName: "alb-2"},
 	}
 	c := &Collector{clientFactory: &cwOnlyFactory{}}
-	c.processRegionLB(config.CloudAccount{AccountID: "acc"}, "us-east-1", prod, &fixedLister{lbs: lbs})
+	c.processRegionLB(context.Background(), config.CloudAccount{AccountID: "acc"}, "us-east-1", prod, &fixedLister{lbs: lbs})
 }
 
 func TestProcessRegionLB_CLB_BuildQueries_HandleCWError(t *testing.T) {
@@ -158,7 +158,7 @@ func TestProcessRegionLB_CLB_BuildQueries_HandleCWError(t *testing.T) {
 		{Name: "clb-1", CodeName: "clb-1"},
 	}
 	c := &Collector{clientFactory: &cwOnlyFactory{}}
-	c.processRegionLB(config.CloudAccount{AccountID: "acc"}, "us-east-1", prod, &fixedLister{lbs: lbs})
+	c.processRegionLB(context.Background(), config.CloudAccount{AccountID: "acc"}, "us-east-1", prod, &fixedLister{lbs: lbs})
 }
 
 func TestProcessRegionLB_NLB_BuildQueries_HandleCWError(t *testing.T) {
@@ -172,7 +172,7 @@ func TestProcessRegionLB_NLB_BuildQueries_HandleCWError(t *testing.T) {
 		{Name: "nlb-1", ARN: "arn:aws:elasticloadbalancing:us-east-1:123:loadbalancer/net/nlb-1/cccccccccccccccc", CodeName: "nlb-1"},
 	}
 	c := &Collector{clientFactory: &cwOnlyFactory{}}
-	c.processRegionLB(config.CloudAccount{AccountID: "acc"}, "us-east-1", prod, &fixedLister{lbs: lbs})
+	c.processRegionLB(context.Background(), config.CloudAccount{AccountID: "acc"}, "us-east-1", prod, &fixedLister{lbs: lbs})
 }
 
 func TestProcessRegionLB_GWLB_BuildQueries_HandleCWError(t *testing.T) {
@@ -186,7 +186,7 @@ func TestProcessRegionLB_GWLB_BuildQueries_HandleCWError(t *testing.T) {
 		{Name: "gwlb-1", ARN: "arn:aws:elasticloadbalancing:us-east-1:123:loadbalancer/gwlb/gwlb-1/dddddddddddddddd", CodeName: "gwlb-1"},
 	}
 	c := &Collector{clientFactory: &cwOnlyFactory{}}
-	c.processRegionLB(config.CloudAccount{AccountID: "acc"}, "us-east-1", prod, &fixedLister{lbs: lbs})
+	c.processRegionLB(context.Background(), config.CloudAccount{AccountID: "acc"}, "us-east-1", prod, &fixedLister{lbs: lbs})
 }
 func TestProcessRegionLB_BatchQueries_SplitsCorrectly(t *testing.T) {
 	var metricsList []string
@@ -201,7 +201,7 @@ func TestProcessRegionLB_BatchQueries_SplitsCorrectly(t *testing.T) {
 		{Name: "alb-1", ARN: "arn:aws:elasticloadbalancing:us-east-1:123:loadbalancer/app/alb-1/aaaaaaaaaaaaaaaa", CodeName: "alb-1"},
 	}
 	c := &Collector{clientFactory: &cwOnlyFactory{}}
-	c.processRegionLB(config.CloudAccount{AccountID: "acc"}, "us-east-1", prod, &fixedLister{lbs: lbs})
+	c.processRegionLB(context.Background(), config.CloudAccount{AccountID: "acc"}, "us-east-1", prod, &fixedLister{lbs: lbs})
 }
 
 type cwMock struct {
@@ -269,7 +269,7 @@ func TestProcessRegionLB_ResultsApplyRate_ALB(t *testing.T) {
 	}
 	c := &Collector{clientFactory: cwMockFactory{}}
 	acc := config.CloudAccount{AccountID: "acc"}
-	c.processRegionLB(acc, "us-east-1", prod, &fixedLister{lbs: lbs})
+	c.processRegionLB(context.Background(), acc, "us-east-1", prod, &fixedLister{lbs: lbs})
 	val, ok := findGaugeValue("alb_traffic_rx_bps", map[string]string{ // Changed from alb_processedbytes
 		"cloud_provider": "aws",
 		"account_id":     "acc",
@@ -297,7 +297,7 @@ func TestProcessRegionLB_ResultsAverage_ALB(t *testing.T) {
 	}
 	c := &Collector{clientFactory: cwMockFactory{}}
 	acc := config.CloudAccount{AccountID: "acc"}
-	c.processRegionLB(acc, "us-east-1", prod, &fixedLister{lbs: lbs})
+	c.processRegionLB(context.Background(), acc, "us-east-1", prod, &fixedLister{lbs: lbs})
 	val, ok := findGaugeValue("alb_active_connection", map[string]string{ // Changed from alb_activeconnectioncount
 		"cloud_provider": "aws",
 		"account_id":     "acc",
@@ -324,7 +324,7 @@ func TestProcessRegionLB_DimensionFallback_ALB(t *testing.T) {
 	}
 	c := &Collector{clientFactory: cwMockFactory{}}
 	acc := config.CloudAccount{AccountID: "acc"}
-	c.processRegionLB(acc, "us-east-1", prod, &fixedLister{lbs: lbs})
+	c.processRegionLB(context.Background(), acc, "us-east-1", prod, &fixedLister{lbs: lbs})
 	_, ok := findGaugeValue("alb_traffic_rx_bps", map[string]string{
 		"resource_id": "alb-bad",
 	})
@@ -347,7 +347,7 @@ func TestProcessRegionLB_ScaleApplied_ALB(t *testing.T) {
 	}
 	c := &Collector{clientFactory: cwMockFactory{}}
 	acc := config.CloudAccount{AccountID: "acc"}
-	c.processRegionLB(acc, "us-east-1", prod, &fixedLister{lbs: lbs})
+	c.processRegionLB(context.Background(), acc, "us-east-1", prod, &fixedLister{lbs: lbs})
 	val, ok := findGaugeValue("alb_traffic_rx_bps", map[string]string{
 		"resource_id": "alb-3",
 	})
@@ -367,7 +367,7 @@ func TestProcessRegionLB_ResultsApplyRate_NLB(t *testing.T) {
 	}
 	c := &Collector{clientFactory: cwMockFactory{}}
 	acc := config.CloudAccount{AccountID: "acc"}
-	c.processRegionLB(acc, "us-east-1", prod, &fixedLister{lbs: lbs})
+	c.processRegionLB(context.Background(), acc, "us-east-1", prod, &fixedLister{lbs: lbs})
 	val, ok := findGaugeValue("nlb_traffic_rx_bps", map[string]string{
 		"cloud_provider": "aws",
 		"account_id":     "acc",
@@ -394,7 +394,7 @@ func TestProcessRegionLB_ResultsAverage_GWLB(t *testing.T) {
 	}
 	c := &Collector{clientFactory: cwMockFactory{}}
 	acc := config.CloudAccount{AccountID: "acc"}
-	c.processRegionLB(acc, "us-east-1", prod, &fixedLister{lbs: lbs})
+	c.processRegionLB(context.Background(), acc, "us-east-1", prod, &fixedLister{lbs: lbs})
 	val, ok := findGaugeValue("gwlb_active_connection", map[string]string{
 		"resource_type": "gwlb",
 		"resource_id":   "gwlb-1",
@@ -415,7 +415,7 @@ func TestProcessRegionLB_ResultsAverage_CLB(t *testing.T) {
 	}
 	c := &Collector{clientFactory: cwMockFactory{}}
 	acc := config.CloudAccount{AccountID: "acc"}
-	c.processRegionLB(acc, "us-east-1", prod, &fixedLister{lbs: lbs})
+	c.processRegionLB(context.Background(), acc, "us-east-1", prod, &fixedLister{lbs: lbs})
 	val, ok := findGaugeValue("clb_rt", map[string]string{
 		"resource_type": "clb",
 		"resource_id":   "clb-1",
@@ -437,7 +437,7 @@ func TestProcessRegionLB_NewConnection_Rate_ALB(t *testing.T) {
 	}
 	c := &Collector{clientFactory: cwMockFactory{}}
 	acc := config.CloudAccount{AccountID: "acc"}
-	c.processRegionLB(acc, "us-east-1", prod, &fixedLister{lbs: lbs})
+	c.processRegionLB(context.Background(), acc, "us-east-1", prod, &fixedLister{lbs: lbs})
 	val, ok := findGaugeValue("alb_new_connection", map[string]string{
 		"cloud_provider": "aws",
 		"account_id":     "acc",
@@ -464,7 +464,7 @@ func TestProcessRegionLB_HostCount_Average_ALB(t *testing.T) {
 	}
 	c := &Collector{clientFactory: cwMockFactory{}}
 	acc := config.CloudAccount{AccountID: "acc"}
-	c.processRegionLB(acc, "us-east-1", prod, &fixedLister{lbs: lbs})
+	c.processRegionLB(context.Background(), acc, "us-east-1", prod, &fixedLister{lbs: lbs})
 	val, ok := findGaugeValue("alb_healthy_host_count", map[string]string{
 		"cloud_provider": "aws",
 		"account_id":     "acc",
@@ -515,7 +515,7 @@ func TestProcessRegionLB_ExposeZero_WhenNoResults(t *testing.T) {
 	}
 	c := &Collector{clientFactory: cwEmptyFactory{}}
 	acc := config.CloudAccount{AccountID: "acc"}
-	c.processRegionLB(acc, "us-east-1", prod, &fixedLister{lbs: lbs})
+	c.processRegionLB(context.Background(), acc, "us-east-1", prod, &fixedLister{lbs: lbs})
 	val, ok := findGaugeValue("clb_qps", map[string]string{
 		"cloud_provider": "aws",
 		"account_id":     "acc",
@@ -556,7 +556,7 @@ func TestProcessRegionLB_CWClientError_NoPanic(t *testing.T) {
 	}
 	lbs := []lbInfo{{Name: "clb-1"}}
 	c := &Collector{clientFactory: badCWFactory{}}
-	c.processRegionLB(config.CloudAccount{AccountID: "acc"}, "us-east-1", prod, &fixedLister{lbs: lbs})
+	c.processRegionLB(context.Background(), config.CloudAccount{AccountID: "acc"}, "us-east-1", prod, &fixedLister{lbs: lbs})
 }
 
 type regionsFactory struct {
@@ -590,20 +590,30 @@ func TestCollectLBGeneric_RegionsWildcard_Fallback(t *testing.T) {
 	_ = mgr.Refresh(context.Background())
 	c := &Collector{disc: mgr, clientFactory: &regionsFactory{err: errors.New("ec2 error")}}
 	acc := config.CloudAccount{AccountID: "acc", Regions: []string{"*"}}
-	c.collectLBGeneric(acc, "AWS/ApplicationELB", &emptyLister{})
+	c.collectLBGeneric(context.Background(), acc, "AWS/ApplicationELB", &emptyLister{})
 }
-func TestResolveCodeName(t *testing.T) {
-	tags := map[string]string{
-		"kubernetes.io/service-name": "ns/svc",
-		"Name":                       "classic-name",
+func TestResolveLBCodeNames(t *testing.T) {
+	c := &Collector{}
+	lbs := []lbInfo{
+		{Name: "fallback", Tags: map[string]string{"kubernetes.io/service-name": "ns/svc", "Name": "classic-name"}},
+		{Name: "f", Tags: map[string]string{"Name": "n"}},
+		{Name: "f"},
 	}
-	if resolveCodeName(tags, "fallback") != "ns/svc" {
-		t.Fatalf("resolveCodeName priority mismatch")
+	c.resolveLBCodeNames(lbs)
+	if lbs[0].CodeName != "ns/svc" {
+		t.Fatalf("code_name priority mismatch")
 	}
-	if resolveCodeName(map[string]string{"Name": "n"}, "f") != "n" {
-		t.Fatalf("resolveCodeName Name mismatch")
+	if lbs[1].CodeName != "n" {
+		t.Fatalf("code_name Name mismatch")
 	}
-	if resolveCodeName(map[string]string{}, "f") != "f" {
-		t.Fatalf("resolveCodeName fallback mismatch")
+	if lbs[2].CodeName != "f" {
+		t.Fatalf("code_name fallback mismatch")
+	}
+
+	// server.code_name_chain 覆盖默认链
+	c.cfg = &config.Config{Server: &config.ServerConf{CodeNameChain: "tags.Name -> id"}}
+	c.resolveLBCodeNames(lbs)
+	if lbs[0].CodeName != "classic-name" || lbs[2].CodeName != "f" {
+		t.Fatalf("configured chain mismatch: %+v", lbs)
 	}
 }
@@ -1,16 +1,37 @@
 package aws
 
 import (
+	"regexp"
+
 	"multicloud-exporter/internal/config"
 	"multicloud-exporter/internal/discovery"
 	"multicloud-exporter/internal/providers"
+	providerscommon "multicloud-exporter/internal/providers/common"
 )
 
+// awsRegionPattern AWS 区域命名规则，如 us-east-1、ap-southeast-2、us-gov-west-1
+var awsRegionPattern = regexp.MustCompile(`^[a-z]{2}(-gov|-iso[a-z]?)?-[a-z]+-[0-9]+$`)
+
 // GetDefaultResources 返回 AWS 默认采集的资源类型
 func (c *Collector) GetDefaultResources() []string {
 	return []string{"s3"}
 }
 
+// SupportedResources 返回可在 resources 中配置的资源类型（含别名）
+func (c *Collector) SupportedResources() []string {
+	return []string{"s3", "alb", "clb", "nlb", "gwlb"}
+}
+
+// ValidRegion 校验区域名称格式
+func (c *Collector) ValidRegion(region string) bool {
+	return awsRegionPattern.MatchString(region)
+}
+
+// Scheduler 返回产品级采集调度器
+func (c *Collector) Scheduler() *providerscommon.ProductScheduler {
+	return c.scheduler
+}
+
 func init() {
 	providers.Register("aws", func(cfg *config.Config, mgr *discovery.Manager) providers.Provider {
 		return NewCollector(cfg, mgr)
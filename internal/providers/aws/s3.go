@@ -20,7 +20,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 )
 
-func (c *Collector) collectS3(account config.CloudAccount) {
+func (c *Collector) collectS3(ctx context.Context, account config.CloudAccount) {
 	if c.cfg == nil {
 		return
 	}
@@ -54,48 +54,45 @@ func (c *Collector) collectS3(account config.CloudAccount) {
 		ctxLog.Debugf("产品跳过（分片不匹配）")
 		return
 	}
+	// 产品级调度：S3 存储类指标按天更新，未配置 Period 时按天粒度调度
+	interval := common.ResolveProductInterval(c.cfg, "aws", *s3Prod, func(string) int { return 86400 })
+	if !c.scheduler.ShouldScrape("aws", account.AccountID, "global", s3Prod.Namespace, interval) {
+		ctxLog := logger.NewContextLogger("AWS", "account_id", account.AccountID, "region", "global", "namespace", s3Prod.Namespace)
+		ctxLog.Debugf("产品跳过（采集周期未到期，周期=%v）", interval)
+		return
+	}
+	// 区域级跳过：账号连续多轮没有存储桶，等待重新发现
+	if common.SkipEmptyRegion(c.regionManager, "aws", account.AccountID, "global", s3Prod.Namespace) {
+		return
+	}
 
-	// 创建上下文用于 S3 采集
-	ctx := context.Background()
+	target := common.StartTarget(ctx, "aws", account.AccountID, "global", s3Prod.Namespace)
+	defer func() {
+		c.scheduler.Finished("aws", account.AccountID, "global", s3Prod.Namespace, target.Finish())
+	}()
 
 	// S3 ListBuckets 是全局接口，region 可用 us-east-1。
 	s3Client, err := c.clientFactory.NewS3Client(ctx, "us-east-1", account.AccessKeyID, account.AccessKeySecret)
 	if err != nil {
 		ctxLog := logger.NewContextLogger("AWS", "account_id", account.AccountID, "region", "us-east-1", "namespace", s3Prod.Namespace)
+		common.RecordTargetError("aws", account.AccountID, "global", s3Prod.Namespace, common.ClassifyAWSError(err))
 		ctxLog.Errorf("S3客户端创建失败: %v", err)
 		return
 	}
-	start := time.Now()
-	var bucketsOut *s3.ListBucketsOutput
-	for attempt := 0; attempt < 5; attempt++ {
-		bucketsOut, err = s3Client.ListBuckets(ctx, &s3.ListBucketsInput{})
-		if err == nil {
-			metrics.RequestTotal.WithLabelValues("aws", "ListBuckets", "success").Inc()
-			metrics.RecordRequest("aws", "ListBuckets", "success")
-			metrics.RequestDuration.WithLabelValues("aws", "ListBuckets").Observe(time.Since(start).Seconds())
-			break
-		}
+	bucketsOut, err := common.Call(ctx, common.CallOptions{
+		Provider: "aws", AccountID: account.AccountID, Region: "us-east-1", API: "ListBuckets",
+		Attempts: 5, Retryable: common.RetryUnlessFatal,
+	}, func() (*s3.ListBucketsOutput, error) {
+		return s3Client.ListBuckets(ctx, &s3.ListBucketsInput{})
+	})
+	if err != nil {
+		ctxLog := logger.NewContextLogger("AWS", "account_id", account.AccountID, "region", "us-east-1", "namespace", s3Prod.Namespace)
 		status := common.ClassifyAWSError(err)
-		metrics.RequestTotal.WithLabelValues("aws", "ListBuckets", status).Inc()
-		metrics.RecordRequest("aws", "ListBuckets", status)
-		if status == "limit_error" {
-			metrics.RateLimitTotal.WithLabelValues("aws", "ListBuckets").Inc()
-		}
-		if status == "auth_error" {
-			ctxLog := logger.NewContextLogger("AWS", "account_id", account.AccountID, "region", "us-east-1", "namespace", s3Prod.Namespace)
+		common.RecordTargetError("aws", account.AccountID, "global", s3Prod.Namespace, status)
+		if status == common.ErrorStatusAuth {
 			ctxLog.Errorf("S3 ListBuckets 认证失败: %v", err)
 			return
 		}
-		if attempt < 4 {
-			sleep := time.Duration(200*(1<<attempt)) * time.Millisecond
-			if sleep > 5*time.Second {
-				sleep = 5 * time.Second
-			}
-			time.Sleep(sleep)
-		}
-	}
-	if err != nil {
-		ctxLog := logger.NewContextLogger("AWS", "account_id", account.AccountID, "region", "us-east-1", "namespace", s3Prod.Namespace)
 		ctxLog.Errorf("S3 ListBuckets API调用失败: %v", err)
 		return
 	}
@@ -106,16 +103,36 @@ func (c *Collector) collectS3(account config.CloudAccount) {
 			buckets = append(buckets, *b.Name)
 		}
 	}
+	// 区域状态按枚举总数更新
+	common.ReportRegionResources(c.regionManager, "aws", account.AccountID, "global", s3Prod.Namespace, len(buckets))
+	buckets = common.FilterIDs(common.NewResourceFilter(c.cfg, account, "s3"), buckets, nil, func(ids []string) map[string]map[string]string {
+		return c.fetchS3BucketTags(ctx, s3Client, ids)
+	})
 	if len(buckets) == 0 {
+		c.recordInventory(account, "global", s3Prod.Namespace, "s3", nil)
 		return
 	}
 
-	codeNames := c.fetchS3BucketCodeNames(ctx, s3Client, buckets)
+	// 配置了 tag_labels 时保留完整标签，用于提升为指标标签
+	var bucketTags map[string]map[string]string
+	var codeNames map[string]string
+	if common.NeedsTagLabels(c.cfg) {
+		bucketTags = c.fetchS3BucketTags(ctx, s3Client, buckets)
+		codeNames = common.ResolveCodeNames(c.cfg, common.DefaultCodeNameChain, buckets, bucketTags, nil)
+	} else {
+		codeNames = c.fetchS3BucketCodeNames(ctx, s3Client, buckets)
+	}
+	items := make([]common.InventoryItem, 0, len(buckets))
+	for _, bn := range buckets {
+		items = append(items, common.InventoryItem{ResourceID: bn, Name: bn, CodeName: codeNames[bn], Tags: bucketTags[bn]})
+	}
+	c.recordInventory(account, "global", s3Prod.Namespace, "s3", items)
 
 	// CloudWatch S3 指标维度：BucketName + StorageType（对存储类指标必填）
 	cwClient, err := c.clientFactory.NewCloudWatchClient(ctx, "us-east-1", account.AccessKeyID, account.AccessKeySecret)
 	if err != nil {
 		ctxLog := logger.NewContextLogger("AWS", "account_id", account.AccountID, "region", "us-east-1", "namespace", s3Prod.Namespace)
+		common.RecordTargetError("aws", account.AccountID, "global", s3Prod.Namespace, common.ClassifyAWSError(err))
 		ctxLog.Errorf("CloudWatch客户端创建失败: %v", err)
 		return
 	}
@@ -188,6 +205,9 @@ func (c *Collector) collectS3(account config.CloudAccount) {
 	minWindow := 30 * time.Minute
 
 	for _, metricInfo := range allMetrics {
+		if ctx.Err() != nil {
+			break
+		}
 		localPeriod := metricInfo.Period
 		metricName := metricInfo.Name
 		stat := metricInfo.Stat
@@ -235,42 +255,25 @@ func (c *Collector) collectS3(account config.CloudAccount) {
 				})
 			}
 
-			reqStart := time.Now()
-			var resp *cloudwatch.GetMetricDataOutput
-			for attempt := 0; attempt < 5; attempt++ {
-				resp, err = cwClient.GetMetricData(ctx, &cloudwatch.GetMetricDataInput{
+			resp, err := common.Call(ctx, common.CallOptions{
+				Provider: "aws", AccountID: account.AccountID, Region: "us-east-1", API: "GetMetricData",
+				Attempts: 5, Retryable: common.RetryUnlessFatal,
+			}, func() (*cloudwatch.GetMetricDataOutput, error) {
+				common.RecordMetricsRequested("aws", account.AccountID, "GetMetricData", len(queries))
+				return cwClient.GetMetricData(ctx, &cloudwatch.GetMetricDataInput{
 					StartTime:         aws.Time(startTime),
 					EndTime:           aws.Time(endTime),
 					MetricDataQueries: queries,
 					ScanBy:            cwtypes.ScanByTimestampDescending,
 				})
-				if err == nil {
-					metrics.RequestTotal.WithLabelValues("aws", "GetMetricData", "success").Inc()
-					metrics.RecordRequest("aws", "GetMetricData", "success")
-					metrics.RequestDuration.WithLabelValues("aws", "GetMetricData").Observe(time.Since(reqStart).Seconds())
-					break
-				}
+			})
+			if err != nil {
 				status := common.ClassifyAWSError(err)
-				metrics.RequestTotal.WithLabelValues("aws", "GetMetricData", status).Inc()
-				metrics.RecordRequest("aws", "GetMetricData", status)
-				if status == "limit_error" {
-					metrics.RateLimitTotal.WithLabelValues("aws", "GetMetricData").Inc()
-				}
-				if status == "auth_error" {
-					ctxLog := logger.NewContextLogger("AWS", "account_id", account.AccountID, "region", "us-east-1", "namespace", s3Prod.Namespace)
+				common.RecordTargetError("aws", account.AccountID, "global", s3Prod.Namespace, status)
+				ctxLog := logger.NewContextLogger("AWS", "account_id", account.AccountID, "region", "us-east-1", "namespace", s3Prod.Namespace)
+				if status == common.ErrorStatusAuth {
 					ctxLog.Errorf("CloudWatch GetMetricData 认证失败, 指标=%s: %v", metricName, err)
-					break
-				}
-				if attempt < 4 {
-					sleep := time.Duration(200*(1<<attempt)) * time.Millisecond
-					if sleep > 5*time.Second {
-						sleep = 5 * time.Second
-					}
-					time.Sleep(sleep)
 				}
-			}
-			if err != nil {
-				ctxLog := logger.NewContextLogger("AWS", "account_id", account.AccountID, "region", "us-east-1", "namespace", s3Prod.Namespace)
 				ctxLog.Warnf("CloudWatch GetMetricData API调用失败, 指标=%s, 批次=%d-%d: %v", metricName, batchStart, batchEnd, err)
 				continue
 			}
@@ -281,11 +284,6 @@ func (c *Collector) collectS3(account config.CloudAccount) {
 					allResults[*r.Id] = r
 				}
 			}
-
-			// 批次间轻微节流
-			if batchEnd < len(buckets) {
-				time.Sleep(50 * time.Millisecond)
-			}
 		}
 
 		// 第三步：处理当前指标的所有结果
@@ -321,18 +319,12 @@ func (c *Collector) collectS3(account config.CloudAccount) {
 				// BucketName 维度值 = resource_id (bn)，FilterId 维度值 = filterID
 				labels = append(labels, bn, filterID)
 			}
-			// 确保 labels 数量与 GaugeVec 的标签数量匹配
-			if len(labels) > count {
-				labels = labels[:count]
-			} else {
-				for len(labels) < count {
-					labels = append(labels, "")
-				}
-			}
+			// 确保 labels 数量与 GaugeVec 的标签数量匹配，并追加自定义标签
+			labels = metrics.LabelValues(count, common.CustomLabels(c.cfg, account, bucketTags[bn]), labels...)
 			// CloudWatch 返回 float64，scale 统一通过 mappings 注册（若配置了）
 			scaled := val * metrics.GetMetricScale(s3Prod.Namespace, metricName)
 			vec.WithLabelValues(labels...).Set(scaled)
-			metrics.IncSampleCount(s3Prod.Namespace, 1)
+			common.RecordTargetSamples("aws", account.AccountID, "global", s3Prod.Namespace, 1)
 			metricsCollected[metricName]++
 		}
 
@@ -381,8 +373,14 @@ func statForS3Metric(metricName string) string {
 	}
 }
 
+// fetchS3BucketCodeNames 拉取 bucket 标签并按解析链生成 code_name
 func (c *Collector) fetchS3BucketCodeNames(ctx context.Context, client S3API, buckets []string) map[string]string {
-	out := make(map[string]string, len(buckets))
+	return common.ResolveCodeNames(c.cfg, common.DefaultCodeNameChain, buckets, c.fetchS3BucketTags(ctx, client, buckets), nil)
+}
+
+// fetchS3BucketTags 并发获取 bucket 的全部标签
+func (c *Collector) fetchS3BucketTags(ctx context.Context, client S3API, buckets []string) map[string]map[string]string {
+	out := make(map[string]map[string]string, len(buckets))
 	var mu sync.Mutex
 	const maxConcurrency = 10
 	sem := make(chan struct{}, maxConcurrency)
@@ -395,39 +393,12 @@ func (c *Collector) fetchS3BucketCodeNames(ctx context.Context, client S3API, bu
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			reqStart := time.Now()
-			var resp *s3.GetBucketTaggingOutput
-			var err error
-			for attempt := 0; attempt < 3; attempt++ {
-				resp, err = client.GetBucketTagging(ctx, &s3.GetBucketTaggingInput{Bucket: aws.String(bucket)})
-				if err == nil {
-					metrics.RequestTotal.WithLabelValues("aws", "GetBucketTagging", "success").Inc()
-					metrics.RecordRequest("aws", "GetBucketTagging", "success")
-					metrics.RequestDuration.WithLabelValues("aws", "GetBucketTagging").Observe(time.Since(reqStart).Seconds())
-					break
-				}
-				status := common.ClassifyAWSError(err)
-				metrics.RequestTotal.WithLabelValues("aws", "GetBucketTagging", status).Inc()
-				metrics.RecordRequest("aws", "GetBucketTagging", status)
-				if status == "limit_error" {
-					metrics.RateLimitTotal.WithLabelValues("aws", "GetBucketTagging").Inc()
-				}
-				// NoSuchTagSet 是正常情况（bucket 没有标签），不需要重试
-				if strings.Contains(err.Error(), "NoSuchTagSet") {
-					return
-				}
-				if status == "auth_error" {
-					return
-				}
-				// 指数退避重试
-				if attempt < 2 {
-					sleep := time.Duration(200*(1<<attempt)) * time.Millisecond
-					if sleep > 5*time.Second {
-						sleep = 5 * time.Second
-					}
-					time.Sleep(sleep)
-				}
-			}
+			// NoSuchTagSet 是正常情况（bucket 没有标签），仅重试限流与网络错误
+			resp, err := common.Call(ctx, common.CallOptions{
+				Provider: "aws", API: "GetBucketTagging", Attempts: 3,
+			}, func() (*s3.GetBucketTaggingOutput, error) {
+				return client.GetBucketTagging(ctx, &s3.GetBucketTaggingInput{Bucket: aws.String(bucket)})
+			})
 			if err != nil {
 				return
 			}
@@ -437,13 +408,12 @@ func (c *Collector) fetchS3BucketCodeNames(ctx context.Context, client S3API, bu
 				return
 			}
 
-			mu.Lock()
+			tags := make(map[string]string, len(resp.TagSet))
 			for _, t := range resp.TagSet {
-				if strings.EqualFold(aws.ToString(t.Key), "CodeName") || strings.EqualFold(aws.ToString(t.Key), "code_name") {
-					out[bucket] = aws.ToString(t.Value)
-					break
-				}
+				tags[aws.ToString(t.Key)] = aws.ToString(t.Value)
 			}
+			mu.Lock()
+			out[bucket] = tags
 			mu.Unlock()
 		}(b)
 	}
@@ -87,14 +87,14 @@ func TestFetchS3BucketCodeNames_ErrorNoPanic(t *testing.T) {
 
 func TestCollectS3_NoConfig(t *testing.T) {
 	c := &Collector{}
-	c.collectS3(config.CloudAccount{AccountID: "a"})
+	c.collectS3(context.Background(), config.CloudAccount{AccountID: "a"})
 }
 
 func TestCollectS3_NoProduct(t *testing.T) {
 	mgr := discovery.NewManager(&config.Config{})
 	_ = mgr.Refresh(context.Background())
 	c := &Collector{cfg: &config.Config{}, disc: mgr}
-	c.collectS3(config.CloudAccount{AccountID: "a"})
+	c.collectS3(context.Background(), config.CloudAccount{AccountID: "a"})
 }
 
 type mockDiscovererS3 struct {
@@ -134,7 +134,7 @@ func TestCollectS3_ListBuckets_ErrorPaths(t *testing.T) {
 	mgr := discovery.NewManager(&config.Config{})
 	_ = mgr.Refresh(context.Background())
 	c := &Collector{cfg: &config.Config{}, disc: mgr, clientFactory: localS3Factory{}}
-	c.collectS3(config.CloudAccount{AccountID: "a"})
+	c.collectS3(context.Background(), config.CloudAccount{AccountID: "a"})
 }
 
 type s3ListMock struct {
@@ -288,7 +288,7 @@ func TestCollectS3_BucketSizeBytes_AverageLabelsAndValue(t *testing.T) {
 		values:  map[string]float64{"q0": 1024},
 	}
 	c := &Collector{cfg: &config.Config{}, disc: mgr, clientFactory: f}
-	c.collectS3(config.CloudAccount{AccountID: "acc"})
+	c.collectS3(context.Background(), config.CloudAccount{AccountID: "acc"})
 	val, ok := findGaugeValue("s3_storage_usage_bytes", map[string]string{
 		"cloud_provider": "aws",
 		"account_id":     "acc",
@@ -318,7 +318,7 @@ func TestCollectS3_GetRequests_FilterIdLabel(t *testing.T) {
 		values:  map[string]float64{"q0": 3600},
 	}
 	c := &Collector{cfg: &config.Config{}, disc: mgr, clientFactory: f}
-	c.collectS3(config.CloudAccount{AccountID: "acc"})
+	c.collectS3(context.Background(), config.CloudAccount{AccountID: "acc"})
 	val, ok := findGaugeValue("s3_requests_get", map[string]string{
 		"cloud_provider": "aws",
 		"account_id":     "acc",
@@ -348,7 +348,7 @@ func TestCollectS3_NumberOfObjects_StorageTypeAll(t *testing.T) {
 		values:  map[string]float64{"q0": 42},
 	}
 	c := &Collector{cfg: &config.Config{}, disc: mgr, clientFactory: f}
-	c.collectS3(config.CloudAccount{AccountID: "acc"})
+	c.collectS3(context.Background(), config.CloudAccount{AccountID: "acc"})
 	val, ok := findGaugeValue("s3_number_of_objects", map[string]string{
 		"cloud_provider": "aws",
 		"account_id":     "acc",
@@ -379,7 +379,7 @@ func TestCollectS3_ScaleApplied_GetRequests(t *testing.T) {
 		values:  map[string]float64{"q0": 3600},
 	}
 	c := &Collector{cfg: &config.Config{}, disc: mgr, clientFactory: f}
-	c.collectS3(config.CloudAccount{AccountID: "acc"})
+	c.collectS3(context.Background(), config.CloudAccount{AccountID: "acc"})
 	val, ok := findGaugeValue("s3_requests_get", map[string]string{
 		"resource_id": "b4",
 		"code_name":   "",
@@ -402,7 +402,7 @@ func TestCollectS3_ListBuckets_RateLimitCounter(t *testing.T) {
 		values:  map[string]float64{"q0": 1},
 	}
 	c := &Collector{cfg: &config.Config{}, disc: mgr, clientFactory: f}
-	c.collectS3(config.CloudAccount{AccountID: "acc"})
+	c.collectS3(context.Background(), config.CloudAccount{AccountID: "acc"})
 	cnt, ok := findCounterValue("multicloud_rate_limit_total", map[string]string{
 		"cloud_provider": "aws",
 		"api":            "ListBuckets",
@@ -425,7 +425,7 @@ func TestCollectS3_GetMetricData_RateLimitCounter(t *testing.T) {
 		values:  map[string]float64{"q0": 100},
 	}
 	c := &Collector{cfg: &config.Config{}, disc: mgr, clientFactory: f}
-	c.collectS3(config.CloudAccount{AccountID: "acc"})
+	c.collectS3(context.Background(), config.CloudAccount{AccountID: "acc"})
 	cnt, ok := findCounterValue("multicloud_rate_limit_total", map[string]string{
 		"cloud_provider": "aws",
 		"api":            "GetMetricData",
@@ -0,0 +1,273 @@
+package common
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"multicloud-exporter/internal/config"
+	"multicloud-exporter/internal/logger"
+	"multicloud-exporter/internal/metrics"
+	"multicloud-exporter/internal/utils"
+)
+
+// 熔断器状态
+const (
+	BreakerClosed   = "closed"    // 正常采集
+	BreakerOpen     = "open"      // 熔断中，跳过采集
+	BreakerHalfOpen = "half_open" // 熔断到期，放行一轮采集作为探测
+)
+
+// 熔断默认参数
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerOpen      = 5 * time.Minute
+	defaultBreakerMaxOpen   = time.Hour
+)
+
+// BreakerEntry 熔断器状态快照（/status 展示）
+type BreakerEntry struct {
+	Provider  string `json:"provider"`
+	AccountID string `json:"account_id"`
+	// Region 为空表示账号级熔断器
+	Region    string    `json:"region,omitempty"`
+	State     string    `json:"state"`
+	Failures  int       `json:"failures"`
+	LastError string    `json:"last_error,omitempty"`
+	OpenedAt  time.Time `json:"opened_at,omitempty"`
+	NextProbe time.Time `json:"next_probe,omitempty"`
+}
+
+// CircuitBreaker 账号或区域的熔断器：连续错误达到阈值后熔断，熔断期间跳过采集；
+// 到期后进入半开状态放行一轮采集，探测成功恢复正常，失败则熔断时长翻倍（不超过上限）重新熔断
+type CircuitBreaker struct {
+	provider  string
+	accountID string
+	region    string
+
+	mu        sync.Mutex
+	state     string
+	failures  int
+	lastError string
+	openedAt  time.Time
+	openFor   time.Duration
+	nextProbe time.Time
+}
+
+// breakerParams 生效的熔断参数
+type breakerParams struct {
+	enabled   bool
+	threshold int
+	open      time.Duration
+	maxOpen   time.Duration
+}
+
+var (
+	breakerMu     sync.Mutex
+	breakerConfig breakerParams
+	// breakers provider|account|region -> 熔断器，账号级熔断器的 region 为空；首次出错时创建
+	breakers = make(map[string]*CircuitBreaker)
+	// breakerNow 当前时间，测试时替换
+	breakerNow = time.Now
+)
+
+// ConfigureCircuitBreakers 设置熔断参数（server.circuit_breaker），并丢弃已有的熔断器
+func ConfigureCircuitBreakers(conf *config.CircuitBreakerConf) {
+	s := breakerParams{threshold: defaultBreakerThreshold, open: defaultBreakerOpen, maxOpen: defaultBreakerMaxOpen}
+	if conf != nil {
+		s.enabled = conf.Enabled
+		if conf.FailureThreshold > 0 {
+			s.threshold = conf.FailureThreshold
+		}
+		s.open = parseBreakerDuration("open_duration", conf.OpenDuration, s.open)
+		s.maxOpen = parseBreakerDuration("max_open_duration", conf.MaxOpenDuration, s.maxOpen)
+		if s.maxOpen < s.open {
+			s.maxOpen = s.open
+		}
+	}
+	breakerMu.Lock()
+	defer breakerMu.Unlock()
+	breakerConfig = s
+	breakers = make(map[string]*CircuitBreaker)
+	metrics.CircuitBreakerState.Reset()
+}
+
+func parseBreakerDuration(name, v string, def time.Duration) time.Duration {
+	if v == "" {
+		return def
+	}
+	d, err := utils.ParseDuration(v)
+	if err != nil || d <= 0 {
+		ctxLog := logger.NewContextLogger("CircuitBreaker", "resource_type", "Config")
+		ctxLog.Warnf("circuit_breaker.%s 解析失败，使用默认值 %v: %s", name, def, v)
+		return def
+	}
+	return d
+}
+
+func breakerKey(provider, accountID, region string) string {
+	return provider + "|" + accountID + "|" + region
+}
+
+// getBreaker 获取熔断器，create 为 false 且不存在时返回 nil；未启用熔断时返回 nil
+func getBreaker(provider, accountID, region string, create bool) (*CircuitBreaker, breakerParams) {
+	breakerMu.Lock()
+	defer breakerMu.Unlock()
+	s := breakerConfig
+	if !s.enabled || accountID == "" {
+		return nil, s
+	}
+	key := breakerKey(provider, accountID, region)
+	b, ok := breakers[key]
+	if !ok && create {
+		b = &CircuitBreaker{provider: provider, accountID: accountID, region: region, state: BreakerClosed}
+		breakers[key] = b
+	}
+	return b, s
+}
+
+// AllowAccount 判断账号本轮是否采集：熔断中返回 false；熔断到期时转为半开并放行本轮作为探测
+func AllowAccount(provider, accountID string) bool {
+	b, _ := getBreaker(provider, accountID, "", false)
+	return b == nil || b.allow(breakerNow())
+}
+
+// AllowRegion 判断账号的区域是否采集：账号熔断中（含本轮采集中途熔断）或区域熔断中时返回 false
+func AllowRegion(provider, accountID, region string) bool {
+	if b, _ := getBreaker(provider, accountID, "", false); b != nil && b.State() == BreakerOpen {
+		return false
+	}
+	b, _ := getBreaker(provider, accountID, region, false)
+	return b == nil || b.allow(breakerNow())
+}
+
+// recordBreakerFailure 记录一次熔断相关错误：账号级 auth_error，区域级 region_skip
+func recordBreakerFailure(provider, accountID, region, status string) {
+	b, s := getBreaker(provider, accountID, region, true)
+	if b != nil {
+		b.onFailure(s, status, breakerNow())
+	}
+}
+
+// recordBreakerSuccess 记录一次成功：清零连续错误数，半开状态下恢复正常
+func recordBreakerSuccess(provider, accountID, region string) {
+	if b, _ := getBreaker(provider, accountID, region, false); b != nil {
+		b.onSuccess()
+	}
+}
+
+// BreakerEntries 返回按账号/区域排序的熔断器状态快照
+func BreakerEntries() []BreakerEntry {
+	breakerMu.Lock()
+	list := make([]*CircuitBreaker, 0, len(breakers))
+	for _, b := range breakers {
+		list = append(list, b)
+	}
+	breakerMu.Unlock()
+	out := make([]BreakerEntry, 0, len(list))
+	for _, b := range list {
+		out = append(out, b.Entry())
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return breakerKey(out[i].Provider, out[i].AccountID, out[i].Region) < breakerKey(out[j].Provider, out[j].AccountID, out[j].Region)
+	})
+	return out
+}
+
+// State 返回熔断器当前状态
+func (b *CircuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Entry 返回熔断器状态快照
+func (b *CircuitBreaker) Entry() BreakerEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e := BreakerEntry{
+		Provider:  b.provider,
+		AccountID: b.accountID,
+		Region:    b.region,
+		State:     b.state,
+		Failures:  b.failures,
+		LastError: b.lastError,
+	}
+	if b.state != BreakerClosed {
+		e.OpenedAt = b.openedAt
+		e.NextProbe = b.nextProbe
+	}
+	return e
+}
+
+func (b *CircuitBreaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state != BreakerOpen {
+		return true
+	}
+	if now.Before(b.nextProbe) {
+		return false
+	}
+	b.setStateLocked(BreakerHalfOpen)
+	b.ctxLogger().Infof("熔断到期，半开探测")
+	return true
+}
+
+func (b *CircuitBreaker) onFailure(s breakerParams, status string, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.lastError = status
+	switch b.state {
+	case BreakerOpen:
+		return
+	case BreakerHalfOpen:
+		// 探测失败：熔断时长翻倍
+		b.openFor *= 2
+		if b.openFor > s.maxOpen {
+			b.openFor = s.maxOpen
+		}
+	default:
+		if b.failures < s.threshold {
+			return
+		}
+		b.openFor = s.open
+	}
+	b.openedAt = now
+	b.nextProbe = now.Add(b.openFor)
+	b.setStateLocked(BreakerOpen)
+	b.ctxLogger().Warnf("连续 %d 次 %s，熔断 %v，期间跳过采集", b.failures, status, b.openFor)
+}
+
+func (b *CircuitBreaker) onSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	if b.state != BreakerHalfOpen {
+		return
+	}
+	b.openFor = 0
+	b.setStateLocked(BreakerClosed)
+	b.ctxLogger().Infof("探测成功，恢复采集")
+}
+
+// setStateLocked 切换状态并导出指标，调用方持有 b.mu
+func (b *CircuitBreaker) setStateLocked(state string) {
+	b.state = state
+	v := 0.0
+	switch state {
+	case BreakerOpen:
+		v = 1
+	case BreakerHalfOpen:
+		v = 2
+	}
+	metrics.CircuitBreakerState.WithLabelValues(b.provider, b.accountID, b.region).Set(v)
+}
+
+func (b *CircuitBreaker) ctxLogger() *logger.ContextLogger {
+	if b.region != "" {
+		return logger.NewContextLogger("CircuitBreaker", "cloud_provider", b.provider, "account_id", b.accountID, "region", b.region)
+	}
+	return logger.NewContextLogger("CircuitBreaker", "cloud_provider", b.provider, "account_id", b.accountID)
+}
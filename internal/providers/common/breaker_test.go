@@ -0,0 +1,158 @@
+package common
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"multicloud-exporter/internal/config"
+	"multicloud-exporter/internal/metrics"
+)
+
+// useBreakers 以可控时钟启用熔断，测试结束后停用
+func useBreakers(t *testing.T, conf config.CircuitBreakerConf, now *time.Time) {
+	t.Helper()
+	conf.Enabled = true
+	ConfigureCircuitBreakers(&conf)
+	breakerNow = func() time.Time { return *now }
+	t.Cleanup(func() {
+		breakerNow = time.Now
+		ConfigureCircuitBreakers(nil)
+	})
+}
+
+func TestCircuitBreaker_AccountOpensAndProbes(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	useBreakers(t, config.CircuitBreakerConf{FailureThreshold: 3, OpenDuration: "1m", MaxOpenDuration: "3m"}, &now)
+
+	for i := 0; i < 2; i++ {
+		RecordAccountError("mock", "acc-cb", ErrorStatusAuth)
+	}
+	if !AllowAccount("mock", "acc-cb") {
+		t.Fatalf("breaker should stay closed below the threshold")
+	}
+	// 其他错误类别不计入
+	RecordAccountError("mock", "acc-cb", ErrorStatusNetwork)
+	if !AllowAccount("mock", "acc-cb") {
+		t.Fatalf("non-auth errors must not open the breaker")
+	}
+	RecordTargetError("mock", "acc-cb", "r1", "ns", ErrorStatusAuth)
+	TakeAccountErrors("mock", "acc-cb")
+	if AllowAccount("mock", "acc-cb") || AllowRegion("mock", "acc-cb", "r2") {
+		t.Fatalf("breaker should open after repeated auth errors")
+	}
+	if v := testutil.ToFloat64(metrics.CircuitBreakerState.WithLabelValues("mock", "acc-cb", "")); v != 1 {
+		t.Fatalf("state gauge expected 1 (open), got %v", v)
+	}
+
+	// 到期后半开探测，探测失败熔断时长翻倍
+	now = now.Add(time.Minute)
+	if !AllowAccount("mock", "acc-cb") {
+		t.Fatalf("breaker should allow a probe after open_duration")
+	}
+	if !AllowRegion("mock", "acc-cb", "r1") {
+		t.Fatalf("regions should be collected during the probe")
+	}
+	RecordAccountError("mock", "acc-cb", ErrorStatusAuth)
+	TakeAccountErrors("mock", "acc-cb")
+	entries := BreakerEntries()
+	if len(entries) != 1 || entries[0].State != BreakerOpen || !entries[0].NextProbe.Equal(now.Add(2*time.Minute)) {
+		t.Fatalf("failed probe should reopen with doubled duration, got %+v", entries)
+	}
+	now = now.Add(2 * time.Minute)
+	AllowAccount("mock", "acc-cb")
+	RecordAccountError("mock", "acc-cb", ErrorStatusAuth)
+	TakeAccountErrors("mock", "acc-cb")
+	if e := BreakerEntries()[0]; !e.NextProbe.Equal(now.Add(3 * time.Minute)) {
+		t.Fatalf("open duration should be capped by max_open_duration, got next probe %v", e.NextProbe)
+	}
+
+	// 探测成功后恢复
+	now = now.Add(3 * time.Minute)
+	if !AllowAccount("mock", "acc-cb") {
+		t.Fatalf("breaker should allow a probe")
+	}
+	RecordRequest("mock", "acc-cb", "Describe", "success")
+	if e := BreakerEntries()[0]; e.State != BreakerClosed || e.Failures != 0 {
+		t.Fatalf("successful probe should close the breaker, got %+v", e)
+	}
+	if v := testutil.ToFloat64(metrics.CircuitBreakerState.WithLabelValues("mock", "acc-cb", "")); v != 0 {
+		t.Fatalf("state gauge expected 0 (closed), got %v", v)
+	}
+}
+
+func TestCircuitBreaker_SuccessResetsFailures(t *testing.T) {
+	now := time.Now()
+	useBreakers(t, config.CircuitBreakerConf{FailureThreshold: 2}, &now)
+
+	for i := 0; i < 5; i++ {
+		RecordAccountError("mock", "acc-flaky", ErrorStatusAuth)
+		RecordRequest("mock", "acc-flaky", "Describe", "success")
+	}
+	TakeAccountErrors("mock", "acc-flaky")
+	if !AllowAccount("mock", "acc-flaky") {
+		t.Fatalf("interleaved successes should keep the breaker closed")
+	}
+}
+
+func TestCircuitBreaker_RegionSkip(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	useBreakers(t, config.CircuitBreakerConf{FailureThreshold: 2, OpenDuration: "1m"}, &now)
+
+	for i := 0; i < 2; i++ {
+		RecordTargetError("mock", "acc-rs", "bad-region", "ns", ErrorStatusRegion)
+	}
+	TakeAccountErrors("mock", "acc-rs")
+	if AllowRegion("mock", "acc-rs", "bad-region") {
+		t.Fatalf("region breaker should open after repeated region_skip")
+	}
+	if !AllowRegion("mock", "acc-rs", "good-region") || !AllowAccount("mock", "acc-rs") {
+		t.Fatalf("region breaker must not affect other regions or the account")
+	}
+
+	// 探测：区域目标成功后恢复
+	now = now.Add(time.Minute)
+	if !AllowRegion("mock", "acc-rs", "bad-region") {
+		t.Fatalf("region should be probed after open_duration")
+	}
+	StartTarget(context.Background(), "mock", "acc-rs", "bad-region", "ns").Finish()
+	if !AllowRegion("mock", "acc-rs", "bad-region") {
+		t.Fatalf("successful target should close the region breaker")
+	}
+	for _, e := range BreakerEntries() {
+		if e.Region == "bad-region" && e.State != BreakerClosed {
+			t.Fatalf("region breaker expected closed, got %+v", e)
+		}
+	}
+}
+
+func TestCircuitBreaker_RegionSkipTargetDoesNotReset(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	useBreakers(t, config.CircuitBreakerConf{FailureThreshold: 2, OpenDuration: "1m"}, &now)
+
+	// 每轮目标都只遇到 region_skip：目标本轮不算失败，但不能借此清零区域熔断器的连续错误数
+	for i := 0; i < 2; i++ {
+		target := StartTarget(context.Background(), "mock", "acc-rsk", "skip-region", "ns")
+		RecordTargetError("mock", "acc-rsk", "skip-region", "ns", ErrorStatusRegion)
+		if !target.Finish() {
+			t.Fatalf("region_skip alone should not fail the target")
+		}
+	}
+	TakeAccountErrors("mock", "acc-rsk")
+	if AllowRegion("mock", "acc-rsk", "skip-region") {
+		t.Fatalf("region breaker should open when every cycle hits region_skip")
+	}
+}
+
+func TestCircuitBreaker_Disabled(t *testing.T) {
+	ConfigureCircuitBreakers(nil)
+	for i := 0; i < 20; i++ {
+		RecordAccountError("mock", "acc-off", ErrorStatusAuth)
+	}
+	TakeAccountErrors("mock", "acc-off")
+	if !AllowAccount("mock", "acc-off") || len(BreakerEntries()) != 0 {
+		t.Fatalf("disabled breaker must always allow")
+	}
+}
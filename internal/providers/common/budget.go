@@ -0,0 +1,504 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"multicloud-exporter/internal/config"
+	"multicloud-exporter/internal/logger"
+	"multicloud-exporter/internal/metrics"
+)
+
+// 预算默认参数
+const (
+	defaultBudgetDegradeFactor = 4
+	defaultBudgetPersistFile   = "budget_usage.json"
+	defaultBudgetDataDir       = "/app/data"
+	budgetHistoryDays          = 7
+	budgetDayLayout            = "2006-01-02"
+)
+
+// DefaultBudgetPrices 内置单价（云厂商公开定价），可被 budgets.prices 覆盖
+var DefaultBudgetPrices = map[string]config.BudgetPrice{
+	// AWS CloudWatch GetMetricData：每千个请求的指标 0.01 USD
+	"aws.GetMetricData": {PerThousandMetrics: 0.01},
+}
+
+// BudgetDay 单日用量汇总
+type BudgetDay struct {
+	Day     string  `json:"day"`
+	Calls   int64   `json:"calls"`
+	Metrics int64   `json:"metrics"`
+	Cost    float64 `json:"cost"`
+}
+
+// BudgetUsageStatus 账号或云的当日用量与预算
+type BudgetUsageStatus struct {
+	Provider  string              `json:"provider"`
+	AccountID string              `json:"account_id,omitempty"`
+	Calls     int64               `json:"calls"`
+	Metrics   int64               `json:"metrics,omitempty"`
+	Cost      float64             `json:"cost"`
+	Limit     *config.BudgetLimit `json:"limit,omitempty"`
+	Exceeded  bool                `json:"exceeded"`
+}
+
+// BudgetStatus 预算状态（/status 展示）
+type BudgetStatus struct {
+	Day                  string              `json:"day"`
+	Accounts             []BudgetUsageStatus `json:"accounts"`
+	Providers            []BudgetUsageStatus `json:"providers"`
+	CostToday            float64             `json:"cost_today"`
+	ProjectedMonthlyCost float64             `json:"projected_monthly_cost"`
+	DegradeFactor        int                 `json:"degrade_factor"`
+	History              []BudgetDay         `json:"history,omitempty"`
+}
+
+// apiUsage 单个账号按 API 统计的用量
+type apiUsage struct {
+	Calls   map[string]int64 `json:"calls"`
+	Metrics map[string]int64 `json:"metrics,omitempty"`
+}
+
+// budgetState 持久化的当日用量
+type budgetState struct {
+	Day string `json:"day"`
+	// Since 当日开始计量的时间，用于估算全天用量
+	Since time.Time `json:"since"`
+	// Usage provider|account -> API 用量
+	Usage   map[string]*apiUsage `json:"usage"`
+	History []BudgetDay          `json:"history,omitempty"`
+}
+
+// BudgetTracker 每日云 API 调用预算跟踪器
+type BudgetTracker struct {
+	conf         config.BudgetConf
+	factor       int
+	baseInterval time.Duration
+	path         string
+	now          func() time.Time
+
+	mu    sync.Mutex
+	state budgetState
+	// exceeded provider|account 或 provider -> 当日预算已用尽
+	exceeded map[string]bool
+	dirty    bool
+}
+
+var (
+	budgetMu sync.RWMutex
+	budget   *BudgetTracker
+)
+
+// ConfigureBudgets 启用每日预算（server.budgets），conf 为 nil 时停用。
+// dataDir 为用量持久化目录（region_discovery.data_dir），baseInterval 为采集间隔，
+// 用于放大每轮都采集（周期为 0）的产品。返回的跟踪器已加载当日持久化用量。
+func ConfigureBudgets(conf *config.BudgetConf, dataDir string, baseInterval time.Duration) *BudgetTracker {
+	var t *BudgetTracker
+	if conf != nil {
+		t = newBudgetTracker(*conf, dataDir, baseInterval, time.Now)
+		if err := t.Load(); err != nil {
+			ctxLog := logger.NewContextLogger("Budget", "resource_type", "Persistence")
+			ctxLog.Warnf("加载预算用量失败，从零开始计量: %v", err)
+		}
+	}
+	budgetMu.Lock()
+	budget = t
+	budgetMu.Unlock()
+	metrics.BudgetUsage.Reset()
+	metrics.BudgetExceeded.Reset()
+	if t != nil {
+		t.mu.Lock()
+		t.refreshLocked()
+		t.mu.Unlock()
+	}
+	return t
+}
+
+func newBudgetTracker(conf config.BudgetConf, dataDir string, baseInterval time.Duration, now func() time.Time) *BudgetTracker {
+	factor := conf.DegradeFactor
+	if factor <= 0 {
+		factor = defaultBudgetDegradeFactor
+	}
+	if dataDir == "" {
+		dataDir = defaultBudgetDataDir
+	}
+	file := conf.PersistFile
+	if file == "" {
+		file = defaultBudgetPersistFile
+	}
+	return &BudgetTracker{
+		conf:         conf,
+		factor:       factor,
+		baseInterval: baseInterval,
+		path:         filepath.Join(dataDir, file),
+		now:          now,
+		state:        budgetState{Usage: make(map[string]*apiUsage)},
+		exceeded:     make(map[string]bool),
+	}
+}
+
+func activeBudget() *BudgetTracker {
+	budgetMu.RLock()
+	defer budgetMu.RUnlock()
+	return budget
+}
+
+// RecordBudgetCall 计入一次云 API 调用（与 RecordRequest 同一计量点）
+func RecordBudgetCall(provider, accountID, api string) {
+	if t := activeBudget(); t != nil {
+		t.record(provider, accountID, api, 1, 0)
+	}
+}
+
+// RecordMetricsRequested 计入一次调用请求的指标数（CloudWatch GetMetricData 按指标数计费）
+func RecordMetricsRequested(provider, accountID, api string, n int) {
+	if t := activeBudget(); t != nil && n > 0 {
+		t.record(provider, accountID, api, 0, int64(n))
+	}
+}
+
+// BudgetExhausted 判断账号当日预算（账号或所属云）是否已用尽
+func BudgetExhausted(provider, accountID string) bool {
+	t := activeBudget()
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rolloverLocked(t.now())
+	return t.exceeded[provider+"|"+accountID] || t.exceeded[provider]
+}
+
+// DegradeInterval 返回账号产品的有效采集周期：预算用尽时放大为 max(interval, 采集间隔) × degrade_factor
+func DegradeInterval(provider, accountID string, interval time.Duration) time.Duration {
+	t := activeBudget()
+	if t == nil || !BudgetExhausted(provider, accountID) {
+		return interval
+	}
+	base := interval
+	if base < t.baseInterval {
+		base = t.baseInterval
+	}
+	if base <= 0 {
+		return interval
+	}
+	return base * time.Duration(t.factor)
+}
+
+// SaveBudgetUsage 持久化当日用量，未启用预算或无变化时不写入
+func SaveBudgetUsage() error {
+	if t := activeBudget(); t != nil {
+		return t.Save()
+	}
+	return nil
+}
+
+// BudgetSnapshot 返回预算状态，未启用预算时返回 nil
+func BudgetSnapshot() *BudgetStatus {
+	if t := activeBudget(); t != nil {
+		s := t.Status()
+		return &s
+	}
+	return nil
+}
+
+func (t *BudgetTracker) record(provider, accountID, api string, calls, n int64) {
+	if accountID == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rolloverLocked(t.now())
+	key := provider + "|" + accountID
+	u := t.state.Usage[key]
+	if u == nil {
+		u = &apiUsage{Calls: make(map[string]int64), Metrics: make(map[string]int64)}
+		t.state.Usage[key] = u
+	}
+	if calls > 0 {
+		u.Calls[api] += calls
+	}
+	if n > 0 {
+		if u.Metrics == nil {
+			u.Metrics = make(map[string]int64)
+		}
+		u.Metrics[api] += n
+	}
+	t.dirty = true
+	accCalls, accMetrics, _ := t.totalsLocked(provider, accountID)
+	metrics.BudgetUsage.WithLabelValues(provider, accountID, "calls").Set(float64(accCalls))
+	metrics.BudgetUsage.WithLabelValues(provider, accountID, "metrics").Set(float64(accMetrics))
+	if limit, ok := t.accountLimit(provider, accountID); ok && overBudget(limit, accCalls, accMetrics) {
+		t.markExceededLocked(key, provider, accountID)
+	}
+	if limit, ok := t.conf.Providers[provider]; ok {
+		pCalls, pMetrics, _ := t.totalsLocked(provider, "")
+		if overBudget(limit, pCalls, pMetrics) {
+			t.markExceededLocked(provider, provider, "")
+		}
+	}
+}
+
+func overBudget(limit config.BudgetLimit, calls, n int64) bool {
+	return (limit.DailyCalls > 0 && calls >= limit.DailyCalls) || (limit.DailyMetrics > 0 && n >= limit.DailyMetrics)
+}
+
+// accountLimit 解析账号预算：provider.account_id > provider
+func (t *BudgetTracker) accountLimit(provider, accountID string) (config.BudgetLimit, bool) {
+	if l, ok := t.conf.Accounts[provider+"."+accountID]; ok {
+		return l, true
+	}
+	l, ok := t.conf.Accounts[provider]
+	return l, ok
+}
+
+// markExceededLocked 标记预算用尽并记录日志，调用方持有 t.mu
+func (t *BudgetTracker) markExceededLocked(key, provider, accountID string) {
+	if t.exceeded[key] {
+		return
+	}
+	t.exceeded[key] = true
+	ctxLog := logger.NewContextLogger("Budget", "cloud_provider", provider, "account_id", accountID)
+	ctxLog.Warnf("当日云 API 预算已用尽，采集周期放大 %d 倍至次日", t.factor)
+	if accountID != "" {
+		metrics.BudgetExceeded.WithLabelValues(provider, accountID).Set(1)
+		return
+	}
+	for k := range t.state.Usage {
+		if p, acc := splitBudgetKey(k); p == provider {
+			metrics.BudgetExceeded.WithLabelValues(p, acc).Set(1)
+		}
+	}
+}
+
+// totalsLocked 汇总账号（accountID 为空时为整个云）的当日调用数、指标数与成本
+func (t *BudgetTracker) totalsLocked(provider, accountID string) (calls, n int64, cost float64) {
+	for k, u := range t.state.Usage {
+		p, acc := splitBudgetKey(k)
+		if p != provider || (accountID != "" && acc != accountID) {
+			continue
+		}
+		for api, c := range u.Calls {
+			calls += c
+			cost += float64(c) / 1000 * t.price(p, api).PerThousandCalls
+		}
+		for api, m := range u.Metrics {
+			n += m
+			cost += float64(m) / 1000 * t.price(p, api).PerThousandMetrics
+		}
+	}
+	return calls, n, cost
+}
+
+func (t *BudgetTracker) price(provider, api string) config.BudgetPrice {
+	if p, ok := t.conf.Prices[provider+"."+api]; ok {
+		return p
+	}
+	return DefaultBudgetPrices[provider+"."+api]
+}
+
+func splitBudgetKey(key string) (provider, accountID string) {
+	for i := 0; i < len(key); i++ {
+		if key[i] == '|' {
+			return key[:i], key[i+1:]
+		}
+	}
+	return key, ""
+}
+
+// rolloverLocked 跨天时归档前一日用量并清空预算状态，调用方持有 t.mu
+func (t *BudgetTracker) rolloverLocked(now time.Time) {
+	day := now.Format(budgetDayLayout)
+	if t.state.Day == day {
+		return
+	}
+	if t.state.Day != "" {
+		t.archiveLocked()
+		y, m, d := now.Date()
+		t.state.Since = time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	} else {
+		t.state.Since = now
+	}
+	t.state.Day = day
+	t.state.Usage = make(map[string]*apiUsage)
+	t.exceeded = make(map[string]bool)
+	t.dirty = true
+	metrics.BudgetUsage.Reset()
+	metrics.BudgetExceeded.Reset()
+}
+
+// archiveLocked 将当日用量汇总写入历史（保留最近 7 天）
+func (t *BudgetTracker) archiveLocked() {
+	var day BudgetDay
+	day.Day = t.state.Day
+	for _, p := range t.providersLocked() {
+		c, n, cost := t.totalsLocked(p, "")
+		day.Calls += c
+		day.Metrics += n
+		day.Cost += cost
+	}
+	t.state.History = append(t.state.History, day)
+	if len(t.state.History) > budgetHistoryDays {
+		t.state.History = t.state.History[len(t.state.History)-budgetHistoryDays:]
+	}
+}
+
+func (t *BudgetTracker) providersLocked() []string {
+	seen := make(map[string]bool)
+	var out []string
+	for k := range t.state.Usage {
+		p, _ := splitBudgetKey(k)
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// refreshLocked 根据当前用量重新计算预算状态与指标（加载持久化用量后调用）
+func (t *BudgetTracker) refreshLocked() {
+	t.rolloverLocked(t.now())
+	for k := range t.state.Usage {
+		p, acc := splitBudgetKey(k)
+		calls, n, _ := t.totalsLocked(p, acc)
+		metrics.BudgetUsage.WithLabelValues(p, acc, "calls").Set(float64(calls))
+		metrics.BudgetUsage.WithLabelValues(p, acc, "metrics").Set(float64(n))
+		if limit, ok := t.accountLimit(p, acc); ok && overBudget(limit, calls, n) {
+			t.markExceededLocked(k, p, acc)
+		}
+	}
+	for p, limit := range t.conf.Providers {
+		calls, n, _ := t.totalsLocked(p, "")
+		if overBudget(limit, calls, n) {
+			t.markExceededLocked(p, p, "")
+		}
+	}
+}
+
+// Status 返回当日用量、预算状态与月度成本估算
+func (t *BudgetTracker) Status() BudgetStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	t.rolloverLocked(now)
+	st := BudgetStatus{Day: t.state.Day, DegradeFactor: t.factor, History: append([]BudgetDay(nil), t.state.History...)}
+	keys := make([]string, 0, len(t.state.Usage))
+	for k := range t.state.Usage {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		p, acc := splitBudgetKey(k)
+		calls, n, cost := t.totalsLocked(p, acc)
+		u := BudgetUsageStatus{Provider: p, AccountID: acc, Calls: calls, Metrics: n, Cost: cost, Exceeded: t.exceeded[k] || t.exceeded[p]}
+		if l, ok := t.accountLimit(p, acc); ok {
+			u.Limit = &l
+		}
+		st.Accounts = append(st.Accounts, u)
+	}
+	for _, p := range t.providersLocked() {
+		calls, n, cost := t.totalsLocked(p, "")
+		u := BudgetUsageStatus{Provider: p, Calls: calls, Metrics: n, Cost: cost, Exceeded: t.exceeded[p]}
+		if l, ok := t.conf.Providers[p]; ok {
+			u.Limit = &l
+		}
+		st.Providers = append(st.Providers, u)
+		st.CostToday += cost
+	}
+	st.ProjectedMonthlyCost = t.projectLocked(now, st.CostToday)
+	metrics.BudgetProjectedMonthlyCost.Set(st.ProjectedMonthlyCost)
+	return st
+}
+
+// publishProjectionLocked 更新月度成本估算指标（每轮采集结束时由 Save 调用，无需等待 /status 请求）
+func (t *BudgetTracker) publishProjectionLocked() {
+	now := t.now()
+	t.rolloverLocked(now)
+	var costToday float64
+	for _, p := range t.providersLocked() {
+		_, _, cost := t.totalsLocked(p, "")
+		costToday += cost
+	}
+	metrics.BudgetProjectedMonthlyCost.Set(t.projectLocked(now, costToday))
+}
+
+// projectLocked 估算月度成本：按当日已计量时长外推全天用量 × 30；
+// 计量不足 1 小时且有历史数据时使用前一日成本，避免刚开始计量时估算失真
+func (t *BudgetTracker) projectLocked(now time.Time, costToday float64) float64 {
+	elapsed := now.Sub(t.state.Since)
+	if elapsed < time.Hour && len(t.state.History) > 0 {
+		return t.state.History[len(t.state.History)-1].Cost * 30
+	}
+	if elapsed < time.Minute {
+		elapsed = time.Minute
+	}
+	return costToday * float64(24*time.Hour) / float64(elapsed) * 30
+}
+
+// Load 加载持久化用量：同一天的用量继续累计，较早的用量归档为历史
+func (t *BudgetTracker) Load() error {
+	data, err := os.ReadFile(t.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	var st budgetState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return fmt.Errorf("解析预算用量失败: %w", err)
+	}
+	if st.Usage == nil {
+		st.Usage = make(map[string]*apiUsage)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.state = st
+	t.exceeded = make(map[string]bool)
+	t.rolloverLocked(t.now())
+	return nil
+}
+
+// Save 更新月度成本估算指标并原子写入当日用量；写入失败时保留待写入标记，下次继续重试
+func (t *BudgetTracker) Save() (err error) {
+	t.mu.Lock()
+	t.publishProjectionLocked()
+	if !t.dirty {
+		t.mu.Unlock()
+		return nil
+	}
+	data, err := json.MarshalIndent(t.state, "", "  ")
+	t.dirty = false
+	t.mu.Unlock()
+	defer func() {
+		if err != nil {
+			t.mu.Lock()
+			t.dirty = true
+			t.mu.Unlock()
+		}
+	}()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+		return err
+	}
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, t.path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
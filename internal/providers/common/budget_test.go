@@ -0,0 +1,177 @@
+package common
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"multicloud-exporter/internal/config"
+	"multicloud-exporter/internal/metrics"
+)
+
+// useBudget 以可控时钟启用预算跟踪器，测试结束后停用
+func useBudget(t *testing.T, conf config.BudgetConf, dir string, now *time.Time) *BudgetTracker {
+	t.Helper()
+	bt := newBudgetTracker(conf, dir, time.Minute, func() time.Time { return *now })
+	if err := bt.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	budgetMu.Lock()
+	budget = bt
+	budgetMu.Unlock()
+	t.Cleanup(func() { ConfigureBudgets(nil, "", 0) })
+	return bt
+}
+
+func TestBudget_AccountLimitDegradesInterval(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	useBudget(t, config.BudgetConf{
+		Accounts: map[string]config.BudgetLimit{
+			"mock":         {DailyCalls: 100},
+			"mock.acc-vip": {DailyCalls: 3},
+		},
+	}, t.TempDir(), &now)
+
+	for i := 0; i < 2; i++ {
+		RecordRequest("mock", "acc-vip", "Describe", "success")
+	}
+	if BudgetExhausted("mock", "acc-vip") {
+		t.Fatalf("budget should not be exhausted before reaching the cap")
+	}
+	if d := DegradeInterval("mock", "acc-vip", 0); d != 0 {
+		t.Fatalf("interval should be unchanged within budget, got %v", d)
+	}
+
+	RecordRequest("mock", "acc-vip", "Describe", "limit_error")
+	if !BudgetExhausted("mock", "acc-vip") {
+		t.Fatalf("account-specific cap should be exhausted")
+	}
+	if v := testutil.ToFloat64(metrics.BudgetExceeded.WithLabelValues("mock", "acc-vip")); v != 1 {
+		t.Fatalf("exceeded gauge expected 1, got %v", v)
+	}
+	// 每轮采集的产品放大为 采集间隔 × 4，配置了周期的产品按周期放大
+	if d := DegradeInterval("mock", "acc-vip", 0); d != 4*time.Minute {
+		t.Fatalf("degraded interval expected 4m, got %v", d)
+	}
+	if d := DegradeInterval("mock", "acc-vip", 10*time.Minute); d != 40*time.Minute {
+		t.Fatalf("degraded interval expected 40m, got %v", d)
+	}
+	// 其他账号使用 provider 级账号预算
+	RecordRequest("mock", "acc-other", "Describe", "success")
+	if BudgetExhausted("mock", "acc-other") {
+		t.Fatalf("other accounts must not be degraded")
+	}
+
+	// 跨天后预算恢复
+	now = now.Add(24 * time.Hour)
+	if BudgetExhausted("mock", "acc-vip") {
+		t.Fatalf("budget should reset on day rollover")
+	}
+}
+
+func TestBudget_ProviderLimitAndMetrics(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	useBudget(t, config.BudgetConf{
+		Providers: map[string]config.BudgetLimit{"mock": {DailyMetrics: 1000}},
+	}, t.TempDir(), &now)
+
+	RecordMetricsRequested("mock", "acc-a", "GetMetricData", 600)
+	RecordMetricsRequested("mock", "acc-b", "GetMetricData", 300)
+	if BudgetExhausted("mock", "acc-a") {
+		t.Fatalf("provider cap not reached yet")
+	}
+	RecordMetricsRequested("mock", "acc-b", "GetMetricData", 100)
+	if !BudgetExhausted("mock", "acc-a") || !BudgetExhausted("mock", "acc-b") {
+		t.Fatalf("provider cap should degrade every account of the provider")
+	}
+	if BudgetExhausted("other", "acc-a") {
+		t.Fatalf("other providers must not be affected")
+	}
+}
+
+func TestBudget_StatusAndProjection(t *testing.T) {
+	now := time.Date(2026, 3, 1, 6, 0, 0, 0, time.Local)
+	useBudget(t, config.BudgetConf{
+		Prices: map[string]config.BudgetPrice{"mock.Describe": {PerThousandCalls: 1}},
+	}, t.TempDir(), &now)
+
+	RecordMetricsRequested("aws", "acc", "GetMetricData", 10000)
+	for i := 0; i < 500; i++ {
+		RecordRequest("mock", "acc", "Describe", "success")
+	}
+	// 6 小时内：aws 0.1（内置单价）+ mock 0.5
+	now = now.Add(6 * time.Hour)
+	// 每轮采集结束保存用量时即更新估算指标，不依赖 /status 请求
+	if err := SaveBudgetUsage(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if got := testutil.ToFloat64(metrics.BudgetProjectedMonthlyCost); math.Abs(got-0.6*4*30) > 1e-6 {
+		t.Fatalf("projected cost gauge expected %v, got %v", 0.6*4*30, got)
+	}
+	st := BudgetSnapshot()
+	if st == nil {
+		t.Fatalf("status expected when budgets are enabled")
+	}
+	if math.Abs(st.CostToday-0.6) > 1e-9 {
+		t.Fatalf("cost today expected 0.6, got %v", st.CostToday)
+	}
+	if math.Abs(st.ProjectedMonthlyCost-0.6*4*30) > 1e-6 {
+		t.Fatalf("projection expected %v, got %v", 0.6*4*30, st.ProjectedMonthlyCost)
+	}
+	if len(st.Accounts) != 2 || len(st.Providers) != 2 {
+		t.Fatalf("unexpected status: %+v", st)
+	}
+	if st.Accounts[0].Provider != "aws" || st.Accounts[0].Metrics != 10000 {
+		t.Fatalf("aws usage expected first, got %+v", st.Accounts[0])
+	}
+}
+
+func TestBudget_PersistRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	conf := config.BudgetConf{Accounts: map[string]config.BudgetLimit{"mock": {DailyCalls: 5}}}
+	bt := useBudget(t, conf, dir, &now)
+	for i := 0; i < 5; i++ {
+		RecordRequest("mock", "acc", "Describe", "success")
+	}
+	// 写入失败时保留待写入标记，下次保存重试
+	path := bt.path
+	bt.path = filepath.Join(dir, "missing", "\x00", "budget.json")
+	if err := bt.Save(); err == nil {
+		t.Fatal("save to an invalid path should fail")
+	}
+	bt.path = path
+	if err := bt.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("failed save should be retried: %v", err)
+	}
+
+	// 重启后同一天继续累计，预算状态恢复
+	restored := newBudgetTracker(conf, dir, time.Minute, func() time.Time { return now })
+	if err := restored.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	restored.mu.Lock()
+	restored.refreshLocked()
+	restored.mu.Unlock()
+	if st := restored.Status(); len(st.Accounts) != 1 || st.Accounts[0].Calls != 5 || !st.Accounts[0].Exceeded {
+		t.Fatalf("usage should survive restarts, got %+v", st.Accounts)
+	}
+
+	// 次日加载时前一日用量归档为历史
+	next := now.Add(24 * time.Hour)
+	later := newBudgetTracker(conf, dir, time.Minute, func() time.Time { return next })
+	if err := later.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	st := later.Status()
+	if len(st.Accounts) != 0 || len(st.History) != 1 || st.History[0].Calls != 5 {
+		t.Fatalf("previous day should be archived, got %+v", st)
+	}
+}
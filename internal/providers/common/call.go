@@ -0,0 +1,177 @@
+package common
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"multicloud-exporter/internal/logger"
+	"multicloud-exporter/internal/metrics"
+)
+
+// CallOptions 描述一次云 API 调用
+type CallOptions struct {
+	Provider string
+	// AccountID 为空时使用 ctx 上绑定的账号（BeginCollect）
+	AccountID string
+	// Region 调用所在区域，仅用于追踪与日志
+	Region string
+	API    string
+	// Attempts 最大尝试次数（含首次），<=1 表示不重试
+	Attempts int
+	// Backoff 重试退避参数，零值字段使用 DefaultRetryConfig
+	Backoff RetryConfig
+	// Classify 错误分类函数，默认按 Provider 选择内置分类器
+	Classify func(error) string
+	// Retryable 判断错误状态是否重试，默认仅重试限流与网络错误（RetryOnTransient）
+	Retryable func(status string) bool
+}
+
+// CallTrace 单次调用尝试的追踪记录
+type CallTrace struct {
+	Provider  string
+	AccountID string
+	Region    string
+	API       string
+	// Attempt 第几次尝试，从 1 开始
+	Attempt  int
+	Start    time.Time
+	Duration time.Duration
+	// Status 调用结果：success 或 ErrorStatus* 常量
+	Status string
+	Err    error
+}
+
+var (
+	callTracerMu sync.RWMutex
+	callTracer   func(CallTrace)
+)
+
+// SetCallTracer 设置调用追踪回调（如接入 OpenTelemetry），每次调用尝试结束后调用；nil 表示仅输出 Debug 日志
+func SetCallTracer(f func(CallTrace)) {
+	callTracerMu.Lock()
+	callTracer = f
+	callTracerMu.Unlock()
+}
+
+// RetryOnTransient 仅重试限流与网络错误（Call 的默认重试策略）
+func RetryOnTransient(status string) bool {
+	return status == ErrorStatusLimit || status == ErrorStatusNetwork
+}
+
+// RetryUnlessFatal 除认证失败、区域跳过与取消外均重试
+func RetryUnlessFatal(status string) bool {
+	return status != ErrorStatusAuth && status != ErrorStatusRegion && status != ErrorStatusCanceled
+}
+
+// ClassifierFor 返回云平台的内置错误分类函数，未知云平台的错误均归为 ErrorStatusUnknown（取消除外）
+func ClassifierFor(provider string) func(error) string {
+	switch provider {
+	case "aliyun":
+		return ClassifyAliyunError
+	case "tencent":
+		return ClassifyTencentError
+	case "aws":
+		return ClassifyAWSError
+	case "huawei":
+		return ClassifyHuaweiError
+	}
+	return func(err error) string {
+		if isCanceled(err) {
+			return ErrorStatusCanceled
+		}
+		return ErrorStatusUnknown
+	}
+}
+
+// Call 执行一次带埋点的云 API 调用：每次尝试前获取限流令牌（WaitRateLimit），
+// 尝试结束后记录 multicloud_request_total / multicloud_request_duration_seconds / 限流次数，
+// 反馈给自适应并发、每日预算与熔断器（RecordRequest），并输出追踪记录；
+// 可重试的错误按指数退避重试，尝试前或退避期间 ctx 结束时立即返回。
+// 返回最后一次尝试的结果与错误；ctx 结束（取消或本轮采集超时）导致的错误仍匹配 ctx.Err()，
+// 且各云平台分类函数均归为 ErrorStatusCanceled，调用方可通过 ClassifierFor 获取错误状态。
+func Call[T any](ctx context.Context, opts CallOptions, fn func() (T, error)) (T, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	accountID := opts.AccountID
+	if accountID == "" {
+		accountID = AccountIDFromContext(ctx)
+	}
+	classify := opts.Classify
+	if classify == nil {
+		classify = ClassifierFor(opts.Provider)
+	}
+	retryable := opts.Retryable
+	if retryable == nil {
+		retryable = RetryOnTransient
+	}
+	attempts := opts.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	backoff := opts.Backoff.withDefaults()
+
+	var zero T
+	for attempt := 0; ; attempt++ {
+		// 未配置限流时 WaitRateLimit 不检查 ctx，已取消的采集不应再发起调用
+		if err := ctx.Err(); err != nil {
+			return zero, abortedByContext(ctx, err)
+		}
+		if err := WaitRateLimit(ctx, opts.Provider, accountID, opts.API); err != nil {
+			return zero, abortedByContext(ctx, err)
+		}
+		start := time.Now()
+		v, err := fn()
+		// 采集 ctx 结束（如本轮采集超时）导致的失败归为取消，不计入自适应并发、熔断与目标错误
+		err = abortedByContext(ctx, err)
+		status := "success"
+		if err != nil {
+			status = classify(err)
+		}
+		observeCall(opts, accountID, attempt+1, start, status, err)
+		if err == nil {
+			return v, nil
+		}
+		if attempt+1 >= attempts || !retryable(status) {
+			return v, err
+		}
+		if sleepErr := SleepContext(ctx, backoff.delay(attempt)); sleepErr != nil {
+			return v, abortedByContext(ctx, sleepErr)
+		}
+	}
+}
+
+// observeCall 记录单次尝试的指标与追踪
+func observeCall(opts CallOptions, accountID string, attempt int, start time.Time, status string, err error) {
+	d := time.Since(start)
+	metrics.RequestTotal.WithLabelValues(opts.Provider, opts.API, status).Inc()
+	metrics.RequestDuration.WithLabelValues(opts.Provider, opts.API).Observe(d.Seconds())
+	if status == ErrorStatusLimit {
+		metrics.RateLimitTotal.WithLabelValues(opts.Provider, opts.API).Inc()
+	}
+	RecordRequest(opts.Provider, accountID, opts.API, status)
+
+	trace := CallTrace{
+		Provider:  opts.Provider,
+		AccountID: accountID,
+		Region:    opts.Region,
+		API:       opts.API,
+		Attempt:   attempt,
+		Start:     start,
+		Duration:  d,
+		Status:    status,
+		Err:       err,
+	}
+	callTracerMu.RLock()
+	tracer := callTracer
+	callTracerMu.RUnlock()
+	if tracer != nil {
+		tracer(trace)
+		return
+	}
+	if err != nil {
+		ctxLog := logger.NewContextLogger("Call", "cloud_provider", opts.Provider, "account_id", accountID, "region", opts.Region, "api", opts.API)
+		ctxLog.Debugf("云 API 调用失败，尝试=%d 状态=%s 耗时=%v 错误=%v", attempt, status, d, err)
+	}
+}
@@ -0,0 +1,189 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"multicloud-exporter/internal/metrics"
+)
+
+var (
+	errThrottled = errors.New("Throttling")
+	errBadInput  = errors.New("InvalidParameter")
+)
+
+func classifyMock(err error) string {
+	switch {
+	case errors.Is(err, errThrottled):
+		return ErrorStatusLimit
+	case isCanceled(err):
+		return ErrorStatusCanceled
+	}
+	return ErrorStatusUnknown
+}
+
+// useCallTracer 收集调用追踪记录，测试结束后移除
+func useCallTracer(t *testing.T) func() []CallTrace {
+	t.Helper()
+	var mu sync.Mutex
+	var traces []CallTrace
+	SetCallTracer(func(tr CallTrace) {
+		mu.Lock()
+		traces = append(traces, tr)
+		mu.Unlock()
+	})
+	t.Cleanup(func() { SetCallTracer(nil) })
+	return func() []CallTrace {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]CallTrace(nil), traces...)
+	}
+}
+
+func TestCall_RetriesTransientErrors(t *testing.T) {
+	traces := useCallTracer(t)
+	calls := 0
+	v, err := Call(context.Background(), CallOptions{
+		Provider: "mock", AccountID: "acc-call", Region: "r1", API: "CallRetry",
+		Attempts: 3, Backoff: RetryConfig{InitialDelay: time.Millisecond}, Classify: classifyMock,
+	}, func() (int, error) {
+		calls++
+		if calls < 3 {
+			return 0, errThrottled
+		}
+		return 42, nil
+	})
+	if err != nil || v != 42 || calls != 3 {
+		t.Fatalf("expected success on the third attempt, got v=%d err=%v calls=%d", v, err, calls)
+	}
+	if n := testutil.ToFloat64(metrics.RequestTotal.WithLabelValues("mock", "CallRetry", ErrorStatusLimit)); n != 2 {
+		t.Fatalf("limit_error requests expected 2, got %v", n)
+	}
+	if n := testutil.ToFloat64(metrics.RequestTotal.WithLabelValues("mock", "CallRetry", "success")); n != 1 {
+		t.Fatalf("success requests expected 1, got %v", n)
+	}
+	if n := testutil.ToFloat64(metrics.RateLimitTotal.WithLabelValues("mock", "CallRetry")); n != 2 {
+		t.Fatalf("rate limit counter expected 2, got %v", n)
+	}
+	got := traces()
+	if len(got) != 3 {
+		t.Fatalf("expected one trace per attempt, got %d", len(got))
+	}
+	for i, tr := range got {
+		if tr.Attempt != i+1 || tr.AccountID != "acc-call" || tr.Region != "r1" {
+			t.Fatalf("unexpected trace %d: %+v", i, tr)
+		}
+	}
+	if got[2].Status != "success" || got[0].Status != ErrorStatusLimit || got[0].Err == nil {
+		t.Fatalf("unexpected trace statuses: %+v", got)
+	}
+}
+
+func TestCall_StopsOnNonRetryableError(t *testing.T) {
+	calls := 0
+	_, err := Call(context.Background(), CallOptions{
+		Provider: "mock", AccountID: "acc-call", API: "CallFatal",
+		Attempts: 5, Backoff: RetryConfig{InitialDelay: time.Millisecond}, Classify: classifyMock,
+	}, func() (string, error) {
+		calls++
+		return "", errBadInput
+	})
+	if !errors.Is(err, errBadInput) || calls != 1 {
+		t.Fatalf("default policy should not retry generic errors, got err=%v calls=%d", err, calls)
+	}
+
+	// RetryUnlessFatal 重试一般错误直至次数用尽
+	calls = 0
+	_, err = Call(context.Background(), CallOptions{
+		Provider: "mock", AccountID: "acc-call", API: "CallFatal",
+		Attempts: 3, Backoff: RetryConfig{InitialDelay: time.Millisecond}, Classify: classifyMock,
+		Retryable: RetryUnlessFatal,
+	}, func() (string, error) {
+		calls++
+		return "", errBadInput
+	})
+	if !errors.Is(err, errBadInput) || calls != 3 {
+		t.Fatalf("RetryUnlessFatal should exhaust attempts, got err=%v calls=%d", err, calls)
+	}
+}
+
+func TestCall_CanceledDuringBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := 0
+	done := make(chan error, 1)
+	go func() {
+		_, err := Call(ctx, CallOptions{
+			Provider: "mock", AccountID: "acc-call", API: "CallCancel",
+			Attempts: 3, Backoff: RetryConfig{InitialDelay: time.Hour, MaxDelay: time.Hour}, Classify: classifyMock,
+		}, func() (int, error) {
+			calls++
+			cancel()
+			return 0, errThrottled
+		})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) || calls != 1 {
+			t.Fatalf("expected context.Canceled after one attempt, got err=%v calls=%d", err, calls)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Call did not return after cancellation")
+	}
+}
+
+func TestCall_CanceledBeforeFirstAttempt(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls := 0
+	_, err := Call(ctx, CallOptions{Provider: "mock", AccountID: "acc-call", API: "CallCanceled"}, func() (int, error) {
+		calls++
+		return 0, nil
+	})
+	if !errors.Is(err, context.Canceled) || calls != 0 {
+		t.Fatalf("canceled ctx should skip the call, got err=%v calls=%d", err, calls)
+	}
+}
+
+func TestCall_CycleDeadlineClassifiedAsCanceled(t *testing.T) {
+	traces := useCallTracer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	errTimeout := errors.New("Post https://example.com: context deadline exceeded (Client.Timeout exceeded)")
+	calls := 0
+	_, err := Call(ctx, CallOptions{
+		Provider: "aliyun", AccountID: "acc-call", API: "CallDeadline",
+		Attempts: 3, Backoff: RetryConfig{InitialDelay: time.Millisecond},
+	}, func() (int, error) {
+		calls++
+		<-ctx.Done()
+		return 0, errTimeout
+	})
+	if calls != 1 || !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, errTimeout) {
+		t.Fatalf("expected one attempt aborted by the cycle deadline, got err=%v calls=%d", err, calls)
+	}
+	if s := ClassifierFor("aliyun")(err); s != ErrorStatusCanceled {
+		t.Fatalf("returned error should classify as canceled, got %s", s)
+	}
+	got := traces()
+	if len(got) != 1 || got[0].Status != ErrorStatusCanceled {
+		t.Fatalf("attempt aborted by the cycle deadline should be traced as canceled, got %+v", got)
+	}
+	if n := testutil.ToFloat64(metrics.RequestTotal.WithLabelValues("aliyun", "CallDeadline", ErrorStatusNetwork)); n != 0 {
+		t.Fatalf("cycle deadline must not count as network_error, got %v", n)
+	}
+
+	// ctx 仍有效时的单次请求超时仍按网络错误分类
+	_, err = Call(context.Background(), CallOptions{Provider: "aliyun", AccountID: "acc-call", API: "CallDeadline"}, func() (int, error) {
+		return 0, errTimeout
+	})
+	if s := ClassifierFor("aliyun")(err); s == ErrorStatusCanceled {
+		t.Fatalf("request timeout with a live ctx should not classify as canceled")
+	}
+}
@@ -0,0 +1,86 @@
+// Package common 提供统一的 code_name 解析
+// 各云的标签拉取路径只负责获取完整标签，code_name 统一按 server.code_name_chain
+// （未配置时使用各云内置默认链）解析。
+package common
+
+import (
+	"strings"
+	"sync"
+
+	"multicloud-exporter/internal/config"
+	"multicloud-exporter/internal/logger"
+)
+
+// DefaultCodeNameChain 默认解析链：仅使用 CodeName 标签
+const DefaultCodeNameChain = "tags.CodeName -> tags.code_name"
+
+var (
+	codeNameChainMu    sync.Mutex
+	codeNameChainCache = make(map[string][]config.CodeNameStep)
+)
+
+// codeNameSteps 返回生效的解析链：server.code_name_chain 优先，否则使用 defaultChain
+func codeNameSteps(cfg *config.Config, defaultChain string) []config.CodeNameStep {
+	chain := defaultChain
+	if cfg != nil {
+		if server := cfg.GetServer(); server != nil && strings.TrimSpace(server.CodeNameChain) != "" {
+			chain = server.CodeNameChain
+		}
+	}
+	codeNameChainMu.Lock()
+	defer codeNameChainMu.Unlock()
+	if steps, ok := codeNameChainCache[chain]; ok {
+		return steps
+	}
+	steps, err := config.ParseCodeNameChain(chain)
+	if err != nil {
+		// 配置校验阶段已报告，这里回退到默认链
+		logger.NewContextLogger("CodeName").Warnf("code_name 解析链无效，使用默认链 chain=%q 错误=%v", chain, err)
+		steps, _ = config.ParseCodeNameChain(defaultChain)
+	}
+	codeNameChainCache[chain] = steps
+	return steps
+}
+
+// ResolveCodeName 按解析链返回第一个非空值，均为空时返回空字符串
+func ResolveCodeName(cfg *config.Config, defaultChain string, tags map[string]string, name, id string) string {
+	for _, step := range codeNameSteps(cfg, defaultChain) {
+		var v string
+		switch step.Source {
+		case "tags":
+			v = lookupTag(tags, step.Key)
+		case "name":
+			v = name
+		case "id":
+			v = id
+		}
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// ResolveCodeNames 批量解析 code_name，仅返回非空结果
+func ResolveCodeNames(cfg *config.Config, defaultChain string, ids []string, tagsByID map[string]map[string]string, names map[string]string) map[string]string {
+	out := make(map[string]string, len(ids))
+	for _, id := range ids {
+		if v := ResolveCodeName(cfg, defaultChain, tagsByID[id], names[id], id); v != "" {
+			out[id] = v
+		}
+	}
+	return out
+}
+
+// lookupTag 标签键优先精确匹配，其次忽略大小写匹配
+func lookupTag(tags map[string]string, key string) string {
+	if v, ok := tags[key]; ok {
+		return v
+	}
+	for k, v := range tags {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return ""
+}
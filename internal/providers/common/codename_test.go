@@ -0,0 +1,31 @@
+package common
+
+import (
+	"testing"
+
+	"multicloud-exporter/internal/config"
+)
+
+func TestResolveCodeName_DefaultAndConfigured(t *testing.T) {
+	tags := map[string]string{"codename": "svc", "Name": "web"}
+	// 默认链：标签键忽略大小写匹配
+	if got := ResolveCodeName(nil, DefaultCodeNameChain, tags, "lb-name", "lb-1"); got != "svc" {
+		t.Fatalf("default chain got %q", got)
+	}
+	if got := ResolveCodeName(nil, DefaultCodeNameChain, nil, "lb-name", "lb-1"); got != "" {
+		t.Fatalf("default chain without tags should be empty, got %q", got)
+	}
+
+	cfg := &config.Config{Server: &config.ServerConf{CodeNameChain: "tags.Name -> name -> id"}}
+	if got := ResolveCodeName(cfg, DefaultCodeNameChain, tags, "lb-name", "lb-1"); got != "web" {
+		t.Fatalf("configured chain got %q", got)
+	}
+	if got := ResolveCodeName(cfg, DefaultCodeNameChain, nil, "", "lb-1"); got != "lb-1" {
+		t.Fatalf("configured chain fallback got %q", got)
+	}
+
+	out := ResolveCodeNames(cfg, DefaultCodeNameChain, []string{"a", "b"}, map[string]map[string]string{"a": {"Name": "x"}}, map[string]string{"b": "bn"})
+	if out["a"] != "x" || out["b"] != "bn" {
+		t.Fatalf("unexpected batch result: %v", out)
+	}
+}
@@ -0,0 +1,280 @@
+package common
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"multicloud-exporter/internal/config"
+	"multicloud-exporter/internal/logger"
+	"multicloud-exporter/internal/metrics"
+	"multicloud-exporter/internal/utils"
+)
+
+// 自适应并发的作用层级
+const (
+	ScopeRegion  = "region"  // 区域级：同一账号下并行采集的地域
+	ScopeProduct = "product" // 产品级：同一地域下并行处理的命名空间
+	ScopeMetric  = "metric"  // 指标级：同一地域下并行拉取的指标批次
+)
+
+// 自适应并发默认参数
+const (
+	defaultAdaptiveMin            = 1
+	defaultAdaptiveDecreaseFactor = 0.5
+	defaultAdaptiveCooldown       = 5 * time.Second
+)
+
+// ConcurrencyController 单个 provider/账号/层级的 AIMD 并发控制器：
+// 云 API 返回限流错误时有效并发按系数成倍降低（冷却期内只降低一次），
+// 连续成功的调用数达到当前有效并发时加 1，有效并发介于下限与配置的并发上限之间。
+// 未启用自适应并发时有效并发固定为上限。
+type ConcurrencyController struct {
+	provider  string
+	accountID string
+	scope     string
+
+	mu           sync.Mutex
+	min          int
+	max          int
+	limit        int
+	successes    int
+	lastDecrease time.Time
+	// raised 有效并发提高时关闭并替换，唤醒等待中的信号量
+	raised chan struct{}
+}
+
+// adaptiveSettings 生效的自适应并发参数
+type adaptiveSettings struct {
+	enabled        bool
+	min            int
+	decreaseFactor float64
+	cooldown       time.Duration
+}
+
+var (
+	concurrencyMu       sync.Mutex
+	concurrencySettings adaptiveSettings
+	// concurrencyControllers provider|account|scope -> 控制器
+	concurrencyControllers = make(map[string]*ConcurrencyController)
+)
+
+// ConfigureAdaptiveConcurrency 设置自适应并发参数（server.adaptive_concurrency），并丢弃已创建的控制器
+func ConfigureAdaptiveConcurrency(conf *config.AdaptiveConcurrencyConf) {
+	s := adaptiveSettings{min: defaultAdaptiveMin, decreaseFactor: defaultAdaptiveDecreaseFactor, cooldown: defaultAdaptiveCooldown}
+	if conf != nil {
+		s.enabled = conf.Enabled
+		if conf.Min > 0 {
+			s.min = conf.Min
+		}
+		if conf.DecreaseFactor > 0 && conf.DecreaseFactor < 1 {
+			s.decreaseFactor = conf.DecreaseFactor
+		}
+		if conf.Cooldown != "" {
+			if d, err := utils.ParseDuration(conf.Cooldown); err == nil && d >= 0 {
+				s.cooldown = d
+			} else {
+				ctxLog := logger.NewContextLogger("Concurrency", "resource_type", "Config")
+				ctxLog.Warnf("adaptive_concurrency.cooldown 解析失败，使用默认值 %v: %s", defaultAdaptiveCooldown, conf.Cooldown)
+			}
+		}
+	}
+	concurrencyMu.Lock()
+	defer concurrencyMu.Unlock()
+	concurrencySettings = s
+	concurrencyControllers = make(map[string]*ConcurrencyController)
+	metrics.ConcurrencyLimit.Reset()
+}
+
+// ConcurrencyFor 获取 provider/账号/层级的并发控制器，max 为该层级配置的并发上限。
+// 同一控制器的上限随配置变化时更新，有效并发被截断到新的上限内。
+func ConcurrencyFor(provider, accountID, scope string, max int) *ConcurrencyController {
+	if max < 1 {
+		max = 1
+	}
+	key := provider + "|" + accountID + "|" + scope
+	concurrencyMu.Lock()
+	s := concurrencySettings
+	c, ok := concurrencyControllers[key]
+	if !ok {
+		c = &ConcurrencyController{provider: provider, accountID: accountID, scope: scope, limit: max, raised: make(chan struct{})}
+		concurrencyControllers[key] = c
+	}
+	concurrencyMu.Unlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	lower := s.min
+	if !s.enabled || lower > max {
+		lower = max
+	}
+	c.min, c.max = lower, max
+	c.clampLocked()
+	return c
+}
+
+// Limit 返回当前有效并发
+func (c *ConcurrencyController) Limit() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.limit
+}
+
+// clampLocked 将有效并发截断到 [min, max] 并导出指标，调用方持有 c.mu
+func (c *ConcurrencyController) clampLocked() {
+	prev := c.limit
+	if c.limit > c.max {
+		c.limit = c.max
+	}
+	if c.limit < c.min {
+		c.limit = c.min
+	}
+	if c.limit > prev {
+		c.notifyLocked()
+	}
+	metrics.ConcurrencyLimit.WithLabelValues(c.provider, c.accountID, c.scope).Set(float64(c.limit))
+}
+
+func (c *ConcurrencyController) notifyLocked() {
+	close(c.raised)
+	c.raised = make(chan struct{})
+}
+
+// onThrottle 乘性降低：冷却期内的重复限流只计一次
+func (c *ConcurrencyController) onThrottle(s adaptiveSettings, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.successes = 0
+	if !c.lastDecrease.IsZero() && now.Sub(c.lastDecrease) < s.cooldown {
+		return
+	}
+	c.lastDecrease = now
+	next := int(math.Floor(float64(c.limit) * s.decreaseFactor))
+	if next < c.min {
+		next = c.min
+	}
+	if next == c.limit {
+		return
+	}
+	ctxLog := logger.NewContextLogger("Concurrency", "cloud_provider", c.provider, "account_id", c.accountID, "scope", c.scope)
+	ctxLog.Infof("云 API 限流，降低有效并发 %d -> %d", c.limit, next)
+	c.limit = next
+	c.clampLocked()
+}
+
+// onSuccess 加性提高：连续成功的调用数达到当前有效并发时加 1
+func (c *ConcurrencyController) onSuccess() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.limit >= c.max {
+		c.successes = 0
+		return
+	}
+	c.successes++
+	if c.successes < c.limit {
+		return
+	}
+	c.successes = 0
+	c.limit++
+	c.clampLocked()
+}
+
+// ObserveAPIStatus 将云 API 调用结果反馈给账号的并发控制器：limit_error 降低有效并发，success 逐步恢复。
+// 其他错误类别（含取消）不影响并发；未启用自适应并发时不做任何处理。
+func ObserveAPIStatus(provider, accountID, status string) {
+	if accountID == "" || (status != ErrorStatusLimit && status != "success") {
+		return
+	}
+	prefix := provider + "|" + accountID + "|"
+	concurrencyMu.Lock()
+	s := concurrencySettings
+	if !s.enabled {
+		concurrencyMu.Unlock()
+		return
+	}
+	var targets []*ConcurrencyController
+	for _, scope := range []string{ScopeRegion, ScopeProduct, ScopeMetric} {
+		if c, ok := concurrencyControllers[prefix+scope]; ok {
+			targets = append(targets, c)
+		}
+	}
+	concurrencyMu.Unlock()
+
+	now := time.Now()
+	for _, c := range targets {
+		if status == ErrorStatusLimit {
+			c.onThrottle(s, now)
+		} else {
+			c.onSuccess()
+		}
+	}
+}
+
+// RecordRequest 记录云 API 调用结果（multicloud_request 滑动窗口统计），反馈给账号的自适应并发控制器，
+// 计入账号的每日调用预算；调用成功时清零账号熔断器的连续错误数
+func RecordRequest(provider, accountID, api, status string) {
+	metrics.RecordRequest(provider, api, status)
+	ObserveAPIStatus(provider, accountID, status)
+	RecordBudgetCall(provider, accountID, api)
+	if status == "success" {
+		recordBreakerSuccess(provider, accountID, "")
+	}
+}
+
+// AdaptiveSemaphore 按控制器有效并发限制并行度的信号量。
+// 每个并行区域（如一次账号采集的区域循环）持有独立的信号量，共享同一控制器的有效并发；
+// 有效并发降低后，已持有的许可不受影响，新的获取等待至并行数低于有效并发。
+type AdaptiveSemaphore struct {
+	ctrl *ConcurrencyController
+
+	mu       sync.Mutex
+	inflight int
+	released chan struct{}
+}
+
+// NewSemaphore 创建受控制器约束的信号量
+func (c *ConcurrencyController) NewSemaphore() *AdaptiveSemaphore {
+	return &AdaptiveSemaphore{ctrl: c, released: make(chan struct{})}
+}
+
+// Acquire 获取一个许可，ctx 结束时返回 ctx.Err()
+func (s *AdaptiveSemaphore) Acquire(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		s.ctrl.mu.Lock()
+		limit := s.ctrl.limit
+		raised := s.ctrl.raised
+		s.ctrl.mu.Unlock()
+
+		s.mu.Lock()
+		if s.inflight < limit {
+			s.inflight++
+			s.mu.Unlock()
+			return nil
+		}
+		released := s.released
+		s.mu.Unlock()
+
+		select {
+		case <-released:
+		case <-raised:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Release 归还许可
+func (s *AdaptiveSemaphore) Release() {
+	s.mu.Lock()
+	s.inflight--
+	close(s.released)
+	s.released = make(chan struct{})
+	s.mu.Unlock()
+}
@@ -0,0 +1,132 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"multicloud-exporter/internal/config"
+	"multicloud-exporter/internal/metrics"
+)
+
+func TestConcurrency_AIMD(t *testing.T) {
+	ConfigureAdaptiveConcurrency(&config.AdaptiveConcurrencyConf{Enabled: true, Min: 2, Cooldown: "0s"})
+	defer ConfigureAdaptiveConcurrency(nil)
+
+	c := ConcurrencyFor("mock", "acc-aimd", ScopeRegion, 8)
+	if c.Limit() != 8 {
+		t.Fatalf("initial limit should be the configured max, got %d", c.Limit())
+	}
+
+	ObserveAPIStatus("mock", "acc-aimd", ErrorStatusLimit)
+	if c.Limit() != 4 {
+		t.Fatalf("throttle should halve the limit, got %d", c.Limit())
+	}
+	ObserveAPIStatus("mock", "acc-aimd", ErrorStatusLimit)
+	ObserveAPIStatus("mock", "acc-aimd", ErrorStatusLimit)
+	if c.Limit() != 2 {
+		t.Fatalf("limit should be bounded by min, got %d", c.Limit())
+	}
+	if v := testutil.ToFloat64(metrics.ConcurrencyLimit.WithLabelValues("mock", "acc-aimd", ScopeRegion)); v != 2 {
+		t.Fatalf("gauge should export the effective limit, got %v", v)
+	}
+
+	// 其他错误不影响并发
+	ObserveAPIStatus("mock", "acc-aimd", ErrorStatusNetwork)
+	if c.Limit() != 2 {
+		t.Fatalf("non-throttle errors must not change the limit, got %d", c.Limit())
+	}
+
+	// 连续成功次数达到当前并发时加 1
+	RecordRequest("mock", "acc-aimd", "Any", "success")
+	if c.Limit() != 2 {
+		t.Fatalf("single success should not raise the limit yet, got %d", c.Limit())
+	}
+	RecordRequest("mock", "acc-aimd", "Any", "success")
+	if c.Limit() != 3 {
+		t.Fatalf("limit should increase additively, got %d", c.Limit())
+	}
+	for i := 0; i < 100; i++ {
+		ObserveAPIStatus("mock", "acc-aimd", "success")
+	}
+	if c.Limit() != 8 {
+		t.Fatalf("limit should be bounded by max, got %d", c.Limit())
+	}
+
+	// 其他账号不受影响
+	other := ConcurrencyFor("mock", "acc-other", ScopeRegion, 8)
+	ObserveAPIStatus("mock", "acc-aimd", ErrorStatusLimit)
+	if other.Limit() != 8 {
+		t.Fatalf("throttling must be tracked per account, got %d", other.Limit())
+	}
+}
+
+func TestConcurrency_Cooldown(t *testing.T) {
+	ConfigureAdaptiveConcurrency(&config.AdaptiveConcurrencyConf{Enabled: true, Cooldown: "1h"})
+	defer ConfigureAdaptiveConcurrency(nil)
+
+	c := ConcurrencyFor("mock", "acc-cool", ScopeMetric, 8)
+	for i := 0; i < 5; i++ {
+		ObserveAPIStatus("mock", "acc-cool", ErrorStatusLimit)
+	}
+	if c.Limit() != 4 {
+		t.Fatalf("a burst of throttling within cooldown should decrease once, got %d", c.Limit())
+	}
+}
+
+func TestConcurrency_DisabledIsStatic(t *testing.T) {
+	ConfigureAdaptiveConcurrency(nil)
+
+	c := ConcurrencyFor("mock", "acc-static", ScopeRegion, 4)
+	ObserveAPIStatus("mock", "acc-static", ErrorStatusLimit)
+	if c.Limit() != 4 {
+		t.Fatalf("disabled controller must keep the configured limit, got %d", c.Limit())
+	}
+}
+
+func TestAdaptiveSemaphore(t *testing.T) {
+	ConfigureAdaptiveConcurrency(&config.AdaptiveConcurrencyConf{Enabled: true, Cooldown: "0s"})
+	defer ConfigureAdaptiveConcurrency(nil)
+
+	c := ConcurrencyFor("mock", "acc-sem", ScopeProduct, 2)
+	sem := c.NewSemaphore()
+	ctx := context.Background()
+	if err := sem.Acquire(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := sem.Acquire(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// 达到有效并发时阻塞，直到 ctx 结束
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := sem.Acquire(short); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	// 限流后有效并发降为 1：释放一个许可后仍无法获取
+	ObserveAPIStatus("mock", "acc-sem", ErrorStatusLimit)
+	sem.Release()
+	short2, cancel2 := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel2()
+	if err := sem.Acquire(short2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("acquire should wait while inflight >= reduced limit, got %v", err)
+	}
+
+	// 释放后可再次获取
+	done := make(chan error, 1)
+	go func() { done <- sem.Acquire(ctx) }()
+	sem.Release()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("acquire should succeed after release")
+	}
+}
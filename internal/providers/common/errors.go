@@ -1,7 +1,11 @@
 // Package common 提供云厂商通用的错误处理和重试逻辑
 package common
 
-import "strings"
+import (
+	"context"
+	"errors"
+	"strings"
+)
 
 // 统一错误状态码常量
 // 这些常量用于标识不同类型的错误，便于统一处理和重试决策
@@ -20,8 +24,41 @@ const (
 	ErrorStatusNetwork = "network_error"
 	// ErrorStatusUnknown 表示未知错误，无法明确分类的错误
 	ErrorStatusUnknown = "error"
+	// ErrorStatusCanceled 表示调用因采集被取消（如服务关闭）或本轮采集超时而中止
+	// 此类错误不应重试，也不计为目标采集失败
+	ErrorStatusCanceled = "canceled"
 )
 
+// abortedError 包装因采集 ctx 结束（取消或本轮采集超时）而中止的调用错误，
+// 仍可通过 errors.Is / errors.As 匹配 ctx.Err() 与原始错误
+type abortedError struct {
+	ctxErr error
+	err    error
+}
+
+func (e *abortedError) Error() string   { return e.err.Error() }
+func (e *abortedError) Unwrap() []error { return []error{e.ctxErr, e.err} }
+
+// abortedByContext 在 ctx 已结束时将 err 包装为 abortedError，否则原样返回。
+// 单次请求超时（ctx 仍有效）不受影响，仍按网络错误分类
+func abortedByContext(ctx context.Context, err error) error {
+	ctxErr := ctx.Err()
+	if err == nil || ctxErr == nil {
+		return err
+	}
+	var aborted *abortedError
+	if errors.As(err, &aborted) {
+		return err
+	}
+	return &abortedError{ctxErr: ctxErr, err: err}
+}
+
+// isCanceled 判断错误是否由 context 取消或 Call 所用的采集 ctx 结束（含本轮采集超时）导致
+func isCanceled(err error) bool {
+	var aborted *abortedError
+	return errors.Is(err, context.Canceled) || errors.As(err, &aborted)
+}
+
 // ErrorClassifier 定义错误分类接口
 // 实现该接口的类型可以将云厂商特定的错误分类为统一的错误状态码
 type ErrorClassifier interface {
@@ -39,6 +76,9 @@ func (c *AliyunErrorClassifier) Classify(err error) string {
 	if err == nil {
 		return ErrorStatusUnknown
 	}
+	if isCanceled(err) {
+		return ErrorStatusCanceled
+	}
 	msg := err.Error()
 	if strings.Contains(msg, "InvalidAccessKeyId") || strings.Contains(msg, "Forbidden") || strings.Contains(msg, "SignatureDoesNotMatch") {
 		return ErrorStatusAuth
@@ -63,6 +103,9 @@ func (c *TencentErrorClassifier) Classify(err error) string {
 	if err == nil {
 		return ErrorStatusUnknown
 	}
+	if isCanceled(err) {
+		return ErrorStatusCanceled
+	}
 	msg := err.Error()
 	if strings.Contains(msg, "AuthFailure") || strings.Contains(msg, "InvalidCredential") {
 		return ErrorStatusAuth
@@ -84,6 +127,9 @@ func (c *AWSErrorClassifier) Classify(err error) string {
 	if err == nil {
 		return ErrorStatusUnknown
 	}
+	if isCanceled(err) {
+		return ErrorStatusCanceled
+	}
 	msg := err.Error()
 	if strings.Contains(msg, "ExpiredToken") || strings.Contains(msg, "InvalidClientTokenId") || strings.Contains(msg, "AccessDenied") {
 		return ErrorStatusAuth
@@ -105,6 +151,9 @@ func (c *HuaweiErrorClassifier) Classify(err error) string {
 	if err == nil {
 		return ErrorStatusUnknown
 	}
+	if isCanceled(err) {
+		return ErrorStatusCanceled
+	}
 	msg := err.Error()
 	// 认证错误
 	if strings.Contains(msg, "Authenticate") || strings.Contains(msg, "401") ||
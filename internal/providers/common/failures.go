@@ -0,0 +1,44 @@
+package common
+
+import "sync"
+
+// accountErrors 账号级错误记录：provider|account_id -> 错误状态 -> 次数。
+// 采集调度器在账号采集开始前清空、结束后读取，用于判定账号本轮采集是否失败。
+var (
+	accountErrorsMu sync.Mutex
+	accountErrors   = make(map[string]map[string]int)
+)
+
+// RecordAccountError 记录账号在本轮采集中遇到的错误状态（ErrorStatus* 常量），认证失败同时计入账号熔断器
+func RecordAccountError(provider, accountID, status string) {
+	if status == "" {
+		return
+	}
+	if IsAccountFailure(status) {
+		recordBreakerFailure(provider, accountID, "", status)
+	}
+	key := provider + "|" + accountID
+	accountErrorsMu.Lock()
+	defer accountErrorsMu.Unlock()
+	m := accountErrors[key]
+	if m == nil {
+		m = make(map[string]int)
+		accountErrors[key] = m
+	}
+	m[status]++
+}
+
+// TakeAccountErrors 返回并清空账号已记录的错误状态计数
+func TakeAccountErrors(provider, accountID string) map[string]int {
+	key := provider + "|" + accountID
+	accountErrorsMu.Lock()
+	defer accountErrorsMu.Unlock()
+	m := accountErrors[key]
+	delete(accountErrors, key)
+	return m
+}
+
+// IsAccountFailure 判断错误状态是否意味着账号采集失败（认证失败无法通过重试或跳过区域恢复）
+func IsAccountFailure(status string) bool {
+	return status == ErrorStatusAuth
+}
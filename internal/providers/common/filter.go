@@ -0,0 +1,219 @@
+// Package common 提供资源级 include/exclude 过滤
+// 过滤在资源枚举之后、调用任何监控 API 之前执行，被过滤的资源不产生监控调用。
+package common
+
+import (
+	"regexp"
+	"sync"
+
+	"multicloud-exporter/internal/config"
+	"multicloud-exporter/internal/logger"
+)
+
+// ResourceInfo 过滤所需的资源信息
+type ResourceInfo struct {
+	ID       string
+	Name     string
+	CodeName string
+	Tags     map[string]string
+}
+
+// ResourceFilter 账号级与资源类型级过滤规则的组合，二者需同时通过
+type ResourceFilter struct {
+	filters []config.ResourceFilter
+	cfg     *config.Config // 用于按 server.code_name_chain 解析未提供 CodeName 的资源
+}
+
+// NewResourceFilter 根据账号配置构建资源过滤器
+// cfg 提供 server.code_name_chain，可为 nil（使用默认解析链）；names 为资源类型名称及其别名（如 "s3", "oss"），取第一个已配置的资源类型规则。
+// 未配置任何规则时返回 nil，nil 过滤器放行所有资源。
+func NewResourceFilter(cfg *config.Config, account config.CloudAccount, names ...string) *ResourceFilter {
+	if account.Filters == nil {
+		return nil
+	}
+	var fs []config.ResourceFilter
+	if !account.Filters.ResourceFilter.IsEmpty() {
+		fs = append(fs, account.Filters.ResourceFilter)
+	}
+	for _, n := range names {
+		if rf, ok := account.Filters.Resources[n]; ok && !rf.IsEmpty() {
+			fs = append(fs, rf)
+			break
+		}
+	}
+	if len(fs) == 0 {
+		return nil
+	}
+	return &ResourceFilter{filters: fs, cfg: cfg}
+}
+
+// NeedsTags 判断规则是否依赖资源标签（依赖时调用方需要补充标签信息）
+func (f *ResourceFilter) NeedsTags() bool {
+	if f == nil {
+		return false
+	}
+	for _, rf := range f.filters {
+		if rf.Include != nil && len(rf.Include.Tags) > 0 {
+			return true
+		}
+		if rf.Exclude != nil && len(rf.Exclude.Tags) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+var tagFilterWarned sync.Map
+
+// WarnTagsUnsupported 资源类型无法获取标签而规则依赖标签时告警（每个账号与资源类型一次）：
+// 账号级标签规则对该资源类型而言 include 不会命中、exclude 不会生效
+func WarnTagsUnsupported(f *ResourceFilter, provider, accountID, rtype string) {
+	if !f.NeedsTags() {
+		return
+	}
+	if _, loaded := tagFilterWarned.LoadOrStore(provider+"/"+accountID+"/"+rtype, struct{}{}); loaded {
+		return
+	}
+	logger.NewContextLogger("Filter", "cloud_provider", provider, "account_id", accountID, "rtype", rtype).
+		Warnf("该资源类型不支持按标签过滤，标签条件视为未命中")
+}
+
+// Match 判断资源是否通过过滤：需命中 include（若配置）且不命中 exclude
+func (f *ResourceFilter) Match(r ResourceInfo) bool {
+	if f == nil {
+		return true
+	}
+	for _, rf := range f.filters {
+		if rf.Include != nil && !rf.Include.IsEmpty() && !f.matchRule(rf.Include, r) {
+			return false
+		}
+		if rf.Exclude != nil && !rf.Exclude.IsEmpty() && f.matchRule(rf.Exclude, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchRule 规则内各类条件为“与”关系：ids 任一命中、name_regex 任一命中、tags 全部命中
+func (f *ResourceFilter) matchRule(rule *config.ResourceFilterRule, r ResourceInfo) bool {
+	if len(rule.IDs) > 0 {
+		hit := false
+		for _, id := range rule.IDs {
+			if id == r.ID {
+				hit = true
+				break
+			}
+		}
+		if !hit {
+			return false
+		}
+	}
+	if len(rule.NameRegex) > 0 {
+		name := r.Name
+		if name == "" {
+			name = r.ID
+		}
+		codeName := r.CodeName
+		if codeName == "" {
+			codeName = ResolveCodeName(f.cfg, DefaultCodeNameChain, r.Tags, "", "")
+		}
+		hit := false
+		for _, p := range rule.NameRegex {
+			re := compileFilterRegex(p)
+			if re == nil {
+				continue
+			}
+			if re.MatchString(name) || (codeName != "" && re.MatchString(codeName)) {
+				hit = true
+				break
+			}
+		}
+		if !hit {
+			return false
+		}
+	}
+	for k, want := range rule.Tags {
+		got, ok := r.Tags[k]
+		if !ok {
+			return false
+		}
+		if want != "" && want != "*" && got != want {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	filterRegexMu    sync.Mutex
+	filterRegexCache = make(map[string]*regexp.Regexp)
+)
+
+// compileFilterRegex 编译并缓存正则，非法表达式返回 nil（配置校验阶段已报告）
+func compileFilterRegex(p string) *regexp.Regexp {
+	filterRegexMu.Lock()
+	defer filterRegexMu.Unlock()
+	if re, ok := filterRegexCache[p]; ok {
+		return re
+	}
+	re, err := regexp.Compile(p)
+	if err != nil {
+		logger.NewContextLogger("Filter").Warnf("资源过滤正则无效 pattern=%s 错误=%v", p, err)
+		re = nil
+	}
+	filterRegexCache[p] = re
+	return re
+}
+
+// FilterResources 对枚举结果执行过滤
+// 规则依赖标签且资源缺少标签时，通过 tagsOf 批量补充（可为 nil，表示该资源类型无法获取标签）。
+func FilterResources(f *ResourceFilter, items []ResourceInfo, tagsOf func(ids []string) map[string]map[string]string) []ResourceInfo {
+	if f == nil || len(items) == 0 {
+		return items
+	}
+	if f.NeedsTags() && tagsOf != nil {
+		var missing []string
+		for _, it := range items {
+			if it.Tags == nil {
+				missing = append(missing, it.ID)
+			}
+		}
+		if len(missing) > 0 {
+			tags := tagsOf(missing)
+			for i := range items {
+				if items[i].Tags == nil {
+					items[i].Tags = tags[items[i].ID]
+				}
+			}
+		}
+	}
+	out := make([]ResourceInfo, 0, len(items))
+	for _, it := range items {
+		if f.Match(it) {
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+// FilterIDs 对仅有 ID（及可选名称）的枚举结果执行过滤，返回保留的 ID 列表
+func FilterIDs(f *ResourceFilter, ids []string, names map[string]string, tagsOf func(ids []string) map[string]map[string]string) []string {
+	if f == nil || len(ids) == 0 {
+		return ids
+	}
+	kept := FilterResources(f, ResourceInfosFromIDs(ids, names), tagsOf)
+	out := make([]string, 0, len(kept))
+	for _, it := range kept {
+		out = append(out, it.ID)
+	}
+	return out
+}
+
+// ResourceInfosFromIDs 将 ID 列表（及可选名称）转换为过滤输入
+func ResourceInfosFromIDs(ids []string, names map[string]string) []ResourceInfo {
+	items := make([]ResourceInfo, 0, len(ids))
+	for _, id := range ids {
+		items = append(items, ResourceInfo{ID: id, Name: names[id]})
+	}
+	return items
+}
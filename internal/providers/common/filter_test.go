@@ -0,0 +1,130 @@
+package common
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"multicloud-exporter/internal/config"
+)
+
+func parseAccountFilters(t *testing.T, s string) config.CloudAccount {
+	t.Helper()
+	var acc config.CloudAccount
+	if err := yaml.Unmarshal([]byte(s), &acc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return acc
+}
+
+func TestNewResourceFilter_NoRules(t *testing.T) {
+	if f := NewResourceFilter(nil, config.CloudAccount{}, "clb"); f != nil {
+		t.Fatalf("expected nil filter without rules")
+	}
+	var f *ResourceFilter
+	if !f.Match(ResourceInfo{ID: "x"}) {
+		t.Fatalf("nil filter should match everything")
+	}
+}
+
+func TestResourceFilter_IncludeExclude(t *testing.T) {
+	acc := parseAccountFilters(t, `
+account_id: acc
+filters:
+  exclude:
+    name_regex: ["^test-"]
+  resources:
+    s3:
+      include:
+        tags:
+          env: prod
+          owner: "*"
+    clb:
+      include:
+        ids: ["lb-1", "lb-2"]
+`)
+	clb := NewResourceFilter(nil, acc, "slb", "clb")
+	if clb == nil {
+		t.Fatalf("expected clb filter")
+	}
+	if !clb.Match(ResourceInfo{ID: "lb-1", Name: "web"}) {
+		t.Fatalf("lb-1 should pass")
+	}
+	if clb.Match(ResourceInfo{ID: "lb-3", Name: "web"}) {
+		t.Fatalf("lb-3 not in include ids")
+	}
+	if clb.Match(ResourceInfo{ID: "lb-2", Name: "test-web"}) {
+		t.Fatalf("account-level exclude should apply")
+	}
+	// 别名顺序：第一个命中的资源类型规则生效
+	oss := NewResourceFilter(nil, acc, "oss", "s3")
+	if oss.NeedsTags() == false {
+		t.Fatalf("tag rule should need tags")
+	}
+	if !oss.Match(ResourceInfo{ID: "b1", Tags: map[string]string{"env": "prod", "owner": "ops"}}) {
+		t.Fatalf("tags should match")
+	}
+	if oss.Match(ResourceInfo{ID: "b2", Tags: map[string]string{"env": "prod"}}) {
+		t.Fatalf("missing tag key should not match")
+	}
+	// 未配置资源类型规则时仅账号级规则生效
+	gwlb := NewResourceFilter(nil, acc, "gwlb")
+	if gwlb == nil || !gwlb.Match(ResourceInfo{ID: "g1"}) || gwlb.Match(ResourceInfo{ID: "g2", Name: "test-g"}) {
+		t.Fatalf("unexpected account-level filtering")
+	}
+}
+
+func TestResourceFilter_CodeNameRegex(t *testing.T) {
+	acc := parseAccountFilters(t, `
+filters:
+  include:
+    name_regex: ["^svc-"]
+`)
+	f := NewResourceFilter(nil, acc, "clb")
+	if !f.Match(ResourceInfo{ID: "lb-1", Name: "random", CodeName: "svc-api"}) {
+		t.Fatalf("code_name should match name_regex")
+	}
+	if !f.Match(ResourceInfo{ID: "lb-2", Tags: map[string]string{"CodeName": "svc-web"}}) {
+		t.Fatalf("CodeName tag should match name_regex")
+	}
+	if f.Match(ResourceInfo{ID: "lb-3", Name: "other"}) {
+		t.Fatalf("lb-3 should not match")
+	}
+
+	// 配置了 server.code_name_chain 时按配置的解析链取 code_name
+	cfg := &config.Config{Server: &config.ServerConf{CodeNameChain: "tags.Project -> tags.CodeName"}}
+	f = NewResourceFilter(cfg, acc, "clb")
+	if !f.Match(ResourceInfo{ID: "lb-4", Tags: map[string]string{"Project": "svc-pay"}}) {
+		t.Fatalf("configured code_name_chain should be used")
+	}
+}
+
+func TestFilterIDs_FetchesTagsOnDemand(t *testing.T) {
+	acc := parseAccountFilters(t, `
+filters:
+  resources:
+    s3:
+      exclude:
+        tags:
+          skip: ""
+`)
+	calls := 0
+	tagsOf := func(ids []string) map[string]map[string]string {
+		calls++
+		return map[string]map[string]string{"b2": {"skip": "yes"}}
+	}
+	got := FilterIDs(NewResourceFilter(nil, acc, "s3"), []string{"b1", "b2", "b3"}, nil, tagsOf)
+	if calls != 1 {
+		t.Fatalf("tagsOf should be called once, got %d", calls)
+	}
+	if len(got) != 2 || got[0] != "b1" || got[1] != "b3" {
+		t.Fatalf("unexpected ids: %v", got)
+	}
+
+	// 无标签规则时不应查询标签
+	calls = 0
+	got = FilterIDs(NewResourceFilter(nil, acc, "clb"), []string{"x"}, nil, tagsOf)
+	if calls != 0 || len(got) != 1 {
+		t.Fatalf("unexpected tag lookup or filtering: calls=%d ids=%v", calls, got)
+	}
+}
@@ -0,0 +1,169 @@
+package common
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"multicloud-exporter/internal/metrics"
+)
+
+// TargetRun 一次采集目标（云/账号/区域/命名空间）的采集记录。
+// 云采集器在目标开始采集时调用 StartTarget，采集过程中通过 RecordTargetError 上报错误，
+// 结束时调用 Finish 导出 multicloud_collection_* 健康指标。
+type TargetRun struct {
+	provider  string
+	accountID string
+	region    string
+	namespace string
+	start     time.Time
+	ctx       context.Context
+	recorder  *ResultRecorder
+
+	mu      sync.Mutex
+	errors  map[string]int
+	samples int
+}
+
+// activeTargets 进行中的采集目标：provider|account|region|namespace -> TargetRun。
+// 错误上报点通常位于深层调用，不持有 TargetRun，按目标键查找。
+var (
+	activeTargetsMu sync.Mutex
+	activeTargets   = make(map[string]*TargetRun)
+)
+
+func targetKey(provider, accountID, region, namespace string) string {
+	return provider + "|" + accountID + "|" + region + "|" + namespace
+}
+
+// StartTarget 开始一次目标采集；同一目标重复开始时沿用进行中的记录。
+// ctx 上绑定了结果记录器（BeginCollect）时，目标结束后结果写入该记录器。
+func StartTarget(ctx context.Context, provider, accountID, region, namespace string) *TargetRun {
+	key := targetKey(provider, accountID, region, namespace)
+	activeTargetsMu.Lock()
+	defer activeTargetsMu.Unlock()
+	if r, ok := activeTargets[key]; ok {
+		return r
+	}
+	r := &TargetRun{
+		provider:  provider,
+		accountID: accountID,
+		region:    region,
+		namespace: namespace,
+		start:     time.Now(),
+		ctx:       ctx,
+		recorder:  recorderFromContext(ctx),
+		errors:    make(map[string]int),
+	}
+	activeTargets[key] = r
+	return r
+}
+
+// RecordTargetError 上报目标采集错误（ErrorStatus* 常量）：累加错误计数，
+// 标记进行中的目标本轮失败，并同步记录账号级错误（用于判定账号失败）；region_skip 计入区域熔断器。
+// 因采集取消导致的错误只计入进行中的目标，不计入错误指标。
+func RecordTargetError(provider, accountID, region, namespace, status string) {
+	if status == "" {
+		return
+	}
+	if status != ErrorStatusCanceled {
+		metrics.CollectionErrorsTotal.WithLabelValues(provider, accountID, region, namespace, status).Inc()
+		RecordAccountError(provider, accountID, status)
+	}
+	if status == ErrorStatusRegion {
+		recordBreakerFailure(provider, accountID, region, status)
+	}
+	if r := activeTarget(provider, accountID, region, namespace); r != nil {
+		r.mu.Lock()
+		r.errors[status]++
+		r.mu.Unlock()
+	}
+}
+
+// RecordTargetSamples 上报目标导出的样本数，同时累加命名空间样本计数
+func RecordTargetSamples(provider, accountID, region, namespace string, n int) {
+	metrics.IncSampleCount(ScheduleKey(provider, accountID, region, namespace), namespace, n)
+	if r := activeTarget(provider, accountID, region, namespace); r != nil {
+		r.mu.Lock()
+		r.samples += n
+		r.mu.Unlock()
+	}
+}
+
+func activeTarget(provider, accountID, region, namespace string) *TargetRun {
+	activeTargetsMu.Lock()
+	defer activeTargetsMu.Unlock()
+	return activeTargets[targetKey(provider, accountID, region, namespace)]
+}
+
+// Errors 返回目标本轮已记录的错误状态计数
+func (r *TargetRun) Errors() map[string]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make(map[string]int, len(r.errors))
+	for k, v := range r.errors {
+		out[k] = v
+	}
+	return out
+}
+
+// Finish 结束目标采集并导出健康指标，返回本轮是否成功。
+// 区域跳过（region_skip）不视为失败，其余错误类别均使目标本轮失败；
+// 采集被取消时结果记为失败，但不更新健康指标，避免关闭服务时误报目标异常。
+func (r *TargetRun) Finish() bool {
+	activeTargetsMu.Lock()
+	key := targetKey(r.provider, r.accountID, r.region, r.namespace)
+	if activeTargets[key] == r {
+		delete(activeTargets, key)
+	}
+	activeTargetsMu.Unlock()
+
+	errs := r.Errors()
+	ok := true
+	for status := range errs {
+		if IsTargetFailure(status) {
+			ok = false
+			break
+		}
+	}
+	canceled := r.ctx != nil && r.ctx.Err() != nil
+	if canceled {
+		ok = false
+	}
+	now := time.Now()
+	if r.recorder != nil {
+		r.mu.Lock()
+		samples := r.samples
+		r.mu.Unlock()
+		r.recorder.add(TargetResult{
+			Region:    r.region,
+			Namespace: r.namespace,
+			Success:   ok,
+			Samples:   samples,
+			Errors:    errs,
+			Duration:  now.Sub(r.start),
+		})
+	}
+	if canceled {
+		return false
+	}
+	metrics.CollectionDuration.WithLabelValues(r.provider, r.accountID, r.region, r.namespace).Set(now.Sub(r.start).Seconds())
+	if ok {
+		// 目标成功即说明账号凭证与区域可用，恢复半开的熔断器；
+		// 仅因区域跳过而“成功”的目标没有在该区域成功调用，不恢复区域熔断器
+		recordBreakerSuccess(r.provider, r.accountID, "")
+		if errs[ErrorStatusRegion] == 0 {
+			recordBreakerSuccess(r.provider, r.accountID, r.region)
+		}
+		metrics.CollectionUp.WithLabelValues(r.provider, r.accountID, r.region, r.namespace).Set(1)
+		metrics.CollectionLastSuccess.WithLabelValues(r.provider, r.accountID, r.region, r.namespace).Set(float64(now.Unix()))
+	} else {
+		metrics.CollectionUp.WithLabelValues(r.provider, r.accountID, r.region, r.namespace).Set(0)
+	}
+	return ok
+}
+
+// IsTargetFailure 判断错误状态是否意味着目标本轮采集失败
+func IsTargetFailure(status string) bool {
+	return status != "" && status != ErrorStatusRegion
+}
@@ -0,0 +1,84 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"multicloud-exporter/internal/metrics"
+)
+
+func metricValue(t *testing.T, c prometheus.Collector, labels ...string) float64 {
+	t.Helper()
+	var m dto.Metric
+	switch v := c.(type) {
+	case *prometheus.GaugeVec:
+		if err := v.WithLabelValues(labels...).Write(&m); err != nil {
+			t.Fatal(err)
+		}
+		return m.GetGauge().GetValue()
+	case *prometheus.CounterVec:
+		if err := v.WithLabelValues(labels...).Write(&m); err != nil {
+			t.Fatal(err)
+		}
+		return m.GetCounter().GetValue()
+	}
+	t.Fatalf("unsupported collector %T", c)
+	return 0
+}
+
+func TestTargetRun_Success(t *testing.T) {
+	labels := []string{"mock", "acc-ok", "r1", "ns1"}
+	r := StartTarget(context.Background(), "mock", "acc-ok", "r1", "ns1")
+	// 区域跳过不视为失败
+	RecordTargetError("mock", "acc-ok", "r1", "ns1", ErrorStatusRegion)
+	if !r.Finish() {
+		t.Fatalf("region_skip should not fail the target")
+	}
+	if v := metricValue(t, metrics.CollectionUp, labels...); v != 1 {
+		t.Fatalf("up = %v, want 1", v)
+	}
+	if v := metricValue(t, metrics.CollectionLastSuccess, labels...); v <= 0 {
+		t.Fatalf("last success timestamp not set")
+	}
+	if v := metricValue(t, metrics.CollectionErrorsTotal, append(labels, ErrorStatusRegion)...); v != 1 {
+		t.Fatalf("errors_total{region_skip} = %v, want 1", v)
+	}
+	TakeAccountErrors("mock", "acc-ok")
+}
+
+func TestTargetRun_Failure(t *testing.T) {
+	labels := []string{"mock", "acc-bad", "r1", "ns1"}
+	metrics.CollectionLastSuccess.WithLabelValues(labels...).Set(42)
+
+	r := StartTarget(context.Background(), "mock", "acc-bad", "r1", "ns1")
+	if StartTarget(context.Background(), "mock", "acc-bad", "r1", "ns1") != r {
+		t.Fatalf("restarting an active target should reuse the run")
+	}
+	RecordTargetError("mock", "acc-bad", "r1", "ns1", ErrorStatusAuth)
+	RecordTargetError("mock", "acc-bad", "r1", "ns1", ErrorStatusAuth)
+	if got := r.Errors()[ErrorStatusAuth]; got != 2 {
+		t.Fatalf("auth errors = %d, want 2", got)
+	}
+	if r.Finish() {
+		t.Fatalf("auth error should fail the target")
+	}
+	if v := metricValue(t, metrics.CollectionUp, labels...); v != 0 {
+		t.Fatalf("up = %v, want 0", v)
+	}
+	if v := metricValue(t, metrics.CollectionLastSuccess, labels...); v != 42 {
+		t.Fatalf("last success should be kept on failure, got %v", v)
+	}
+	if errs := TakeAccountErrors("mock", "acc-bad"); errs[ErrorStatusAuth] != 2 {
+		t.Fatalf("account errors not recorded: %v", errs)
+	}
+
+	// 结束后的错误不再计入已完成的目标
+	RecordTargetError("mock", "acc-bad", "r1", "ns1", ErrorStatusLimit)
+	if got := r.Errors()[ErrorStatusLimit]; got != 0 {
+		t.Fatalf("finished run should not collect new errors")
+	}
+	TakeAccountErrors("mock", "acc-bad")
+}
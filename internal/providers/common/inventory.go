@@ -0,0 +1,128 @@
+package common
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// InventoryItem 资源清单条目
+type InventoryItem struct {
+	Provider     string                 `json:"provider"`
+	AccountID    string                 `json:"account_id"`
+	Region       string                 `json:"region"`
+	Namespace    string                 `json:"namespace"`
+	ResourceType string                 `json:"resource_type"`
+	ResourceID   string                 `json:"resource_id"`
+	Name         string                 `json:"name,omitempty"`
+	CodeName     string                 `json:"code_name,omitempty"`
+	Tags         map[string]string      `json:"tags,omitempty"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	UpdatedAt    time.Time              `json:"updated_at"`
+}
+
+// InventoryProvider 可选接口：上报采集器当前持有的资源清单（来自资源缓存，不触发云 API 调用）
+type InventoryProvider interface {
+	Inventory() []InventoryItem
+}
+
+// SplitCacheKey 拆分 "account|region|namespace|rtype" 形式的资源缓存键
+func SplitCacheKey(key string) (account, region, namespace, rtype string, ok bool) {
+	parts := strings.SplitN(key, "|", 4)
+	if len(parts) != 4 {
+		return "", "", "", "", false
+	}
+	return parts[0], parts[1], parts[2], parts[3], true
+}
+
+// InventoryQuery 资源清单查询条件，空字段表示不过滤
+type InventoryQuery struct {
+	Provider     string
+	AccountID    string
+	Region       string
+	Namespace    string
+	ResourceType string
+	// Search 在资源 ID、名称与 code_name 中做不区分大小写的子串匹配
+	Search string
+	// Tags 要求资源标签全部匹配，值为空或 "*" 表示仅要求标签键存在
+	Tags map[string]string
+	// Offset/Limit 分页，Limit <= 0 表示不分页
+	Offset int
+	Limit  int
+}
+
+// Match 判断条目是否满足查询条件（不含分页）
+func (q InventoryQuery) Match(it InventoryItem) bool {
+	if q.Provider != "" && it.Provider != q.Provider {
+		return false
+	}
+	if q.AccountID != "" && it.AccountID != q.AccountID {
+		return false
+	}
+	if q.Region != "" && it.Region != q.Region {
+		return false
+	}
+	if q.Namespace != "" && it.Namespace != q.Namespace {
+		return false
+	}
+	if q.ResourceType != "" && it.ResourceType != q.ResourceType {
+		return false
+	}
+	if q.Search != "" {
+		s := strings.ToLower(q.Search)
+		if !strings.Contains(strings.ToLower(it.ResourceID), s) &&
+			!strings.Contains(strings.ToLower(it.Name), s) &&
+			!strings.Contains(strings.ToLower(it.CodeName), s) {
+			return false
+		}
+	}
+	for k, v := range q.Tags {
+		tv, ok := it.Tags[k]
+		if !ok || (v != "" && v != "*" && tv != v) {
+			return false
+		}
+	}
+	return true
+}
+
+// QueryInventory 排序、过滤并分页，返回当前页与过滤后的总数
+func QueryInventory(items []InventoryItem, q InventoryQuery) ([]InventoryItem, int) {
+	matched := make([]InventoryItem, 0, len(items))
+	for _, it := range items {
+		if q.Match(it) {
+			matched = append(matched, it)
+		}
+	}
+	SortInventory(matched)
+	total := len(matched)
+	if q.Offset > 0 {
+		if q.Offset >= total {
+			return []InventoryItem{}, total
+		}
+		matched = matched[q.Offset:]
+	}
+	if q.Limit > 0 && len(matched) > q.Limit {
+		matched = matched[:q.Limit]
+	}
+	return matched, total
+}
+
+// SortInventory 按 provider、account、region、namespace、resource_id 排序，保证分页稳定
+func SortInventory(items []InventoryItem) {
+	sort.Slice(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		if a.AccountID != b.AccountID {
+			return a.AccountID < b.AccountID
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.ResourceID < b.ResourceID
+	})
+}
@@ -0,0 +1,61 @@
+package common
+
+import "testing"
+
+func inventoryFixture() []InventoryItem {
+	return []InventoryItem{
+		{Provider: "aws", AccountID: "a1", Region: "us-east-1", Namespace: "AWS/S3", ResourceType: "s3", ResourceID: "bucket-b", Tags: map[string]string{"env": "prod"}},
+		{Provider: "aliyun", AccountID: "a2", Region: "cn-hangzhou", Namespace: "acs_slb_dashboard", ResourceType: "clb", ResourceID: "lb-2", Name: "Web-LB"},
+		{Provider: "aliyun", AccountID: "a2", Region: "cn-hangzhou", Namespace: "acs_slb_dashboard", ResourceType: "clb", ResourceID: "lb-1", Tags: map[string]string{"env": "dev"}},
+		{Provider: "aws", AccountID: "a1", Region: "us-east-1", Namespace: "AWS/S3", ResourceType: "s3", ResourceID: "bucket-a", Tags: map[string]string{"env": "prod", "team": "x"}},
+	}
+}
+
+func TestSplitCacheKey(t *testing.T) {
+	acc, region, ns, rtype, ok := SplitCacheKey("a1|cn-hangzhou|acs_slb_dashboard|clb")
+	if !ok || acc != "a1" || region != "cn-hangzhou" || ns != "acs_slb_dashboard" || rtype != "clb" {
+		t.Fatalf("unexpected split: %q %q %q %q %v", acc, region, ns, rtype, ok)
+	}
+	if _, _, _, _, ok := SplitCacheKey("a1|cn-hangzhou"); ok {
+		t.Fatalf("short key should not split")
+	}
+}
+
+func TestQueryInventory_Filters(t *testing.T) {
+	cases := []struct {
+		name string
+		q    InventoryQuery
+		want []string
+	}{
+		{"all sorted", InventoryQuery{}, []string{"lb-1", "lb-2", "bucket-a", "bucket-b"}},
+		{"provider", InventoryQuery{Provider: "aws"}, []string{"bucket-a", "bucket-b"}},
+		{"resource type", InventoryQuery{ResourceType: "clb"}, []string{"lb-1", "lb-2"}},
+		{"search name case-insensitive", InventoryQuery{Search: "web"}, []string{"lb-2"}},
+		{"tag value", InventoryQuery{Tags: map[string]string{"env": "prod"}}, []string{"bucket-a", "bucket-b"}},
+		{"tag key only", InventoryQuery{Tags: map[string]string{"team": ""}}, []string{"bucket-a"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, total := QueryInventory(inventoryFixture(), tc.q)
+			if total != len(tc.want) || len(got) != len(tc.want) {
+				t.Fatalf("got %d items (total %d), want %v", len(got), total, tc.want)
+			}
+			for i, id := range tc.want {
+				if got[i].ResourceID != id {
+					t.Fatalf("item %d = %s, want %s", i, got[i].ResourceID, id)
+				}
+			}
+		})
+	}
+}
+
+func TestQueryInventory_Pagination(t *testing.T) {
+	got, total := QueryInventory(inventoryFixture(), InventoryQuery{Offset: 1, Limit: 2})
+	if total != 4 || len(got) != 2 || got[0].ResourceID != "lb-2" || got[1].ResourceID != "bucket-a" {
+		t.Fatalf("unexpected page: total=%d items=%v", total, got)
+	}
+	got, total = QueryInventory(inventoryFixture(), InventoryQuery{Offset: 10, Limit: 2})
+	if total != 4 || len(got) != 0 {
+		t.Fatalf("offset past end should return empty page, got %d", len(got))
+	}
+}
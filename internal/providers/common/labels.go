@@ -0,0 +1,50 @@
+// Package common 提供自定义标签解析
+// 标签来源优先级（后者覆盖前者）：datatag < 账号 labels < server.tag_labels 命中的资源标签。
+package common
+
+import (
+	"multicloud-exporter/internal/config"
+	"multicloud-exporter/internal/metrics"
+)
+
+// CustomLabels 返回资源的自定义标签值，键为最终标签名（与 metrics.CustomLabels 一致）。
+// tags 为资源的云标签，可为 nil（仅返回静态标签）。
+func CustomLabels(cfg *config.Config, account config.CloudAccount, tags map[string]string) map[string]string {
+	out := make(map[string]string)
+	if cfg != nil {
+		for _, dt := range cfg.DataTag {
+			if dt.Key != "" {
+				out[metrics.CustomLabelName(dt.Key)] = dt.Val
+			}
+		}
+	}
+	for k, v := range account.Labels {
+		out[metrics.CustomLabelName(k)] = v
+	}
+	if len(tags) == 0 {
+		return out
+	}
+	for _, k := range TagLabelKeys(cfg) {
+		if v, ok := tags[k]; ok && v != "" {
+			out[metrics.CustomLabelName(k)] = v
+		}
+	}
+	return out
+}
+
+// TagLabelKeys 返回需要提升为指标标签的资源标签键
+func TagLabelKeys(cfg *config.Config) []string {
+	if cfg == nil {
+		return nil
+	}
+	server := cfg.GetServer()
+	if server == nil {
+		return nil
+	}
+	return server.TagLabels
+}
+
+// NeedsTagLabels 判断是否配置了 tag_labels（未配置时无需为标签提升额外查询标签）
+func NeedsTagLabels(cfg *config.Config) bool {
+	return len(TagLabelKeys(cfg)) > 0
+}
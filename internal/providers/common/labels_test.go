@@ -0,0 +1,28 @@
+package common
+
+import (
+	"testing"
+
+	"multicloud-exporter/internal/config"
+)
+
+func TestCustomLabels_Precedence(t *testing.T) {
+	cfg := &config.Config{
+		Server:  &config.ServerConf{TagLabels: []string{"env", "cost-center"}},
+		DataTag: []config.DataTag{{Key: "env", Val: "default"}, {Key: "team", Val: "ops"}},
+	}
+	account := config.CloudAccount{Labels: map[string]string{"team": "infra"}}
+
+	got := CustomLabels(cfg, account, nil)
+	if got["env"] != "default" || got["team"] != "infra" {
+		t.Fatalf("unexpected static labels: %v", got)
+	}
+
+	got = CustomLabels(cfg, account, map[string]string{"env": "prod", "cost-center": "cc-1", "owner": "x"})
+	if got["env"] != "prod" || got["cost_center"] != "cc-1" || got["team"] != "infra" {
+		t.Fatalf("unexpected labels: %v", got)
+	}
+	if _, ok := got["owner"]; ok {
+		t.Fatalf("tags not listed in tag_labels should not be promoted")
+	}
+}
@@ -20,6 +20,12 @@ const (
 	NamespaceTencentCVM  = "QCE/CVM"
 )
 
+// 华为云命名空间常量
+const (
+	NamespaceHuaweiELB = "SYS.ELB"
+	NamespaceHuaweiOBS = "SYS.OBS"
+)
+
 // AWS 命名空间常量
 const (
 	NamespaceAWSS3  = "AWS/S3"
@@ -0,0 +1,131 @@
+package common
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"multicloud-exporter/internal/config"
+	"multicloud-exporter/internal/metrics"
+)
+
+// DefaultRateLimits 内置的云 API 限流预算，取自各云厂商文档的默认限额，可被 server.rate_limits 覆盖。
+// Key 为 "provider.API" 或 "provider"，含义同 config.ServerConf.RateLimits。
+var DefaultRateLimits = map[string]config.RateLimitConf{
+	// 阿里云云监控：DescribeMetricLast / DescribeMetricList 单账号 50 次/秒
+	"aliyun.DescribeMetricLast": {QPS: 50},
+	"aliyun.DescribeMetricList": {QPS: 50},
+	// 腾讯云云监控：GetMonitorData 20 次/秒
+	"tencent.GetMonitorData": {QPS: 20},
+	// 华为云 CES：BatchListMetricData 300 次/分钟
+	"huawei.BatchListMetricData": {QPS: 5},
+	// AWS CloudWatch：GetMetricData 50 TPS
+	"aws.GetMetricData": {QPS: 50},
+
+	// 资源枚举与标签 API：分页与分批调用经令牌桶限速，取代固定的页间 sleep
+	"aliyun.DescribeLoadBalancers":           {QPS: 10},
+	"aliyun.ListLoadBalancers":               {QPS: 10},
+	"aliyun.DescribeCommonBandwidthPackages": {QPS: 10},
+	"aliyun.ListBuckets":                     {QPS: 10},
+	"aliyun.ListTagResources":                {QPS: 10},
+	"tencent.DescribeLoadBalancers":          {QPS: 10},
+	"tencent.DescribeBandwidthPackages":      {QPS: 10},
+	"tencent.ListBuckets":                    {QPS: 10},
+	"huawei.ListLoadBalancers":               {QPS: 10},
+	"huawei.ListBuckets":                     {QPS: 10},
+	"aws.DescribeLoadBalancers":              {QPS: 10},
+	"aws.ListBuckets":                        {QPS: 10},
+}
+
+// rateLimiters 令牌桶注册表：provider|account|api -> limiter，首次调用时按预算创建
+var (
+	rateLimitMu     sync.Mutex
+	rateLimitConfig = map[string]config.RateLimitConf{}
+	rateLimiters    = make(map[string]*rate.Limiter)
+)
+
+// ConfigureRateLimits 设置限流预算（server.rate_limits），并丢弃已创建的令牌桶
+func ConfigureRateLimits(limits map[string]config.RateLimitConf) {
+	rateLimitMu.Lock()
+	defer rateLimitMu.Unlock()
+	rateLimitConfig = make(map[string]config.RateLimitConf, len(limits))
+	for k, v := range limits {
+		rateLimitConfig[k] = v
+	}
+	rateLimiters = make(map[string]*rate.Limiter)
+}
+
+// ResolveRateLimit 按 配置 provider.API > 配置 provider > 内置 provider.API > 内置 provider 的顺序解析预算
+func ResolveRateLimit(provider, api string) (config.RateLimitConf, bool) {
+	rateLimitMu.Lock()
+	defer rateLimitMu.Unlock()
+	return resolveRateLimit(provider, api)
+}
+
+func resolveRateLimit(provider, api string) (config.RateLimitConf, bool) {
+	for _, m := range []map[string]config.RateLimitConf{rateLimitConfig, DefaultRateLimits} {
+		if rl, ok := m[provider+"."+api]; ok {
+			return rl, true
+		}
+		if rl, ok := m[provider]; ok {
+			return rl, true
+		}
+	}
+	return config.RateLimitConf{}, false
+}
+
+// limiterFor 获取 provider/账号/API 的令牌桶，未配置预算或 qps 为 0 时返回 nil（不限流）
+func limiterFor(provider, accountID, api string) *rate.Limiter {
+	key := provider + "|" + accountID + "|" + api
+	rateLimitMu.Lock()
+	defer rateLimitMu.Unlock()
+	if l, ok := rateLimiters[key]; ok {
+		return l
+	}
+	var l *rate.Limiter
+	if rl, ok := resolveRateLimit(provider, api); ok && rl.QPS > 0 {
+		burst := rl.Burst
+		if burst <= 0 {
+			burst = int(math.Max(1, math.Ceil(rl.QPS)))
+		}
+		l = rate.NewLimiter(rate.Limit(rl.QPS), burst)
+	}
+	rateLimiters[key] = l
+	return l
+}
+
+// WaitRateLimit 云 API 调用前获取令牌：阻塞直到令牌可用或 ctx 结束，等待时间计入
+// multicloud_rate_limit_wait_seconds。ctx 结束时立即返回 ctx.Err()，调用方按取消处理。
+// accountID 为空时使用 ctx 上绑定的账号（BeginCollect）。
+func WaitRateLimit(ctx context.Context, provider, accountID, api string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if accountID == "" {
+		accountID = AccountIDFromContext(ctx)
+	}
+	l := limiterFor(provider, accountID, api)
+	if l == nil {
+		return nil
+	}
+	start := time.Now()
+	// 使用 Reserve 而非 Wait：Wait 在截止时间早于令牌可用时间时直接返回错误且不等待，
+	// 会让临近截止时间的调用绕过限流
+	r := l.Reserve()
+	if d := r.Delay(); d > 0 {
+		t := time.NewTimer(d)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			r.Cancel()
+			metrics.RateLimitWait.WithLabelValues(provider, api).Observe(time.Since(start).Seconds())
+			return ctx.Err()
+		}
+	}
+	metrics.RateLimitWait.WithLabelValues(provider, api).Observe(time.Since(start).Seconds())
+	return nil
+}
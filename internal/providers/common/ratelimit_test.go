@@ -0,0 +1,96 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"multicloud-exporter/internal/config"
+)
+
+func TestResolveRateLimit_Precedence(t *testing.T) {
+	ConfigureRateLimits(map[string]config.RateLimitConf{
+		"tencent":                   {QPS: 3},
+		"aliyun.DescribeMetricLast": {QPS: 7, Burst: 2},
+	})
+	defer ConfigureRateLimits(nil)
+
+	if rl, ok := ResolveRateLimit("aliyun", "DescribeMetricLast"); !ok || rl.QPS != 7 || rl.Burst != 2 {
+		t.Fatalf("configured API limit should win, got %+v %v", rl, ok)
+	}
+	if rl, ok := ResolveRateLimit("tencent", "GetMonitorData"); !ok || rl.QPS != 3 {
+		t.Fatalf("configured provider limit should override built-in API default, got %+v %v", rl, ok)
+	}
+	if rl, ok := ResolveRateLimit("huawei", "BatchListMetricData"); !ok || rl.QPS != 5 {
+		t.Fatalf("built-in default expected, got %+v %v", rl, ok)
+	}
+	// 资源枚举 API 同样有内置预算，分页调用经令牌桶限速
+	if rl, ok := ResolveRateLimit("aws", "ListBuckets"); !ok || rl.QPS != 10 {
+		t.Fatalf("built-in listing default expected, got %+v %v", rl, ok)
+	}
+	if _, ok := ResolveRateLimit("aws", "GetBucketLocation"); ok {
+		t.Fatalf("unconfigured API should be unlimited")
+	}
+}
+
+func TestWaitRateLimit_Throttles(t *testing.T) {
+	ConfigureRateLimits(map[string]config.RateLimitConf{"mock.Slow": {QPS: 20, Burst: 1}})
+	defer ConfigureRateLimits(nil)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := WaitRateLimit(context.Background(), "mock", "acc-a", "Slow"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	// burst=1、20 qps：后两次各等待约 50ms
+	if d := time.Since(start); d < 80*time.Millisecond {
+		t.Fatalf("calls were not throttled, took %v", d)
+	}
+
+	// 不同账号使用独立的令牌桶
+	start = time.Now()
+	if err := WaitRateLimit(context.Background(), "mock", "acc-b", "Slow"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d := time.Since(start); d > 30*time.Millisecond {
+		t.Fatalf("separate account should not wait, took %v", d)
+	}
+}
+
+func TestWaitRateLimit_Canceled(t *testing.T) {
+	ConfigureRateLimits(map[string]config.RateLimitConf{"mock": {QPS: 0.1, Burst: 1}})
+	defer ConfigureRateLimits(nil)
+
+	// ctx 绑定的账号作为令牌桶的账号维度
+	ctx, _ := BeginCollect(context.Background(), "mock", "acc-c")
+	if err := WaitRateLimit(ctx, "mock", "", "Any"); err != nil {
+		t.Fatalf("first call should use the burst token: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := WaitRateLimit(ctx, "mock", "acc-c", "Any")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("wait should stop when ctx is done, took %v", d)
+	}
+}
+
+func TestWaitRateLimit_Unlimited(t *testing.T) {
+	ConfigureRateLimits(map[string]config.RateLimitConf{"mock.Free": {QPS: 0}})
+	defer ConfigureRateLimits(nil)
+
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		if err := WaitRateLimit(context.Background(), "mock", "acc", "Free"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if d := time.Since(start); d > 100*time.Millisecond {
+		t.Fatalf("qps 0 should not throttle, took %v", d)
+	}
+}
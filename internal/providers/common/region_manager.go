@@ -13,11 +13,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"multicloud-exporter/internal/config"
 	"multicloud-exporter/internal/logger"
+	"multicloud-exporter/internal/metrics"
 )
 
 // RegionStatus 区域状态
@@ -67,22 +72,33 @@ type RegionManagerStats struct {
 	UpdateCount     int64     `json:"update_count"`
 }
 
-// RegionManager 区域管理器接口
+// RegionManager 区域管理器接口。状态按 (provider, account, region, namespace) 记录，
+// 同一区域中只有 OSS 的账号不会再为 SLB 调用枚举接口。
 type RegionManager interface {
-	// GetActiveRegions 获取活跃区域列表
-	GetActiveRegions(accountID string, allRegions []string) []string
+	// GetActiveRegions 获取活跃区域列表：namespaces 中任一命名空间未被跳过的区域都会保留，
+	// namespaces 为空时按该区域已记录的命名空间判断
+	GetActiveRegions(provider, accountID string, allRegions, namespaces []string) []string
 
-	// UpdateRegionStatus 更新区域状态
-	UpdateRegionStatus(accountID, region string, resourceCount int, status RegionStatus)
+	// UpdateRegionStatus 更新命名空间在区域内的状态
+	UpdateRegionStatus(provider, accountID, region, namespace string, resourceCount int, status RegionStatus)
 
-	// MarkRegionForRediscovery 标记区域为需重新发现
-	MarkRegionForRediscovery(accountID, region string)
+	// MarkRegionForRediscovery 标记区域（全部命名空间）为需重新发现，返回标记的命名空间数
+	MarkRegionForRediscovery(provider, accountID, region string) int
 
-	// GetRegionInfo 获取区域信息
-	GetRegionInfo(accountID, region string) (RegionInfo, bool)
+	// MarkAccountForRediscovery 标记账号全部区域为需重新发现，返回标记的条目数
+	MarkAccountForRediscovery(provider, accountID string) int
 
-	// ShouldSkipRegion 判断是否跳过该区域
-	ShouldSkipRegion(accountID, region string) bool
+	// Reset 删除状态记录，provider/accountID 为空时不按该字段过滤，返回删除的条目数
+	Reset(provider, accountID string) int
+
+	// Entries 返回状态记录（按 provider、account、region、namespace 排序），过滤规则同 Reset
+	Entries(provider, accountID string) []RegionEntry
+
+	// GetRegionInfo 获取命名空间在区域内的状态
+	GetRegionInfo(provider, accountID, region, namespace string) (RegionInfo, bool)
+
+	// ShouldSkipRegion 判断是否跳过该区域的命名空间；namespace 为空时仅当区域内已记录的命名空间全部可跳过才跳过
+	ShouldSkipRegion(provider, accountID, region, namespace string) bool
 
 	// Load 加载持久化状态
 	Load() error
@@ -107,7 +123,7 @@ type RegionManager interface {
 type SmartRegionManager struct {
 	mu            sync.RWMutex
 	config        RegionDiscoveryConfig
-	regionMap     map[string]map[string]RegionInfo
+	regionMap     map[string]map[string]map[string]RegionInfo // provider|account -> region -> namespace -> info
 	stopChan      chan struct{}
 	stopped       atomic.Bool
 	schedulerOnce sync.Once
@@ -144,7 +160,7 @@ func NewRegionManager(config RegionDiscoveryConfig) RegionManager {
 
 	rm := &SmartRegionManager{
 		config:    config,
-		regionMap: make(map[string]map[string]RegionInfo),
+		regionMap: make(map[string]map[string]map[string]RegionInfo),
 		stopChan:  make(chan struct{}),
 		stats: RegionManagerStats{
 			LastCleanupTime: time.Now(),
@@ -160,8 +176,67 @@ func NewRegionManager(config RegionDiscoveryConfig) RegionManager {
 	return rm
 }
 
+// accountKey 状态表的一级键；旧版持久化文件迁移来的记录不区分云，provider 为空
+func accountKey(provider, accountID string) string {
+	return provider + "|" + accountID
+}
+
+// namespaceInfosLocked 返回区域内各命名空间的状态；该云在区域内尚无记录时回退到迁移自旧版文件的
+// 账号级记录（namespace 为空），legacy 为 true
+func (rm *SmartRegionManager) namespaceInfosLocked(provider, accountID, region string) (infos map[string]RegionInfo, legacy bool) {
+	if infos = rm.regionMap[accountKey(provider, accountID)][region]; len(infos) > 0 {
+		return infos, false
+	}
+	if provider != "" {
+		if infos = rm.regionMap[accountKey("", accountID)][region]; len(infos) > 0 {
+			return infos, true
+		}
+	}
+	return nil, false
+}
+
+func (rm *SmartRegionManager) skippable(info RegionInfo) bool {
+	return info.Status == RegionStatusEmpty && info.EmptyCount >= rm.config.EmptyThreshold
+}
+
+// regionStateLocked 汇总区域内 namespaces（为空时取已记录的命名空间）的状态：
+// 任一命名空间活跃则 active；全部已记录且可跳过则 skip
+func (rm *SmartRegionManager) regionStateLocked(provider, accountID, region string, namespaces []string) (active, skip bool) {
+	infos, legacy := rm.namespaceInfosLocked(provider, accountID, region)
+	if len(infos) == 0 {
+		return false, false
+	}
+	lookup := func(ns string) (RegionInfo, bool) {
+		if legacy {
+			ns = ""
+		}
+		info, ok := infos[ns]
+		return info, ok
+	}
+	if len(namespaces) == 0 {
+		for ns := range infos {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	skip = true
+	for _, ns := range namespaces {
+		info, ok := lookup(ns)
+		if !ok {
+			skip = false
+			continue
+		}
+		if info.Status == RegionStatusActive {
+			active = true
+		}
+		if !rm.skippable(info) {
+			skip = false
+		}
+	}
+	return active, skip
+}
+
 // GetActiveRegions 获取活跃区域列表
-func (rm *SmartRegionManager) GetActiveRegions(accountID string, allRegions []string) []string {
+func (rm *SmartRegionManager) GetActiveRegions(provider, accountID string, allRegions, namespaces []string) []string {
 	rm.mu.RLock()
 	defer rm.mu.RUnlock()
 
@@ -171,13 +246,12 @@ func (rm *SmartRegionManager) GetActiveRegions(accountID string, allRegions []st
 
 	// 内存保护
 	if rm.config.MaxAccounts > 0 && len(rm.regionMap) >= rm.config.MaxAccounts {
-		ctxLog := logger.NewContextLogger("RegionManager", "resource_type", "RegionSelection", "account_id", accountID)
+		ctxLog := logger.NewContextLogger("RegionManager", "resource_type", "RegionSelection", "cloud_provider", provider, "account_id", accountID)
 		ctxLog.Warnf("账号数达上限 %d，跳过智能区域选择", rm.config.MaxAccounts)
 		return allRegions
 	}
 
-	accountRegions, ok := rm.regionMap[accountID]
-	if !ok {
+	if rm.regionMap[accountKey(provider, accountID)] == nil && rm.regionMap[accountKey("", accountID)] == nil {
 		return allRegions
 	}
 
@@ -187,55 +261,58 @@ func (rm *SmartRegionManager) GetActiveRegions(accountID string, allRegions []st
 	skippedCount := 0
 
 	for _, region := range allRegions {
-		info, exists := accountRegions[region]
-		if !exists {
-			unknownRegions = append(unknownRegions, region)
-			continue
-		}
-
-		switch info.Status {
-		case RegionStatusActive:
+		active, skip := rm.regionStateLocked(provider, accountID, region, namespaces)
+		switch {
+		case skip:
+			skippedCount++
+		case active:
 			activeRegions = append(activeRegions, region)
-		case RegionStatusUnknown:
+		default:
 			unknownRegions = append(unknownRegions, region)
-		case RegionStatusEmpty:
-			if info.EmptyCount < rm.config.EmptyThreshold {
-				unknownRegions = append(unknownRegions, region)
-			} else {
-				skippedCount++
-			}
 		}
 	}
 
 	result := append(activeRegions, unknownRegions...)
 
+	ctxLog := logger.NewContextLogger("RegionManager", "resource_type", "RegionSelection", "cloud_provider", provider, "account_id", accountID)
 	if len(result) == 0 {
-		ctxLog := logger.NewContextLogger("RegionManager", "resource_type", "RegionSelection", "account_id", accountID)
 		ctxLog.Warnf("无可用区域，返回全部")
 		return allRegions
 	}
 
-	ctxLog := logger.NewContextLogger("RegionManager", "resource_type", "RegionSelection", "account_id", accountID)
 	ctxLog.Infof("智能区域选择 总=%d 活跃=%d 未知=%d 跳过=%d",
 		len(allRegions), len(activeRegions), len(unknownRegions), skippedCount)
+	if skippedCount > 0 {
+		metrics.RegionSkippedTotal.WithLabelValues(provider).Add(float64(skippedCount))
+	}
 
 	return result
 }
 
-// UpdateRegionStatus 更新区域状态
-func (rm *SmartRegionManager) UpdateRegionStatus(accountID, region string, resourceCount int, status RegionStatus) {
+// UpdateRegionStatus 更新命名空间在区域内的状态；该区域迁移自旧版文件的账号级记录随之删除
+func (rm *SmartRegionManager) UpdateRegionStatus(provider, accountID, region, namespace string, resourceCount int, status RegionStatus) {
 	rm.mu.Lock()
 	defer rm.mu.Unlock()
 
 	atomic.AddInt64(&rm.stats.UpdateCount, 1)
 
 	now := time.Now()
+	key := accountKey(provider, accountID)
 
-	if rm.regionMap[accountID] == nil {
-		rm.regionMap[accountID] = make(map[string]RegionInfo)
+	if rm.regionMap[key] == nil {
+		rm.regionMap[key] = make(map[string]map[string]RegionInfo)
+	}
+	if rm.regionMap[key][region] == nil {
+		rm.regionMap[key][region] = make(map[string]RegionInfo)
+	}
+	if legacy := rm.regionMap[accountKey("", accountID)]; provider != "" && legacy != nil {
+		delete(legacy, region)
+		if len(legacy) == 0 {
+			delete(rm.regionMap, accountKey("", accountID))
+		}
 	}
 
-	info, exists := rm.regionMap[accountID][region]
+	info, exists := rm.regionMap[key][region][namespace]
 	if !exists {
 		info = RegionInfo{
 			LastSeen: now,
@@ -260,76 +337,190 @@ func (rm *SmartRegionManager) UpdateRegionStatus(accountID, region string, resou
 		info.Priority = 50 // 未知区域中等优先级
 	}
 
-	// 限制每账号区域数量
-	if len(rm.regionMap[accountID]) >= rm.config.MaxRegionsPerAccount {
-		// 清理最旧的低优先级区域
+	// 限制每账号条目（区域 × 命名空间）数量
+	if countEntries(rm.regionMap[key]) >= rm.config.MaxRegionsPerAccount {
+		// 清理最旧的低优先级条目
 		// 注意：此方法在持锁状态下调用，不会导致锁重入
-		rm.evictLowPriorityRegionsLocked(accountID)
+		rm.evictLowPriorityRegionsLocked(key)
 	}
 
-	rm.regionMap[accountID][region] = info
+	rm.regionMap[key][region][namespace] = info
 }
 
-// evictLowPriorityRegionsLocked 驱逐低优先级区域（必须在持锁状态下调用）
-func (rm *SmartRegionManager) evictLowPriorityRegionsLocked(accountID string) {
-	regions := rm.regionMap[accountID]
-	if len(regions) <= rm.config.MaxRegionsPerAccount {
+func countEntries(regions map[string]map[string]RegionInfo) int {
+	n := 0
+	for _, byNS := range regions {
+		n += len(byNS)
+	}
+	return n
+}
+
+// evictLowPriorityRegionsLocked 驱逐低优先级条目（必须在持锁状态下调用）
+func (rm *SmartRegionManager) evictLowPriorityRegionsLocked(key string) {
+	regions := rm.regionMap[key]
+	if countEntries(regions) <= rm.config.MaxRegionsPerAccount {
 		return
 	}
 
-	// 找出最旧的空区域
-	var oldestRegion string
+	// 找出最旧的可跳过条目
+	var oldestRegion, oldestNS string
 	var oldestTime time.Time
-	for region, info := range regions {
-		if info.Status == RegionStatusEmpty && info.EmptyCount >= rm.config.EmptyThreshold {
-			if oldestRegion == "" || info.LastSeen.Before(oldestTime) {
-				oldestRegion = region
-				oldestTime = info.LastSeen
+	found := false
+	for region, byNS := range regions {
+		for ns, info := range byNS {
+			if rm.skippable(info) && (!found || info.LastSeen.Before(oldestTime)) {
+				oldestRegion, oldestNS, oldestTime, found = region, ns, info.LastSeen, true
 			}
 		}
 	}
 
-	if oldestRegion != "" {
-		delete(regions, oldestRegion)
-		ctxLog := logger.NewContextLogger("RegionManager", "resource_type", "Eviction", "account_id", accountID, "region", oldestRegion)
+	if found {
+		delete(regions[oldestRegion], oldestNS)
+		if len(regions[oldestRegion]) == 0 {
+			delete(regions, oldestRegion)
+		}
+		ctxLog := logger.NewContextLogger("RegionManager", "resource_type", "Eviction", "account_id", key, "region", oldestRegion, "namespace", oldestNS)
 		ctxLog.Infof("驱逐旧区域")
 	}
 }
 
 // MarkRegionForRediscovery 标记区域为需重新发现
-func (rm *SmartRegionManager) MarkRegionForRediscovery(accountID, region string) {
+func (rm *SmartRegionManager) MarkRegionForRediscovery(provider, accountID, region string) int {
 	rm.mu.Lock()
 	defer rm.mu.Unlock()
 
-	if rm.regionMap[accountID] == nil {
-		return
+	infos, _ := rm.namespaceInfosLocked(provider, accountID, region)
+	if len(infos) == 0 {
+		return 0
 	}
+	markUnknown(infos)
+	ctxLog := logger.NewContextLogger("RegionManager", "resource_type", "Rediscovery", "cloud_provider", provider, "account_id", accountID, "region", region)
+	ctxLog.Infof("标记区域重新发现")
+	return len(infos)
+}
 
-	if info, ok := rm.regionMap[accountID][region]; ok {
+// MarkAccountForRediscovery 标记账号全部区域为需重新发现（含迁移自旧版文件的账号级记录）
+func (rm *SmartRegionManager) MarkAccountForRediscovery(provider, accountID string) int {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	marked := 0
+	for _, key := range []string{accountKey(provider, accountID), accountKey("", accountID)} {
+		for _, byNS := range rm.regionMap[key] {
+			marked += markUnknown(byNS)
+		}
+	}
+	if marked > 0 {
+		ctxLog := logger.NewContextLogger("RegionManager", "resource_type", "Rediscovery", "cloud_provider", provider, "account_id", accountID)
+		ctxLog.Infof("标记账号重新发现，条目数=%d", marked)
+	}
+	return marked
+}
+
+// markUnknown 将区域内全部命名空间重置为 unknown，返回条目数
+func markUnknown(infos map[string]RegionInfo) int {
+	for ns, info := range infos {
 		info.Status = RegionStatusUnknown
 		info.EmptyCount = 0
 		info.Priority = 50
-		rm.regionMap[accountID][region] = info
-		ctxLog := logger.NewContextLogger("RegionManager", "resource_type", "Rediscovery", "account_id", accountID, "region", region)
-		ctxLog.Infof("标记区域重新发现")
+		infos[ns] = info
 	}
+	return len(infos)
 }
 
-// GetRegionInfo 获取区域信息
-func (rm *SmartRegionManager) GetRegionInfo(accountID, region string) (RegionInfo, bool) {
+// splitAccountKey 拆分 accountKey 为 provider 与 accountID
+func splitAccountKey(key string) (provider, accountID string) {
+	if i := strings.IndexByte(key, '|'); i >= 0 {
+		return key[:i], key[i+1:]
+	}
+	return "", key
+}
+
+func matchAccountKey(key, provider, accountID string) bool {
+	p, acc := splitAccountKey(key)
+	return (provider == "" || p == provider) && (accountID == "" || acc == accountID)
+}
+
+// Reset 删除状态记录，下一轮采集时重新探测
+func (rm *SmartRegionManager) Reset(provider, accountID string) int {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	removed := 0
+	for key, regions := range rm.regionMap {
+		if !matchAccountKey(key, provider, accountID) {
+			continue
+		}
+		removed += countEntries(regions)
+		delete(rm.regionMap, key)
+	}
+	ctxLog := logger.NewContextLogger("RegionManager", "resource_type", "Reset", "cloud_provider", provider, "account_id", accountID)
+	ctxLog.Infof("重置区域状态，删除条目数=%d", removed)
+	return removed
+}
+
+// RegionEntry 单个 (provider, account, region, namespace) 的状态；迁移自旧版文件的记录 provider 与 namespace 为空
+type RegionEntry struct {
+	Provider  string `json:"provider"`
+	AccountID string `json:"account_id"`
+	Region    string `json:"region"`
+	Namespace string `json:"namespace"`
+	RegionInfo
+	Skipped bool `json:"skipped"` // 连续为空达到阈值，采集时跳过
+}
+
+// Entries 返回状态记录
+func (rm *SmartRegionManager) Entries(provider, accountID string) []RegionEntry {
 	rm.mu.RLock()
 	defer rm.mu.RUnlock()
 
-	if rm.regionMap[accountID] == nil {
-		return RegionInfo{}, false
+	var out []RegionEntry
+	for key, regions := range rm.regionMap {
+		if !matchAccountKey(key, provider, accountID) {
+			continue
+		}
+		p, acc := splitAccountKey(key)
+		for region, byNS := range regions {
+			for ns, info := range byNS {
+				out = append(out, RegionEntry{
+					Provider: p, AccountID: acc, Region: region, Namespace: ns,
+					RegionInfo: info,
+					Skipped:    rm.config.Enabled && rm.skippable(info),
+				})
+			}
+		}
 	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		if a.AccountID != b.AccountID {
+			return a.AccountID < b.AccountID
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		return a.Namespace < b.Namespace
+	})
+	return out
+}
 
-	info, ok := rm.regionMap[accountID][region]
+// GetRegionInfo 获取命名空间在区域内的状态
+func (rm *SmartRegionManager) GetRegionInfo(provider, accountID, region, namespace string) (RegionInfo, bool) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	infos, legacy := rm.namespaceInfosLocked(provider, accountID, region)
+	if legacy {
+		namespace = ""
+	}
+	info, ok := infos[namespace]
 	return info, ok
 }
 
-// ShouldSkipRegion 判断是否跳过该区域
-func (rm *SmartRegionManager) ShouldSkipRegion(accountID, region string) bool {
+// ShouldSkipRegion 判断是否跳过该区域的命名空间
+func (rm *SmartRegionManager) ShouldSkipRegion(provider, accountID, region, namespace string) bool {
 	rm.mu.RLock()
 	defer rm.mu.RUnlock()
 
@@ -337,16 +528,24 @@ func (rm *SmartRegionManager) ShouldSkipRegion(accountID, region string) bool {
 		return false
 	}
 
-	if rm.regionMap[accountID] == nil {
-		return false
+	var namespaces []string
+	if namespace != "" {
+		namespaces = []string{namespace}
 	}
+	_, skip := rm.regionStateLocked(provider, accountID, region, namespaces)
+	return skip
+}
 
-	info, ok := rm.regionMap[accountID][region]
-	if !ok {
-		return false
-	}
+// regionStatusVersion 当前持久化文件版本
+const regionStatusVersion = 2
 
-	return info.Status == RegionStatusEmpty && info.EmptyCount >= rm.config.EmptyThreshold
+// regionStatusFile 持久化文件格式。v1 只有 region_map（account -> region -> info），
+// v2 为 accounts（provider|account -> region -> namespace -> info）
+type regionStatusFile struct {
+	Version   int                                         `json:"version,omitempty"`
+	Accounts  map[string]map[string]map[string]RegionInfo `json:"accounts,omitempty"`
+	RegionMap map[string]map[string]RegionInfo            `json:"region_map,omitempty"`
+	UpdatedAt time.Time                                   `json:"updated_at"`
 }
 
 // Load 加载持久化状态
@@ -370,20 +569,30 @@ func (rm *SmartRegionManager) Load() error {
 		return nil
 	}
 
-	var persisted struct {
-		RegionMap map[string]map[string]RegionInfo `json:"region_map"`
-	}
-
+	var persisted regionStatusFile
 	if err := json.Unmarshal(data, &persisted); err != nil {
 		ctxLog := logger.NewContextLogger("RegionManager", "resource_type", "Persistence")
 		ctxLog.Errorf("解析区域状态失败: %v", err)
 		return err
 	}
 
-	if persisted.RegionMap != nil {
-		rm.regionMap = persisted.RegionMap
+	switch {
+	case persisted.Accounts != nil:
+		rm.regionMap = persisted.Accounts
 		ctxLog := logger.NewContextLogger("RegionManager", "resource_type", "Persistence")
 		ctxLog.Infof("成功加载区域状态，账号数=%d", len(rm.regionMap))
+	case persisted.RegionMap != nil:
+		// v1 文件不区分云与命名空间：迁移为账号级记录，各云首次上报该区域时替换
+		rm.regionMap = make(map[string]map[string]map[string]RegionInfo, len(persisted.RegionMap))
+		for accountID, regions := range persisted.RegionMap {
+			migrated := make(map[string]map[string]RegionInfo, len(regions))
+			for region, info := range regions {
+				migrated[region] = map[string]RegionInfo{"": info}
+			}
+			rm.regionMap[accountKey("", accountID)] = migrated
+		}
+		ctxLog := logger.NewContextLogger("RegionManager", "resource_type", "Persistence")
+		ctxLog.Infof("迁移旧版区域状态文件，账号数=%d，下次保存时写入 v%d 格式", len(persisted.RegionMap), regionStatusVersion)
 	}
 
 	rm.statsMu.Lock()
@@ -429,11 +638,9 @@ func (rm *SmartRegionManager) trySave() error {
 
 	persistPath := filepath.Join(rm.config.DataDir, rm.config.PersistFile)
 
-	data, err := json.MarshalIndent(struct {
-		RegionMap map[string]map[string]RegionInfo `json:"region_map"`
-		UpdatedAt time.Time                        `json:"updated_at"`
-	}{
-		RegionMap: snapshot,
+	data, err := json.MarshalIndent(regionStatusFile{
+		Version:   regionStatusVersion,
+		Accounts:  snapshot,
 		UpdatedAt: time.Now(),
 	}, "", "  ")
 
@@ -478,23 +685,26 @@ func (rm *SmartRegionManager) trySave() error {
 }
 
 // createSnapshot 创建快照（优化版本，限制大小防止性能问题）
-func (rm *SmartRegionManager) createSnapshot() map[string]map[string]RegionInfo {
+func (rm *SmartRegionManager) createSnapshot() map[string]map[string]map[string]RegionInfo {
 	const maxSnapshotSize = 5000 // 快照上限，防止 OOM 和性能问题
 
-	snapshot := make(map[string]map[string]RegionInfo, len(rm.regionMap))
+	snapshot := make(map[string]map[string]map[string]RegionInfo, len(rm.regionMap))
 	count := 0
 
-	for accountID, regions := range rm.regionMap {
+	for key, regions := range rm.regionMap {
 		if count >= maxSnapshotSize {
 			ctxLog := logger.NewContextLogger("RegionManager", "resource_type", "Snapshot")
 			ctxLog.Warnf("快照达到上限 %d，停止拷贝", maxSnapshotSize)
 			break
 		}
 
-		snapshot[accountID] = make(map[string]RegionInfo, len(regions))
-		for region, info := range regions {
-			snapshot[accountID][region] = info
-			count++
+		snapshot[key] = make(map[string]map[string]RegionInfo, len(regions))
+		for region, byNS := range regions {
+			snapshot[key][region] = make(map[string]RegionInfo, len(byNS))
+			for ns, info := range byNS {
+				snapshot[key][region][ns] = info
+				count++
+			}
 		}
 	}
 
@@ -560,6 +770,12 @@ func (rm *SmartRegionManager) performPeriodicTasks() {
 			ctxLog.Infof("定期清理完成，清理了 %d 个不活跃账号", cleaned)
 		}
 	}
+
+	// 3. 持久化，进程异常退出时最多丢失一个周期的状态
+	if err := rm.Save(); err != nil {
+		ctxLog := logger.NewContextLogger("RegionManager", "resource_type", "Persistence")
+		ctxLog.Warnf("定期保存区域状态失败: %v", err)
+	}
 }
 
 // Stop 停止所有后台任务（优化版本，避免阻塞）
@@ -598,21 +814,22 @@ func (rm *SmartRegionManager) triggerRediscovery() {
 
 	totalMarked := 0
 
-	for accountID, regions := range rm.regionMap {
-		for region, info := range regions {
-			if info.Status == RegionStatusActive || info.Status == RegionStatusEmpty {
-				info.Status = RegionStatusUnknown
-				info.EmptyCount = 0
-				info.Priority = 50
-				regions[region] = info
-				totalMarked++
+	for _, regions := range rm.regionMap {
+		for _, byNS := range regions {
+			for ns, info := range byNS {
+				if info.Status == RegionStatusActive || info.Status == RegionStatusEmpty {
+					info.Status = RegionStatusUnknown
+					info.EmptyCount = 0
+					info.Priority = 50
+					byNS[ns] = info
+					totalMarked++
+				}
 			}
 		}
-		rm.regionMap[accountID] = regions
 	}
 
 	ctxLog := logger.NewContextLogger("RegionManager", "resource_type", "Rediscovery")
-	ctxLog.Infof("区域重新发现完成，标记 %d 个区域为 unknown", totalMarked)
+	ctxLog.Infof("区域重新发现完成，标记 %d 个区域命名空间为 unknown", totalMarked)
 }
 
 // GetStats 获取统计信息（优化版本，实时计算所有统计数据）
@@ -625,19 +842,22 @@ func (rm *SmartRegionManager) GetStats() RegionManagerStats {
 	unknownCount := 0
 	skippedCount := 0 // 实时计算，不累积
 
+	// 按 (区域, 命名空间) 条目计数
 	for _, regions := range rm.regionMap {
-		for _, info := range regions {
-			switch info.Status {
-			case RegionStatusActive:
-				activeCount++
-			case RegionStatusEmpty:
-				emptyCount++
-				// 实时计算是否会被跳过
-				if info.EmptyCount >= rm.config.EmptyThreshold {
-					skippedCount++
+		for _, byNS := range regions {
+			for _, info := range byNS {
+				switch info.Status {
+				case RegionStatusActive:
+					activeCount++
+				case RegionStatusEmpty:
+					emptyCount++
+					// 实时计算是否会被跳过
+					if info.EmptyCount >= rm.config.EmptyThreshold {
+						skippedCount++
+					}
+				case RegionStatusUnknown:
+					unknownCount++
 				}
-			case RegionStatusUnknown:
-				unknownCount++
 			}
 		}
 	}
@@ -670,22 +890,24 @@ func (rm *SmartRegionManager) CleanupInactiveAccounts(olderThan time.Duration) i
 	now := time.Now()
 	toDelete := make([]string, 0)
 
-	for accountID, regions := range rm.regionMap {
+	for key, regions := range rm.regionMap {
 		hasRecentActivity := false
-		for _, info := range regions {
-			if now.Sub(info.LastSeen) < olderThan {
-				hasRecentActivity = true
-				break
+		for _, byNS := range regions {
+			for _, info := range byNS {
+				if now.Sub(info.LastSeen) < olderThan {
+					hasRecentActivity = true
+					break
+				}
 			}
 		}
 
 		if !hasRecentActivity && len(regions) > 0 {
-			toDelete = append(toDelete, accountID)
+			toDelete = append(toDelete, key)
 		}
 	}
 
-	for _, accountID := range toDelete {
-		delete(rm.regionMap, accountID)
+	for _, key := range toDelete {
+		delete(rm.regionMap, key)
 	}
 
 	rm.statsMu.Lock()
@@ -699,3 +921,117 @@ func (rm *SmartRegionManager) CleanupInactiveAccounts(olderThan time.Duration) i
 
 	return len(toDelete)
 }
+
+var (
+	sharedMu       sync.Mutex
+	sharedManagers = make(map[string]RegionManager)
+)
+
+// SharedRegionManager 返回按 server.region_discovery 创建的进程级区域管理器，conf 为 nil 时返回 nil。
+// 各云共用同一持久化文件（以 data_dir/persist_file 区分），首次调用时加载状态并启动调度器。
+func SharedRegionManager(conf *config.RegionDiscoveryConf) RegionManager {
+	if conf == nil {
+		return nil
+	}
+	rdc := RegionDiscoveryConfig{
+		Enabled:        conf.Enabled,
+		EmptyThreshold: conf.EmptyThreshold,
+		DataDir:        conf.DataDir,
+		PersistFile:    conf.PersistFile,
+	}
+	if d, err := time.ParseDuration(conf.DiscoveryInterval); err == nil {
+		rdc.DiscoveryInterval = d
+	}
+	rm := NewRegionManager(rdc).(*SmartRegionManager)
+	key := filepath.Join(rm.config.DataDir, rm.config.PersistFile)
+
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+	if existing, ok := sharedManagers[key]; ok {
+		return existing
+	}
+	if err := rm.Load(); err != nil {
+		ctxLog := logger.NewContextLogger("RegionManager", "resource_type", "Persistence")
+		ctxLog.Warnf("加载区域状态失败: %v", err)
+	}
+	rm.StartRediscoveryScheduler()
+	sharedManagers[key] = rm
+	return rm
+}
+
+// SaveRegionStatus 持久化全部共享区域管理器的状态，返回遇到的第一个错误
+func SaveRegionStatus() error {
+	sharedMu.Lock()
+	managers := make([]RegionManager, 0, len(sharedManagers))
+	for _, rm := range sharedManagers {
+		managers = append(managers, rm)
+	}
+	sharedMu.Unlock()
+	var first error
+	for _, rm := range managers {
+		if err := rm.Save(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// ReportRegionResources 上报一次资源枚举结果：count > 0 记为 active，否则记为 empty。rm 为 nil 时忽略
+func ReportRegionResources(rm RegionManager, provider, accountID, region, namespace string, count int) {
+	if rm == nil {
+		return
+	}
+	status := RegionStatusEmpty
+	if count > 0 {
+		status = RegionStatusActive
+	}
+	rm.UpdateRegionStatus(provider, accountID, region, namespace, count, status)
+	ctxLog := logger.NewContextLogger("RegionManager", "cloud_provider", provider, "account_id", accountID, "region", region, "namespace", namespace)
+	ctxLog.Debugf("更新区域状态 status=%s count=%d", status, count)
+}
+
+// SkipEmptyRegion 判断命名空间是否因在该区域连续无资源而跳过本轮采集。rm 为 nil 时不跳过
+func SkipEmptyRegion(rm RegionManager, provider, accountID, region, namespace string) bool {
+	if rm == nil || !rm.ShouldSkipRegion(provider, accountID, region, namespace) {
+		return false
+	}
+	ctxLog := logger.NewContextLogger("RegionManager", "cloud_provider", provider, "account_id", accountID, "region", region, "namespace", namespace)
+	ctxLog.Debugf("产品跳过（区域连续无资源）")
+	metrics.RegionSkippedTotal.WithLabelValues(provider).Inc()
+	return true
+}
+
+// PublishRegionMetrics 按共享区域管理器的当前状态重建 multicloud_region_info 与
+// multicloud_region_status_total，已删除的记录不再导出
+func PublishRegionMetrics() {
+	sharedMu.Lock()
+	managers := make([]RegionManager, 0, len(sharedManagers))
+	for _, rm := range sharedManagers {
+		managers = append(managers, rm)
+	}
+	sharedMu.Unlock()
+
+	metrics.RegionInfo.Reset()
+	metrics.RegionDiscoveryStatus.Reset()
+	counts := make(map[[2]string]int)
+	for _, rm := range managers {
+		for _, e := range rm.Entries("", "") {
+			metrics.RegionInfo.WithLabelValues(e.Provider, e.AccountID, e.Region, e.Namespace, string(e.Status), strconv.Itoa(e.EmptyCount)).Set(1)
+			counts[[2]string{e.Provider, string(e.Status)}]++
+		}
+	}
+	for k, n := range counts {
+		metrics.RegionDiscoveryStatus.WithLabelValues(k[0], k[1]).Set(float64(n))
+	}
+}
+
+// ProductNamespaces 返回产品配置中的命名空间，用作 GetActiveRegions 的 namespaces 参数
+func ProductNamespaces(prods []config.Product) []string {
+	out := make([]string, 0, len(prods))
+	for _, p := range prods {
+		if p.Namespace != "" {
+			out = append(out, p.Namespace)
+		}
+	}
+	return out
+}
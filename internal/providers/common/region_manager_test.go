@@ -0,0 +1,186 @@
+package common
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"multicloud-exporter/internal/config"
+)
+
+func newTestRegionManager(dir string) *SmartRegionManager {
+	return NewRegionManager(RegionDiscoveryConfig{
+		Enabled:        true,
+		EmptyThreshold: 2,
+		DataDir:        dir,
+		PersistFile:    "region_status.json",
+	}).(*SmartRegionManager)
+}
+
+func TestRegionManager_SkipsEmptyNamespacePerRegion(t *testing.T) {
+	rm := newTestRegionManager(t.TempDir())
+	all := []string{"cn-hangzhou", "cn-beijing", "cn-shanghai"}
+	for i := 0; i < 2; i++ {
+		rm.UpdateRegionStatus("aliyun", "acc", "cn-hangzhou", NamespaceAliyunSLBDashboard, 0, RegionStatusEmpty)
+		rm.UpdateRegionStatus("aliyun", "acc", "cn-hangzhou", NamespaceAliyunOSSDashboard, 3, RegionStatusActive)
+		rm.UpdateRegionStatus("aliyun", "acc", "cn-beijing", NamespaceAliyunSLBDashboard, 0, RegionStatusEmpty)
+		rm.UpdateRegionStatus("aliyun", "acc", "cn-beijing", NamespaceAliyunOSSDashboard, 0, RegionStatusEmpty)
+	}
+
+	// 只有 OSS 的区域：SLB 跳过，OSS 照常采集
+	if !rm.ShouldSkipRegion("aliyun", "acc", "cn-hangzhou", NamespaceAliyunSLBDashboard) {
+		t.Fatal("empty SLB namespace should be skipped")
+	}
+	if rm.ShouldSkipRegion("aliyun", "acc", "cn-hangzhou", NamespaceAliyunOSSDashboard) {
+		t.Fatal("active OSS namespace should not be skipped")
+	}
+	if rm.ShouldSkipRegion("aliyun", "acc", "cn-hangzhou", "") {
+		t.Fatal("region with an active namespace should not be skipped")
+	}
+	// 其它云同名账号互不影响
+	if rm.ShouldSkipRegion("tencent", "acc", "cn-beijing", NamespaceAliyunSLBDashboard) {
+		t.Fatal("status must be tracked per provider")
+	}
+
+	got := rm.GetActiveRegions("aliyun", "acc", all, []string{NamespaceAliyunSLBDashboard, NamespaceAliyunOSSDashboard})
+	if want := []string{"cn-hangzhou", "cn-shanghai"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("active regions = %v, want %v", got, want)
+	}
+	// 新增的命名空间在各区域都未记录，不能因其它命名空间为空而跳过区域
+	got = rm.GetActiveRegions("aliyun", "acc", all, []string{NamespaceAliyunSLBDashboard, NamespaceAliyunALB})
+	if len(got) != 3 {
+		t.Fatalf("unrecorded namespace should keep all regions, got %v", got)
+	}
+
+	rm.MarkRegionForRediscovery("aliyun", "acc", "cn-beijing")
+	if info, ok := rm.GetRegionInfo("aliyun", "acc", "cn-beijing", NamespaceAliyunOSSDashboard); !ok || info.Status != RegionStatusUnknown || info.EmptyCount != 0 {
+		t.Fatalf("rediscovery info = %+v %v", info, ok)
+	}
+	if st := rm.GetStats(); st.TotalAccounts != 1 || st.TotalRegions != 4 || st.ActiveRegions != 1 || st.SkippedRegions != 1 {
+		t.Fatalf("stats = %+v", st)
+	}
+}
+
+func TestRegionManager_MigratesLegacyFile(t *testing.T) {
+	dir := t.TempDir()
+	legacy := map[string]interface{}{
+		"region_map": map[string]map[string]RegionInfo{
+			"acc": {
+				"cn-beijing":  {Status: RegionStatusEmpty, EmptyCount: 5},
+				"cn-hangzhou": {Status: RegionStatusActive, ResourceCount: 2},
+			},
+		},
+	}
+	data, _ := json.Marshal(legacy)
+	if err := os.WriteFile(filepath.Join(dir, "region_status.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rm := newTestRegionManager(dir)
+	if err := rm.Load(); err != nil {
+		t.Fatal(err)
+	}
+	// 旧记录不区分云与命名空间，在各云首次上报前作为账号级状态使用
+	if !rm.ShouldSkipRegion("aliyun", "acc", "cn-beijing", NamespaceAliyunSLBDashboard) {
+		t.Fatal("legacy empty region should be skipped")
+	}
+	if info, ok := rm.GetRegionInfo("tencent", "acc", "cn-hangzhou", NamespaceTencentLB); !ok || info.ResourceCount != 2 {
+		t.Fatalf("legacy info = %+v %v", info, ok)
+	}
+	rm.UpdateRegionStatus("aliyun", "acc", "cn-beijing", NamespaceAliyunOSSDashboard, 1, RegionStatusActive)
+	if rm.ShouldSkipRegion("tencent", "acc", "cn-beijing", NamespaceTencentLB) {
+		t.Fatal("legacy record should be replaced once a provider reports the region")
+	}
+	if err := rm.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	var saved regionStatusFile
+	data, _ = os.ReadFile(filepath.Join(dir, "region_status.json"))
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved.Version != regionStatusVersion || saved.RegionMap != nil {
+		t.Fatalf("saved file not migrated: version=%d region_map=%v", saved.Version, saved.RegionMap)
+	}
+	if _, ok := saved.Accounts["aliyun|acc"]["cn-beijing"][NamespaceAliyunOSSDashboard]; !ok {
+		t.Fatalf("accounts = %+v", saved.Accounts)
+	}
+	if _, ok := saved.Accounts["|acc"]["cn-hangzhou"][""]; !ok {
+		t.Fatalf("untouched legacy region should be kept: %+v", saved.Accounts)
+	}
+
+	reloaded := newTestRegionManager(dir)
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if info, ok := reloaded.GetRegionInfo("aliyun", "acc", "cn-beijing", NamespaceAliyunOSSDashboard); !ok || info.Status != RegionStatusActive {
+		t.Fatalf("reloaded info = %+v %v", info, ok)
+	}
+}
+
+func TestSharedRegionManager_OneFileForAllProviders(t *testing.T) {
+	if SharedRegionManager(nil) != nil {
+		t.Fatal("nil config should disable the region manager")
+	}
+	dir := t.TempDir()
+	conf := &config.RegionDiscoveryConf{Enabled: true, DataDir: dir, PersistFile: "region_status.json"}
+	a := SharedRegionManager(conf)
+	b := SharedRegionManager(&config.RegionDiscoveryConf{Enabled: true, DataDir: dir, PersistFile: "region_status.json"})
+	t.Cleanup(func() {
+		sharedMu.Lock()
+		delete(sharedManagers, filepath.Join(dir, "region_status.json"))
+		sharedMu.Unlock()
+		a.Stop()
+	})
+	if a != b {
+		t.Fatal("providers should share one region manager per persist file")
+	}
+
+	ReportRegionResources(a, "aliyun", "acc", "cn-hangzhou", NamespaceAliyunOSSDashboard, 2)
+	ReportRegionResources(b, "huawei", "acc", "cn-north-4", NamespaceHuaweiELB, 0)
+	ReportRegionResources(nil, "aws", "acc", "us-east-1", NamespaceAWSELB, 1)
+	if err := SaveRegionStatus(); err != nil {
+		t.Fatal(err)
+	}
+	var saved regionStatusFile
+	data, err := os.ReadFile(filepath.Join(dir, "region_status.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Accounts) != 2 || saved.Accounts["huawei|acc"]["cn-north-4"][NamespaceHuaweiELB].Status != RegionStatusEmpty {
+		t.Fatalf("accounts = %+v", saved.Accounts)
+	}
+}
+
+func TestRegionManager_AdminOperations(t *testing.T) {
+	rm := newTestRegionManager(t.TempDir())
+	for i := 0; i < 2; i++ {
+		rm.UpdateRegionStatus("aliyun", "acc", "cn-hangzhou", NamespaceAliyunSLBDashboard, 0, RegionStatusEmpty)
+		rm.UpdateRegionStatus("aliyun", "acc", "cn-beijing", NamespaceAliyunSLBDashboard, 0, RegionStatusEmpty)
+		rm.UpdateRegionStatus("aliyun", "other", "cn-beijing", NamespaceAliyunSLBDashboard, 0, RegionStatusEmpty)
+		rm.UpdateRegionStatus("tencent", "acc", "ap-guangzhou", NamespaceTencentLB, 0, RegionStatusEmpty)
+	}
+
+	entries := rm.Entries("aliyun", "acc")
+	if len(entries) != 2 || entries[0].Region != "cn-beijing" || !entries[0].Skipped || entries[0].EmptyCount != 2 {
+		t.Fatalf("entries = %+v", entries)
+	}
+	if n := rm.MarkAccountForRediscovery("aliyun", "acc"); n != 2 {
+		t.Fatalf("marked = %d", n)
+	}
+	if rm.ShouldSkipRegion("aliyun", "acc", "cn-hangzhou", "") || !rm.ShouldSkipRegion("aliyun", "other", "cn-beijing", "") {
+		t.Fatal("rediscovery should only reset the given account")
+	}
+	if n := rm.Reset("", "acc"); n != 3 {
+		t.Fatalf("reset = %d", n)
+	}
+	if entries := rm.Entries("", ""); len(entries) != 1 || entries[0].AccountID != "other" {
+		t.Fatalf("entries after reset = %+v", entries)
+	}
+}
@@ -0,0 +1,148 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TargetResult 单个采集目标（区域/命名空间）的本轮结果
+type TargetResult struct {
+	Region    string         `json:"region"`
+	Namespace string         `json:"namespace"`
+	Success   bool           `json:"success"`
+	Samples   int            `json:"samples"`
+	Errors    map[string]int `json:"errors,omitempty"`
+	Duration  time.Duration  `json:"duration"`
+}
+
+// CollectResult 一次账号采集的结构化结果
+type CollectResult struct {
+	Provider  string         `json:"provider"`
+	AccountID string         `json:"account_id"`
+	Targets   []TargetResult `json:"targets,omitempty"`
+	// Samples 本轮导出的样本总数
+	Samples int `json:"samples"`
+	// Errors 本轮记录的错误状态计数（含未归属到目标的账号级错误，如区域发现失败）
+	Errors map[string]int `json:"errors,omitempty"`
+	// Err 致命错误：采集被取消或超时、panic 等
+	Err      error         `json:"-"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Failure 返回账号本轮采集失败的原因：致命错误或账号级失败（如认证失败），成功时返回 nil
+func (r CollectResult) Failure() error {
+	if r.Err != nil {
+		return r.Err
+	}
+	statuses := make([]string, 0, len(r.Errors))
+	for status := range r.Errors {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	for _, status := range statuses {
+		if IsAccountFailure(status) {
+			return fmt.Errorf("%s", status)
+		}
+	}
+	return nil
+}
+
+// FailedTargets 返回本轮失败的目标数
+func (r CollectResult) FailedTargets() int {
+	n := 0
+	for _, t := range r.Targets {
+		if !t.Success {
+			n++
+		}
+	}
+	return n
+}
+
+// ResultRecorder 账号采集结果记录器，随 context 传递，目标采集结束时写入目标结果
+type ResultRecorder struct {
+	provider  string
+	accountID string
+	start     time.Time
+
+	mu      sync.Mutex
+	targets []TargetResult
+}
+
+type recorderKey struct{}
+
+// BeginCollect 开始一次账号采集：清空账号遗留的错误记录，并将结果记录器绑定到返回的 context
+func BeginCollect(ctx context.Context, provider, accountID string) (context.Context, *ResultRecorder) {
+	TakeAccountErrors(provider, accountID)
+	r := &ResultRecorder{provider: provider, accountID: accountID, start: time.Now()}
+	return context.WithValue(ctx, recorderKey{}, r), r
+}
+
+// recorderFromContext 获取 context 上绑定的结果记录器
+func recorderFromContext(ctx context.Context) *ResultRecorder {
+	if ctx == nil {
+		return nil
+	}
+	r, _ := ctx.Value(recorderKey{}).(*ResultRecorder)
+	return r
+}
+
+// AccountIDFromContext 返回 ctx 上绑定的账号 ID（BeginCollect），未绑定时返回空串
+func AccountIDFromContext(ctx context.Context) string {
+	if r := recorderFromContext(ctx); r != nil {
+		return r.accountID
+	}
+	return ""
+}
+
+func (r *ResultRecorder) add(t TargetResult) {
+	r.mu.Lock()
+	r.targets = append(r.targets, t)
+	r.mu.Unlock()
+}
+
+// Finish 结束账号采集并生成结果；ctx 已取消或超时时结果携带 ctx.Err()
+func (r *ResultRecorder) Finish(ctx context.Context) CollectResult {
+	r.mu.Lock()
+	targets := append([]TargetResult(nil), r.targets...)
+	r.mu.Unlock()
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].Region != targets[j].Region {
+			return targets[i].Region < targets[j].Region
+		}
+		return targets[i].Namespace < targets[j].Namespace
+	})
+
+	res := CollectResult{
+		Provider:  r.provider,
+		AccountID: r.accountID,
+		Targets:   targets,
+		Errors:    TakeAccountErrors(r.provider, r.accountID),
+		Duration:  time.Since(r.start),
+	}
+	for _, t := range targets {
+		res.Samples += t.Samples
+	}
+	if ctx != nil {
+		res.Err = ctx.Err()
+	}
+	return res
+}
+
+// SleepContext 等待 d 或 ctx 结束，ctx 结束时返回 ctx.Err()；用于重试退避，保证取消能及时生效
+func SleepContext(ctx context.Context, d time.Duration) error {
+	if ctx == nil {
+		time.Sleep(d)
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
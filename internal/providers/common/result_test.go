@@ -0,0 +1,78 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestResultRecorder_AggregatesTargets(t *testing.T) {
+	RecordAccountError("mock", "acc-res", ErrorStatusNetwork) // 上一轮遗留，BeginCollect 应清空
+	ctx, rec := BeginCollect(context.Background(), "mock", "acc-res")
+
+	a := StartTarget(ctx, "mock", "acc-res", "r2", "ns")
+	RecordTargetSamples("mock", "acc-res", "r2", "ns", 2)
+	RecordTargetError("mock", "acc-res", "r2", "ns", ErrorStatusRegion)
+	a.Finish()
+	b := StartTarget(ctx, "mock", "acc-res", "r1", "ns")
+	RecordTargetSamples("mock", "acc-res", "r1", "ns", 5)
+	RecordTargetError("mock", "acc-res", "r1", "ns", ErrorStatusAuth)
+	b.Finish()
+
+	res := rec.Finish(ctx)
+	if res.Provider != "mock" || res.AccountID != "acc-res" {
+		t.Fatalf("unexpected identity: %+v", res)
+	}
+	if len(res.Targets) != 2 || res.Targets[0].Region != "r1" || res.Targets[1].Region != "r2" {
+		t.Fatalf("targets should be sorted by region: %+v", res.Targets)
+	}
+	if res.Targets[0].Success || !res.Targets[1].Success {
+		t.Fatalf("unexpected target success: %+v", res.Targets)
+	}
+	if res.Samples != 7 || res.FailedTargets() != 1 {
+		t.Fatalf("samples=%d failed=%d", res.Samples, res.FailedTargets())
+	}
+	if res.Errors[ErrorStatusNetwork] != 0 || res.Errors[ErrorStatusAuth] != 1 {
+		t.Fatalf("unexpected errors: %v", res.Errors)
+	}
+	if err := res.Failure(); err == nil || err.Error() != ErrorStatusAuth {
+		t.Fatalf("auth error should fail the account, got %v", err)
+	}
+}
+
+func TestResultRecorder_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx, rec := BeginCollect(ctx, "mock", "acc-cancel")
+	r := StartTarget(ctx, "mock", "acc-cancel", "r1", "ns")
+	cancel()
+	RecordTargetError("mock", "acc-cancel", "r1", "ns", ClassifyAWSError(ctx.Err()))
+	if r.Finish() {
+		t.Fatalf("canceled target should not succeed")
+	}
+	res := rec.Finish(ctx)
+	if !errors.Is(res.Failure(), context.Canceled) {
+		t.Fatalf("expected canceled failure, got %v", res.Failure())
+	}
+	if len(res.Errors) != 0 {
+		t.Fatalf("canceled errors should not count as account errors: %v", res.Errors)
+	}
+	if res.Targets[0].Errors[ErrorStatusCanceled] != 1 {
+		t.Fatalf("target should record canceled status: %+v", res.Targets[0])
+	}
+}
+
+func TestSleepContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	if err := SleepContext(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected canceled, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("SleepContext should return immediately after cancel")
+	}
+	if err := SleepContext(context.Background(), time.Millisecond); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
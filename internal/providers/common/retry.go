@@ -51,18 +51,7 @@ func DefaultRetryConfig() RetryConfig {
 //	    return someAPI()
 //	}, shouldRetry)
 func RetryWithBackoff(ctx context.Context, cfg RetryConfig, fn func() error, shouldRetry func(error) bool) error {
-	if cfg.MaxAttempts <= 0 {
-		cfg.MaxAttempts = 5
-	}
-	if cfg.InitialDelay <= 0 {
-		cfg.InitialDelay = 200 * time.Millisecond
-	}
-	if cfg.MaxDelay <= 0 {
-		cfg.MaxDelay = 5 * time.Second
-	}
-	if cfg.BackoffFactor <= 0 {
-		cfg.BackoffFactor = 2.0
-	}
+	cfg = cfg.withDefaults()
 
 	var lastErr error
 	for attempt := 0; attempt <= cfg.MaxAttempts; attempt++ {
@@ -92,27 +81,42 @@ func RetryWithBackoff(ctx context.Context, cfg RetryConfig, fn func() error, sho
 			break
 		}
 
-		// 计算延迟时间（指数退避）
-		delay := time.Duration(float64(cfg.InitialDelay) * pow(cfg.BackoffFactor, float64(attempt)))
-		if delay > cfg.MaxDelay {
-			delay = cfg.MaxDelay
-		}
-
-		// 等待延迟时间
-		if ctx != nil {
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-time.After(delay):
-			}
-		} else {
-			time.Sleep(delay)
+		// 等待退避时间
+		if err := SleepContext(ctx, cfg.delay(attempt)); err != nil {
+			return err
 		}
 	}
 
 	return lastErr
 }
 
+// withDefaults 将无效的配置值替换为默认值
+func (cfg RetryConfig) withDefaults() RetryConfig {
+	def := DefaultRetryConfig()
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = def.MaxAttempts
+	}
+	if cfg.InitialDelay <= 0 {
+		cfg.InitialDelay = def.InitialDelay
+	}
+	if cfg.MaxDelay <= 0 {
+		cfg.MaxDelay = def.MaxDelay
+	}
+	if cfg.BackoffFactor <= 0 {
+		cfg.BackoffFactor = def.BackoffFactor
+	}
+	return cfg
+}
+
+// delay 计算第 attempt 次（从 0 开始）失败后的退避时间（指数退避，不超过 MaxDelay）
+func (cfg RetryConfig) delay(attempt int) time.Duration {
+	d := time.Duration(float64(cfg.InitialDelay) * pow(cfg.BackoffFactor, float64(attempt)))
+	if d > cfg.MaxDelay || d <= 0 {
+		d = cfg.MaxDelay
+	}
+	return d
+}
+
 // pow 计算 x 的 y 次方（简单实现，避免引入 math 包）
 func pow(x, y float64) float64 {
 	result := 1.0
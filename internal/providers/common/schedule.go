@@ -0,0 +1,203 @@
+// Package common 提供按产品独立的采集调度
+// 每个 (provider, account, region, namespace) 维护下一次到期时间，
+// 未到期的产品在本轮采集中直接跳过，已导出的指标值保持不变。
+package common
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"multicloud-exporter/internal/config"
+	"multicloud-exporter/internal/metrics"
+	"multicloud-exporter/internal/utils"
+)
+
+// ScheduleEntry 单个产品的调度状态
+type ScheduleEntry struct {
+	Key      string    `json:"key"`
+	Interval string    `json:"interval"`
+	LastRun  time.Time `json:"last_run"`
+	NextDue  time.Time `json:"next_due"`
+	Skipped  int64     `json:"skipped"`
+}
+
+type scheduleState struct {
+	interval time.Duration
+	lastRun  time.Time
+	nextDue  time.Time
+	skipped  int64
+}
+
+// ProductScheduler 产品级采集调度器（并发安全）
+type ProductScheduler struct {
+	mu     sync.Mutex
+	states map[string]*scheduleState
+	now    func() time.Time
+}
+
+// NewProductScheduler 创建产品级调度器
+func NewProductScheduler() *ProductScheduler {
+	return &ProductScheduler{
+		states: make(map[string]*scheduleState),
+		now:    time.Now,
+	}
+}
+
+// ScheduleProvider 由支持产品级调度的采集器实现，用于状态展示与重置
+type ScheduleProvider interface {
+	Scheduler() *ProductScheduler
+}
+
+// ScheduleKey 生成调度键，格式：provider|AccountID|Region|Namespace
+func ScheduleKey(provider, accountID, region, namespace string) string {
+	return provider + "|" + accountID + "|" + region + "|" + namespace
+}
+
+// Due 判断产品是否到期；到期时登记本次运行，下一次到期时间由 Done 按采集结果推进，
+// 未调用 Done 的产品下一轮仍视为到期。interval<=0 或调度器为 nil 时每轮都采集。
+// 为避免采集循环的调度抖动导致整轮错过，允许提前 interval/10 视为到期。
+func (s *ProductScheduler) Due(key string, interval time.Duration) bool {
+	if s == nil {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	st, ok := s.states[key]
+	if !ok {
+		st = &scheduleState{}
+		s.states[key] = st
+	}
+	st.interval = interval
+	if ok && interval > 0 && now.Before(st.nextDue.Add(-interval/10)) {
+		st.skipped++
+		return false
+	}
+	st.lastRun = now
+	return true
+}
+
+// Done 登记到期产品本轮的采集结果：成功时下一次到期时间推进一个周期；
+// 失败（认证、网络、熔断、采集被取消等）时不推进，下一轮（scrape_interval 后）重试
+func (s *ProductScheduler) Done(key string, ok bool) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, found := s.states[key]
+	if !found {
+		return
+	}
+	if ok {
+		st.nextDue = st.lastRun.Add(st.interval)
+	} else {
+		st.nextDue = st.lastRun
+	}
+}
+
+// Reset 清空全部调度状态，下一轮所有产品均视为到期
+func (s *ProductScheduler) Reset() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states = make(map[string]*scheduleState)
+}
+
+// Entries 返回按键排序的调度状态快照
+func (s *ProductScheduler) Entries() []ScheduleEntry {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]ScheduleEntry, 0, len(s.states))
+	for k, st := range s.states {
+		out = append(out, ScheduleEntry{
+			Key:      k,
+			Interval: st.interval.String(),
+			LastRun:  st.lastRun,
+			NextDue:  st.nextDue,
+			Skipped:  st.skipped,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// ResolveProductInterval 解析产品的采集周期
+// 优先级：server.scrape_schedules 显式配置 > 指标 Period（指标组 > 产品 > periodOf 回调）。
+// 产品周期取各指标周期的最小值，保证最快的指标不被拖慢；返回 0 表示每轮都采集。
+// periodOf 用于按需查询云端元数据中的最小周期（秒），可为 nil。
+func ResolveProductInterval(cfg *config.Config, provider string, prod config.Product, periodOf func(metric string) int) time.Duration {
+	if d, ok := explicitInterval(cfg, provider, prod.Namespace); ok {
+		return d
+	}
+	min := 0
+	for _, group := range prod.MetricInfo {
+		for _, m := range group.MetricList {
+			p := 0
+			switch {
+			case group.Period != nil:
+				p = *group.Period
+			case prod.Period != nil:
+				p = *prod.Period
+			case periodOf != nil:
+				p = periodOf(m)
+			}
+			if p <= 0 {
+				// 任一指标周期未知时按每轮采集处理
+				return 0
+			}
+			if min == 0 || p < min {
+				min = p
+			}
+		}
+	}
+	return time.Duration(min) * time.Second
+}
+
+// explicitInterval 查找显式配置的产品周期，键支持 "provider.namespace" 与 "namespace"
+func explicitInterval(cfg *config.Config, provider, namespace string) (time.Duration, bool) {
+	if cfg == nil {
+		return 0, false
+	}
+	server := cfg.GetServer()
+	if server == nil || len(server.ScrapeSchedules) == 0 {
+		return 0, false
+	}
+	for _, k := range []string{provider + "." + namespace, namespace} {
+		v, ok := server.ScrapeSchedules[k]
+		if !ok {
+			continue
+		}
+		d, err := utils.ParseDuration(strings.TrimSpace(v))
+		if err != nil {
+			return 0, false
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// ShouldScrape 判断产品在本轮是否需要采集，未到期时记录跳过指标并沿用上一轮的样本计数；
+// 账号当日预算用尽时按降级周期判断
+func (s *ProductScheduler) ShouldScrape(provider, accountID, region, namespace string, interval time.Duration) bool {
+	interval = DegradeInterval(provider, accountID, interval)
+	key := ScheduleKey(provider, accountID, region, namespace)
+	if s.Due(key, interval) {
+		return true
+	}
+	metrics.ScheduleSkippedTotal.WithLabelValues(provider, namespace).Inc()
+	metrics.KeepSampleCount(key)
+	return false
+}
+
+// Finished 登记产品本轮采集结果，ok 通常为 TargetRun.Finish 的返回值
+func (s *ProductScheduler) Finished(provider, accountID, region, namespace string, ok bool) {
+	s.Done(ScheduleKey(provider, accountID, region, namespace), ok)
+}
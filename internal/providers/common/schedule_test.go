@@ -0,0 +1,145 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"multicloud-exporter/internal/config"
+	"multicloud-exporter/internal/metrics"
+)
+
+func TestProductScheduler_Due(t *testing.T) {
+	s := NewProductScheduler()
+	now := time.Unix(1700000000, 0)
+	s.now = func() time.Time { return now }
+	key := ScheduleKey("aliyun", "acc", "cn-hangzhou", "acs_oss_dashboard")
+
+	if !s.Due(key, time.Hour) {
+		t.Fatalf("first run should be due")
+	}
+	s.Done(key, true)
+	now = now.Add(time.Minute)
+	if s.Due(key, time.Hour) {
+		t.Fatalf("should not be due within interval")
+	}
+	// 提前 interval/10 内视为到期，吸收调度抖动
+	now = now.Add(54 * time.Minute)
+	if !s.Due(key, time.Hour) {
+		t.Fatalf("should be due within jitter tolerance")
+	}
+	entries := s.Entries()
+	if len(entries) != 1 || entries[0].Skipped != 1 || entries[0].Interval != "1h0m0s" {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestProductScheduler_FailedRunRetriesNextCycle(t *testing.T) {
+	s := NewProductScheduler()
+	now := time.Unix(1700000000, 0)
+	s.now = func() time.Time { return now }
+	key := ScheduleKey("aws", "acc", "global", "AWS/S3")
+
+	if !s.Due(key, 24*time.Hour) {
+		t.Fatalf("first run should be due")
+	}
+	s.Done(key, false)
+	now = now.Add(time.Minute)
+	if !s.Due(key, 24*time.Hour) {
+		t.Fatalf("failed run should be retried on the next cycle")
+	}
+	// 未登记结果（如采集前被取消）同样在下一轮重试
+	now = now.Add(time.Minute)
+	if !s.Due(key, 24*time.Hour) {
+		t.Fatalf("run without result should be retried on the next cycle")
+	}
+	s.Done(key, true)
+	now = now.Add(time.Minute)
+	if s.Due(key, 24*time.Hour) {
+		t.Fatalf("successful run should advance next due")
+	}
+	if e := s.Entries(); len(e) != 1 || !e[0].NextDue.Equal(now.Add(-time.Minute).Add(24*time.Hour)) {
+		t.Fatalf("unexpected entries: %+v", e)
+	}
+}
+
+func TestProductScheduler_SkippedKeepsSampleCount(t *testing.T) {
+	s := NewProductScheduler()
+	now := time.Unix(1700000000, 0)
+	s.now = func() time.Time { return now }
+	const ns = "acs_sched_samples"
+
+	metrics.ResetSampleCounts()
+	if !s.ShouldScrape("aliyun", "acc", "cn-hangzhou", ns, time.Hour) {
+		t.Fatalf("first run should be due")
+	}
+	RecordTargetSamples("aliyun", "acc", "cn-hangzhou", ns, 3)
+	s.Finished("aliyun", "acc", "cn-hangzhou", ns, true)
+	RecordTargetSamples("aliyun", "acc", "cn-shanghai", ns, 2)
+
+	// 下一轮 cn-hangzhou 未到期被跳过，沿用上一轮样本数；cn-shanghai 本轮无样本
+	now = now.Add(time.Minute)
+	metrics.ResetSampleCounts()
+	if s.ShouldScrape("aliyun", "acc", "cn-hangzhou", ns, time.Hour) {
+		t.Fatalf("should not be due within interval")
+	}
+	if got := metrics.GetSampleCounts()[ns]; got != 3 {
+		t.Fatalf("skipped target should keep its last sample count, got %d", got)
+	}
+
+	// 再跳过一轮仍保留
+	now = now.Add(time.Minute)
+	metrics.ResetSampleCounts()
+	s.ShouldScrape("aliyun", "acc", "cn-hangzhou", ns, time.Hour)
+	if got := metrics.GetSampleCounts()[ns]; got != 3 {
+		t.Fatalf("sample count should carry over consecutive skips, got %d", got)
+	}
+}
+
+func TestProductScheduler_ZeroIntervalAlwaysDue(t *testing.T) {
+	s := NewProductScheduler()
+	for i := 0; i < 3; i++ {
+		if !s.Due("k", 0) {
+			t.Fatalf("zero interval should always be due")
+		}
+	}
+	s.Reset()
+	if len(s.Entries()) != 0 {
+		t.Fatalf("reset should clear entries")
+	}
+}
+
+func TestResolveProductInterval(t *testing.T) {
+	p60, p86400 := 60, 86400
+	prod := config.Product{
+		Namespace: "acs_oss_dashboard",
+		MetricInfo: []config.MetricGroup{
+			{MetricList: []string{"UserStorage"}, Period: &p86400},
+			{MetricList: []string{"InternetSend"}, Period: &p60},
+		},
+	}
+	if got := ResolveProductInterval(nil, "aliyun", prod, nil); got != time.Minute {
+		t.Errorf("min group period = %v, want 1m", got)
+	}
+
+	// 未配置 Period 时使用回调
+	prod2 := config.Product{Namespace: "QCE/COS", MetricInfo: []config.MetricGroup{{MetricList: []string{"StdStorage"}}}}
+	if got := ResolveProductInterval(nil, "tencent", prod2, func(string) int { return 3600 }); got != time.Hour {
+		t.Errorf("periodOf = %v, want 1h", got)
+	}
+	if got := ResolveProductInterval(nil, "tencent", prod2, nil); got != 0 {
+		t.Errorf("unknown period = %v, want 0", got)
+	}
+
+	// 显式配置优先，provider.namespace 优先于 namespace
+	cfg := &config.Config{Server: &config.ServerConf{ScrapeSchedules: map[string]string{
+		"aliyun.acs_oss_dashboard": "1d",
+		"acs_oss_dashboard":        "6h",
+		"QCE/COS":                  "2h",
+	}}}
+	if got := ResolveProductInterval(cfg, "aliyun", prod, nil); got != 24*time.Hour {
+		t.Errorf("explicit provider.namespace = %v, want 24h", got)
+	}
+	if got := ResolveProductInterval(cfg, "tencent", prod2, nil); got != 2*time.Hour {
+		t.Errorf("explicit namespace = %v, want 2h", got)
+	}
+}
@@ -2,6 +2,7 @@
 package huawei
 
 import (
+	"context"
 	"time"
 
 	"multicloud-exporter/internal/config"
@@ -14,14 +15,18 @@ import (
 	elbmodel "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/elb/v3/model"
 )
 
+// elbCodeNameChain ELB 默认 code_name 解析链：CodeName 标签 > 负载均衡器名称 > ID
+const elbCodeNameChain = providerscommon.DefaultCodeNameChain + " -> name -> id"
+
 // elbInfo 负载均衡器信息
 type elbInfo struct {
 	ID   string
 	Name string
+	Tags map[string]string
 }
 
 // collectELB 采集 ELB 负载均衡资源
-func (h *Collector) collectELB(account config.CloudAccount, region string) {
+func (h *Collector) collectELB(ctx context.Context, account config.CloudAccount, region string) {
 	if h.cfg == nil {
 		return
 	}
@@ -48,22 +53,37 @@ func (h *Collector) collectELB(account config.CloudAccount, region string) {
 			ctxLog.Debugf("ELB 产品跳过（分片不匹配）")
 			continue
 		}
-		elbs := h.listELBInstances(account, region)
-		if len(elbs) == 0 {
+		interval := providerscommon.ResolveProductInterval(h.cfg, "huawei", p, func(string) int { return 300 })
+		if !h.scheduler.ShouldScrape("huawei", account.AccountID, region, p.Namespace, interval) {
+			ctxLog.Debugf("ELB 产品跳过（采集周期未到期，周期=%v）", interval)
+			continue
+		}
+		// 区域级跳过：该命名空间在本区域连续多轮枚举为空，等待重新发现
+		if providerscommon.SkipEmptyRegion(h.regionManager, "huawei", account.AccountID, region, p.Namespace) {
 			continue
 		}
-		h.fetchELBMonitor(account, region, p, elbs)
+		target := providerscommon.StartTarget(ctx, "huawei", account.AccountID, region, p.Namespace)
+		if elbs := h.listELBInstances(ctx, account, region); len(elbs) > 0 {
+			h.fetchELBMonitor(ctx, account, region, p, elbs)
+		}
+		h.scheduler.Finished("huawei", account.AccountID, region, p.Namespace, target.Finish())
 	}
 }
 
 // listELBInstances 枚举 ELB 实例
-func (h *Collector) listELBInstances(account config.CloudAccount, region string) []elbInfo {
+func (h *Collector) listELBInstances(ctx context.Context, account config.CloudAccount, region string) []elbInfo {
 	ctxLog := logger.NewContextLogger("Huawei", "account_id", account.AccountID, "region", region, "rtype", "elb")
 
 	if ids, hit := h.getCachedIDs(account, region, "SYS.ELB", "elb"); hit {
 		var elbs []elbInfo
 		for _, id := range ids {
-			elbs = append(elbs, elbInfo{ID: id, Name: id})
+			// 名称与标签取最近一次枚举时记录的结果
+			info := h.resourceInfo(account, region, "elb", id)
+			name := info.Name
+			if name == "" {
+				name = id
+			}
+			elbs = append(elbs, elbInfo{ID: id, Name: name, Tags: info.Tags})
 		}
 		ctxLog.Debugf("ELB 缓存命中，数量=%d", len(ids))
 		return elbs
@@ -87,36 +107,20 @@ func (h *Collector) listELBInstances(account config.CloudAccount, region string)
 			Marker: marker,
 		}
 
-		start := time.Now()
-		var resp *elbmodel.ListLoadBalancersResponse
-		var callErr error
-		for attempt := 0; attempt < 3; attempt++ {
-			resp, callErr = client.ListLoadBalancers(req)
-			if callErr == nil {
-				metrics.RequestTotal.WithLabelValues("huawei", "ListLoadBalancers", "success").Inc()
-				metrics.RecordRequest("huawei", "ListLoadBalancers", "success")
-				metrics.RequestDuration.WithLabelValues("huawei", "ListLoadBalancers").Observe(time.Since(start).Seconds())
-				break
-			}
+		resp, callErr := providerscommon.Call(ctx, providerscommon.CallOptions{
+			Provider: "huawei", AccountID: account.AccountID, Region: region, API: "ListLoadBalancers",
+			Attempts: 3, Retryable: providerscommon.RetryUnlessFatal,
+		}, func() (*elbmodel.ListLoadBalancersResponse, error) {
+			return client.ListLoadBalancers(req)
+		})
+		if callErr != nil {
 			status := providerscommon.ClassifyHuaweiError(callErr)
-			metrics.RequestTotal.WithLabelValues("huawei", "ListLoadBalancers", status).Inc()
-			metrics.RecordRequest("huawei", "ListLoadBalancers", status)
-			if status == "limit_error" {
-				metrics.RateLimitTotal.WithLabelValues("huawei", "ListLoadBalancers").Inc()
+			providerscommon.RecordTargetError("huawei", account.AccountID, region, providerscommon.NamespaceHuaweiELB, status)
+			if status != providerscommon.ErrorStatusAuth {
+				ctxLog.Warnf("ELB ListLoadBalancers 失败: %v", callErr)
 			}
-			if status == "auth_error" {
-				return nil
-			}
-			// 指数退避重试
-			sleep := time.Duration(200*(1<<attempt)) * time.Millisecond
-			if sleep > 5*time.Second {
-				sleep = 5 * time.Second
-			}
-			time.Sleep(sleep)
-		}
-		if callErr != nil {
-			ctxLog.Warnf("ELB ListLoadBalancers 失败: %v", callErr)
-			break
+			// 枚举失败：不更新区域状态，避免把区域误标为无资源
+			return nil
 		}
 
 		if resp == nil || resp.Loadbalancers == nil {
@@ -128,7 +132,17 @@ func (h *Collector) listELBInstances(account config.CloudAccount, region string)
 			if lb.Name != "" {
 				name = lb.Name
 			}
-			elbs = append(elbs, elbInfo{ID: lb.Id, Name: name})
+			tags := make(map[string]string, len(lb.Tags))
+			for _, t := range lb.Tags {
+				if t.Key != nil {
+					v := ""
+					if t.Value != nil {
+						v = *t.Value
+					}
+					tags[*t.Key] = v
+				}
+			}
+			elbs = append(elbs, elbInfo{ID: lb.Id, Name: name, Tags: tags})
 		}
 
 		// 检查分页
@@ -136,26 +150,32 @@ func (h *Collector) listELBInstances(account config.CloudAccount, region string)
 			break
 		}
 		marker = resp.PageInfo.NextMarker
-		time.Sleep(50 * time.Millisecond)
 	}
 
-	// 缓存 ID 列表
+	// 资源过滤（区域状态按过滤前的数量判断）
+	listed := len(elbs)
+	if f := providerscommon.NewResourceFilter(h.cfg, account, "elb", "clb"); f != nil {
+		kept := elbs[:0]
+		for _, elb := range elbs {
+			if f.Match(providerscommon.ResourceInfo{ID: elb.ID, Name: elb.Name, Tags: elb.Tags}) {
+				kept = append(kept, elb)
+			}
+		}
+		elbs = kept
+	}
+
+	// 缓存 ID 列表与资源信息
 	var ids []string
+	items := make([]providerscommon.ResourceInfo, 0, len(elbs))
 	for _, elb := range elbs {
 		ids = append(ids, elb.ID)
+		items = append(items, providerscommon.ResourceInfo{ID: elb.ID, Name: elb.Name, Tags: elb.Tags})
 	}
 	h.setCachedIDs(account, region, "SYS.ELB", "elb", ids)
+	h.recordResources(account, region, "elb", items)
 
 	// 更新区域状态
-	if h.regionManager != nil {
-		status := providerscommon.RegionStatusEmpty
-		if len(ids) > 0 {
-			status = providerscommon.RegionStatusActive
-		}
-		h.regionManager.UpdateRegionStatus(account.AccountID, region, len(ids), status)
-		ctxLog.Debugf("更新区域状态，status=%s，count=%d",
-			status, len(ids))
-	}
+	providerscommon.ReportRegionResources(h.regionManager, "huawei", account.AccountID, region, providerscommon.NamespaceHuaweiELB, listed)
 
 	if len(elbs) > 0 {
 		max := 5
@@ -174,7 +194,7 @@ func (h *Collector) listELBInstances(account config.CloudAccount, region string)
 }
 
 // fetchELBMonitor 采集 ELB 监控指标
-func (h *Collector) fetchELBMonitor(account config.CloudAccount, region string, prod config.Product, elbs []elbInfo) {
+func (h *Collector) fetchELBMonitor(ctx context.Context, account config.CloudAccount, region string, prod config.Product, elbs []elbInfo) {
 	ctxLog := logger.NewContextLogger("Huawei", "account_id", account.AccountID, "region", region, "rtype", "elb")
 
 	client, err := h.clientFactory.NewCESClient(region, account.AccessKeyID, account.AccessKeySecret)
@@ -238,30 +258,30 @@ func (h *Collector) fetchELBMonitor(account config.CloudAccount, region string,
 					},
 				}
 
-				reqStart := time.Now()
-				resp, err := client.BatchListMetricData(req)
+				// 华为云 CES 限流 300 次/分钟，由共享令牌桶控制（huawei.BatchListMetricData）
+				resp, err := providerscommon.Call(ctx, providerscommon.CallOptions{
+					Provider: "huawei", AccountID: account.AccountID, Region: region, API: "BatchListMetricData",
+				}, func() (*cesmodel.BatchListMetricDataResponse, error) {
+					return client.BatchListMetricData(req)
+				})
 				if err != nil {
-					status := providerscommon.ClassifyHuaweiError(err)
-					metrics.RequestTotal.WithLabelValues("huawei", "BatchListMetricData", status).Inc()
-					metrics.RecordRequest("huawei", "BatchListMetricData", status)
-					if status == "limit_error" {
-						metrics.RateLimitTotal.WithLabelValues("huawei", "BatchListMetricData").Inc()
+					if ctx.Err() != nil {
+						return
 					}
+					status := providerscommon.ClassifyHuaweiError(err)
+					providerscommon.RecordTargetError("huawei", account.AccountID, region, prod.Namespace, status)
 					ctxLog.Warnf("BatchListMetricData 错误，指标=%s 错误=%v", metricName, err)
 					continue
 				}
-				metrics.RequestTotal.WithLabelValues("huawei", "BatchListMetricData", "success").Inc()
-				metrics.RecordRequest("huawei", "BatchListMetricData", "success")
-				metrics.RequestDuration.WithLabelValues("huawei", "BatchListMetricData").Observe(time.Since(reqStart).Seconds())
 
 				if resp == nil || resp.Metrics == nil || len(*resp.Metrics) == 0 {
 					continue
 				}
 
 				// 构建 ELB ID 到名称的映射
-				elbNameMap := make(map[string]string)
+				elbByID := make(map[string]elbInfo)
 				for _, elb := range batch {
-					elbNameMap[elb.ID] = elb.Name
+					elbByID[elb.ID] = elb
 				}
 
 				for _, metricData := range *resp.Metrics {
@@ -297,23 +317,13 @@ func (h *Collector) fetchELBMonitor(account config.CloudAccount, region string,
 						rtype = "clb"
 					}
 
-					codeName := elbNameMap[resourceID]
-					if codeName == "" {
-						codeName = resourceID
-					}
+					elb := elbByID[resourceID]
+					codeName := providerscommon.ResolveCodeName(h.cfg, elbCodeNameChain, elb.Tags, elb.Name, resourceID)
 
 					labels := []string{"huawei", account.AccountID, region, rtype, resourceID, prod.Namespace, metricName, codeName}
-					for len(labels) < count {
-						labels = append(labels, "")
-					}
-					vec.WithLabelValues(labels...).Set(val)
-					metrics.IncSampleCount(prod.Namespace, 1)
+					vec.WithLabelValues(metrics.LabelValues(count, h.customLabels(account, region, "elb", resourceID), labels...)...).Set(val)
+					providerscommon.RecordTargetSamples("huawei", account.AccountID, region, prod.Namespace, 1)
 				}
-
-				// 华为云 API 限流控制：300 次/分钟
-				// 为避免触发限流，在每次批量请求后添加延迟
-				// 计算：300 次/分钟 = 5 次/秒，安全起见使用 250ms 延迟（4 次/秒）
-				time.Sleep(250 * time.Millisecond)
 			}
 		}
 	}
@@ -2,10 +2,12 @@
 package huawei
 
 import (
+	"context"
 	"strings"
 	"sync"
 	"time"
 
+	"multicloud-exporter/internal/cache"
 	"multicloud-exporter/internal/config"
 	"multicloud-exporter/internal/discovery"
 	"multicloud-exporter/internal/logger"
@@ -30,12 +32,15 @@ var defaultHuaweiRegions = []string{
 type Collector struct {
 	cfg           *config.Config
 	disc          *discovery.Manager
-	resCache      map[string]resCacheEntry
-	cacheMu       sync.RWMutex
+	resCache      cache.Cache // 资源发现：account|region|namespace|rtype -> resCacheEntry
 	clientFactory ClientFactory
 	regionManager providerscommon.RegionManager
+	scheduler     *providerscommon.ProductScheduler                  // 产品级采集调度
+	resInfo       map[string]map[string]providerscommon.ResourceInfo // 最近一次枚举的资源信息：account:region:rtype -> resourceID -> info
+	resInfoMu     sync.RWMutex
 }
 
+// resCacheEntry 缓存资源 ID，UpdatedAt 用于判断发现结果是否超过 discovery_ttl
 type resCacheEntry struct {
 	IDs       []string
 	UpdatedAt time.Time
@@ -43,58 +48,89 @@ type resCacheEntry struct {
 
 // NewCollector 创建华为云采集器实例
 func NewCollector(cfg *config.Config, mgr *discovery.Manager) *Collector {
-	return &Collector{
+	c := &Collector{
 		cfg:           cfg,
 		disc:          mgr,
-		resCache:      make(map[string]resCacheEntry),
+		resCache:      cache.Open("huawei_resources"),
+		resInfo:       make(map[string]map[string]providerscommon.ResourceInfo),
 		clientFactory: &defaultClientFactory{},
+		scheduler:     providerscommon.NewProductScheduler(),
 	}
+
+	// 区域管理器：各云共用，首次创建时加载持久化状态并启动重新发现调度器
+	if cfg != nil && cfg.GetServer() != nil {
+		c.regionManager = providerscommon.SharedRegionManager(cfg.GetServer().RegionDiscovery)
+	}
+
+	return c
 }
 
 // Collect 根据账号配置遍历区域与资源类型并采集
 // 注意：分片逻辑已下沉到产品级（collectELB/collectOBS），此处不做区域级分片
 // 这样可以避免双重分片导致的任务丢失问题
-func (h *Collector) Collect(account config.CloudAccount) {
+// ctx 取消后不再启动新的区域与请求
+func (h *Collector) Collect(ctx context.Context, account config.CloudAccount) providerscommon.CollectResult {
+	ctx, rec := providerscommon.BeginCollect(ctx, "huawei", account.AccountID)
 	regions := account.Regions
 	if len(regions) == 0 || (len(regions) == 1 && regions[0] == "*") {
 		regions = defaultHuaweiRegions
 	}
 
-	// 使用区域管理器进行智能过滤
+	// 使用区域管理器进行智能过滤：保留任一已配置命名空间可能有资源的区域
 	if h.regionManager != nil {
-		activeRegions := h.regionManager.GetActiveRegions(account.AccountID, regions)
+		var namespaces []string
+		if h.disc != nil {
+			namespaces = providerscommon.ProductNamespaces(h.disc.Get()["huawei"])
+		}
+		activeRegions := h.regionManager.GetActiveRegions("huawei", account.AccountID, regions, namespaces)
 		ctxLog := logger.NewContextLogger("Huawei", "account_id", account.AccountID, "resource_type", "RegionSelection")
 		ctxLog.Infof("智能区域选择: 总=%d 活跃=%d",
 			len(regions), len(activeRegions))
 		regions = activeRegions
 	}
 
+	// 区域默认全部并行；启用自适应并发时，限流后降低并行的区域数
+	sem := providerscommon.ConcurrencyFor("huawei", account.AccountID, providerscommon.ScopeRegion, len(regions)).NewSemaphore()
 	var wg sync.WaitGroup
 	for _, region := range regions {
+		// 账号或区域熔断中时跳过（账号可能在本轮采集中途熔断）
+		if !providerscommon.AllowRegion("huawei", account.AccountID, region) {
+			ctxLog := logger.NewContextLogger("Huawei", "account_id", account.AccountID, "region", region)
+			ctxLog.Debugf("区域跳过（熔断中）")
+			continue
+		}
+		if sem.Acquire(ctx) != nil {
+			break
+		}
 		wg.Add(1)
 		go func(r string) {
 			defer wg.Done()
-			h.collectRegion(account, r)
+			defer sem.Release()
+			h.collectRegion(ctx, account, r)
 		}(region)
 	}
 	wg.Wait()
+	return rec.Finish(ctx)
 }
 
 // collectRegion 采集指定区域的资源
-func (h *Collector) collectRegion(account config.CloudAccount, region string) {
+func (h *Collector) collectRegion(ctx context.Context, account config.CloudAccount, region string) {
 	ctxLog := logger.NewContextLogger("Huawei", "account_id", account.AccountID, "region", region, "resource_type", "RegionCollector")
 	ctxLog.Debugf("开始采集区域")
 	for _, resource := range account.Resources {
+		if ctx.Err() != nil {
+			return
+		}
 		r := strings.ToLower(resource)
 		if resource == "*" {
-			h.collectELB(account, region)
-			h.collectOBS(account, region)
+			h.collectELB(ctx, account, region)
+			h.collectOBS(ctx, account, region)
 		} else {
 			switch r {
 			case "clb", "elb":
-				h.collectELB(account, region)
+				h.collectELB(ctx, account, region)
 			case "s3", "obs":
-				h.collectOBS(account, region)
+				h.collectOBS(ctx, account, region)
 			default:
 				ctxLog := logger.NewContextLogger("Huawei", "account_id", account.AccountID, "region", region, "resource_type", resource)
 				ctxLog.Warnf("资源类型尚未实现")
@@ -110,10 +146,8 @@ func (h *Collector) cacheKey(account config.CloudAccount, region, namespace, rty
 
 // getCachedIDs 获取缓存的资源 ID 列表
 func (h *Collector) getCachedIDs(account config.CloudAccount, region, namespace, rtype string) ([]string, bool) {
-	h.cacheMu.RLock()
-	entry, ok := h.resCache[h.cacheKey(account, region, namespace, rtype)]
-	h.cacheMu.RUnlock()
-	if !ok || len(entry.IDs) == 0 {
+	var entry resCacheEntry
+	if !h.resCache.Get(h.cacheKey(account, region, namespace, rtype), &entry) || len(entry.IDs) == 0 {
 		return nil, false
 	}
 	ttlDur := time.Hour
@@ -138,7 +172,41 @@ func (h *Collector) getCachedIDs(account config.CloudAccount, region, namespace,
 
 // setCachedIDs 设置缓存的资源 ID 列表
 func (h *Collector) setCachedIDs(account config.CloudAccount, region, namespace, rtype string, ids []string) {
-	h.cacheMu.Lock()
-	h.resCache[h.cacheKey(account, region, namespace, rtype)] = resCacheEntry{IDs: ids, UpdatedAt: time.Now()}
-	h.cacheMu.Unlock()
+	// 条目不设过期时间：超过 discovery_ttl 后不再用于采集，但仍作为资源清单的最近一次结果
+	_ = h.resCache.Set(h.cacheKey(account, region, namespace, rtype), resCacheEntry{IDs: ids, UpdatedAt: time.Now()}, 0)
+}
+
+// recordResources 记录枚举时获取的资源信息，缓存命中的采集轮次复用
+func (h *Collector) recordResources(account config.CloudAccount, region, rtype string, items []providerscommon.ResourceInfo) {
+	byID := make(map[string]providerscommon.ResourceInfo, len(items))
+	for _, it := range items {
+		byID[it.ID] = it
+	}
+	h.resInfoMu.Lock()
+	defer h.resInfoMu.Unlock()
+	if h.resInfo == nil {
+		h.resInfo = make(map[string]map[string]providerscommon.ResourceInfo)
+	}
+	h.resInfo[account.AccountID+":"+region+":"+rtype] = byID
+}
+
+// resourceInfo 返回最近一次枚举时记录的资源信息
+func (h *Collector) resourceInfo(account config.CloudAccount, region, rtype, rid string) providerscommon.ResourceInfo {
+	h.resInfoMu.RLock()
+	defer h.resInfoMu.RUnlock()
+	if info, ok := h.resInfo[account.AccountID+":"+region+":"+rtype][rid]; ok {
+		return info
+	}
+	return providerscommon.ResourceInfo{ID: rid}
+}
+
+// customLabels 返回资源的自定义标签值（静态标签 + tag_labels 命中的资源标签）
+func (h *Collector) customLabels(account config.CloudAccount, region, rtype, rid string) map[string]string {
+	return providerscommon.CustomLabels(h.cfg, account, h.resourceInfo(account, region, rtype, rid).Tags)
+}
+
+// codeName 按 code_name 解析链返回资源的 code_name，defaultChain 为该资源类型的内置默认链
+func (h *Collector) codeName(account config.CloudAccount, region, rtype, rid, defaultChain string) string {
+	info := h.resourceInfo(account, region, rtype, rid)
+	return providerscommon.ResolveCodeName(h.cfg, defaultChain, info.Tags, info.Name, rid)
 }
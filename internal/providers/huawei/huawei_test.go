@@ -1,6 +1,7 @@
 package huawei
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -110,7 +111,7 @@ func TestCollector_CollectWithEmptyAccount(t *testing.T) {
 	// 测试空账号不会 panic
 	account := config.CloudAccount{}
 	require.NotPanics(t, func() {
-		c.Collect(account)
+		c.Collect(context.Background(), account)
 	})
 }
 
@@ -0,0 +1,42 @@
+package huawei
+
+import (
+	"multicloud-exporter/internal/config"
+	providerscommon "multicloud-exporter/internal/providers/common"
+)
+
+// Inventory 返回资源缓存中的资源清单，名称与标签取自最近一次枚举结果
+func (h *Collector) Inventory() []providerscommon.InventoryItem {
+	var out []providerscommon.InventoryItem
+	for _, key := range h.resCache.Keys("") {
+		var entry resCacheEntry
+		if !h.resCache.Get(key, &entry) {
+			continue
+		}
+		accountID, region, namespace, rtype, ok := providerscommon.SplitCacheKey(key)
+		if !ok {
+			continue
+		}
+		chain := "id"
+		if rtype == "elb" {
+			chain = elbCodeNameChain
+		}
+		account := config.CloudAccount{AccountID: accountID}
+		for _, id := range entry.IDs {
+			info := h.resourceInfo(account, region, rtype, id)
+			out = append(out, providerscommon.InventoryItem{
+				Provider:     "huawei",
+				AccountID:    accountID,
+				Region:       region,
+				Namespace:    namespace,
+				ResourceType: rtype,
+				ResourceID:   id,
+				Name:         info.Name,
+				CodeName:     providerscommon.ResolveCodeName(h.cfg, chain, info.Tags, info.Name, id),
+				Tags:         info.Tags,
+				UpdatedAt:    entry.UpdatedAt,
+			})
+		}
+	}
+	return out
+}
@@ -2,6 +2,7 @@
 package huawei
 
 import (
+	"context"
 	"strings"
 	"time"
 
@@ -22,7 +23,7 @@ type obsInfo struct {
 }
 
 // collectOBS 采集 OBS 存储桶资源
-func (h *Collector) collectOBS(account config.CloudAccount, region string) {
+func (h *Collector) collectOBS(ctx context.Context, account config.CloudAccount, region string) {
 	if h.cfg == nil {
 		return
 	}
@@ -49,16 +50,25 @@ func (h *Collector) collectOBS(account config.CloudAccount, region string) {
 			ctxLog.Debugf("OBS 产品跳过（分片不匹配）namespace=%s", p.Namespace)
 			continue
 		}
-		buckets := h.listOBSBuckets(account, region)
-		if len(buckets) == 0 {
+		interval := providerscommon.ResolveProductInterval(h.cfg, "huawei", p, func(string) int { return 300 })
+		if !h.scheduler.ShouldScrape("huawei", account.AccountID, region, p.Namespace, interval) {
+			ctxLog.Debugf("OBS 产品跳过（采集周期未到期，周期=%v）namespace=%s", interval, p.Namespace)
 			continue
 		}
-		h.fetchOBSMonitor(account, region, p, buckets)
+		// 区域级跳过：该命名空间在本区域连续多轮枚举为空，等待重新发现
+		if providerscommon.SkipEmptyRegion(h.regionManager, "huawei", account.AccountID, region, p.Namespace) {
+			continue
+		}
+		target := providerscommon.StartTarget(ctx, "huawei", account.AccountID, region, p.Namespace)
+		if buckets := h.listOBSBuckets(ctx, account, region); len(buckets) > 0 {
+			h.fetchOBSMonitor(ctx, account, region, p, buckets)
+		}
+		h.scheduler.Finished("huawei", account.AccountID, region, p.Namespace, target.Finish())
 	}
 }
 
 // listOBSBuckets 枚举 OBS 存储桶
-func (h *Collector) listOBSBuckets(account config.CloudAccount, region string) []obsInfo {
+func (h *Collector) listOBSBuckets(ctx context.Context, account config.CloudAccount, region string) []obsInfo {
 	ctxLog := logger.NewContextLogger("Huawei", "account_id", account.AccountID, "region", region, "rtype", "obs")
 
 	if ids, hit := h.getCachedIDs(account, region, "SYS.OBS", "obs"); hit {
@@ -79,34 +89,18 @@ func (h *Collector) listOBSBuckets(account config.CloudAccount, region string) [
 
 	ctxLog.Debugf("开始枚举 OBS 存储桶")
 
-	start := time.Now()
-	var output *obs.ListBucketsOutput
-	var callErr error
-	for attempt := 0; attempt < 3; attempt++ {
-		output, callErr = client.ListBuckets(&obs.ListBucketsInput{QueryLocation: true})
-		if callErr == nil {
-			metrics.RequestTotal.WithLabelValues("huawei", "ListBuckets", "success").Inc()
-			metrics.RecordRequest("huawei", "ListBuckets", "success")
-			metrics.RequestDuration.WithLabelValues("huawei", "ListBuckets").Observe(time.Since(start).Seconds())
-			break
-		}
+	output, callErr := providerscommon.Call(ctx, providerscommon.CallOptions{
+		Provider: "huawei", AccountID: account.AccountID, Region: region, API: "ListBuckets",
+		Attempts: 3, Retryable: providerscommon.RetryUnlessFatal,
+	}, func() (*obs.ListBucketsOutput, error) {
+		return client.ListBuckets(&obs.ListBucketsInput{QueryLocation: true})
+	})
+	if callErr != nil {
 		status := providerscommon.ClassifyHuaweiError(callErr)
-		metrics.RequestTotal.WithLabelValues("huawei", "ListBuckets", status).Inc()
-		metrics.RecordRequest("huawei", "ListBuckets", status)
-		if status == "limit_error" {
-			metrics.RateLimitTotal.WithLabelValues("huawei", "ListBuckets").Inc()
-		}
-		if status == "auth_error" {
+		providerscommon.RecordTargetError("huawei", account.AccountID, region, providerscommon.NamespaceHuaweiOBS, status)
+		if status == providerscommon.ErrorStatusAuth {
 			return nil
 		}
-		// 指数退避重试
-		sleep := time.Duration(200*(1<<attempt)) * time.Millisecond
-		if sleep > 5*time.Second {
-			sleep = 5 * time.Second
-		}
-		time.Sleep(sleep)
-	}
-	if callErr != nil {
 		ctxLog.Warnf("OBS ListBuckets 失败: %v", callErr)
 		return nil
 	}
@@ -140,22 +134,29 @@ func (h *Collector) listOBSBuckets(account config.CloudAccount, region string) [
 		}
 	}
 
+	// 资源过滤（区域状态按过滤前的数量判断；OBS 标签需逐桶查询，暂不支持按标签过滤）
+	listed := len(buckets)
+	if f := providerscommon.NewResourceFilter(h.cfg, account, "obs", "s3"); f != nil {
+		providerscommon.WarnTagsUnsupported(f, "huawei", account.AccountID, "obs")
+		kept := buckets[:0]
+		for _, bucket := range buckets {
+			if f.Match(providerscommon.ResourceInfo{ID: bucket.Name, Name: bucket.Name}) {
+				kept = append(kept, bucket)
+			}
+		}
+		buckets = kept
+	}
+
 	// 缓存 ID 列表
 	var ids []string
 	for _, bucket := range buckets {
 		ids = append(ids, bucket.Name)
 	}
 	h.setCachedIDs(account, region, "SYS.OBS", "obs", ids)
+	h.recordResources(account, region, "obs", providerscommon.ResourceInfosFromIDs(ids, nil))
 
 	// 更新区域状态
-	if h.regionManager != nil {
-		status := providerscommon.RegionStatusEmpty
-		if len(ids) > 0 {
-			status = providerscommon.RegionStatusActive
-		}
-		h.regionManager.UpdateRegionStatus(account.AccountID, region, len(ids), status)
-		ctxLog.Debugf("更新区域状态，status=%s，count=%d", status, len(ids))
-	}
+	providerscommon.ReportRegionResources(h.regionManager, "huawei", account.AccountID, region, providerscommon.NamespaceHuaweiOBS, listed)
 
 	if len(buckets) > 0 {
 		max := 5
@@ -181,7 +182,7 @@ func isOBSCapacityMetric(metricName string) bool {
 }
 
 // fetchOBSMonitor 采集 OBS 监控指标
-func (h *Collector) fetchOBSMonitor(account config.CloudAccount, region string, prod config.Product, buckets []obsInfo) {
+func (h *Collector) fetchOBSMonitor(ctx context.Context, account config.CloudAccount, region string, prod config.Product, buckets []obsInfo) {
 	ctxLog := logger.NewContextLogger("Huawei", "account_id", account.AccountID, "region", region, "rtype", "obs")
 
 	client, err := h.clientFactory.NewCESClient(region, account.AccessKeyID, account.AccessKeySecret)
@@ -267,21 +268,21 @@ func (h *Collector) fetchOBSMonitor(account config.CloudAccount, region string,
 					},
 				}
 
-				reqStart := time.Now()
-				resp, err := client.BatchListMetricData(req)
+				// 华为云 CES 限流 300 次/分钟，由共享令牌桶控制（huawei.BatchListMetricData）
+				resp, err := providerscommon.Call(ctx, providerscommon.CallOptions{
+					Provider: "huawei", AccountID: account.AccountID, Region: region, API: "BatchListMetricData",
+				}, func() (*cesmodel.BatchListMetricDataResponse, error) {
+					return client.BatchListMetricData(req)
+				})
 				if err != nil {
-					status := providerscommon.ClassifyHuaweiError(err)
-					metrics.RequestTotal.WithLabelValues("huawei", "BatchListMetricData", status).Inc()
-					metrics.RecordRequest("huawei", "BatchListMetricData", status)
-					if status == "limit_error" {
-						metrics.RateLimitTotal.WithLabelValues("huawei", "BatchListMetricData").Inc()
+					if ctx.Err() != nil {
+						return
 					}
+					status := providerscommon.ClassifyHuaweiError(err)
+					providerscommon.RecordTargetError("huawei", account.AccountID, region, prod.Namespace, status)
 					ctxLog.Warnf("OBS BatchListMetricData 错误，指标=%s period=%s 错误=%v", metricName, periodStr, err)
 					continue
 				}
-				metrics.RequestTotal.WithLabelValues("huawei", "BatchListMetricData", "success").Inc()
-				metrics.RecordRequest("huawei", "BatchListMetricData", "success")
-				metrics.RequestDuration.WithLabelValues("huawei", "BatchListMetricData").Observe(time.Since(reqStart).Seconds())
 
 				if resp == nil || resp.Metrics == nil || len(*resp.Metrics) == 0 {
 					ctxLog.Debugf("OBS BatchListMetricData 无数据，指标=%s period=%s", metricName, periodStr)
@@ -334,20 +335,12 @@ func (h *Collector) fetchOBSMonitor(account config.CloudAccount, region string,
 						rtype = "s3"
 					}
 
-					labels := []string{"huawei", account.AccountID, region, rtype, resourceID, prod.Namespace, metricName, resourceID}
-					for len(labels) < count {
-						labels = append(labels, "")
-					}
-					vec.WithLabelValues(labels...).Set(val)
-					metrics.IncSampleCount(prod.Namespace, 1)
+					labels := []string{"huawei", account.AccountID, region, rtype, resourceID, prod.Namespace, metricName, h.codeName(account, region, "obs", resourceID, "id")}
+					vec.WithLabelValues(metrics.LabelValues(count, h.customLabels(account, region, "obs", resourceID), labels...)...).Set(val)
+					providerscommon.RecordTargetSamples("huawei", account.AccountID, region, prod.Namespace, 1)
 
 					ctxLog.Debugf("OBS 暴露指标，指标=%s bucket=%s period=%s 值=%.2f", metricName, resourceID, periodStr, val)
 				}
-
-				// 华为云 API 限流控制：300 次/分钟
-				// 为避免触发限流，在每次批量请求后添加延迟
-				// 计算：300 次/分钟 = 5 次/秒，安全起见使用 250ms 延迟（4 次/秒）
-				time.Sleep(250 * time.Millisecond)
 			}
 		}
 	}
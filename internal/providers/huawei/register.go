@@ -5,6 +5,7 @@ import (
 	"multicloud-exporter/internal/config"
 	"multicloud-exporter/internal/discovery"
 	"multicloud-exporter/internal/providers"
+	providerscommon "multicloud-exporter/internal/providers/common"
 )
 
 // GetDefaultResources 返回华为云默认采集的资源类型
@@ -12,6 +13,21 @@ func (h *Collector) GetDefaultResources() []string {
 	return []string{"clb", "s3"}
 }
 
+// SupportedResources 返回可在 resources 中配置的资源类型（含别名）
+func (h *Collector) SupportedResources() []string {
+	return []string{"clb", "elb", "s3", "obs"}
+}
+
+// ValidRegion 校验区域名称格式
+func (h *Collector) ValidRegion(region string) bool {
+	return providers.IsRegionName(region)
+}
+
+// Scheduler 返回产品级采集调度器
+func (h *Collector) Scheduler() *providerscommon.ProductScheduler {
+	return h.scheduler
+}
+
 func init() {
 	providers.Register("huawei", func(cfg *config.Config, mgr *discovery.Manager) providers.Provider {
 		return NewCollector(cfg, mgr)
@@ -1,18 +1,84 @@
 package providers
 
 import (
+	"context"
+	"regexp"
 	"sync"
 
 	"multicloud-exporter/internal/config"
 	"multicloud-exporter/internal/discovery"
+	"multicloud-exporter/internal/providers/common"
 )
 
-// Provider 定义云厂商采集接口
+// CollectResult 一次账号采集的结构化结果（各区域/命名空间结果、样本数与错误）
+type CollectResult = common.CollectResult
+
+// TargetResult 单个采集目标（区域/命名空间）的结果
+type TargetResult = common.TargetResult
+
+// Provider 定义云厂商采集接口。
+// Collect 需在 ctx 取消或超时后尽快返回，并将 ctx 传递到各云 SDK 调用；
+// 实现通常以 common.BeginCollect 开始、以 ResultRecorder.Finish 生成结果。
 type Provider interface {
+	Collect(ctx context.Context, account config.CloudAccount) CollectResult
+	GetDefaultResources() []string
+}
+
+// LegacyProvider 旧版采集接口（无 context、无返回值），通过 Adapt 适配为 Provider
+type LegacyProvider interface {
 	Collect(account config.CloudAccount)
 	GetDefaultResources() []string
 }
 
+// Adapt 将旧版采集器适配为 Provider：ctx 仅在采集开始前检查，
+// 结果由采集期间上报的目标健康与账号级错误生成
+func Adapt(p LegacyProvider) Provider {
+	return &legacyAdapter{p: p}
+}
+
+type legacyAdapter struct {
+	p LegacyProvider
+}
+
+func (a *legacyAdapter) Collect(ctx context.Context, account config.CloudAccount) CollectResult {
+	ctx, rec := common.BeginCollect(ctx, account.Provider, account.AccountID)
+	if ctx.Err() == nil {
+		a.p.Collect(account)
+	}
+	return rec.Finish(ctx)
+}
+
+func (a *legacyAdapter) GetDefaultResources() []string {
+	return a.p.GetDefaultResources()
+}
+
+// Unwrap 返回被适配的旧版采集器
+func (a *legacyAdapter) Unwrap() LegacyProvider {
+	return a.p
+}
+
+// Underlying 返回 Provider 的实际实现（适配器返回被适配的旧版采集器），用于检查可选接口
+func Underlying(p Provider) interface{} {
+	if a, ok := p.(*legacyAdapter); ok {
+		return a.p
+	}
+	return p
+}
+
+// Describer 可选接口：声明 Provider 支持的全部资源类型（含别名）与区域命名规则，供 validate 子命令离线校验配置
+type Describer interface {
+	SupportedResources() []string
+	ValidRegion(region string) bool
+}
+
+// regionNamePattern 通用区域命名规则，如 cn-hangzhou、ap-southeast-1、cn-north-4、na-siliconvalley
+var regionNamePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]+)+$`)
+
+// IsRegionName 判断是否符合通用区域命名规则
+func IsRegionName(region string) bool {
+	return regionNamePattern.MatchString(region)
+}
+
 // Factory 创建 Provider 实例的工厂函数
 type Factory func(cfg *config.Config, mgr *discovery.Manager) Provider
 
@@ -21,6 +87,9 @@ var (
 	mu       sync.RWMutex
 )
 
+// LegacyFactory 创建旧版采集器的工厂函数
+type LegacyFactory func(cfg *config.Config, mgr *discovery.Manager) LegacyProvider
+
 // Register 注册云厂商 Provider
 func Register(name string, factory Factory) {
 	mu.Lock()
@@ -28,6 +97,13 @@ func Register(name string, factory Factory) {
 	registry[name] = factory
 }
 
+// RegisterLegacy 注册旧版采集器，创建时自动适配为 Provider
+func RegisterLegacy(name string, factory LegacyFactory) {
+	Register(name, func(cfg *config.Config, mgr *discovery.Manager) Provider {
+		return Adapt(factory(cfg, mgr))
+	})
+}
+
 // GetFactory 获取指定云厂商的 Factory
 func GetFactory(name string) (Factory, bool) {
 	mu.RLock()
@@ -1,19 +1,29 @@
 package providers
 
 import (
+	"context"
 	"sort"
 	"testing"
 
 	"multicloud-exporter/internal/config"
 	"multicloud-exporter/internal/discovery"
+	"multicloud-exporter/internal/providers/common"
 
 	"github.com/stretchr/testify/assert"
 )
 
-type mockProvider struct{}
+type mockProvider struct {
+	calls  int
+	status string
+}
 
-func (m *mockProvider) Collect(account config.CloudAccount) {}
-func (m *mockProvider) GetDefaultResources() []string       { return []string{} }
+func (m *mockProvider) Collect(account config.CloudAccount) {
+	m.calls++
+	if m.status != "" {
+		common.RecordAccountError(account.Provider, account.AccountID, m.status)
+	}
+}
+func (m *mockProvider) GetDefaultResources() []string { return []string{} }
 
 func TestRegistry(t *testing.T) {
 	// Backup original registry
@@ -34,7 +44,7 @@ func TestRegistry(t *testing.T) {
 	}()
 
 	mockFactory := func(cfg *config.Config, mgr *discovery.Manager) Provider {
-		return &mockProvider{}
+		return Adapt(&mockProvider{})
 	}
 
 	// Test Register
@@ -56,3 +66,22 @@ func TestRegistry(t *testing.T) {
 	sort.Strings(providers)
 	assert.Equal(t, []string{"mock", "mock2"}, providers)
 }
+
+func TestAdapt_LegacyProvider(t *testing.T) {
+	legacy := &mockProvider{status: common.ErrorStatusAuth}
+	p := Adapt(legacy)
+	assert.Same(t, legacy, Underlying(p))
+
+	res := p.Collect(context.Background(), config.CloudAccount{Provider: "legacy", AccountID: "a1"})
+	assert.Equal(t, 1, legacy.calls)
+	assert.Equal(t, "legacy", res.Provider)
+	assert.Equal(t, 1, res.Errors[common.ErrorStatusAuth])
+	assert.EqualError(t, res.Failure(), common.ErrorStatusAuth)
+
+	// 已取消的 ctx 不再调用旧版采集器
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res = p.Collect(ctx, config.CloudAccount{Provider: "legacy", AccountID: "a1"})
+	assert.Equal(t, 1, legacy.calls)
+	assert.ErrorIs(t, res.Failure(), context.Canceled)
+}
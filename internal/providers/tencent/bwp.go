@@ -1,6 +1,7 @@
 package tencent
 
 import (
+	"context"
 	"time"
 
 	"multicloud-exporter/internal/config"
@@ -13,7 +14,7 @@ import (
 	vpc "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/vpc/v20170312"
 )
 
-func (t *Collector) listBWPIDs(account config.CloudAccount, region string) []string {
+func (t *Collector) listBWPIDs(ctx context.Context, account config.CloudAccount, region string) []string {
 	ctxLog := logger.NewContextLogger("Tencent", "account_id", account.AccountID, "region", region, "rtype", "bwp")
 
 	if ids, hit := t.getCachedIDs(account, region, "QCE/BWP", "bwp"); hit {
@@ -29,45 +30,30 @@ func (t *Collector) listBWPIDs(account config.CloudAccount, region string) []str
 	ctxLog.Debugf("开始枚举 BWP IDs")
 
 	var ids []string
+	names := make(map[string]string)
 	limit := uint64(100) // 腾讯云 VPC API 默认单次最多返回 100 条
 	offset := uint64(0)
 
 	for {
 		req := vpc.NewDescribeBandwidthPackagesRequest()
+		req.SetContext(ctx)
 		req.Limit = common.Uint64Ptr(limit)
 		req.Offset = common.Uint64Ptr(offset)
 
-		start := time.Now()
-		var resp *vpc.DescribeBandwidthPackagesResponse
-		var callErr error
-		for attempt := 0; attempt < 3; attempt++ {
-			resp, callErr = client.DescribeBandwidthPackages(req)
-			if callErr == nil {
-				metrics.RequestTotal.WithLabelValues("tencent", "DescribeBandwidthPackages", "success").Inc()
-				metrics.RecordRequest("tencent", "DescribeBandwidthPackages", "success")
-				metrics.RequestDuration.WithLabelValues("tencent", "DescribeBandwidthPackages").Observe(time.Since(start).Seconds())
-				break
-			}
+		resp, callErr := providerscommon.Call(ctx, providerscommon.CallOptions{
+			Provider: "tencent", AccountID: account.AccountID, Region: region, API: "DescribeBandwidthPackages",
+			Attempts: 3, Retryable: providerscommon.RetryUnlessFatal,
+		}, func() (*vpc.DescribeBandwidthPackagesResponse, error) {
+			return client.DescribeBandwidthPackages(req)
+		})
+		if callErr != nil {
 			status := providerscommon.ClassifyTencentError(callErr)
-			metrics.RequestTotal.WithLabelValues("tencent", "DescribeBandwidthPackages", status).Inc()
-			metrics.RecordRequest("tencent", "DescribeBandwidthPackages", status)
-			if status == "limit_error" {
-				// 记录限流指标
-				metrics.RateLimitTotal.WithLabelValues("tencent", "DescribeBandwidthPackages").Inc()
-			}
-			if status == "auth_error" {
-				return []string{}
+			providerscommon.RecordTargetError("tencent", account.AccountID, region, providerscommon.NamespaceTencentBWP, status)
+			if status != providerscommon.ErrorStatusAuth {
+				ctxLog.Errorf("BWP DescribeBandwidthPackages API调用失败, offset=%d: %v", offset, callErr)
 			}
-			// 指数退避重试
-			sleep := time.Duration(200*(1<<attempt)) * time.Millisecond
-			if sleep > 5*time.Second {
-				sleep = 5 * time.Second
-			}
-			time.Sleep(sleep)
-		}
-		if callErr != nil {
-			ctxLog.Errorf("BWP DescribeBandwidthPackages API调用失败, offset=%d: %v", offset, callErr)
-			break
+			// 枚举失败：不缓存也不更新区域状态，避免把区域误标为无资源
+			return []string{}
 		}
 
 		if resp == nil || resp.Response == nil || resp.Response.BandwidthPackageSet == nil {
@@ -84,6 +70,7 @@ func (t *Collector) listBWPIDs(account config.CloudAccount, region string) []str
 				continue
 			}
 			ids = append(ids, *bp.BandwidthPackageId)
+			names[*bp.BandwidthPackageId] = stringValue(bp.BandwidthPackageName)
 		}
 
 		// 使用 TotalCount 和当前已获取的数量来判断是否还有更多数据
@@ -107,20 +94,15 @@ func (t *Collector) listBWPIDs(account config.CloudAccount, region string) []str
 		// 继续下一页
 		offset += limit
 		ctxLog.Debugf("BWP分页采集, offset=%d, current_count=%d, total_collected=%d", offset, currentCount, len(ids))
-		time.Sleep(50 * time.Millisecond)
 	}
 
+	// 资源过滤：区域状态按枚举总数更新，缓存与监控仅使用过滤后的资源
+	listed := len(ids)
+	ids = t.filterResources(account, region, "bwp", providerscommon.ResourceInfosFromIDs(ids, names), nil)
 	t.setCachedIDs(account, region, "QCE/BWP", "bwp", ids)
 
 	// 更新区域状态
-	if t.regionManager != nil {
-		status := providerscommon.RegionStatusEmpty
-		if len(ids) > 0 {
-			status = providerscommon.RegionStatusActive
-		}
-		t.regionManager.UpdateRegionStatus(account.AccountID, region, len(ids), status)
-		ctxLog.Debugf("更新区域状态, status=%s, count=%d", status, len(ids))
-	}
+	providerscommon.ReportRegionResources(t.regionManager, "tencent", account.AccountID, region, providerscommon.NamespaceTencentBWP, listed)
 
 	if len(ids) > 0 {
 		max := 5
@@ -135,7 +117,7 @@ func (t *Collector) listBWPIDs(account config.CloudAccount, region string) []str
 	return ids
 }
 
-func (t *Collector) fetchBWPMonitor(account config.CloudAccount, region string, prod config.Product, ids []string) {
+func (t *Collector) fetchBWPMonitor(ctx context.Context, account config.CloudAccount, region string, prod config.Product, ids []string) {
 	client, err := t.clientFactory.NewMonitorClient(region, account.AccessKeyID, account.AccessKeySecret)
 	if err != nil {
 		return
@@ -150,6 +132,7 @@ func (t *Collector) fetchBWPMonitor(account config.CloudAccount, region string,
 		}
 		for _, m := range group.MetricList {
 			req := monitor.NewGetMonitorDataRequest()
+			req.SetContext(ctx)
 			req.Namespace = common.StringPtr("QCE/BWP")
 			req.MetricName = common.StringPtr(m)
 			per := period
@@ -158,7 +141,7 @@ func (t *Collector) fetchBWPMonitor(account config.CloudAccount, region string,
 				if server := t.cfg.GetServer(); server != nil && server.PeriodFallback > 0 {
 					fallback = int64(server.PeriodFallback)
 				}
-				per = minPeriodForMetric(region, account, "QCE/BWP", m, fallback)
+				per = minPeriodForMetric(ctx, region, account, "QCE/BWP", m, fallback)
 			}
 			req.Period = common.Uint64Ptr(uint64(per))
 			var inst []*monitor.Instance
@@ -174,20 +157,19 @@ func (t *Collector) fetchBWPMonitor(account config.CloudAccount, region string,
 			end := time.Now()
 			req.StartTime = common.StringPtr(start.UTC().Format("2006-01-02T15:04:05Z"))
 			req.EndTime = common.StringPtr(end.UTC().Format("2006-01-02T15:04:05Z"))
-			reqStart := time.Now()
-			resp, err := client.GetMonitorData(req)
+			resp, err := providerscommon.Call(ctx, providerscommon.CallOptions{
+				Provider: "tencent", AccountID: account.AccountID, Region: region, API: "GetMonitorData",
+			}, func() (*monitor.GetMonitorDataResponse, error) {
+				return client.GetMonitorData(req)
+			})
 			if err != nil {
-				status := providerscommon.ClassifyTencentError(err)
-				metrics.RequestTotal.WithLabelValues("tencent", "GetMonitorData", status).Inc()
-				metrics.RecordRequest("tencent", "GetMonitorData", status)
-				if status == "limit_error" {
-					metrics.RateLimitTotal.WithLabelValues("tencent", "GetMonitorData").Inc()
+				if ctx.Err() != nil {
+					return
 				}
+				status := providerscommon.ClassifyTencentError(err)
+				providerscommon.RecordTargetError("tencent", account.AccountID, region, prod.Namespace, status)
 				continue
 			}
-			metrics.RequestTotal.WithLabelValues("tencent", "GetMonitorData", "success").Inc()
-			metrics.RecordRequest("tencent", "GetMonitorData", "success")
-			metrics.RequestDuration.WithLabelValues("tencent", "GetMonitorData").Observe(time.Since(reqStart).Seconds())
 
 			if resp == nil || resp.Response == nil || resp.Response.DataPoints == nil || len(resp.Response.DataPoints) == 0 {
 				// 如果没有数据点，不暴露指标（而不是设置 0 值）
@@ -210,12 +192,9 @@ func (t *Collector) fetchBWPMonitor(account config.CloudAccount, region string,
 				val := *v
 				alias, count := metrics.NamespaceGauge("QCE/BWP", m)
 				scaled := scaleBWPMetric(m, val)
-				labels := []string{"tencent", account.AccountID, region, "bwp", rid, "QCE/BWP", m, ""}
-				for len(labels) < count {
-					labels = append(labels, "")
-				}
-				alias.WithLabelValues(labels...).Set(scaled)
-				metrics.IncSampleCount("QCE/BWP", 1)
+				labels := []string{"tencent", account.AccountID, region, "bwp", rid, "QCE/BWP", m, t.codeName(account, region, "bwp", rid)}
+				alias.WithLabelValues(metrics.LabelValues(count, t.customLabels(account, region, "bwp", rid), labels...)...).Set(scaled)
+				providerscommon.RecordTargetSamples("tencent", account.AccountID, region, "QCE/BWP", 1)
 			}
 		}
 	}
@@ -1,6 +1,7 @@
 package tencent
 
 import (
+	"context"
 	"time"
 
 	"multicloud-exporter/internal/config"
@@ -13,7 +14,7 @@ import (
 	monitor "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/monitor/v20180724"
 )
 
-func (t *Collector) listCLBVips(account config.CloudAccount, region string) []string {
+func (t *Collector) listCLBVips(ctx context.Context, account config.CloudAccount, region string) []string {
 	ctxLog := logger.NewContextLogger("Tencent", "account_id", account.AccountID, "region", region, "rtype", "clb")
 
 	if ids, hit := t.getCachedIDs(account, region, "QCE/LB", "clb"); hit {
@@ -28,46 +29,30 @@ func (t *Collector) listCLBVips(account config.CloudAccount, region string) []st
 
 	ctxLog.Debugf("开始枚举 CLB VIPs")
 
-	var vips []string
+	var items []providerscommon.ResourceInfo
 	limit := int64(100) // 腾讯云 CLB API 默认单次最多返回 100 条
 	offset := int64(0)
 
 	for {
 		req := clb.NewDescribeLoadBalancersRequest()
+		req.SetContext(ctx)
 		req.Limit = common.Int64Ptr(limit)
 		req.Offset = common.Int64Ptr(offset)
 
-		start := time.Now()
-		var resp *clb.DescribeLoadBalancersResponse
-		var callErr error
-		for attempt := 0; attempt < 3; attempt++ {
-			resp, callErr = client.DescribeLoadBalancers(req)
-			if callErr == nil {
-				metrics.RequestTotal.WithLabelValues("tencent", "DescribeLoadBalancers", "success").Inc()
-				metrics.RecordRequest("tencent", "DescribeLoadBalancers", "success")
-				metrics.RequestDuration.WithLabelValues("tencent", "DescribeLoadBalancers").Observe(time.Since(start).Seconds())
-				break
-			}
+		resp, callErr := providerscommon.Call(ctx, providerscommon.CallOptions{
+			Provider: "tencent", AccountID: account.AccountID, Region: region, API: "DescribeLoadBalancers",
+			Attempts: 3, Retryable: providerscommon.RetryUnlessFatal,
+		}, func() (*clb.DescribeLoadBalancersResponse, error) {
+			return client.DescribeLoadBalancers(req)
+		})
+		if callErr != nil {
 			status := providerscommon.ClassifyTencentError(callErr)
-			metrics.RequestTotal.WithLabelValues("tencent", "DescribeLoadBalancers", status).Inc()
-			metrics.RecordRequest("tencent", "DescribeLoadBalancers", status)
-			if status == "limit_error" {
-				// 记录限流指标
-				metrics.RateLimitTotal.WithLabelValues("tencent", "DescribeLoadBalancers").Inc()
-			}
-			if status == "auth_error" {
-				return []string{}
+			providerscommon.RecordTargetError("tencent", account.AccountID, region, providerscommon.NamespaceTencentLB, status)
+			if status != providerscommon.ErrorStatusAuth {
+				ctxLog.Warnf("CLB DescribeLoadBalancers 失败 offset=%d: %v", offset, callErr)
 			}
-			// 指数退避重试
-			sleep := time.Duration(200*(1<<attempt)) * time.Millisecond
-			if sleep > 5*time.Second {
-				sleep = 5 * time.Second
-			}
-			time.Sleep(sleep)
-		}
-		if callErr != nil {
-			ctxLog.Warnf("CLB DescribeLoadBalancers 失败 offset=%d: %v", offset, callErr)
-			break
+			// 枚举失败：不缓存也不更新区域状态，避免把区域误标为无资源
+			return []string{}
 		}
 
 		if resp == nil || resp.Response == nil || resp.Response.LoadBalancerSet == nil {
@@ -83,9 +68,20 @@ func (t *Collector) listCLBVips(account config.CloudAccount, region string) []st
 			if lb == nil || lb.LoadBalancerVips == nil {
 				continue
 			}
+			tags := make(map[string]string, len(lb.Tags))
+			for _, tg := range lb.Tags {
+				if tg != nil && tg.TagKey != nil {
+					tags[*tg.TagKey] = stringValue(tg.TagValue)
+				}
+			}
+			// 指标以 VIP 作为资源 ID，过滤时名称与标签取所属 CLB
 			for _, vip := range lb.LoadBalancerVips {
 				if vip != nil {
-					vips = append(vips, *vip)
+					items = append(items, providerscommon.ResourceInfo{
+						ID:   *vip,
+						Name: stringValue(lb.LoadBalancerName),
+						Tags: tags,
+					})
 				}
 			}
 		}
@@ -94,7 +90,7 @@ func (t *Collector) listCLBVips(account config.CloudAccount, region string) []st
 		// 如果返回的数据量小于 limit，说明已经是最后一页
 		// 如果返回的数据量等于 limit，需要检查是否还有更多页
 		if resp.Response.TotalCount != nil && *resp.Response.TotalCount > 0 {
-			totalCollected := uint64(len(vips))
+			totalCollected := uint64(len(items))
 			if totalCollected >= *resp.Response.TotalCount {
 				// 已收集的数量达到总数，停止分页
 				ctxLog.Debugf("CLB 分页采集完成 offset=%d current_count=%d total_collected=%d total_count=%d",
@@ -110,22 +106,15 @@ func (t *Collector) listCLBVips(account config.CloudAccount, region string) []st
 
 		// 继续下一页
 		offset += limit
-		ctxLog.Debugf("CLB 分页采集 offset=%d current_count=%d total_collected=%d", offset, currentCount, len(vips))
-		time.Sleep(50 * time.Millisecond)
+		ctxLog.Debugf("CLB 分页采集 offset=%d current_count=%d total_collected=%d", offset, currentCount, len(items))
 	}
 
+	// 资源过滤：区域状态按枚举总数更新，缓存与监控仅使用过滤后的 VIP
+	vips := t.filterResources(account, region, "clb", items, nil)
 	t.setCachedIDs(account, region, "QCE/LB", "clb", vips)
 
 	// 更新区域状态
-	if t.regionManager != nil {
-		status := providerscommon.RegionStatusEmpty
-		if len(vips) > 0 {
-			status = providerscommon.RegionStatusActive
-		}
-		t.regionManager.UpdateRegionStatus(account.AccountID, region, len(vips), status)
-		ctxLog.Debugf("更新区域状态 account=%s region=%s status=%s count=%d",
-			account.AccountID, region, status, len(vips))
-	}
+	providerscommon.ReportRegionResources(t.regionManager, "tencent", account.AccountID, region, providerscommon.NamespaceTencentLB, len(items))
 
 	if len(vips) > 0 {
 		max := 5
@@ -142,7 +131,7 @@ func (t *Collector) listCLBVips(account config.CloudAccount, region string) []st
 	return vips
 }
 
-func (t *Collector) fetchCLBMonitor(account config.CloudAccount, region string, prod config.Product, vips []string) {
+func (t *Collector) fetchCLBMonitor(ctx context.Context, account config.CloudAccount, region string, prod config.Product, vips []string) {
 	client, err := t.clientFactory.NewMonitorClient(region, account.AccessKeyID, account.AccessKeySecret)
 	if err != nil {
 		return
@@ -157,6 +146,7 @@ func (t *Collector) fetchCLBMonitor(account config.CloudAccount, region string,
 		}
 		for _, m := range group.MetricList {
 			req := monitor.NewGetMonitorDataRequest()
+			req.SetContext(ctx)
 			req.Namespace = common.StringPtr(prod.Namespace)
 			req.MetricName = common.StringPtr(m)
 			per := period
@@ -165,7 +155,7 @@ func (t *Collector) fetchCLBMonitor(account config.CloudAccount, region string,
 				if server := t.cfg.GetServer(); server != nil && server.PeriodFallback > 0 {
 					fallback = int64(server.PeriodFallback)
 				}
-				per = minPeriodForMetric(region, account, prod.Namespace, m, fallback)
+				per = minPeriodForMetric(ctx, region, account, prod.Namespace, m, fallback)
 			}
 			req.Period = common.Uint64Ptr(uint64(per))
 			var inst []*monitor.Instance
@@ -182,20 +172,19 @@ func (t *Collector) fetchCLBMonitor(account config.CloudAccount, region string,
 			req.StartTime = common.StringPtr(start.UTC().Format("2006-01-02T15:04:05Z"))
 			req.EndTime = common.StringPtr(end.UTC().Format("2006-01-02T15:04:05Z"))
 
-			reqStart := time.Now()
-			resp, err := client.GetMonitorData(req)
+			resp, err := providerscommon.Call(ctx, providerscommon.CallOptions{
+				Provider: "tencent", AccountID: account.AccountID, Region: region, API: "GetMonitorData",
+			}, func() (*monitor.GetMonitorDataResponse, error) {
+				return client.GetMonitorData(req)
+			})
 			if err != nil {
-				status := providerscommon.ClassifyTencentError(err)
-				metrics.RequestTotal.WithLabelValues("tencent", "GetMonitorData", status).Inc()
-				metrics.RecordRequest("tencent", "GetMonitorData", status)
-				if status == "limit_error" {
-					metrics.RateLimitTotal.WithLabelValues("tencent", "GetMonitorData").Inc()
+				if ctx.Err() != nil {
+					return
 				}
+				status := providerscommon.ClassifyTencentError(err)
+				providerscommon.RecordTargetError("tencent", account.AccountID, region, prod.Namespace, status)
 				continue
 			}
-			metrics.RequestTotal.WithLabelValues("tencent", "GetMonitorData", "success").Inc()
-			metrics.RecordRequest("tencent", "GetMonitorData", "success")
-			metrics.RequestDuration.WithLabelValues("tencent", "GetMonitorData").Observe(time.Since(reqStart).Seconds())
 
 			if resp == nil || resp.Response == nil || resp.Response.DataPoints == nil || len(resp.Response.DataPoints) == 0 {
 				// 如果没有数据点，不暴露指标（而不是设置 0 值）
@@ -228,12 +217,9 @@ func (t *Collector) fetchCLBMonitor(account config.CloudAccount, region string,
 					ctxLog := logger.NewContextLogger("Tencent", "account_id", account.AccountID, "region", region, "resource_type", "CLB")
 					ctxLog.Debugf("CLB指标映射: 命名空间=%s 原始=%s 别名=%s 最终名称=%s_%s", prod.Namespace, m, metricAlias, rtype, metricAlias)
 				}
-				labels := []string{"tencent", account.AccountID, region, rtype, rid, prod.Namespace, m, ""}
-				for len(labels) < count {
-					labels = append(labels, "")
-				}
-				alias.WithLabelValues(labels...).Set(scaled)
-				metrics.IncSampleCount(prod.Namespace, 1)
+				labels := []string{"tencent", account.AccountID, region, rtype, rid, prod.Namespace, m, t.codeName(account, region, "clb", rid)}
+				alias.WithLabelValues(metrics.LabelValues(count, t.customLabels(account, region, "clb", rid), labels...)...).Set(scaled)
+				providerscommon.RecordTargetSamples("tencent", account.AccountID, region, prod.Namespace, 1)
 			}
 		}
 	}
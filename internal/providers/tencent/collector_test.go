@@ -147,7 +147,7 @@ func TestCollector_Collect(t *testing.T) {
 	c.clientFactory = factory
 
 	// Execute Collect for CLB
-	c.Collect(config.CloudAccount{
+	c.Collect(context.Background(), config.CloudAccount{
 		AccountID:       "test-acc",
 		AccessKeyID:     "ak",
 		AccessKeySecret: "sk",
@@ -44,7 +44,7 @@ func TestListCLBVips(t *testing.T) {
 	c.clientFactory = factory
 
 	// Case 1: Success
-	vips := c.listCLBVips(config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou")
+	vips := c.listCLBVips(context.Background(), config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou")
 	assert.Len(t, vips, 2)
 	assert.Contains(t, vips, "1.1.1.1")
 	assert.Contains(t, vips, "2.2.2.2")
@@ -54,18 +54,16 @@ func TestListCLBVips(t *testing.T) {
 	mockCLB.DescribeLoadBalancersFunc = func(request *clb.DescribeLoadBalancersRequest) (*clb.DescribeLoadBalancersResponse, error) {
 		return nil, fmt.Errorf("should not be called")
 	}
-	vipsCached := c.listCLBVips(config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou")
+	vipsCached := c.listCLBVips(context.Background(), config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou")
 	assert.Len(t, vipsCached, 2)
 
 	// Case 3: Error
-	c.cacheMu.Lock()
-	delete(c.resCache, c.cacheKey(config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou", "QCE/LB", "clb"))
-	c.cacheMu.Unlock()
+	c.resCache.Invalidate(c.cacheKey(config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou", "QCE/LB", "clb"))
 
 	mockCLB.DescribeLoadBalancersFunc = func(request *clb.DescribeLoadBalancersRequest) (*clb.DescribeLoadBalancersResponse, error) {
 		return nil, fmt.Errorf("api error")
 	}
-	vipsError := c.listCLBVips(config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou")
+	vipsError := c.listCLBVips(context.Background(), config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou")
 	assert.Empty(t, vipsError)
 }
 
@@ -88,7 +86,7 @@ func TestListBWPIDs(t *testing.T) {
 	c.clientFactory = factory
 
 	// Case 1: Success
-	ids := c.listBWPIDs(config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou")
+	ids := c.listBWPIDs(context.Background(), config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou")
 	assert.Len(t, ids, 2)
 	assert.Contains(t, ids, "bwp-1")
 
@@ -96,18 +94,16 @@ func TestListBWPIDs(t *testing.T) {
 	mockVPC.DescribeBandwidthPackagesFunc = func(request *vpc.DescribeBandwidthPackagesRequest) (*vpc.DescribeBandwidthPackagesResponse, error) {
 		return nil, fmt.Errorf("should not be called")
 	}
-	idsCached := c.listBWPIDs(config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou")
+	idsCached := c.listBWPIDs(context.Background(), config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou")
 	assert.Len(t, idsCached, 2)
 
 	// Case 3: Error
-	c.cacheMu.Lock()
-	delete(c.resCache, c.cacheKey(config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou", "QCE/BWP", "bwp"))
-	c.cacheMu.Unlock()
+	c.resCache.Invalidate(c.cacheKey(config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou", "QCE/BWP", "bwp"))
 
 	mockVPC.DescribeBandwidthPackagesFunc = func(request *vpc.DescribeBandwidthPackagesRequest) (*vpc.DescribeBandwidthPackagesResponse, error) {
 		return nil, fmt.Errorf("api error")
 	}
-	idsError := c.listBWPIDs(config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou")
+	idsError := c.listBWPIDs(context.Background(), config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou")
 	assert.Empty(t, idsError)
 }
 
@@ -128,7 +124,7 @@ func TestListCOSBuckets(t *testing.T) {
 	c.clientFactory = factory
 
 	// Case 1: Success (Filter by region)
-	buckets := c.listCOSBuckets(config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou")
+	buckets := c.listCOSBuckets(context.Background(), config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou")
 	assert.Len(t, buckets, 1)
 	assert.Equal(t, "bucket-1", buckets[0])
 
@@ -136,18 +132,16 @@ func TestListCOSBuckets(t *testing.T) {
 	mockCOS.GetServiceFunc = func(ctx context.Context) (*cos.ServiceGetResult, *cos.Response, error) {
 		return nil, nil, fmt.Errorf("should not be called")
 	}
-	bucketsCached := c.listCOSBuckets(config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou")
+	bucketsCached := c.listCOSBuckets(context.Background(), config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou")
 	assert.Len(t, bucketsCached, 1)
 
 	// Case 3: Error
-	c.cacheMu.Lock()
-	delete(c.resCache, c.cacheKey(config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou", "QCE/COS", "cos"))
-	c.cacheMu.Unlock()
+	c.resCache.Invalidate(c.cacheKey(config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou", "QCE/COS", "cos"))
 
 	mockCOS.GetServiceFunc = func(ctx context.Context) (*cos.ServiceGetResult, *cos.Response, error) {
 		return nil, nil, fmt.Errorf("api error")
 	}
-	bucketsError := c.listCOSBuckets(config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou")
+	bucketsError := c.listCOSBuckets(context.Background(), config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou")
 	assert.Empty(t, bucketsError)
 }
 
@@ -168,7 +162,7 @@ func TestFetchCLBMonitor_Error(t *testing.T) {
 			{MetricList: []string{"TrafficRX"}},
 		},
 	}
-	c.fetchCLBMonitor(config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou", prod, []string{"vip1"})
+	c.fetchCLBMonitor(context.Background(), config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou", prod, []string{"vip1"})
 }
 
 func TestFetchBWPMonitor_Error(t *testing.T) {
@@ -187,7 +181,7 @@ func TestFetchBWPMonitor_Error(t *testing.T) {
 			{MetricList: []string{"OutBandwidth"}},
 		},
 	}
-	c.fetchBWPMonitor(config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou", prod, []string{"bwp1"})
+	c.fetchBWPMonitor(context.Background(), config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou", prod, []string{"bwp1"})
 }
 
 func TestFetchCOSMonitor_Error(t *testing.T) {
@@ -206,5 +200,5 @@ func TestFetchCOSMonitor_Error(t *testing.T) {
 			{MetricList: []string{"StdStorage"}},
 		},
 	}
-	c.fetchCOSMonitor(config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou", prod, []string{"bucket1"})
+	c.fetchCOSMonitor(context.Background(), config.CloudAccount{AccountID: "acc1"}, "ap-guangzhou", prod, []string{"bucket1"})
 }
@@ -2,7 +2,6 @@ package tencent
 
 import (
 	"context"
-	"strings"
 	"sync"
 	"time"
 
@@ -17,7 +16,7 @@ import (
 	"github.com/tencentyun/cos-go-sdk-v5"
 )
 
-func (t *Collector) collectCOS(account config.CloudAccount, region string) {
+func (t *Collector) collectCOS(ctx context.Context, account config.CloudAccount, region string) {
 	if t.cfg == nil {
 		return
 	}
@@ -46,11 +45,17 @@ func (t *Collector) collectCOS(account config.CloudAccount, region string) {
 			ctxLog.Debugf("COS 产品跳过（分片不匹配）")
 			continue
 		}
-		buckets := t.listCOSBuckets(account, region)
+		if !t.shouldScrapeProduct(ctx, account, region, p) {
+			continue
+		}
+		target := providerscommon.StartTarget(ctx, "tencent", account.AccountID, region, p.Namespace)
+		buckets := t.listCOSBuckets(ctx, account, region)
 		if len(buckets) == 0 {
+			t.scheduler.Finished("tencent", account.AccountID, region, p.Namespace, target.Finish())
 			return
 		}
-		t.fetchCOSMonitor(account, region, p, buckets)
+		t.fetchCOSMonitor(ctx, account, region, p, buckets)
+		t.scheduler.Finished("tencent", account.AccountID, region, p.Namespace, target.Finish())
 	}
 }
 
@@ -73,7 +78,7 @@ func isCOSCapacityMetric(metricName string) bool {
 	return capacityMetrics[metricName]
 }
 
-func (t *Collector) listCOSBuckets(account config.CloudAccount, region string) []string {
+func (t *Collector) listCOSBuckets(ctx context.Context, account config.CloudAccount, region string) []string {
 	ctxLog := logger.NewContextLogger("Tencent", "account_id", account.AccountID, "region", region, "rtype", "cos")
 
 	if ids, hit := t.getCachedIDs(account, region, "QCE/COS", "cos"); hit {
@@ -94,39 +99,23 @@ func (t *Collector) listCOSBuckets(account config.CloudAccount, region string) [
 		return []string{}
 	}
 
-	start := time.Now()
 	// Get Service lists all buckets
 	// 注意：腾讯云 COS GetService API 遵循 S3 兼容协议，一次性返回所有 bucket，不支持分页
 	// 通常一个账号的 bucket 数量不会太多（通常 < 1000），所以单次返回是合理的
-	var s *cos.ServiceGetResult
-	var callErr error
-	for attempt := 0; attempt < 3; attempt++ {
-		s, _, callErr = client.GetService(context.Background())
-		if callErr == nil {
-			metrics.RequestTotal.WithLabelValues("tencent", "ListBuckets", "success").Inc()
-			metrics.RequestDuration.WithLabelValues("tencent", "ListBuckets").Observe(time.Since(start).Seconds())
-			metrics.RecordRequest("tencent", "ListBuckets", "success")
-			break
-		}
+	s, callErr := providerscommon.Call(ctx, providerscommon.CallOptions{
+		Provider: "tencent", AccountID: account.AccountID, Region: region, API: "ListBuckets",
+		Attempts: 3, Retryable: providerscommon.RetryUnlessFatal,
+	}, func() (*cos.ServiceGetResult, error) {
+		res, _, err := client.GetService(ctx)
+		return res, err
+	})
+	if callErr != nil {
 		status := providerscommon.ClassifyTencentError(callErr)
-		metrics.RequestTotal.WithLabelValues("tencent", "ListBuckets", status).Inc()
-		metrics.RecordRequest("tencent", "ListBuckets", status)
-		if status == "limit_error" {
-			// 记录限流指标
-			metrics.RateLimitTotal.WithLabelValues("tencent", "ListBuckets").Inc()
-		}
-		if status == "auth_error" {
+		providerscommon.RecordTargetError("tencent", account.AccountID, region, providerscommon.NamespaceTencentCOS, status)
+		if status == providerscommon.ErrorStatusAuth {
 			ctxLog.Errorf("ListBuckets 认证错误: %v", callErr)
 			return []string{}
 		}
-		// 指数退避重试
-		sleep := time.Duration(200*(1<<attempt)) * time.Millisecond
-		if sleep > 5*time.Second {
-			sleep = 5 * time.Second
-		}
-		time.Sleep(sleep)
-	}
-	if callErr != nil {
 		ctxLog.Errorf("ListBuckets API调用错误: %v", callErr)
 		return []string{}
 	}
@@ -140,17 +129,15 @@ func (t *Collector) listCOSBuckets(account config.CloudAccount, region string) [
 		}
 	}
 
+	// 资源过滤：区域状态按枚举总数更新，缓存与监控仅使用过滤后的资源
+	listed := len(buckets)
+	buckets = t.filterResources(account, region, "cos", providerscommon.ResourceInfosFromIDs(buckets, nil), func(missing []string) map[string]map[string]string {
+		return t.fetchCOSBucketTags(ctx, account, region, missing)
+	})
 	t.setCachedIDs(account, region, "QCE/COS", "cos", buckets)
 
 	// 更新区域状态
-	if t.regionManager != nil {
-		status := providerscommon.RegionStatusEmpty
-		if len(buckets) > 0 {
-			status = providerscommon.RegionStatusActive
-		}
-		t.regionManager.UpdateRegionStatus(account.AccountID, region, len(buckets), status)
-		ctxLog.Debugf("更新区域状态，status=%s，count=%d", status, len(buckets))
-	}
+	providerscommon.ReportRegionResources(t.regionManager, "tencent", account.AccountID, region, providerscommon.NamespaceTencentCOS, listed)
 
 	if len(buckets) > 0 {
 		max := 5
@@ -165,12 +152,19 @@ func (t *Collector) listCOSBuckets(account config.CloudAccount, region string) [
 	return buckets
 }
 
-func (t *Collector) fetchCOSBucketCodeNames(account config.CloudAccount, region string, buckets []string) map[string]string {
-	out := make(map[string]string, len(buckets))
+// fetchCOSBucketCodeNames 拉取存储桶标签并按解析链生成 code_name
+func (t *Collector) fetchCOSBucketCodeNames(ctx context.Context, account config.CloudAccount, region string, buckets []string) map[string]string {
+	return providerscommon.ResolveCodeNames(t.cfg, providerscommon.DefaultCodeNameChain, buckets, t.fetchCOSBucketTags(ctx, account, region, buckets), nil)
+}
+
+// fetchCOSBucketTags 并发获取存储桶的完整标签
+func (t *Collector) fetchCOSBucketTags(ctx context.Context, account config.CloudAccount, region string, buckets []string) map[string]map[string]string {
+	out := make(map[string]map[string]string, len(buckets))
 	client, err := t.clientFactory.NewCOSClient(region, account.AccessKeyID, account.AccessKeySecret)
 	if err != nil {
 		return out
 	}
+	var mu sync.Mutex
 	limit := 5
 	sem := make(chan struct{}, limit)
 	var wg sync.WaitGroup
@@ -180,44 +174,29 @@ func (t *Collector) fetchCOSBucketCodeNames(account config.CloudAccount, region
 		go func(bucket string) {
 			defer wg.Done()
 			defer func() { <-sem }()
-			var tags map[string]string
-			var callErr error
-			for attempt := 0; attempt < 3; attempt++ {
-				tags, callErr = client.GetBucketTagging(context.Background(), bucket, region)
-				if callErr == nil {
-					break
-				}
-				status := providerscommon.ClassifyTencentError(callErr)
-				if status == "limit_error" {
-					// 记录限流指标
-					metrics.RateLimitTotal.WithLabelValues("tencent", "GetBucketTagging").Inc()
-				}
-				if status == "auth_error" {
-					return
-				}
-				// 指数退避重试
-				sleep := time.Duration(200*(1<<attempt)) * time.Millisecond
-				if sleep > 5*time.Second {
-					sleep = 5 * time.Second
-				}
-				time.Sleep(sleep)
+			tags, callErr := providerscommon.Call(ctx, providerscommon.CallOptions{
+				Provider: "tencent", AccountID: account.AccountID, Region: region, API: "GetBucketTagging",
+				Attempts: 3, Retryable: providerscommon.RetryUnlessFatal,
+			}, func() (map[string]string, error) {
+				return client.GetBucketTagging(ctx, bucket, region)
+			})
+			if callErr != nil && providerscommon.ClassifyTencentError(callErr) == providerscommon.ErrorStatusAuth {
+				providerscommon.RecordTargetError("tencent", account.AccountID, region, providerscommon.NamespaceTencentCOS, providerscommon.ErrorStatusAuth)
+				return
 			}
 			if callErr != nil || len(tags) == 0 {
 				return
 			}
-			for k, v := range tags {
-				if strings.EqualFold(k, "CodeName") || strings.EqualFold(k, "code_name") {
-					out[bucket] = v
-					break
-				}
-			}
+			mu.Lock()
+			out[bucket] = tags
+			mu.Unlock()
 		}(b)
 	}
 	wg.Wait()
 	return out
 }
 
-func (t *Collector) fetchCOSMonitor(account config.CloudAccount, region string, prod config.Product, buckets []string) {
+func (t *Collector) fetchCOSMonitor(ctx context.Context, account config.CloudAccount, region string, prod config.Product, buckets []string) {
 	ctxLog := logger.NewContextLogger("Tencent", "account_id", account.AccountID, "region", region, "rtype", "cos")
 
 	client, err := t.clientFactory.NewMonitorClient(region, account.AccessKeyID, account.AccessKeySecret)
@@ -235,7 +214,7 @@ func (t *Collector) fetchCOSMonitor(account config.CloudAccount, region string,
 	// We need to batch the buckets.
 	batchSize := 10 // Safe batch size
 
-	codeNames := t.fetchCOSBucketCodeNames(account, region, buckets)
+	codeNames := t.fetchCOSBucketCodeNames(ctx, account, region, buckets)
 
 	for _, group := range prod.MetricInfo {
 		groupPeriod := period
@@ -269,6 +248,7 @@ func (t *Collector) fetchCOSMonitor(account config.CloudAccount, region string,
 				batch := buckets[i:end]
 
 				req := monitor.NewGetMonitorDataRequest()
+				req.SetContext(ctx)
 				req.Namespace = common.StringPtr(prod.Namespace)
 				req.MetricName = common.StringPtr(m)
 				req.Period = common.Uint64Ptr(uint64(localPeriod))
@@ -286,22 +266,20 @@ func (t *Collector) fetchCOSMonitor(account config.CloudAccount, region string,
 				req.StartTime = common.StringPtr(startT.UTC().Format("2006-01-02T15:04:05Z"))
 				req.EndTime = common.StringPtr(endT.UTC().Format("2006-01-02T15:04:05Z"))
 
-				reqStart := time.Now()
-				resp, err := client.GetMonitorData(req)
+				resp, err := providerscommon.Call(ctx, providerscommon.CallOptions{
+					Provider: "tencent", AccountID: account.AccountID, Region: region, API: "GetMonitorData",
+				}, func() (*monitor.GetMonitorDataResponse, error) {
+					return client.GetMonitorData(req)
+				})
 				if err != nil {
-					status := providerscommon.ClassifyTencentError(err)
-					metrics.RequestTotal.WithLabelValues("tencent", "GetMonitorData", status).Inc()
-					metrics.RecordRequest("tencent", "GetMonitorData", status)
-					if status == "limit_error" {
-						// 记录限流指标
-						metrics.RateLimitTotal.WithLabelValues("tencent", "GetMonitorData").Inc()
+					if ctx.Err() != nil {
+						return
 					}
+					status := providerscommon.ClassifyTencentError(err)
+					providerscommon.RecordTargetError("tencent", account.AccountID, region, prod.Namespace, status)
 					ctxLog.Warnf("GetMonitorData API 调用错误，指标=%s: %v", m, err)
 					continue
 				}
-				metrics.RequestTotal.WithLabelValues("tencent", "GetMonitorData", "success").Inc()
-				metrics.RecordRequest("tencent", "GetMonitorData", "success")
-				metrics.RequestDuration.WithLabelValues("tencent", "GetMonitorData").Observe(time.Since(reqStart).Seconds())
 
 				if resp == nil || resp.Response == nil || len(resp.Response.DataPoints) == 0 {
 					continue
@@ -336,10 +314,8 @@ func (t *Collector) fetchCOSMonitor(account config.CloudAccount, region string,
 					vec, count := metrics.NamespaceGauge("QCE/COS", m)
 					codeName := codeNames[bucketName]
 					labels := []string{"tencent", account.AccountID, region, "cos", bucketName, "QCE/COS", m, codeName}
-					for len(labels) < count {
-						labels = append(labels, "")
-					}
-					vec.WithLabelValues(labels...).Set(val)
+					vec.WithLabelValues(metrics.LabelValues(count, t.customLabels(account, region, "cos", bucketName), labels...)...).Set(val)
+					providerscommon.RecordTargetSamples("tencent", account.AccountID, region, "QCE/COS", 1)
 				}
 				// 优化：移除指标间延迟，降低云API压力
 				// 原代码: time.Sleep(50 * time.Millisecond)
@@ -1,6 +1,7 @@
 package tencent
 
 import (
+	"context"
 	"time"
 
 	"multicloud-exporter/internal/config"
@@ -13,7 +14,7 @@ import (
 	monitor "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/monitor/v20180724"
 )
 
-func (t *Collector) listGWLBIDs(account config.CloudAccount, region string) []string {
+func (t *Collector) listGWLBIDs(ctx context.Context, account config.CloudAccount, region string) []string {
 	ctxLog := logger.NewContextLogger("Tencent", "account_id", account.AccountID, "region", region, "rtype", "gwlb")
 	ctxLog.Debugf("开始枚举 GWLB IDs")
 
@@ -26,6 +27,7 @@ func (t *Collector) listGWLBIDs(account config.CloudAccount, region string) []st
 		return []string{}
 	}
 	req := monitor.NewGetMonitorDataRequest()
+	req.SetContext(ctx)
 	req.Namespace = common.StringPtr("qce/gwlb")
 	req.MetricName = common.StringPtr("ConcurConn")
 	period := int64(60)
@@ -35,18 +37,19 @@ func (t *Collector) listGWLBIDs(account config.CloudAccount, region string) []st
 	req.StartTime = common.StringPtr(start.UTC().Format("2006-01-02T15:04:05Z"))
 	req.EndTime = common.StringPtr(end.UTC().Format("2006-01-02T15:04:05Z"))
 
-	reqStart := time.Now()
-	resp, err := client.GetMonitorData(req)
+	resp, err := providerscommon.Call(ctx, providerscommon.CallOptions{
+		Provider: "tencent", AccountID: account.AccountID, Region: region, API: "GetMonitorData",
+	}, func() (*monitor.GetMonitorDataResponse, error) {
+		return client.GetMonitorData(req)
+	})
 	if err != nil {
-		status := providerscommon.ClassifyTencentError(err)
-		metrics.RequestTotal.WithLabelValues("tencent", "GetMonitorData", status).Inc()
-		if status == "limit_error" {
-			metrics.RateLimitTotal.WithLabelValues("tencent", "GetMonitorData").Inc()
+		if ctx.Err() != nil {
+			return []string{}
 		}
+		status := providerscommon.ClassifyTencentError(err)
+		providerscommon.RecordTargetError("tencent", account.AccountID, region, providerscommon.NamespaceTencentGWLB, status)
 		return []string{}
 	}
-	metrics.RequestTotal.WithLabelValues("tencent", "GetMonitorData", "success").Inc()
-	metrics.RequestDuration.WithLabelValues("tencent", "GetMonitorData").Observe(time.Since(reqStart).Seconds())
 
 	var ids []string
 	seen := make(map[string]struct{})
@@ -65,17 +68,13 @@ func (t *Collector) listGWLBIDs(account config.CloudAccount, region string) []st
 			}
 		}
 	}
+	// 资源过滤：区域状态按枚举总数更新，缓存与监控仅使用过滤后的资源
+	listed := len(ids)
+	ids = t.filterResources(account, region, "gwlb", providerscommon.ResourceInfosFromIDs(ids, nil), nil)
 	t.setCachedIDs(account, region, "qce/gwlb", "gwlb", ids)
 
 	// 更新区域状态
-	if t.regionManager != nil {
-		status := providerscommon.RegionStatusEmpty
-		if len(ids) > 0 {
-			status = providerscommon.RegionStatusActive
-		}
-		t.regionManager.UpdateRegionStatus(account.AccountID, region, len(ids), status)
-		ctxLog.Debugf("更新区域状态, status=%s, count=%d", status, len(ids))
-	}
+	providerscommon.ReportRegionResources(t.regionManager, "tencent", account.AccountID, region, providerscommon.NamespaceTencentGWLB, listed)
 
 	if len(ids) > 0 {
 		max := 5
@@ -92,7 +91,7 @@ func (t *Collector) listGWLBIDs(account config.CloudAccount, region string) []st
 	return ids
 }
 
-func (t *Collector) fetchGWLBMonitor(account config.CloudAccount, region string, prod config.Product, ids []string) {
+func (t *Collector) fetchGWLBMonitor(ctx context.Context, account config.CloudAccount, region string, prod config.Product, ids []string) {
 	client, err := t.clientFactory.NewMonitorClient(region, account.AccessKeyID, account.AccessKeySecret)
 	if err != nil {
 		return
@@ -107,6 +106,7 @@ func (t *Collector) fetchGWLBMonitor(account config.CloudAccount, region string,
 		}
 		for _, m := range group.MetricList {
 			req := monitor.NewGetMonitorDataRequest()
+			req.SetContext(ctx)
 			req.Namespace = common.StringPtr("qce/gwlb")
 			req.MetricName = common.StringPtr(m)
 			per := period
@@ -115,7 +115,7 @@ func (t *Collector) fetchGWLBMonitor(account config.CloudAccount, region string,
 				if server := t.cfg.GetServer(); server != nil && server.PeriodFallback > 0 {
 					fallback = int64(server.PeriodFallback)
 				}
-				per = minPeriodForMetric(region, account, "qce/gwlb", m, fallback)
+				per = minPeriodForMetric(ctx, region, account, "qce/gwlb", m, fallback)
 			}
 			req.Period = common.Uint64Ptr(uint64(per))
 			var inst []*monitor.Instance
@@ -131,20 +131,19 @@ func (t *Collector) fetchGWLBMonitor(account config.CloudAccount, region string,
 			end := time.Now()
 			req.StartTime = common.StringPtr(start.UTC().Format("2006-01-02T15:04:05Z"))
 			req.EndTime = common.StringPtr(end.UTC().Format("2006-01-02T15:04:05Z"))
-			reqStart := time.Now()
-			resp, err := client.GetMonitorData(req)
+			resp, err := providerscommon.Call(ctx, providerscommon.CallOptions{
+				Provider: "tencent", AccountID: account.AccountID, Region: region, API: "GetMonitorData",
+			}, func() (*monitor.GetMonitorDataResponse, error) {
+				return client.GetMonitorData(req)
+			})
 			if err != nil {
-				status := providerscommon.ClassifyTencentError(err)
-				metrics.RequestTotal.WithLabelValues("tencent", "GetMonitorData", status).Inc()
-				metrics.RecordRequest("tencent", "GetMonitorData", status)
-				if status == "limit_error" {
-					metrics.RateLimitTotal.WithLabelValues("tencent", "GetMonitorData").Inc()
+				if ctx.Err() != nil {
+					return
 				}
+				status := providerscommon.ClassifyTencentError(err)
+				providerscommon.RecordTargetError("tencent", account.AccountID, region, prod.Namespace, status)
 				continue
 			}
-			metrics.RequestTotal.WithLabelValues("tencent", "GetMonitorData", "success").Inc()
-			metrics.RecordRequest("tencent", "GetMonitorData", "success")
-			metrics.RequestDuration.WithLabelValues("tencent", "GetMonitorData").Observe(time.Since(reqStart).Seconds())
 			if resp == nil || resp.Response == nil || resp.Response.DataPoints == nil || len(resp.Response.DataPoints) == 0 {
 				// 如果没有数据点，不暴露指标（而不是设置 0 值）
 				// 根据 Prometheus 最佳实践：不存在资源或无数据时，不应该暴露指标
@@ -169,18 +168,15 @@ func (t *Collector) fetchGWLBMonitor(account config.CloudAccount, region string,
 				if scaled != 0 && scaled != 1 {
 					val = val * scaled
 				}
-				labels := []string{"tencent", account.AccountID, region, "gwlb", rid, "qce/gwlb", m, ""}
-				for len(labels) < count {
-					labels = append(labels, "")
-				}
-				alias.WithLabelValues(labels...).Set(val)
-				metrics.IncSampleCount("qce/gwlb", 1)
+				labels := []string{"tencent", account.AccountID, region, "gwlb", rid, "qce/gwlb", m, t.codeName(account, region, "gwlb", rid)}
+				alias.WithLabelValues(metrics.LabelValues(count, t.customLabels(account, region, "gwlb", rid), labels...)...).Set(val)
+				providerscommon.RecordTargetSamples("tencent", account.AccountID, region, "qce/gwlb", 1)
 			}
 		}
 	}
 }
 
-func (t *Collector) collectGWLB(account config.CloudAccount, region string) {
+func (t *Collector) collectGWLB(ctx context.Context, account config.CloudAccount, region string) {
 	if t.cfg == nil {
 		return
 	}
@@ -207,10 +203,16 @@ func (t *Collector) collectGWLB(account config.CloudAccount, region string) {
 			ctxLog.Debugf("产品跳过（分片不匹配）")
 			continue
 		}
-		ids := t.listGWLBIDs(account, region)
+		if !t.shouldScrapeProduct(ctx, account, region, p) {
+			continue
+		}
+		target := providerscommon.StartTarget(ctx, "tencent", account.AccountID, region, p.Namespace)
+		ids := t.listGWLBIDs(ctx, account, region)
 		if len(ids) == 0 {
+			t.scheduler.Finished("tencent", account.AccountID, region, p.Namespace, target.Finish())
 			return
 		}
-		t.fetchGWLBMonitor(account, region, p, ids)
+		t.fetchGWLBMonitor(ctx, account, region, p, ids)
+		t.scheduler.Finished("tencent", account.AccountID, region, p.Namespace, target.Finish())
 	}
 }
@@ -0,0 +1,38 @@
+package tencent
+
+import (
+	"multicloud-exporter/internal/config"
+	providerscommon "multicloud-exporter/internal/providers/common"
+)
+
+// Inventory 返回资源缓存中的资源清单，名称与标签取自最近一次枚举结果
+func (t *Collector) Inventory() []providerscommon.InventoryItem {
+	var out []providerscommon.InventoryItem
+	for _, key := range t.resCache.Keys("") {
+		var entry resCacheEntry
+		if !t.resCache.Get(key, &entry) {
+			continue
+		}
+		accountID, region, namespace, rtype, ok := providerscommon.SplitCacheKey(key)
+		if !ok {
+			continue
+		}
+		account := config.CloudAccount{AccountID: accountID}
+		for _, id := range entry.IDs {
+			info := t.resourceInfo(account, region, rtype, id)
+			out = append(out, providerscommon.InventoryItem{
+				Provider:     "tencent",
+				AccountID:    accountID,
+				Region:       region,
+				Namespace:    namespace,
+				ResourceType: rtype,
+				ResourceID:   id,
+				Name:         info.Name,
+				CodeName:     providerscommon.ResolveCodeName(t.cfg, providerscommon.DefaultCodeNameChain, info.Tags, info.Name, id),
+				Tags:         info.Tags,
+				UpdatedAt:    entry.UpdatedAt,
+			})
+		}
+	}
+	return out
+}
@@ -1,6 +1,7 @@
 package tencent
 
 import (
+	"context"
 	"multicloud-exporter/internal/config"
 	"testing"
 )
@@ -11,7 +12,7 @@ func TestMinPeriod_PeriodsList(t *testing.T) {
 		return []byte(`{"MetricSet":[{"MetricName":"InTraffic","Periods":[60,300]}]}`), nil
 	}
 	acc := config.CloudAccount{AccessKeyID: "ak", AccessKeySecret: "sk"}
-	p := minPeriodForMetric("ap-guangzhou", acc, "QCE/BWP", "InTraffic", 60)
+	p := minPeriodForMetric(context.Background(), "ap-guangzhou", acc, "QCE/BWP", "InTraffic", 60)
 	if p != 60 {
 		t.Fatalf("expected 60, got %d", p)
 	}
@@ -22,7 +23,7 @@ func TestMinPeriod_SinglePeriod(t *testing.T) {
 		return []byte(`{"MetricSet":[{"MetricName":"VipOuttraffic","Period":300}]}`), nil
 	}
 	acc := config.CloudAccount{}
-	p := minPeriodForMetric("ap-guangzhou", acc, "QCE/LB", "VipOuttraffic", 60)
+	p := minPeriodForMetric(context.Background(), "ap-guangzhou", acc, "QCE/LB", "VipOuttraffic", 60)
 	if p != 300 {
 		t.Fatalf("expected 300, got %d", p)
 	}
@@ -33,7 +34,7 @@ func TestMinPeriod_EmptyFallback(t *testing.T) {
 		return []byte(`{"MetricSet":[]}`), nil
 	}
 	acc := config.CloudAccount{}
-	p := minPeriodForMetric("ap-guangzhou", acc, "QCE/LB", "VipIntraffic", 60)
+	p := minPeriodForMetric(context.Background(), "ap-guangzhou", acc, "QCE/LB", "VipIntraffic", 60)
 	if p != 60 {
 		t.Fatalf("expected 60 fallback, got %d", p)
 	}
@@ -4,6 +4,7 @@ import (
 	"multicloud-exporter/internal/config"
 	"multicloud-exporter/internal/discovery"
 	"multicloud-exporter/internal/providers"
+	providerscommon "multicloud-exporter/internal/providers/common"
 )
 
 // GetDefaultResources 返回腾讯云默认采集的资源类型
@@ -11,6 +12,21 @@ func (t *Collector) GetDefaultResources() []string {
 	return []string{"clb", "bwp", "s3"}
 }
 
+// SupportedResources 返回可在 resources 中配置的资源类型（含别名）
+func (t *Collector) SupportedResources() []string {
+	return []string{"clb", "bwp", "gwlb", "s3", "cos"}
+}
+
+// ValidRegion 校验区域名称格式
+func (t *Collector) ValidRegion(region string) bool {
+	return providers.IsRegionName(region)
+}
+
+// Scheduler 返回产品级采集调度器
+func (t *Collector) Scheduler() *providerscommon.ProductScheduler {
+	return t.scheduler
+}
+
 func init() {
 	providers.Register("tencent", func(cfg *config.Config, mgr *discovery.Manager) providers.Provider {
 		return NewCollector(cfg, mgr)
@@ -1,6 +1,7 @@
 package tencent
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"strconv"
@@ -8,10 +9,10 @@ import (
 	"sync"
 	"time"
 
+	"multicloud-exporter/internal/cache"
 	"multicloud-exporter/internal/config"
 	"multicloud-exporter/internal/discovery"
 	"multicloud-exporter/internal/logger"
-	"multicloud-exporter/internal/metrics"
 	providerscommon "multicloud-exporter/internal/providers/common"
 	"multicloud-exporter/internal/utils"
 
@@ -24,12 +25,15 @@ import (
 type Collector struct {
 	cfg           *config.Config
 	disc          *discovery.Manager
-	resCache      map[string]resCacheEntry
-	cacheMu       sync.RWMutex
+	resCache      cache.Cache // 资源发现：account|region|namespace|rtype -> resCacheEntry
 	clientFactory ClientFactory
 	regionManager providerscommon.RegionManager
+	scheduler     *providerscommon.ProductScheduler                  // 产品级采集调度
+	resInfo       map[string]map[string]providerscommon.ResourceInfo // 最近一次枚举的资源信息：account:region:rtype -> resourceID -> info
+	resInfoMu     sync.RWMutex
 }
 
+// resCacheEntry 缓存资源 ID，UpdatedAt 用于判断发现结果是否超过 discovery_ttl
 type resCacheEntry struct {
 	IDs       []string
 	UpdatedAt time.Time
@@ -39,48 +43,26 @@ func NewCollector(cfg *config.Config, mgr *discovery.Manager) *Collector {
 	c := &Collector{
 		cfg:           cfg,
 		disc:          mgr,
-		resCache:      make(map[string]resCacheEntry),
+		resCache:      cache.Open("tencent_resources"),
+		resInfo:       make(map[string]map[string]providerscommon.ResourceInfo),
 		clientFactory: &defaultClientFactory{},
+		scheduler:     providerscommon.NewProductScheduler(),
 	}
 
-	// 初始化区域管理器
-	if cfg != nil && cfg.GetServer() != nil && cfg.GetServer().RegionDiscovery != nil {
-		c.regionManager = providerscommon.NewRegionManager(providerscommon.RegionDiscoveryConfig{
-			Enabled:           cfg.GetServer().RegionDiscovery.Enabled,
-			DiscoveryInterval: parseDuration(cfg.GetServer().RegionDiscovery.DiscoveryInterval),
-			EmptyThreshold:    cfg.GetServer().RegionDiscovery.EmptyThreshold,
-			DataDir:           cfg.GetServer().RegionDiscovery.DataDir,
-			PersistFile:       cfg.GetServer().RegionDiscovery.PersistFile,
-		})
-
-		// 加载持久化的区域状态
-		if err := c.regionManager.Load(); err != nil {
-			ctxLog := logger.NewContextLogger("Tencent", "resource_type", "RegionManager")
-			ctxLog.Warnf("加载区域状态失败: %v", err)
-		}
-
-		// 启动定期重新发现调度器
-		c.regionManager.StartRediscoveryScheduler()
+	// 区域管理器：各云共用，首次创建时加载持久化状态并启动重新发现调度器
+	if cfg != nil && cfg.GetServer() != nil {
+		c.regionManager = providerscommon.SharedRegionManager(cfg.GetServer().RegionDiscovery)
 	}
 
 	return c
 }
 
-// parseDuration 解析时长字符串为 time.Duration
-func parseDuration(s string) time.Duration {
-	if s == "" {
-		return 0
-	}
-	if d, err := time.ParseDuration(s); err == nil {
-		return d
-	}
-	return 0
-}
-
-func (t *Collector) Collect(account config.CloudAccount) {
+// Collect 遍历账号区域并采集，ctx 取消后不再启动新的区域与请求
+func (t *Collector) Collect(ctx context.Context, account config.CloudAccount) providerscommon.CollectResult {
+	ctx, rec := providerscommon.BeginCollect(ctx, "tencent", account.AccountID)
 	regions := account.Regions
 	if len(regions) == 0 || (len(regions) == 1 && regions[0] == "*") {
-		regions = t.getAllRegions(account)
+		regions = t.getAllRegions(ctx, account)
 		if len(regions) == 0 {
 			regions = []string{"ap-guangzhou"}
 		}
@@ -88,54 +70,47 @@ func (t *Collector) Collect(account config.CloudAccount) {
 
 	// 注意：分片逻辑已下沉到产品级（collectCLB/collectBWP/collectCOS 等），此处不做区域级分片
 	// 这样可以避免双重分片导致的任务丢失问题
+	// 区域默认全部并行；启用自适应并发时，限流后降低并行的区域数
+	sem := providerscommon.ConcurrencyFor("tencent", account.AccountID, providerscommon.ScopeRegion, len(regions)).NewSemaphore()
 	var wg sync.WaitGroup
 	for _, region := range regions {
+		// 账号或区域熔断中时跳过（账号可能在本轮采集中途熔断）
+		if !providerscommon.AllowRegion("tencent", account.AccountID, region) {
+			ctxLog := logger.NewContextLogger("Tencent", "account_id", account.AccountID, "region", region)
+			ctxLog.Debugf("区域跳过（熔断中）")
+			continue
+		}
+		if sem.Acquire(ctx) != nil {
+			break
+		}
 		wg.Add(1)
 		go func(r string) {
 			defer wg.Done()
-			t.collectRegion(account, r)
+			defer sem.Release()
+			t.collectRegion(ctx, account, r)
 		}(region)
 	}
 	wg.Wait()
+	return rec.Finish(ctx)
 }
 
 // getAllRegions 通过 CVM DescribeRegions 自动枚举腾讯云可用区域
-func (t *Collector) getAllRegions(account config.CloudAccount) []string {
+func (t *Collector) getAllRegions(ctx context.Context, account config.CloudAccount) []string {
 	client, err := t.clientFactory.NewCVMClient("ap-guangzhou", account.AccessKeyID, account.AccessKeySecret)
 	if err != nil {
 		return []string{"ap-guangzhou"}
 	}
 	req := cvm.NewDescribeRegionsRequest()
-	start := time.Now()
-	var resp *cvm.DescribeRegionsResponse
-	var callErr error
-	for attempt := 0; attempt < 3; attempt++ {
-		resp, callErr = client.DescribeRegions(req)
-		if callErr == nil && resp != nil && resp.Response != nil && resp.Response.RegionSet != nil {
-			metrics.RequestTotal.WithLabelValues("tencent", "DescribeRegions", "success").Inc()
-			metrics.RecordRequest("tencent", "DescribeRegions", "success")
-			metrics.RequestDuration.WithLabelValues("tencent", "DescribeRegions").Observe(time.Since(start).Seconds())
-			break
-		}
-		if callErr != nil {
-			status := providerscommon.ClassifyTencentError(callErr)
-			metrics.RequestTotal.WithLabelValues("tencent", "DescribeRegions", status).Inc()
-			metrics.RecordRequest("tencent", "DescribeRegions", status)
-			if status == "limit_error" {
-				// 记录限流指标
-				metrics.RateLimitTotal.WithLabelValues("tencent", "DescribeRegions").Inc()
-			}
-			if status == "auth_error" {
-				break
-			}
-			// 指数退避重试
-			sleep := time.Duration(200*(1<<attempt)) * time.Millisecond
-			if sleep > 5*time.Second {
-				sleep = 5 * time.Second
-			}
-			time.Sleep(sleep)
-		} else {
-			break
+	req.SetContext(ctx)
+	resp, callErr := providerscommon.Call(ctx, providerscommon.CallOptions{
+		Provider: "tencent", AccountID: account.AccountID, API: "DescribeRegions",
+		Attempts: 3, Retryable: providerscommon.RetryUnlessFatal,
+	}, func() (*cvm.DescribeRegionsResponse, error) {
+		return client.DescribeRegions(req)
+	})
+	if callErr != nil {
+		if status := providerscommon.ClassifyTencentError(callErr); status == providerscommon.ErrorStatusAuth {
+			providerscommon.RecordAccountError("tencent", account.AccountID, status)
 		}
 	}
 	if callErr != nil || resp == nil || resp.Response == nil || resp.Response.RegionSet == nil {
@@ -165,9 +140,13 @@ func (t *Collector) getAllRegions(account config.CloudAccount) []string {
 		regions = []string{"ap-guangzhou"}
 	}
 
-	// 使用区域管理器进行智能过滤
+	// 使用区域管理器进行智能过滤：保留任一已配置命名空间可能有资源的区域
 	if t.regionManager != nil {
-		activeRegions := t.regionManager.GetActiveRegions(account.AccountID, regions)
+		var namespaces []string
+		if t.disc != nil {
+			namespaces = providerscommon.ProductNamespaces(t.disc.Get()["tencent"])
+		}
+		activeRegions := t.regionManager.GetActiveRegions("tencent", account.AccountID, regions, namespaces)
 		ctxLog := logger.NewContextLogger("Tencent", "account_id", account.AccountID, "resource_type", "RegionManager")
 		ctxLog.Infof("智能区域选择: 总=%d 活跃=%d",
 			len(regions), len(activeRegions))
@@ -177,28 +156,31 @@ func (t *Collector) getAllRegions(account config.CloudAccount) []string {
 	return regions
 }
 
-func (t *Collector) collectRegion(account config.CloudAccount, region string) {
+func (t *Collector) collectRegion(ctx context.Context, account config.CloudAccount, region string) {
 	ctxLog := logger.NewContextLogger("Tencent", "account_id", account.AccountID, "region", region, "resource_type", "RegionCollector")
 	ctxLog.Debugf("开始采集区域")
 	for _, resource := range account.Resources {
+		if ctx.Err() != nil {
+			return
+		}
 		r := strings.ToLower(resource)
 		if resource == "*" {
 			// Collect all supported resources
-			t.collectCLB(account, region)
-			t.collectBWP(account, region)
-			t.collectCOS(account, region)
+			t.collectCLB(ctx, account, region)
+			t.collectBWP(ctx, account, region)
+			t.collectCOS(ctx, account, region)
 		} else {
 			switch r {
 			case "clb":
-				t.collectCLB(account, region)
+				t.collectCLB(ctx, account, region)
 			case "bwp":
-				t.collectBWP(account, region)
+				t.collectBWP(ctx, account, region)
 			case "s3":
-				t.collectCOS(account, region)
+				t.collectCOS(ctx, account, region)
 			case "cos":
-				t.collectCOS(account, region)
+				t.collectCOS(ctx, account, region)
 			case "gwlb":
-				t.collectGWLB(account, region)
+				t.collectGWLB(ctx, account, region)
 			default:
 				ctxLog := logger.NewContextLogger("Tencent", "account_id", account.AccountID, "region", region, "resource_type", resource)
 				ctxLog.Warnf("资源类型尚未实现")
@@ -207,7 +189,7 @@ func (t *Collector) collectRegion(account config.CloudAccount, region string) {
 	}
 }
 
-func (t *Collector) collectCLB(account config.CloudAccount, region string) {
+func (t *Collector) collectCLB(ctx context.Context, account config.CloudAccount, region string) {
 	if t.cfg == nil {
 		return
 	}
@@ -234,15 +216,18 @@ func (t *Collector) collectCLB(account config.CloudAccount, region string) {
 			ctxLog.Debugf("产品跳过（分片不匹配）")
 			continue
 		}
-		vips := t.listCLBVips(account, region)
-		if len(vips) == 0 {
+		if !t.shouldScrapeProduct(ctx, account, region, p) {
 			continue
 		}
-		t.fetchCLBMonitor(account, region, p, vips)
+		target := providerscommon.StartTarget(ctx, "tencent", account.AccountID, region, p.Namespace)
+		if vips := t.listCLBVips(ctx, account, region); len(vips) > 0 {
+			t.fetchCLBMonitor(ctx, account, region, p, vips)
+		}
+		t.scheduler.Finished("tencent", account.AccountID, region, p.Namespace, target.Finish())
 	}
 }
 
-func (t *Collector) collectBWP(account config.CloudAccount, region string) {
+func (t *Collector) collectBWP(ctx context.Context, account config.CloudAccount, region string) {
 	if t.cfg == nil {
 		return
 	}
@@ -269,11 +254,17 @@ func (t *Collector) collectBWP(account config.CloudAccount, region string) {
 			ctxLog.Debugf("产品跳过（分片不匹配）")
 			continue
 		}
-		ids := t.listBWPIDs(account, region)
+		if !t.shouldScrapeProduct(ctx, account, region, p) {
+			continue
+		}
+		target := providerscommon.StartTarget(ctx, "tencent", account.AccountID, region, p.Namespace)
+		ids := t.listBWPIDs(ctx, account, region)
 		if len(ids) == 0 {
+			t.scheduler.Finished("tencent", account.AccountID, region, p.Namespace, target.Finish())
 			return
 		}
-		t.fetchBWPMonitor(account, region, p, ids)
+		t.fetchBWPMonitor(ctx, account, region, p, ids)
+		t.scheduler.Finished("tencent", account.AccountID, region, p.Namespace, target.Finish())
 	}
 }
 
@@ -282,10 +273,8 @@ func (t *Collector) cacheKey(account config.CloudAccount, region, namespace, rty
 }
 
 func (t *Collector) getCachedIDs(account config.CloudAccount, region, namespace, rtype string) ([]string, bool) {
-	t.cacheMu.RLock()
-	entry, ok := t.resCache[t.cacheKey(account, region, namespace, rtype)]
-	t.cacheMu.RUnlock()
-	if !ok || len(entry.IDs) == 0 {
+	var entry resCacheEntry
+	if !t.resCache.Get(t.cacheKey(account, region, namespace, rtype), &entry) || len(entry.IDs) == 0 {
 		return nil, false
 	}
 	ttlDur := time.Hour
@@ -309,9 +298,8 @@ func (t *Collector) getCachedIDs(account config.CloudAccount, region, namespace,
 }
 
 func (t *Collector) setCachedIDs(account config.CloudAccount, region, namespace, rtype string, ids []string) {
-	t.cacheMu.Lock()
-	t.resCache[t.cacheKey(account, region, namespace, rtype)] = resCacheEntry{IDs: ids, UpdatedAt: time.Now()}
-	t.cacheMu.Unlock()
+	// 条目不设过期时间：超过 discovery_ttl 后不再用于采集，但仍作为资源清单的最近一次结果
+	_ = t.resCache.Set(t.cacheKey(account, region, namespace, rtype), resCacheEntry{IDs: ids, UpdatedAt: time.Now()}, 0)
 }
 
 var (
@@ -325,41 +313,102 @@ var (
 		}
 		req := monitor.NewDescribeBaseMetricsRequest()
 		req.Namespace = common.StringPtr(namespace)
-		start := time.Now()
-		var resp *monitor.DescribeBaseMetricsResponse
-		var callErr error
-		for attempt := 0; attempt < 3; attempt++ {
-			resp, callErr = client.DescribeBaseMetrics(req)
-			if callErr == nil && resp != nil && resp.Response != nil {
-				metrics.RequestTotal.WithLabelValues("tencent", "DescribeBaseMetrics", "success").Inc()
-				metrics.RequestDuration.WithLabelValues("tencent", "DescribeBaseMetrics").Observe(time.Since(start).Seconds())
-				metrics.RecordRequest("tencent", "DescribeBaseMetrics", "success")
-				break
+		resp, err := client.DescribeBaseMetrics(req)
+		if err != nil || resp == nil || resp.Response == nil {
+			return nil, err
+		}
+		return json.Marshal(resp.Response)
+	}
+)
+
+// filterResources 按账号过滤规则过滤枚举结果，返回保留的资源 ID
+// rtype 为资源类型（clb/bwp/cos/gwlb），tagsOf 用于规则依赖标签而枚举结果不含标签时补充，可为 nil
+func (t *Collector) filterResources(account config.CloudAccount, region, rtype string, items []providerscommon.ResourceInfo, tagsOf func([]string) map[string]map[string]string) []string {
+	aliases := []string{rtype}
+	switch rtype {
+	case "cos":
+		aliases = []string{"s3", "cos"}
+	case "clb":
+		aliases = []string{"clb", "lb"}
+	}
+	// 配置了 tag_labels 时预先补充标签，供指标的自定义标签使用
+	if providerscommon.NeedsTagLabels(t.cfg) && tagsOf != nil {
+		var missing []string
+		for _, it := range items {
+			if it.Tags == nil {
+				missing = append(missing, it.ID)
 			}
-			if callErr != nil {
-				status := providerscommon.ClassifyTencentError(callErr)
-				metrics.RequestTotal.WithLabelValues("tencent", "DescribeBaseMetrics", status).Inc()
-				metrics.RecordRequest("tencent", "DescribeBaseMetrics", status)
-				if status == "limit_error" {
-					// 记录限流指标
-					metrics.RateLimitTotal.WithLabelValues("tencent", "DescribeBaseMetrics").Inc()
-				}
-				if status == "auth_error" {
-					return nil, callErr
+		}
+		if len(missing) > 0 {
+			tags := tagsOf(missing)
+			for i := range items {
+				if items[i].Tags == nil {
+					items[i].Tags = tags[items[i].ID]
 				}
-				time.Sleep(time.Duration(200*(attempt+1)) * time.Millisecond)
-			} else {
-				return nil, callErr
 			}
 		}
-		if callErr != nil || resp == nil || resp.Response == nil {
-			return nil, callErr
-		}
-		return json.Marshal(resp.Response)
 	}
-)
+	kept := providerscommon.FilterResources(providerscommon.NewResourceFilter(t.cfg, account, aliases...), items, tagsOf)
+	ids := make([]string, 0, len(kept))
+	byID := make(map[string]providerscommon.ResourceInfo, len(kept))
+	for _, it := range kept {
+		ids = append(ids, it.ID)
+		byID[it.ID] = it
+	}
+	t.resInfoMu.Lock()
+	if t.resInfo == nil {
+		t.resInfo = make(map[string]map[string]providerscommon.ResourceInfo)
+	}
+	t.resInfo[account.AccountID+":"+region+":"+rtype] = byID
+	t.resInfoMu.Unlock()
+	if len(ids) != len(items) {
+		ctxLog := logger.NewContextLogger("Tencent", "account_id", account.AccountID, "region", region, "rtype", rtype)
+		ctxLog.Debugf("资源过滤完成 枚举=%d 保留=%d", len(items), len(ids))
+	}
+	return ids
+}
 
-func minPeriodForMetric(region string, account config.CloudAccount, namespace, metric string, periodFallback int64) int64 {
+// resourceInfo 返回最近一次枚举时记录的资源信息（缓存命中的采集轮次复用）
+func (t *Collector) resourceInfo(account config.CloudAccount, region, rtype, rid string) providerscommon.ResourceInfo {
+	t.resInfoMu.RLock()
+	defer t.resInfoMu.RUnlock()
+	if info, ok := t.resInfo[account.AccountID+":"+region+":"+rtype][rid]; ok {
+		return info
+	}
+	return providerscommon.ResourceInfo{ID: rid}
+}
+
+// customLabels 返回资源的自定义标签值（静态标签 + tag_labels 命中的资源标签）
+func (t *Collector) customLabels(account config.CloudAccount, region, rtype, rid string) map[string]string {
+	return providerscommon.CustomLabels(t.cfg, account, t.resourceInfo(account, region, rtype, rid).Tags)
+}
+
+// codeName 按 code_name 解析链返回资源的 code_name
+func (t *Collector) codeName(account config.CloudAccount, region, rtype, rid string) string {
+	info := t.resourceInfo(account, region, rtype, rid)
+	return providerscommon.ResolveCodeName(t.cfg, providerscommon.DefaultCodeNameChain, info.Tags, info.Name, rid)
+}
+
+// shouldScrapeProduct 判断产品在本轮是否到期
+// 周期优先取显式配置与产品 Period，未配置时使用 DescribeBaseMetrics 返回的最小周期
+func (t *Collector) shouldScrapeProduct(ctx context.Context, account config.CloudAccount, region string, p config.Product) bool {
+	fallback := int64(60)
+	if server := t.cfg.GetServer(); server != nil && server.PeriodFallback > 0 {
+		fallback = int64(server.PeriodFallback)
+	}
+	interval := providerscommon.ResolveProductInterval(t.cfg, "tencent", p, func(metric string) int {
+		return int(minPeriodForMetric(ctx, region, account, p.Namespace, metric, fallback))
+	})
+	if !t.scheduler.ShouldScrape("tencent", account.AccountID, region, p.Namespace, interval) {
+		ctxLog := logger.NewContextLogger("Tencent", "account_id", account.AccountID, "region", region, "namespace", p.Namespace)
+		ctxLog.Debugf("产品跳过（采集周期未到期，周期=%v）", interval)
+		return false
+	}
+	// 区域级跳过：该命名空间在本区域连续多轮枚举为空，等待重新发现
+	return !providerscommon.SkipEmptyRegion(t.regionManager, "tencent", account.AccountID, region, p.Namespace)
+}
+
+func minPeriodForMetric(ctx context.Context, region string, account config.CloudAccount, namespace, metric string, periodFallback int64) int64 {
 	key := namespace + "|" + metric
 	periodMu.RLock()
 	if v, ok := periodCache[key]; ok && v > 0 {
@@ -367,10 +416,13 @@ func minPeriodForMetric(region string, account config.CloudAccount, namespace, m
 		return v
 	}
 	periodMu.RUnlock()
-	bs, err := describeBaseMetricsJSON(region, account.AccessKeyID, account.AccessKeySecret, namespace)
+	bs, err := providerscommon.Call(ctx, providerscommon.CallOptions{
+		Provider: "tencent", AccountID: account.AccountID, Region: region, API: "DescribeBaseMetrics",
+		Attempts: 3,
+	}, func() ([]byte, error) {
+		return describeBaseMetricsJSON(region, account.AccessKeyID, account.AccessKeySecret, namespace)
+	})
 	if err != nil {
-		status := providerscommon.ClassifyTencentError(err)
-		metrics.RequestTotal.WithLabelValues("tencent", "DescribeBaseMetrics", status).Inc()
 		return 60
 	}
 	var jr struct {
@@ -441,3 +493,11 @@ func minPeriodForMetric(region string, account config.CloudAccount, namespace, m
 	periodMu.Unlock()
 	return min
 }
+
+// stringValue 安全解引用 SDK 返回的字符串指针
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
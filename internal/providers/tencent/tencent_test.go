@@ -54,7 +54,7 @@ func TestGetAllRegions(t *testing.T) {
 		},
 	}
 
-	regions := collector.getAllRegions(account)
+	regions := collector.getAllRegions(context.Background(), account)
 	assert.Equal(t, []string{"ap-beijing", "ap-shanghai"}, regions)
 
 	// Case 2: Error (Fallback to default)
@@ -63,7 +63,7 @@ func TestGetAllRegions(t *testing.T) {
 			return nil, fmt.Errorf("api error")
 		},
 	}
-	regions = collector.getAllRegions(account)
+	regions = collector.getAllRegions(context.Background(), account)
 	assert.Equal(t, []string{"ap-guangzhou"}, regions)
 
 	// Case 3: Error with ENV
@@ -71,7 +71,7 @@ func TestGetAllRegions(t *testing.T) {
 		t.Fatal(err)
 	}
 	defer func() { _ = os.Unsetenv("DEFAULT_REGIONS") }()
-	regions = collector.getAllRegions(account)
+	regions = collector.getAllRegions(context.Background(), account)
 	assert.Equal(t, []string{"ap-nanjing", "ap-chengdu"}, regions)
 
 	// Case 4: Empty response
@@ -84,14 +84,14 @@ func TestGetAllRegions(t *testing.T) {
 			return resp, nil
 		},
 	}
-	regions = collector.getAllRegions(account)
+	regions = collector.getAllRegions(context.Background(), account)
 	assert.Equal(t, []string{"ap-guangzhou"}, regions)
 
 	// Case 5: Client creation failure
 	// To simulate this, we can make the factory return an error for NewCVMClient
 	// But our mock factory checks for nil. So let's set cvm to nil.
 	factory.cvm = nil
-	regions = collector.getAllRegions(account)
+	regions = collector.getAllRegions(context.Background(), account)
 	assert.Equal(t, []string{"ap-guangzhou"}, regions)
 }
 
@@ -143,16 +143,16 @@ func TestCollectRegion(t *testing.T) {
 	// We can't easily replace the func with a generic one.
 	// But we can check if it triggers the logic.
 
-	collector.collectRegion(account, "ap-beijing")
+	collector.collectRegion(context.Background(), account, "ap-beijing")
 	assert.True(t, calledCLB)
 
 	// Case 2: Wildcard resource
 	account.Resources = []string{"*"}
-	collector.collectRegion(account, "ap-beijing")
+	collector.collectRegion(context.Background(), account, "ap-beijing")
 
 	// Case 3: Unknown resource
 	account.Resources = []string{"unknown_service"}
-	collector.collectRegion(account, "ap-beijing")
+	collector.collectRegion(context.Background(), account, "ap-beijing")
 }
 
 func TestCollect(t *testing.T) {
@@ -178,10 +178,10 @@ func TestCollect(t *testing.T) {
 	}
 
 	// Just ensure no panic
-	collector.Collect(account)
+	collector.Collect(context.Background(), account)
 
 	account.Regions = []string{"*"}
-	collector.Collect(account)
+	collector.Collect(context.Background(), account)
 }
 
 func TestCollectRegion_MoreResources(t *testing.T) {
@@ -217,7 +217,7 @@ func TestCollectRegion_MoreResources(t *testing.T) {
 	resources := []string{"clb", "bwp", "s3"}
 	for _, res := range resources {
 		account.Resources = []string{res}
-		collector.collectRegion(account, "ap-beijing")
+		collector.collectRegion(context.Background(), account, "ap-beijing")
 	}
 }
 
@@ -311,19 +311,19 @@ func TestMinPeriodForMetric(t *testing.T) {
 
 	account := config.CloudAccount{}
 	// Test Cache Miss -> Hit
-	p := minPeriodForMetric("ap-guangzhou", account, "ns", "CPUUsage", 60)
+	p := minPeriodForMetric(context.Background(), "ap-guangzhou", account, "ns", "CPUUsage", 60)
 	assert.Equal(t, int64(60), p)
 
 	// Test Cache Hit
-	p = minPeriodForMetric("ap-guangzhou", account, "ns", "CPUUsage", 60)
+	p = minPeriodForMetric(context.Background(), "ap-guangzhou", account, "ns", "CPUUsage", 60)
 	assert.Equal(t, int64(60), p)
 
 	// Test Periods array (min of 10, 60, 300 -> 10)
-	p = minPeriodForMetric("ap-guangzhou", account, "ns", "MemUsage", 60)
+	p = minPeriodForMetric(context.Background(), "ap-guangzhou", account, "ns", "MemUsage", 60)
 	assert.Equal(t, int64(10), p)
 
 	// Test Periods string array
-	p = minPeriodForMetric("ap-guangzhou", account, "ns", "DiskUsage", 60)
+	p = minPeriodForMetric(context.Background(), "ap-guangzhou", account, "ns", "DiskUsage", 60)
 	assert.Equal(t, int64(60), p)
 
 	// Case 2: API Error
@@ -336,6 +336,6 @@ func TestMinPeriodForMetric(t *testing.T) {
 	delete(periodCache, key)
 	periodMu.Unlock()
 
-	p = minPeriodForMetric("ap-guangzhou", account, "ns", "Unknown", 60)
+	p = minPeriodForMetric(context.Background(), "ap-guangzhou", account, "ns", "Unknown", 60)
 	assert.Equal(t, int64(60), p) // Default fallback
 }
@@ -0,0 +1,499 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"multicloud-exporter/internal/logger"
+	"multicloud-exporter/internal/metrics"
+)
+
+// 成员管理默认参数
+const (
+	DefaultHeartbeatInterval = 5 * time.Second
+	DefaultGracePeriod       = time.Minute
+	// 心跳超时默认为心跳间隔的 3 倍
+	defaultFailureIntervals = 3
+)
+
+// HeartbeatPath 成员间心跳的 HTTP 路径
+const HeartbeatPath = "/cluster/heartbeat"
+
+// MembershipOptions 心跳成员管理参数
+type MembershipOptions struct {
+	// Self 本实例的成员 ID（如 Pod 名），集群内唯一；滚动更新时重建的实例沿用同一 ID
+	Self string
+	// Addr 本实例的心跳地址 host:port，随心跳传播给其他成员
+	Addr string
+	// Seeds 返回种子地址（host:port），每轮心跳调用；成员地址还会通过心跳互相传播
+	Seeds func() []string
+	// Token 心跳共享令牌，请求与校验 Authorization: Bearer；为空时 Handler 拒绝全部心跳
+	Token string
+	// HeartbeatInterval 心跳间隔，默认 5s
+	HeartbeatInterval time.Duration
+	// FailureTimeout 超过该时长未收到对端心跳即视为离开，默认 3 倍心跳间隔
+	FailureTimeout time.Duration
+	// GracePeriod 候选视图需保持不变的时长，之后才替换生效视图并迁移键归属，默认 1m
+	GracePeriod time.Duration
+	// Client 发送心跳的 HTTP 客户端，默认超时为心跳间隔
+	Client *http.Client
+}
+
+// PeerStatus 本实例观察到的对端状态
+type PeerStatus struct {
+	ID       string    `json:"id"`
+	Addr     string    `json:"addr"`
+	Alive    bool      `json:"alive"`
+	LastSeen time.Time `json:"last_seen,omitempty"`
+}
+
+// MembershipStatus 成员视图快照
+type MembershipStatus struct {
+	Self       string   `json:"self"`
+	Generation uint64   `json:"generation"`
+	Members    []string `json:"members"`
+	Index      int      `json:"index"`
+	// Pending 与生效视图不同的候选视图，PendingSince 起保持 GracePeriod 后生效
+	Pending      []string     `json:"pending,omitempty"`
+	PendingSince *time.Time   `json:"pending_since,omitempty"`
+	GracePeriod  string       `json:"grace_period"`
+	Peers        []PeerStatus `json:"peers"`
+}
+
+// heartbeat 心跳请求与响应：发送方身份、生效视图及其已知的存活成员
+type heartbeat struct {
+	ID         string          `json:"id"`
+	Addr       string          `json:"addr"`
+	Generation uint64          `json:"generation"`
+	View       []string        `json:"view"`
+	Members    []heartbeatPeer `json:"members,omitempty"`
+}
+
+type heartbeatPeer struct {
+	ID   string `json:"id"`
+	Addr string `json:"addr"`
+}
+
+// peer 对端成员：lastSeen 为最近一次直接收到其心跳的时间，learned 为经其他成员得知其地址的时间
+type peer struct {
+	addr     string
+	lastSeen time.Time
+	learned  time.Time
+}
+
+// Membership 基于 HTTP 心跳的成员管理：实例间周期性互发心跳并传播已知成员，
+// 存活成员（含本实例）排序后构成候选视图；候选视图保持 GracePeriod 不变后才成为生效视图并递增代数，
+// 滚动更新中短暂重启的实例不会引起键归属迁移。对端已生效且与本实例候选视图相同的更高代数视图会被直接采用，
+// 使各实例尽快收敛到同一视图与代数。
+type Membership struct {
+	opts MembershipOptions
+	now  func() time.Time
+
+	mu           sync.Mutex
+	peers        map[string]*peer
+	view         []string
+	generation   uint64
+	pending      []string
+	pendingSince time.Time
+	// peerView 对端心跳中代数最高的生效视图
+	peerView []string
+	peerGen  uint64
+	// active 是否为 ClusterConfig 使用的成员管理（更新指标与哈希环）
+	active bool
+}
+
+// NewMembership 创建成员管理，未设置的参数使用默认值；Start 或 Tick 之前视图为空
+func NewMembership(opts MembershipOptions) *Membership {
+	if opts.HeartbeatInterval <= 0 {
+		opts.HeartbeatInterval = DefaultHeartbeatInterval
+	}
+	if opts.FailureTimeout <= 0 {
+		opts.FailureTimeout = defaultFailureIntervals * opts.HeartbeatInterval
+	}
+	if opts.GracePeriod < 0 {
+		opts.GracePeriod = 0
+	}
+	if opts.Client == nil {
+		// 心跳在集群内网进行，不走 HTTP_PROXY
+		opts.Client = &http.Client{Timeout: opts.HeartbeatInterval}
+	}
+	return &Membership{opts: opts, now: time.Now, peers: make(map[string]*peer)}
+}
+
+var (
+	activeMu         sync.RWMutex
+	activeMembership *Membership
+)
+
+// UseMembership 让 ClusterConfig 使用 m 的生效视图（nil 恢复 DNS/文件/环境变量发现），
+// 哈希环以成员 ID 建环，成员指标由 m 更新
+func UseMembership(m *Membership) {
+	activeMu.Lock()
+	prev := activeMembership
+	activeMembership = m
+	activeMu.Unlock()
+	if prev != nil && prev != m {
+		prev.mu.Lock()
+		prev.active = false
+		prev.mu.Unlock()
+	}
+	if m == nil {
+		SetShardMembers(nil)
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.active = true
+	if len(m.view) > 0 {
+		SetShardMembers(m.view)
+		m.updateMetricsLocked(nil)
+	}
+}
+
+// ActiveMembership 返回 ClusterConfig 使用的成员管理，未启用时返回 nil
+func ActiveMembership() *Membership {
+	activeMu.RLock()
+	defer activeMu.RUnlock()
+	return activeMembership
+}
+
+// Start 立即进行一轮心跳并确定初始视图，之后按心跳间隔运行直到 ctx 结束
+func (m *Membership) Start(ctx context.Context) {
+	m.Tick(ctx)
+	go func() {
+		ticker := time.NewTicker(m.opts.HeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.Tick(ctx)
+			}
+		}
+	}()
+}
+
+// Tick 向种子与已知成员发送一轮心跳，然后重新计算视图
+func (m *Membership) Tick(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, addr := range m.targets() {
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+			if hb, err := m.send(ctx, addr); err == nil {
+				m.observe(hb)
+			}
+		}(addr)
+	}
+	wg.Wait()
+	m.evaluate()
+}
+
+// targets 返回本轮心跳目标地址（去重，不含本实例）
+func (m *Membership) targets() []string {
+	seen := map[string]bool{m.opts.Addr: true, "": true}
+	var out []string
+	add := func(addr string) {
+		if !seen[addr] {
+			seen[addr] = true
+			out = append(out, addr)
+		}
+	}
+	if m.opts.Seeds != nil {
+		for _, addr := range m.opts.Seeds() {
+			add(addr)
+		}
+	}
+	m.mu.Lock()
+	for _, p := range m.peers {
+		add(p.addr)
+	}
+	m.mu.Unlock()
+	sort.Strings(out)
+	return out
+}
+
+func (m *Membership) send(ctx context.Context, addr string) (heartbeat, error) {
+	var hb heartbeat
+	body, err := json.Marshal(m.local())
+	if err != nil {
+		return hb, err
+	}
+	url := addr
+	if !strings.Contains(url, "://") {
+		url = "http://" + addr
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(url, "/")+HeartbeatPath, bytes.NewReader(body))
+	if err != nil {
+		return hb, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if m.opts.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+m.opts.Token)
+	}
+	resp, err := m.opts.Client.Do(req)
+	if err != nil {
+		return hb, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return hb, fmt.Errorf("heartbeat %s: status %d", addr, resp.StatusCode)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&hb)
+	return hb, err
+}
+
+// local 本实例的心跳内容：只传播存活成员，已离开的成员不会被其他实例重新学习
+func (m *Membership) local() heartbeat {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	hb := heartbeat{ID: m.opts.Self, Addr: m.opts.Addr, Generation: m.generation, View: append([]string(nil), m.view...)}
+	for id, p := range m.peers {
+		if m.aliveLocked(p, now) {
+			hb.Members = append(hb.Members, heartbeatPeer{ID: id, Addr: p.addr})
+		}
+	}
+	sort.Slice(hb.Members, func(i, j int) bool { return hb.Members[i].ID < hb.Members[j].ID })
+	return hb
+}
+
+// observe 记录直接收到的心跳（请求或响应）
+func (m *Membership) observe(hb heartbeat) {
+	if hb.ID == "" || hb.ID == m.opts.Self {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	p := m.peers[hb.ID]
+	if p == nil {
+		p = &peer{}
+		m.peers[hb.ID] = p
+	}
+	if hb.Addr != "" {
+		p.addr = hb.Addr
+	}
+	p.lastSeen = now
+	for _, g := range hb.Members {
+		if g.ID == "" || g.ID == m.opts.Self || g.Addr == "" {
+			continue
+		}
+		if q, ok := m.peers[g.ID]; !ok {
+			m.peers[g.ID] = &peer{addr: g.Addr, learned: now}
+		} else if q.addr == "" {
+			q.addr = g.Addr
+		}
+	}
+	if hb.Generation > m.peerGen && len(hb.View) > 0 {
+		m.peerGen = hb.Generation
+		m.peerView = append([]string(nil), hb.View...)
+	}
+}
+
+func (m *Membership) aliveLocked(p *peer, now time.Time) bool {
+	return !p.lastSeen.IsZero() && now.Sub(p.lastSeen) <= m.opts.FailureTimeout
+}
+
+// candidateLocked 存活成员（含本实例）按 ID 排序
+func (m *Membership) candidateLocked(now time.Time) []string {
+	out := []string{m.opts.Self}
+	for id, p := range m.peers {
+		if m.aliveLocked(p, now) {
+			out = append(out, id)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// evaluate 计算候选视图并按宽限期决定是否替换生效视图
+func (m *Membership) evaluate() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	cand := m.candidateLocked(now)
+
+	switch {
+	case m.generation == 0:
+		// 初始视图：优先沿用对端已生效的视图（可能尚不含本实例，此时本实例在宽限期内不负责任何键）
+		if len(m.peerView) > 0 {
+			m.adoptLocked(m.peerView, m.peerGen)
+		} else {
+			m.adoptLocked(cand, 1)
+		}
+	case m.peerGen > m.generation && equalStrings(m.peerView, cand):
+		// 对端已在宽限期后采用了相同的视图
+		m.adoptLocked(cand, m.peerGen)
+	case equalStrings(cand, m.view):
+		m.pending = nil
+	case !equalStrings(cand, m.pending):
+		m.pending, m.pendingSince = cand, now
+	}
+	if m.pending != nil && now.Sub(m.pendingSince) >= m.opts.GracePeriod {
+		gen := m.generation
+		if m.peerGen > gen {
+			gen = m.peerGen
+		}
+		m.adoptLocked(m.pending, gen+1)
+	}
+
+	// 清理离开超过 FailureTimeout+GracePeriod 且不在视图中的成员
+	expire := m.opts.FailureTimeout + m.opts.GracePeriod
+	for id, p := range m.peers {
+		last := p.lastSeen
+		if p.learned.After(last) {
+			last = p.learned
+		}
+		if now.Sub(last) > expire && !containsString(m.view, id) && !containsString(m.pending, id) {
+			delete(m.peers, id)
+			if m.active {
+				metrics.ClusterPeerUp.DeleteLabelValues(id)
+			}
+		}
+	}
+	if m.active {
+		for id, p := range m.peers {
+			up := 0.0
+			if m.aliveLocked(p, now) {
+				up = 1
+			}
+			metrics.ClusterPeerUp.WithLabelValues(id).Set(up)
+		}
+	}
+}
+
+// adoptLocked 替换生效视图，调用方持有 m.mu
+func (m *Membership) adoptLocked(view []string, gen uint64) {
+	prev := m.view
+	changed := !equalStrings(prev, view)
+	m.view = append([]string(nil), view...)
+	m.generation = gen
+	m.pending = nil
+	if !m.active {
+		return
+	}
+	if changed {
+		SetShardMembers(m.view)
+		ctxLog := logger.NewContextLogger("Cluster", "resource_type", "Membership")
+		ctxLog.Infof("集群成员视图更新: generation=%d members=%v", gen, m.view)
+	}
+	m.updateMetricsLocked(prev)
+}
+
+func (m *Membership) updateMetricsLocked(prev []string) {
+	metrics.ClusterMembers.Set(float64(len(m.view)))
+	metrics.ClusterGeneration.Set(float64(m.generation))
+	if prev == nil {
+		return
+	}
+	for _, id := range m.view {
+		if !containsString(prev, id) {
+			metrics.ClusterMembershipChanges.WithLabelValues("join").Inc()
+		}
+	}
+	for _, id := range prev {
+		if !containsString(m.view, id) {
+			metrics.ClusterMembershipChanges.WithLabelValues("leave").Inc()
+		}
+	}
+}
+
+// ClusterConfig 返回生效视图的成员数与本实例序号，本实例不在视图中时序号为 -1
+func (m *Membership) ClusterConfig() (int, int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.view) == 0 {
+		return 1, 0
+	}
+	return len(m.view), indexOfString(m.view, m.opts.Self)
+}
+
+// Members 返回生效视图（排序后的成员 ID）与代数
+func (m *Membership) Members() ([]string, uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.view...), m.generation
+}
+
+// Status 返回成员视图快照
+func (m *Membership) Status() MembershipStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	st := MembershipStatus{
+		Self:        m.opts.Self,
+		Generation:  m.generation,
+		Members:     append([]string(nil), m.view...),
+		Index:       indexOfString(m.view, m.opts.Self),
+		GracePeriod: m.opts.GracePeriod.String(),
+		Peers:       make([]PeerStatus, 0, len(m.peers)),
+	}
+	if m.pending != nil {
+		since := m.pendingSince
+		st.Pending, st.PendingSince = append([]string(nil), m.pending...), &since
+	}
+	for id, p := range m.peers {
+		st.Peers = append(st.Peers, PeerStatus{ID: id, Addr: p.addr, Alive: m.aliveLocked(p, now), LastSeen: p.lastSeen})
+	}
+	sort.Slice(st.Peers, func(i, j int) bool { return st.Peers[i].ID < st.Peers[j].ID })
+	return st
+}
+
+// Handler 处理对端心跳：记录发送方并返回本实例的心跳内容
+func (m *Membership) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		// 未配置令牌时拒绝全部心跳：伪造的成员会加入视图并分走哈希环上的键
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if m.opts.Token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(m.opts.Token)) != 1 {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		var hb heartbeat
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&hb); err != nil {
+			http.Error(w, "invalid heartbeat", http.StatusBadRequest)
+			return
+		}
+		m.observe(hb)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(m.local())
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(list []string, s string) bool {
+	return indexOfString(list, s) >= 0
+}
+
+func indexOfString(list []string, s string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
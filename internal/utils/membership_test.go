@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"multicloud-exporter/internal/metrics"
+)
+
+// fakeClock 多个实例共享的可调时钟
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.t = c.t.Add(d)
+	c.mu.Unlock()
+}
+
+// testMember 通过 httptest 服务器暴露心跳端点的进程内实例
+type testMember struct {
+	m   *Membership
+	srv *httptest.Server
+}
+
+func newTestCluster(t *testing.T, clock *fakeClock, ids ...string) []*testMember {
+	t.Helper()
+	var seeds []string
+	members := make([]*testMember, len(ids))
+	for i, id := range ids {
+		mux := http.NewServeMux()
+		srv := httptest.NewServer(mux)
+		t.Cleanup(srv.Close)
+		addr := strings.TrimPrefix(srv.URL, "http://")
+		m := NewMembership(MembershipOptions{
+			Self:              id,
+			Addr:              addr,
+			Seeds:             func() []string { return seeds },
+			Token:             "secret",
+			HeartbeatInterval: time.Second,
+			GracePeriod:       30 * time.Second,
+		})
+		m.now = clock.Now
+		mux.HandleFunc(HeartbeatPath, m.Handler())
+		members[i] = &testMember{m: m, srv: srv}
+		seeds = append(seeds, addr)
+	}
+	return members
+}
+
+func tickAll(ctx context.Context, members []*testMember) {
+	for _, tm := range members {
+		tm.m.Tick(ctx)
+	}
+}
+
+func TestMembership_ConvergesAndGracePeriod(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{t: time.Unix(1700000000, 0)}
+	members := newTestCluster(t, clock, "pod-c", "pod-a", "pod-b")
+	UseMembership(members[1].m)
+	defer UseMembership(nil)
+
+	tickAll(ctx, members)
+	tickAll(ctx, members)
+	// 首轮之后的实例沿用对端的初始视图，候选视图变化需等待宽限期
+	clock.Advance(31 * time.Second)
+	for i := 0; i < 3; i++ {
+		clock.Advance(time.Second)
+		tickAll(ctx, members)
+	}
+
+	want := []string{"pod-a", "pod-b", "pod-c"}
+	view, gen := members[0].m.Members()
+	for _, tm := range members {
+		v, g := tm.m.Members()
+		if !equalStrings(v, want) || g != gen {
+			t.Fatalf("%s: view %v gen %d, want %v gen %d", tm.m.opts.Self, v, g, want, gen)
+		}
+	}
+	if !equalStrings(view, want) {
+		t.Fatalf("view = %v", view)
+	}
+	if total, index := ClusterConfig(); total != 3 || index != 0 {
+		t.Fatalf("ClusterConfig() = (%d, %d), want (3, 0)", total, index)
+	}
+	if got := testutil.ToFloat64(metrics.ClusterMembers); got != 3 {
+		t.Fatalf("cluster members metric = %v", got)
+	}
+
+	// pod-c 停止：超过心跳超时但未到宽限期时视图不变
+	members[0].srv.Close()
+	alive := members[1:]
+	leaves := testutil.ToFloat64(metrics.ClusterMembershipChanges.WithLabelValues("leave"))
+	for i := 0; i < 10; i++ {
+		clock.Advance(time.Second)
+		tickAll(ctx, alive)
+	}
+	for _, tm := range alive {
+		if v, g := tm.m.Members(); !equalStrings(v, want) || g != gen {
+			t.Fatalf("%s changed view within grace period: %v gen %d", tm.m.opts.Self, v, g)
+		}
+		if st := tm.m.Status(); len(st.Pending) != 2 {
+			t.Fatalf("%s pending = %v", tm.m.opts.Self, st.Pending)
+		}
+	}
+
+	clock.Advance(30 * time.Second)
+	tickAll(ctx, alive)
+	want = []string{"pod-a", "pod-b"}
+	for _, tm := range alive {
+		if v, g := tm.m.Members(); !equalStrings(v, want) || g <= gen {
+			t.Fatalf("%s: view %v gen %d after grace period", tm.m.opts.Self, v, g)
+		}
+	}
+	if got := testutil.ToFloat64(metrics.ClusterMembershipChanges.WithLabelValues("leave")); got != leaves+1 {
+		t.Fatalf("leave changes = %v, want %v", got, leaves+1)
+	}
+	if total, index := ClusterConfig(); total != 2 || index != 0 {
+		t.Fatalf("ClusterConfig() = (%d, %d), want (2, 0)", total, index)
+	}
+}
+
+func TestMembership_RestartWithinGracePeriod(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{t: time.Unix(1700000000, 0)}
+	members := newTestCluster(t, clock, "pod-a", "pod-b")
+	for i := 0; i < 3; i++ {
+		tickAll(ctx, members)
+		clock.Advance(31 * time.Second)
+	}
+	tickAll(ctx, members)
+	before, gen := members[0].m.Members()
+	if len(before) != 2 {
+		t.Fatalf("view = %v", before)
+	}
+
+	// pod-b 以同一 ID 重启：在宽限期内重新加入，视图与代数不变，新实例直接沿用对端视图
+	restarted := NewMembership(MembershipOptions{Self: "pod-b", Addr: members[1].m.opts.Addr, Token: "secret", GracePeriod: 30 * time.Second})
+	restarted.now = clock.Now
+	restarted.opts.Seeds = members[0].m.opts.Seeds
+	members[1].m = restarted
+	members[1].srv.Config.Handler = restarted.Handler()
+	clock.Advance(10 * time.Second)
+	tickAll(ctx, members)
+	tickAll(ctx, members)
+	for _, tm := range members {
+		if v, g := tm.m.Members(); !equalStrings(v, before) || g != gen {
+			t.Fatalf("%s: view %v gen %d, want %v gen %d", tm.m.opts.Self, v, g, before, gen)
+		}
+	}
+}
+
+func TestMembership_HandlerRejectsBadToken(t *testing.T) {
+	m := NewMembership(MembershipOptions{Self: "a", Token: "secret"})
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, HeartbeatPath, strings.NewReader(`{"id":"b"}`))
+	req.Header.Set("Authorization", "Bearer wrong")
+	m.Handler()(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if st := m.Status(); len(st.Peers) != 0 {
+		t.Fatalf("rejected heartbeat recorded peer: %+v", st.Peers)
+	}
+
+	// 未配置令牌时拒绝全部心跳
+	open := NewMembership(MembershipOptions{Self: "a"})
+	rec = httptest.NewRecorder()
+	open.Handler()(rec, httptest.NewRequest(http.MethodPost, HeartbeatPath, strings.NewReader(`{"id":"b"}`)))
+	if rec.Code != http.StatusForbidden || len(open.Status().Peers) != 0 {
+		t.Fatalf("heartbeat without configured token accepted: status = %d", rec.Code)
+	}
+}
+
+func TestShouldProcess_NotInView(t *testing.T) {
+	if ShouldProcess("any", 1, -1) {
+		t.Error("ShouldProcess should be false when this instance is not in the member view")
+	}
+}
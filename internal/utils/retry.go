@@ -12,6 +12,8 @@ type RetryConfig struct {
 	WaitTime    time.Duration // 重试之间的等待时间
 	MaxWait     time.Duration // 最大等待时间
 	Multiplier  float64       // 指数退避的倍数（默认 2.0）
+	// Retryable 判断错误是否重试，为空时使用 IsTransientError
+	Retryable func(error) bool
 }
 
 // DefaultRetryConfig 返回用于重试瞬态故障的合理默认值
@@ -64,6 +66,10 @@ func RetryWithContext(ctx context.Context, config *RetryConfig, fn func() error)
 		config = DefaultRetryConfig()
 	}
 
+	retryable := config.Retryable
+	if retryable == nil {
+		retryable = IsTransientError
+	}
 	var lastErr error
 	wait := config.WaitTime
 
@@ -79,7 +85,7 @@ func RetryWithContext(ctx context.Context, config *RetryConfig, fn func() error)
 		}
 
 		// 如果是最后一次尝试或错误不是瞬态的，则不重试
-		if attempt == config.MaxAttempts-1 || !IsTransientError(lastErr) {
+		if attempt == config.MaxAttempts-1 || !retryable(lastErr) {
 			return lastErr
 		}
 
@@ -8,60 +8,29 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // lookupIPFunc is used for mocking net.LookupIP in tests
 var lookupIPFunc = net.LookupIP
 
 // ClusterConfig returns the total number of workers and the current worker's index.
-// It supports discovery via Headless Service (DNS), File, or Static env vars.
+// When a heartbeat membership is installed (see UseMembership) its stable view is used;
+// otherwise it supports discovery via Headless Service (DNS), File, or Static env vars.
+// 成员视图不包含本实例时 index 为 -1，本实例不负责任何键。
 func ClusterConfig() (int, int) {
-	// Priority 1: Headless Service (Dynamic)
-	if os.Getenv("CLUSTER_DISCOVERY") == "headless" {
-		svc := os.Getenv("CLUSTER_SVC")
-		selfIP := os.Getenv("POD_IP")
-		if svc != "" && selfIP != "" {
-			if ips, err := lookupIPFunc(svc); err == nil && len(ips) > 0 {
-				var list []string
-				for _, ip := range ips {
-					list = append(list, ip.String())
-				}
-				sort.Strings(list)
-				for i, ip := range list {
-					if ip == selfIP {
-						return len(list), i
-					}
-				}
-			}
-		}
+	if m := ActiveMembership(); m != nil {
+		return m.ClusterConfig()
 	}
 
-	// Priority 2: File Member Discovery
-	if os.Getenv("CLUSTER_DISCOVERY") == "file" {
-		path := os.Getenv("CLUSTER_FILE")
-		self := os.Getenv("POD_NAME")
-		if self == "" {
-			self = os.Getenv("HOSTNAME")
-		}
-		if path != "" && self != "" {
-			if f, err := os.Open(path); err == nil {
-				defer func() { _ = f.Close() }()
-				var members []string
-				sc := bufio.NewScanner(f)
-				for sc.Scan() {
-					line := strings.TrimSpace(sc.Text())
-					if line != "" {
-						members = append(members, line)
-					}
-				}
-				if len(members) > 0 {
-					sort.Strings(members)
-					for i, m := range members {
-						if m == self {
-							return len(members), i
-						}
-					}
-				}
+	// Priority 1/2: Headless Service (Dynamic) or File Member Discovery
+	// 以成员标识（Pod IP / 成员名）建环：新成员排序插入中间时其余成员的序号改变，但键归属不随之迁移
+	if members, self := clusterMembers(); self != "" {
+		for i, m := range members {
+			if m == self {
+				SetShardMembers(members)
+				return len(members), i
 			}
 		}
 	}
@@ -98,20 +67,475 @@ func ClusterConfig() (int, int) {
 	return total, index
 }
 
-// ShardIndex calculates the shard index for a given string key.
+// ClusterPeers 按 CLUSTER_DISCOVERY 发现全部成员（排序后）：headless 模式为 CLUSTER_SVC 解析出的 Pod IP，
+// file 模式为 CLUSTER_FILE 中的成员名；未配置或发现失败时返回 nil
+func ClusterPeers() []string {
+	members, _ := clusterMembers()
+	return members
+}
+
+// clusterMembers 返回发现的成员列表（排序后）及本实例在其中的标识（headless 为 POD_IP，file 为 POD_NAME/HOSTNAME）
+func clusterMembers() ([]string, string) {
+	switch os.Getenv("CLUSTER_DISCOVERY") {
+	case "headless":
+		svc := os.Getenv("CLUSTER_SVC")
+		if svc == "" {
+			return nil, ""
+		}
+		ips, err := lookupIPFunc(svc)
+		if err != nil || len(ips) == 0 {
+			return nil, ""
+		}
+		var list []string
+		for _, ip := range ips {
+			list = append(list, ip.String())
+		}
+		sort.Strings(list)
+		return list, os.Getenv("POD_IP")
+	case "file":
+		path := os.Getenv("CLUSTER_FILE")
+		self := os.Getenv("POD_NAME")
+		if self == "" {
+			self = os.Getenv("HOSTNAME")
+		}
+		if path == "" {
+			return nil, ""
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, ""
+		}
+		defer func() { _ = f.Close() }()
+		var members []string
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			line := strings.TrimSpace(sc.Text())
+			if line != "" {
+				members = append(members, line)
+			}
+		}
+		sort.Strings(members)
+		return members, self
+	}
+	return nil, ""
+}
+
+// 一致性哈希默认参数
+const (
+	// DefaultVirtualNodes 每个分片在哈希环上的虚拟节点数
+	DefaultVirtualNodes = 128
+	// DefaultLoadFactor 带权键分配时单个分片的负载上限（相对平均负载的倍数）
+	DefaultLoadFactor = 1.25
+)
+
+// HashRing 带虚拟节点的一致性哈希环：分片数由 N 变为 N+1 时只有约 1/(N+1) 的键迁移，
+// 其余键保持原分片，避免扩缩容后各分片的资源/标签缓存整体失效
+type HashRing struct {
+	nodes  []string
+	points []ringPoint
+}
+
+type ringPoint struct {
+	hash uint64
+	node int
+}
+
+// NewHashRing 创建哈希环，nodes 为分片标识，vnodes<=0 时使用 DefaultVirtualNodes
+func NewHashRing(nodes []string, vnodes int) *HashRing {
+	if vnodes <= 0 {
+		vnodes = DefaultVirtualNodes
+	}
+	r := &HashRing{nodes: append([]string(nil), nodes...)}
+	r.points = make([]ringPoint, 0, len(nodes)*vnodes)
+	for i, n := range nodes {
+		for v := 0; v < vnodes; v++ {
+			r.points = append(r.points, ringPoint{hash: ringHash(n + "#" + strconv.Itoa(v)), node: i})
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool {
+		if r.points[i].hash != r.points[j].hash {
+			return r.points[i].hash < r.points[j].hash
+		}
+		return r.points[i].node < r.points[j].node
+	})
+	return r
+}
+
+// ringHash FNV-64a 加 64 位混淆，使相近的键在环上分布均匀
+func ringHash(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// Nodes 返回分片标识
+func (r *HashRing) Nodes() []string {
+	return append([]string(nil), r.nodes...)
+}
+
+// Owner 返回键所属分片在 Nodes 中的下标，环为空时返回 -1
+func (r *HashRing) Owner(key string) int {
+	if len(r.points) == 0 {
+		return -1
+	}
+	return r.points[r.successor(ringHash(key))].node
+}
+
+// Owners 返回键的 r 个副本所属分片（互不相同）在 Nodes 中的下标：从键的位置沿环顺时针依次选取，
+// 第一个即 Owner；r 超过分片数时返回全部分片
+func (r *HashRing) Owners(key string, replicas int) []int {
+	if len(r.points) == 0 {
+		return nil
+	}
+	return r.walk(r.successor(ringHash(key)), replicas, nil)
+}
+
+// walk 从环上位置 start 顺时针选取 replicas 个不同分片，first 为已选定的分片
+func (r *HashRing) walk(start, replicas int, first []int) []int {
+	if replicas > len(r.nodes) {
+		replicas = len(r.nodes)
+	}
+	out := first
+	for i := 0; i < len(r.points) && len(out) < replicas; i++ {
+		n := r.points[(start+i)%len(r.points)].node
+		if !containsInt(out, n) {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+func containsInt(list []int, v int) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// successor 返回环上第一个哈希值不小于 h 的虚拟节点位置
+func (r *HashRing) successor(h uint64) int {
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i].hash >= h })
+	if i == len(r.points) {
+		i = 0
+	}
+	return i
+}
+
+// AssignWeighted 按键权重（如资源数）做有界负载分配：键按权重从大到小依次沿环顺时针选择
+// 第一个负载未超过 平均负载 × loadFactor 的分片，使大账号/大区域不集中在同一分片。
+// 分配结果只取决于输入，所有分片使用相同的权重即可得到一致的归属。
+func (r *HashRing) AssignWeighted(weights map[string]float64, loadFactor float64) map[string]int {
+	out := make(map[string]int, len(weights))
+	if len(r.nodes) == 0 || len(weights) == 0 {
+		return out
+	}
+	if loadFactor < 1 {
+		loadFactor = DefaultLoadFactor
+	}
+	keys := make([]string, 0, len(weights))
+	total, heaviest := 0.0, 0.0
+	for k, w := range weights {
+		if w <= 0 {
+			continue
+		}
+		keys = append(keys, k)
+		total += w
+		if w > heaviest {
+			heaviest = w
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if weights[keys[i]] != weights[keys[j]] {
+			return weights[keys[i]] > weights[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	capacity := total / float64(len(r.nodes)) * loadFactor
+	if capacity < heaviest {
+		capacity = heaviest
+	}
+	load := make([]float64, len(r.nodes))
+	for _, k := range keys {
+		w := weights[k]
+		start := r.successor(ringHash(k))
+		owner := r.points[start].node
+		for i := 0; i < len(r.points); i++ {
+			n := r.points[(start+i)%len(r.points)].node
+			if load[n]+w <= capacity {
+				owner = n
+				break
+			}
+		}
+		load[owner] += w
+		out[k] = owner
+	}
+	return out
+}
+
+// ShardKey 分片键的归属
+type ShardKey struct {
+	Key   string `json:"key"`
+	Owner int    `json:"owner"`
+	// Owners 副本数大于 1 时采集该键的全部分片（第一个即 Owner）
+	Owners []int   `json:"owners,omitempty"`
+	Owned  bool    `json:"owned"`
+	Weight float64 `json:"weight,omitempty"`
+}
+
+// ShardRebalance 最近一次分片数变化
+type ShardRebalance struct {
+	From  int       `json:"from"`
+	To    int       `json:"to"`
+	Moved int       `json:"moved"` // 归属发生变化的已知键数
+	Keys  int       `json:"keys"`  // 已知键总数
+	At    time.Time `json:"at"`
+}
+
+// ShardStatus 当前分片的键归属快照
+type ShardStatus struct {
+	Total         int             `json:"total"`
+	Index         int             `json:"index"`
+	VirtualNodes  int             `json:"virtual_nodes"`
+	Replicas      int             `json:"replication_factor"`
+	WeightedKeys  int             `json:"weighted_keys"`
+	Keys          []ShardKey      `json:"keys"`
+	LastRebalance *ShardRebalance `json:"last_rebalance,omitempty"`
+}
+
+// shardView 某一分片数下的哈希环与带权分配结果
+type shardView struct {
+	ring     *HashRing
+	weighted map[string]int
+}
+
+var (
+	shardMu         sync.Mutex
+	shardVNodes     = DefaultVirtualNodes
+	shardLoadFactor = DefaultLoadFactor
+	shardWeights    map[string]float64
+	// shardViews 分片数 -> 视图，配置变化时清空
+	shardViews = make(map[int]*shardView)
+	// shardSeen 本实例判断过的分片键，用于展示归属与统计迁移
+	shardSeen      = make(map[string]struct{})
+	shardLastTotal int
+	shardLastMove  *ShardRebalance
+	// shardMembers 成员 ID（按序号排列），设置后哈希环以成员 ID 而非序号建环
+	shardMembers []string
+	// shardReplicas 每个键的副本数（由几个分片同时采集），见 SetReplicationFactor
+	shardReplicas = 1
+)
+
+// ConfigureSharding 设置一致性哈希参数：vnodes 为每个分片的虚拟节点数，weights 为可选的键权重
+// （键格式同 ShouldProcess，如 AccountID|Region|Namespace），带权键按有界负载分配，其余键按哈希环归属。
+// 所有分片必须使用相同的参数与权重，否则同一键可能被重复采集或遗漏。
+func ConfigureSharding(vnodes int, loadFactor float64, weights map[string]float64) {
+	if vnodes <= 0 {
+		vnodes = DefaultVirtualNodes
+	}
+	if loadFactor < 1 {
+		loadFactor = DefaultLoadFactor
+	}
+	shardMu.Lock()
+	defer shardMu.Unlock()
+	shardVNodes = vnodes
+	shardLoadFactor = loadFactor
+	shardWeights = make(map[string]float64, len(weights))
+	for k, w := range weights {
+		if w > 0 {
+			shardWeights[k] = w
+		}
+	}
+	shardViews = make(map[int]*shardView)
+}
+
+// SetReplicationFactor 设置每个键的副本数 r（<1 视为 1）：键由哈希环上顺时针的 r 个不同分片同时采集，
+// 单个分片失效时其余副本仍有数据，由 Prometheus/Thanos 按 replica 标签去重
+func SetReplicationFactor(r int) {
+	if r < 1 {
+		r = 1
+	}
+	shardMu.Lock()
+	defer shardMu.Unlock()
+	shardReplicas = r
+}
+
+// ReplicationFactor 返回每个键的副本数：环境变量 CLUSTER_REPLICATION_FACTOR 优先（与 CLUSTER_WORKERS 一同提供），
+// 其次为 SetReplicationFactor 设置的值
+func ReplicationFactor() int {
+	if v := os.Getenv("CLUSTER_REPLICATION_FACTOR"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	shardMu.Lock()
+	defer shardMu.Unlock()
+	return shardReplicas
+}
+
+// viewLocked 返回分片数 n 的视图，调用方持有 shardMu
+func viewLocked(n int) *shardView {
+	if v, ok := shardViews[n]; ok {
+		return v
+	}
+	ring := NewHashRing(nodeNamesLocked(n), shardVNodes)
+	v := &shardView{ring: ring, weighted: ring.AssignWeighted(shardWeights, shardLoadFactor)}
+	shardViews[n] = v
+	return v
+}
+
+// nodeNamesLocked 返回分片数 n 时哈希环的分片标识：成员数等于 n 时为成员 ID，否则为序号
+func nodeNamesLocked(n int) []string {
+	if len(shardMembers) == n {
+		return shardMembers
+	}
+	nodes := make([]string, n)
+	for i := range nodes {
+		nodes[i] = strconv.Itoa(i)
+	}
+	return nodes
+}
+
+// SetShardMembers 以成员 ID 建立哈希环（members 按分片序号排列，即成员视图的排序结果）。
+// 环以成员 ID 为分片标识时，成员加入或离开只迁移与该成员相关的键，不受其余成员序号变化影响；
+// 成员变化时统计已知键中归属成员改变的数量（见 ShardSnapshot）；成员未变化时不做任何操作。
+func SetShardMembers(members []string) {
+	shardMu.Lock()
+	defer shardMu.Unlock()
+	if equalStrings(shardMembers, members) {
+		return
+	}
+	from := shardLastTotal
+	var before map[string]string
+	if from != 0 {
+		before = make(map[string]string, len(shardSeen))
+		names := nodeNamesLocked(from)
+		for key := range shardSeen {
+			before[key] = names[ownerLocked(key, from)]
+		}
+	}
+	shardMembers = append([]string(nil), members...)
+	shardViews = make(map[int]*shardView)
+	to := len(members)
+	if to < 1 {
+		return
+	}
+	shardLastTotal = to
+	if before == nil {
+		return
+	}
+	moved := 0
+	for key, owner := range before {
+		if shardMembers[ownerLocked(key, to)] != owner {
+			moved++
+		}
+	}
+	shardLastMove = &ShardRebalance{From: from, To: to, Moved: moved, Keys: len(shardSeen), At: time.Now()}
+}
+
+func (v *shardView) owner(key string) int {
+	if i, ok := v.weighted[key]; ok {
+		return i
+	}
+	return v.ring.Owner(key)
+}
+
+// owners 返回键的 replicas 个副本分片：带权键以有界负载分配的分片为首，其余副本沿环顺时针选取
+func (v *shardView) owners(key string, replicas int) []int {
+	start := v.ring.successor(ringHash(key))
+	if i, ok := v.weighted[key]; ok {
+		return v.ring.walk(start, replicas, []int{i})
+	}
+	return v.ring.walk(start, replicas, nil)
+}
+
+// ShardIndex calculates the shard index for a given string key on the consistent-hash ring.
 func ShardIndex(s string, n int) int {
 	if n <= 1 {
 		return 0
 	}
-	h := fnv.New32a()
-	_, _ = h.Write([]byte(s))
-	return int(h.Sum32() % uint32(n))
+	shardMu.Lock()
+	defer shardMu.Unlock()
+	return viewLocked(n).owner(s)
 }
 
 // ShouldProcess checks if the current worker (index) should process the given key.
+// 副本数大于 1 时键的任一副本分片都返回 true；分片数变化时统计已知键中归属改变的数量（见 ShardSnapshot）。
 func ShouldProcess(key string, total, index int) bool {
-	if total <= 1 {
+	replicas := ReplicationFactor()
+	shardMu.Lock()
+	defer shardMu.Unlock()
+	shardSeen[key] = struct{}{}
+	if total < 1 {
+		total = 1
+	}
+	if shardLastTotal != 0 && shardLastTotal != total {
+		recordRebalanceLocked(shardLastTotal, total)
+	}
+	shardLastTotal = total
+	if index < 0 {
+		return false
+	}
+	if total == 1 {
 		return true
 	}
-	return ShardIndex(key, total) == index
+	if replicas > 1 {
+		return containsInt(viewLocked(total).owners(key, replicas), index)
+	}
+	return viewLocked(total).owner(key) == index
+}
+
+// recordRebalanceLocked 统计分片数由 from 变为 to 时迁移的已知键，调用方持有 shardMu
+func recordRebalanceLocked(from, to int) {
+	moved := 0
+	for key := range shardSeen {
+		if ownerLocked(key, from) != ownerLocked(key, to) {
+			moved++
+		}
+	}
+	shardLastMove = &ShardRebalance{From: from, To: to, Moved: moved, Keys: len(shardSeen), At: time.Now()}
+}
+
+func ownerLocked(key string, n int) int {
+	if n <= 1 {
+		return 0
+	}
+	return viewLocked(n).owner(key)
+}
+
+// ShardSnapshot 返回本实例判断过的分片键在 total/index 下的归属，按键排序；ownedOnly 时只返回本分片的键
+func ShardSnapshot(total, index int, ownedOnly bool) ShardStatus {
+	if total < 1 {
+		total = 1
+	}
+	replicas := ReplicationFactor()
+	shardMu.Lock()
+	defer shardMu.Unlock()
+	st := ShardStatus{Total: total, Index: index, VirtualNodes: shardVNodes, Replicas: replicas, WeightedKeys: len(shardWeights)}
+	if shardLastMove != nil {
+		m := *shardLastMove
+		st.LastRebalance = &m
+	}
+	st.Keys = make([]ShardKey, 0, len(shardSeen))
+	for key := range shardSeen {
+		k := ShardKey{Key: key, Owner: ownerLocked(key, total), Weight: shardWeights[key]}
+		k.Owned = k.Owner == index
+		if replicas > 1 && total > 1 {
+			k.Owners = viewLocked(total).owners(key, replicas)
+			k.Owned = containsInt(k.Owners, index)
+		}
+		if ownedOnly && !k.Owned {
+			continue
+		}
+		st.Keys = append(st.Keys, k)
+	}
+	sort.Slice(st.Keys, func(i, j int) bool { return st.Keys[i].Key < st.Keys[j].Key })
+	return st
 }
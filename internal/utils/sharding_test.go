@@ -1,8 +1,10 @@
 package utils
 
 import (
+	"fmt"
 	"net"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -13,8 +15,8 @@ func TestShardIndex(t *testing.T) {
 		expected int
 	}{
 		{"test", 1, 0},
-		{"key1", 2, 0}, // fnv32a("key1") % 2
-		{"key2", 2, 1}, // fnv32a("key2") % 2
+		{"key1", 2, 0},
+		{"key2", 2, 1},
 	}
 
 	for _, tt := range tests {
@@ -102,6 +104,7 @@ func TestClusterConfig_File(t *testing.T) {
 	t.Setenv("CLUSTER_DISCOVERY", "file")
 	t.Setenv("CLUSTER_FILE", tmpfile.Name())
 	t.Setenv("POD_NAME", "pod-1")
+	t.Cleanup(func() { SetShardMembers(nil) })
 
 	total, index := ClusterConfig()
 	if total != 3 || index != 1 {
@@ -127,9 +130,200 @@ func TestClusterConfig_Headless(t *testing.T) {
 	t.Setenv("CLUSTER_DISCOVERY", "headless")
 	t.Setenv("CLUSTER_SVC", "headless-svc")
 	t.Setenv("POD_IP", "10.0.0.2")
+	t.Cleanup(func() { SetShardMembers(nil) })
 
 	total, index := ClusterConfig()
 	if total != 3 || index != 1 {
 		t.Errorf("ClusterConfig() = (%d, %d); want (3, 1)", total, index)
 	}
 }
+
+func TestClusterConfig_FileMemberInsertedMidOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "members")
+	t.Setenv("CLUSTER_DISCOVERY", "file")
+	t.Setenv("CLUSTER_FILE", path)
+	t.Setenv("POD_NAME", "pod-1")
+	t.Cleanup(func() { SetShardMembers(nil) })
+
+	const keys = 10000
+	owners := func(members string) map[string]string {
+		if err := os.WriteFile(path, []byte(members), 0644); err != nil {
+			t.Fatal(err)
+		}
+		total, _ := ClusterConfig()
+		peers := ClusterPeers()
+		out := make(map[string]string, keys)
+		for i := 0; i < keys; i++ {
+			key := fmt.Sprintf("acc-%d|cn-region-%d|ns-%d", i%97, i%13, i)
+			out[key] = peers[ShardIndex(key, total)]
+		}
+		return out
+	}
+	before := owners("pod-1\npod-2\npod-3\npod-4\npod-5\npod-6\npod-7\npod-8\npod-9\n")
+	// pod-10 排序在 pod-1 与 pod-2 之间，其后成员的序号全部后移
+	after := owners("pod-1\npod-2\npod-3\npod-4\npod-5\npod-6\npod-7\npod-8\npod-9\npod-10\n")
+	moved := 0
+	for key, owner := range before {
+		if after[key] != owner {
+			moved++
+			if after[key] != "pod-10" {
+				t.Fatalf("key %q moved between existing members %s -> %s", key, owner, after[key])
+			}
+		}
+	}
+	// 9 -> 10 个成员时理想迁移比例为 1/10
+	if moved < keys/20 || moved > keys*3/20 {
+		t.Fatalf("expected about 10%% of keys to move, got %d/%d", moved, keys)
+	}
+}
+
+func TestHashRing_MinimalMovement(t *testing.T) {
+	nodes := func(n int) []string {
+		out := make([]string, n)
+		for i := range out {
+			out[i] = fmt.Sprint(i)
+		}
+		return out
+	}
+	r3 := NewHashRing(nodes(3), 0)
+	r4 := NewHashRing(nodes(4), 0)
+	const keys = 10000
+	moved := 0
+	counts := make([]int, 4)
+	for i := 0; i < keys; i++ {
+		key := fmt.Sprintf("acc-%d|cn-region-%d|ns-%d", i%97, i%13, i)
+		before, after := r3.Owner(key), r4.Owner(key)
+		if before != after {
+			moved++
+			if after != 3 {
+				t.Fatalf("key %q moved between existing shards %d -> %d", key, before, after)
+			}
+		}
+		counts[after]++
+	}
+	// 3 -> 4 个分片时理想迁移比例为 1/4
+	if moved < keys/5 || moved > keys*3/10 {
+		t.Fatalf("expected about 25%% of keys to move, got %d/%d", moved, keys)
+	}
+	for i, c := range counts {
+		if c < keys/4*7/10 || c > keys/4*13/10 {
+			t.Fatalf("shard %d holds %d keys, ring is unbalanced: %v", i, c, counts)
+		}
+	}
+}
+
+func TestHashRing_AssignWeighted(t *testing.T) {
+	r := NewHashRing([]string{"0", "1", "2"}, 0)
+	weights := map[string]float64{"big-a": 100, "big-b": 100, "big-c": 100}
+	for i := 0; i < 30; i++ {
+		weights[fmt.Sprintf("small-%d", i)] = 10
+	}
+	assigned := r.AssignWeighted(weights, 1.1)
+	if len(assigned) != len(weights) {
+		t.Fatalf("every weighted key should be assigned, got %d", len(assigned))
+	}
+	load := make([]float64, 3)
+	for k, n := range assigned {
+		load[n] += weights[k]
+	}
+	for i, l := range load {
+		if l > 200*1.1 {
+			t.Fatalf("shard %d exceeds the bounded load: %v", i, load)
+		}
+	}
+	// 同等权重的大键分散到不同分片
+	heavy := r.AssignWeighted(map[string]float64{"big-a": 100, "big-b": 100, "big-c": 100}, 1.1)
+	if heavy["big-a"] == heavy["big-b"] || heavy["big-b"] == heavy["big-c"] || heavy["big-a"] == heavy["big-c"] {
+		t.Fatalf("heavy keys should land on different shards, got %v", heavy)
+	}
+	// 分配结果只取决于输入
+	again := r.AssignWeighted(weights, 1.1)
+	for k, n := range assigned {
+		if again[k] != n {
+			t.Fatalf("assignment must be deterministic, key %q: %d vs %d", k, n, again[k])
+		}
+	}
+}
+
+func TestShardSnapshot_Rebalance(t *testing.T) {
+	ConfigureSharding(0, 0, map[string]float64{"acc|r1|ns-heavy": 50})
+	t.Cleanup(func() { ConfigureSharding(0, 0, nil) })
+
+	keys := []string{"acc|r1|ns-heavy", "acc|r1|ns-a", "acc|r2|ns-b", "acc|r3|ns-c"}
+	owned := 0
+	for i := 0; i < 3; i++ {
+		for _, k := range keys {
+			if ShouldProcess(k, 3, i) {
+				owned++
+			}
+		}
+	}
+	if owned != len(keys) {
+		t.Fatalf("each key should be owned by exactly one shard, got %d", owned)
+	}
+	for _, k := range keys {
+		ShouldProcess(k, 4, 0)
+	}
+	st := ShardSnapshot(4, 0, false)
+	if st.LastRebalance == nil || st.LastRebalance.From != 3 || st.LastRebalance.To != 4 {
+		t.Fatalf("expected a 3 -> 4 rebalance, got %+v", st.LastRebalance)
+	}
+	if st.WeightedKeys != 1 {
+		t.Fatalf("weighted keys expected 1, got %d", st.WeightedKeys)
+	}
+	for _, k := range st.Keys {
+		if k.Owned != (k.Owner == 0) || (k.Key == "acc|r1|ns-heavy" && k.Weight != 50) {
+			t.Fatalf("unexpected key status %+v", k)
+		}
+	}
+	mine := ShardSnapshot(4, 0, true)
+	for _, k := range mine.Keys {
+		if !k.Owned {
+			t.Fatalf("ownedOnly snapshot returned a foreign key %+v", k)
+		}
+	}
+}
+
+func TestShouldProcess_Replicated(t *testing.T) {
+	t.Setenv("CLUSTER_REPLICATION_FACTOR", "2")
+	const total = 4
+	r := NewHashRing([]string{"0", "1", "2", "3"}, 0)
+	for i := 0; i < 200; i++ {
+		key := fmt.Sprintf("acc|r%d|ns", i)
+		owners := 0
+		for idx := 0; idx < total; idx++ {
+			if ShouldProcess(key, total, idx) {
+				owners++
+			}
+		}
+		if owners != 2 {
+			t.Fatalf("key %q collected by %d shards, want 2", key, owners)
+		}
+		if !ShouldProcess(key, total, r.Owner(key)) {
+			t.Fatalf("primary owner of %q should collect it", key)
+		}
+	}
+	if got := NewHashRing([]string{"0", "1"}, 0).Owners("k", 3); len(got) != 2 || got[0] == got[1] {
+		t.Fatalf("Owners should cap at the shard count with distinct shards, got %v", got)
+	}
+}
+
+func TestClusterPeers(t *testing.T) {
+	path := t.TempDir() + "/members"
+	if err := os.WriteFile(path, []byte("pod-2\npod-0\n\npod-1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CLUSTER_DISCOVERY", "file")
+	t.Setenv("CLUSTER_FILE", path)
+	t.Setenv("POD_NAME", "")
+	t.Setenv("HOSTNAME", "")
+
+	peers := ClusterPeers()
+	if len(peers) != 3 || peers[0] != "pod-0" || peers[2] != "pod-2" {
+		t.Fatalf("ClusterPeers() = %v", peers)
+	}
+	t.Setenv("CLUSTER_DISCOVERY", "")
+	if peers := ClusterPeers(); peers != nil {
+		t.Fatalf("ClusterPeers() without discovery = %v", peers)
+	}
+}